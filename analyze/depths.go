@@ -0,0 +1,36 @@
+package analyze
+
+import (
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// autoDepthLevels maps the number of changed files under a top-level
+// directory to its depth. Ordered descending so first match wins.
+var autoDepthLevels = []struct{ minFiles, depth int }{
+	{40, 4}, {12, 3}, {4, 2}, {0, 1},
+}
+
+// AutoDepths picks a depth for each top-level directory from how many files
+// changed under it: a handful fold into the directory's totals, while large
+// changes keep more levels so they are not lumped into one line.
+func AutoDepths(stats *diff.DiffStats) diff.DirDepths {
+	counts := make(map[string]int)
+	for _, f := range stats.Files {
+		if top, _, found := strings.Cut(f.Path, "/"); found {
+			counts[top]++
+		}
+	}
+
+	depths := make(diff.DirDepths, len(counts))
+	for dir, n := range counts {
+		for _, l := range autoDepthLevels {
+			if n >= l.minFiles {
+				depths[dir] = l.depth
+				break
+			}
+		}
+	}
+	return depths
+}
@@ -0,0 +1,25 @@
+package analyze
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestAutoDepths(t *testing.T) {
+	stats := &diff.DiffStats{Files: []diff.FileStat{{Path: "README.md", Additions: 1}, {Path: "docs/a/guide.md", Additions: 3}}}
+	for i := range 5 {
+		stats.Files = append(stats.Files, diff.FileStat{Path: fmt.Sprintf("cmd/tool/f%d.go", i), Additions: 1})
+	}
+	for i := range 45 {
+		stats.Files = append(stats.Files, diff.FileStat{Path: fmt.Sprintf("internal/pkg%d/x/y.go", i%9), Additions: 2})
+	}
+	stats.TotalFiles = len(stats.Files)
+
+	want := diff.DirDepths{"docs": 1, "cmd": 2, "internal": 4}
+	if got := AutoDepths(stats); !reflect.DeepEqual(got, want) {
+		t.Errorf("AutoDepths() = %v, want %v", got, want)
+	}
+}
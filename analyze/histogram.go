@@ -0,0 +1,58 @@
+package analyze
+
+import (
+	"sort"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// Bucket is one file-size range of a change-size histogram.
+type Bucket struct {
+	Label string // e.g., "11-50"
+	Min   int    // Smallest changed-line count in the bucket
+	Max   int    // Largest, or 0 for the open-ended last bucket
+	Files int    // Files whose changed lines fall in the range
+	Lines int    // Changed lines (added plus deleted) in those files
+}
+
+// SizeBuckets are the ranges ChangeHistogram sorts files into.
+var SizeBuckets = []Bucket{
+	{Label: "1-10", Min: 1, Max: 10},
+	{Label: "11-50", Min: 11, Max: 50},
+	{Label: "51-200", Min: 51, Max: 200},
+	{Label: "201+", Min: 201},
+}
+
+// Histogram is the distribution of per-file change sizes in a diff.
+type Histogram struct {
+	Buckets   []Bucket // SizeBuckets with their counts filled in
+	Unchanged int      // Files with no line changes (binary, mode-only)
+	Median    int      // Median changed lines per file with line changes
+}
+
+// ChangeHistogram sorts the files of stats into SizeBuckets by changed lines,
+// telling many small edits apart from a few large rewrites.
+func ChangeHistogram(stats *diff.DiffStats) Histogram {
+	h := Histogram{Buckets: append([]Bucket(nil), SizeBuckets...)}
+	var sizes []int
+	for _, f := range stats.Files {
+		n := f.Additions + f.Deletions
+		if n == 0 {
+			h.Unchanged++
+			continue
+		}
+		sizes = append(sizes, n)
+		for i := range h.Buckets {
+			if b := &h.Buckets[i]; n >= b.Min && (b.Max == 0 || n <= b.Max) {
+				b.Files++
+				b.Lines += n
+				break
+			}
+		}
+	}
+	if len(sizes) > 0 {
+		sort.Ints(sizes)
+		h.Median = sizes[len(sizes)/2]
+	}
+	return h
+}
@@ -0,0 +1,35 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestChangeHistogram(t *testing.T) {
+	stats := &diff.DiffStats{Files: []diff.FileStat{
+		{Path: "a.go", Additions: 1},
+		{Path: "b.go", Additions: 6, Deletions: 4},
+		{Path: "c.go", Additions: 11},
+		{Path: "d.go", Additions: 150, Deletions: 50},
+		{Path: "e.go", Additions: 201},
+		{Path: "logo.png", IsBinary: true},
+	}}
+
+	h := ChangeHistogram(stats)
+	want := []struct{ files, lines int }{{2, 11}, {1, 11}, {1, 200}, {1, 201}}
+	for i, w := range want {
+		if b := h.Buckets[i]; b.Files != w.files || b.Lines != w.lines {
+			t.Errorf("bucket %s = %d files, %d lines; want %d, %d", b.Label, b.Files, b.Lines, w.files, w.lines)
+		}
+	}
+	if h.Unchanged != 1 {
+		t.Errorf("Unchanged = %d, want 1", h.Unchanged)
+	}
+	if h.Median != 11 {
+		t.Errorf("Median = %d, want 11", h.Median)
+	}
+	if SizeBuckets[0].Files != 0 {
+		t.Errorf("ChangeHistogram modified SizeBuckets")
+	}
+}
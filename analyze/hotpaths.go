@@ -0,0 +1,142 @@
+// Package analyze answers questions about a diff as a whole, such as where
+// review attention should go, on top of the per-file stats in package diff.
+package analyze
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// Coverage is the share of total churn (added plus deleted lines) after
+// which HotPaths stops adding subtrees.
+const Coverage = 0.8
+
+// focusShare is the share of a subtree's churn one child must hold for
+// HotPaths to narrow the subtree to that child.
+const focusShare = 0.8
+
+// HotPath is a directory subtree (or a single file) that concentrates churn.
+// The hot paths HotPaths returns never overlap.
+type HotPath struct {
+	Path    string  // Repo-relative; directories end in "/"
+	Adds    int     // Lines added in the subtree
+	Dels    int     // Lines deleted in the subtree
+	Files   int     // Changed files in the subtree
+	Percent float64 // Share of the diff's total churn, 0-100
+}
+
+// HotPaths returns up to k subtrees that together cover at least Coverage of
+// the diff's churn, largest first. It is a greedy cover: each step takes the
+// uncovered subtree with the most churn, narrowed while one child holds
+// focusShare of it, so src/api/handlers/ wins over src/ when that is where
+// the changes are. What the narrowing leaves behind stays in the running for
+// later steps. Fewer than k come back once Coverage is reached. Binary files
+// carry no churn and never appear.
+func HotPaths(stats *diff.DiffStats, k int) []HotPath {
+	root := buildTree(stats.Files)
+	if k <= 0 || root.churn() == 0 {
+		return nil
+	}
+	total := root.churn()
+
+	frontier := root.sortedChildren()
+	var result []HotPath
+	covered := 0
+	for len(result) < k && len(frontier) > 0 && float64(covered) < Coverage*float64(total) {
+		best := frontier[0]
+		frontier = frontier[1:]
+		if best.churn() == 0 {
+			break
+		}
+
+		// Narrow to a dominant child; the siblings passed over go back into
+		// the frontier
+		for {
+			children := best.sortedChildren()
+			if len(children) == 0 || float64(children[0].churn()) < focusShare*float64(best.churn()) {
+				break
+			}
+			frontier = append(frontier, children[1:]...)
+			best = children[0]
+		}
+
+		covered += best.churn()
+		result = append(result, HotPath{
+			Path:    best.path,
+			Adds:    best.adds,
+			Dels:    best.dels,
+			Files:   best.files,
+			Percent: 100 * float64(best.churn()) / float64(total),
+		})
+		sortNodes(frontier)
+	}
+	return result
+}
+
+// node is a directory or file in the churn tree.
+type node struct {
+	path       string
+	adds, dels int
+	files      int
+	children   map[string]*node
+}
+
+func (n *node) churn() int {
+	return n.adds + n.dels
+}
+
+// sortedChildren returns n's children, most churn first.
+func (n *node) sortedChildren() []*node {
+	children := make([]*node, 0, len(n.children))
+	for _, c := range n.children {
+		children = append(children, c)
+	}
+	sortNodes(children)
+	return children
+}
+
+// sortNodes orders nodes by churn, then path for stable output.
+func sortNodes(nodes []*node) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].churn() != nodes[j].churn() {
+			return nodes[i].churn() > nodes[j].churn()
+		}
+		return nodes[i].path < nodes[j].path
+	})
+}
+
+// buildTree sums each file's churn into its ancestors. Renamed files count
+// under their new path.
+func buildTree(files []diff.FileStat) *node {
+	root := &node{children: map[string]*node{}}
+	for _, f := range files {
+		p := f.Path
+		if newPath, ok := diff.RenameTarget(p); ok {
+			p = newPath
+		}
+		adds, dels := f.Additions, f.Deletions
+		if f.IsBinary {
+			adds, dels = 0, 0
+		}
+
+		parts := strings.Split(p, "/")
+		n := root
+		n.adds, n.dels, n.files = n.adds+adds, n.dels+dels, n.files+1
+		for i, part := range parts {
+			child := n.children[part]
+			if child == nil {
+				child = &node{path: strings.Join(parts[:i+1], "/")}
+				if i < len(parts)-1 {
+					child.path += "/"
+					child.children = map[string]*node{}
+				}
+				n.children[part] = child
+			}
+			child.adds, child.dels, child.files = child.adds+adds, child.dels+dels, child.files+1
+			n = child
+		}
+	}
+	return root
+}
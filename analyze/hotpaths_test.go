@@ -0,0 +1,68 @@
+package analyze
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestHotPaths(t *testing.T) {
+	stats := &diff.DiffStats{Files: []diff.FileStat{
+		{Path: "src/api/handlers/a.go", Additions: 300, Deletions: 40},
+		{Path: "src/api/handlers/b.go", Additions: 250},
+		{Path: "src/api/routes.go", Additions: 10},
+		{Path: "src/util/x.go", Additions: 20},
+		{Path: "docs/guide.md", Additions: 100, Deletions: 50},
+		{Path: "docs/{old.md => new.md}", Deletions: 40},
+		{Path: "README.md", Additions: 5},
+		{Path: "logo.png", IsBinary: true},
+	}}
+
+	got := paths(HotPaths(stats, 5))
+	// src/ narrows to handlers/ (590 of 620); docs/ splits evenly and stays
+	// whole. Together they pass 80% of 815, so the rest is left out.
+	want := []string{"src/api/handlers/", "docs/"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HotPaths = %q, want %q", got, want)
+	}
+
+	hot := HotPaths(stats, 1)
+	if len(hot) != 1 || hot[0].Adds != 550 || hot[0].Dels != 40 || hot[0].Files != 2 {
+		t.Fatalf("HotPaths(k=1) = %+v, want handlers/ with +550 -40 in 2 files", hot)
+	}
+	if hot[0].Percent < 72.3 || hot[0].Percent > 72.4 {
+		t.Errorf("Percent = %.2f, want 590/815", hot[0].Percent)
+	}
+}
+
+func TestHotPaths_RevisitsPassedOverSiblings(t *testing.T) {
+	stats := &diff.DiffStats{Files: []diff.FileStat{
+		{Path: "src/big/a.go", Additions: 700},
+		{Path: "src/small/b.go", Additions: 150},
+		{Path: "other/c.go", Additions: 40},
+	}}
+
+	// src/ narrows to big/, leaving small/ to compete with other/
+	if got, want := paths(HotPaths(stats, 3)), []string{"src/big/a.go", "src/small/b.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("HotPaths = %q, want %q", got, want)
+	}
+}
+
+func TestHotPaths_Empty(t *testing.T) {
+	if got := HotPaths(&diff.DiffStats{}, 3); got != nil {
+		t.Errorf("HotPaths(no files) = %v, want nil", got)
+	}
+	binary := &diff.DiffStats{Files: []diff.FileStat{{Path: "a.png", IsBinary: true}}}
+	if got := HotPaths(binary, 3); got != nil {
+		t.Errorf("HotPaths(binary only) = %v, want nil", got)
+	}
+}
+
+func paths(hot []HotPath) []string {
+	var result []string
+	for _, h := range hot {
+		result = append(result, h.Path)
+	}
+	return result
+}
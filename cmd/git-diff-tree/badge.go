@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+// badgeFlag is --badge (plain) or --badge=json.
+type badgeFlag struct {
+	set  bool
+	json bool
+}
+
+func (f *badgeFlag) String() string {
+	switch {
+	case f == nil || !f.set:
+		return ""
+	case f.json:
+		return "json"
+	}
+	return "plain"
+}
+
+func (f *badgeFlag) Set(s string) error {
+	switch s {
+	case "true", "plain":
+		f.set, f.json = true, false
+	case "json":
+		f.set, f.json = true, true
+	case "false":
+		f.set = false
+	default:
+		return fmt.Errorf("unknown badge format %q (valid: plain, json)", s)
+	}
+	return nil
+}
+
+// IsBoolFlag lets --badge stand alone.
+func (f *badgeFlag) IsBoolFlag() bool { return true }
+
+// runBadge prints the working tree badge for shell prompts, using the stats
+// cache so repeated prompts stay fast.
+func runBadge(badge badgeFlag, args []string, verbose bool) {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "error: --badge describes the working tree; it takes no revisions or paths")
+		os.Exit(2)
+	}
+	stats, warnings, err := diff.CachedWorktreeStats()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	printWarnings(warnings, verbose)
+
+	if redactPaths {
+		stats = stats.Redacted()
+	}
+	r := render.NewBadgeRenderer(stdout())
+	r.JSON = badge.json
+	r.Render(stats)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+// runBranches prints a one-line summary per local branch.
+func runBranches(w io.Writer, useColor, verbose bool) {
+	entries, warnings, err := diff.ListBranches()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	printWarnings(warnings, verbose)
+
+	if redactPaths {
+		for i := range entries {
+			entries[i].Name = diff.RedactName(entries[i].Name)
+			if entries[i].Base != "" {
+				entries[i].Base = diff.RedactName(entries[i].Base)
+			}
+			if entries[i].Stats != nil {
+				entries[i].Stats = entries[i].Stats.Redacted()
+			}
+		}
+	}
+	render.NewBranchesRenderer(w, useColor).RenderBranches(entries)
+}
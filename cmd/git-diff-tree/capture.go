@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+// captureSVGTerm is the --capture format for SVG terminal screenshots.
+const captureSVGTerm = "svg-term"
+
+// parseCapture splits a --capture FORMAT=FILE value, returning the file
+// ("" when the flag is unset).
+func parseCapture(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	format, path, ok := strings.Cut(s, "=")
+	if !ok || path == "" {
+		return "", fmt.Errorf("want %s=FILE, got %q", captureSVGTerm, s)
+	}
+	if format != captureSVGTerm {
+		return "", fmt.Errorf("unknown format %q (valid: %s)", format, captureSVGTerm)
+	}
+	return path, nil
+}
+
+// writeScreenshot saves ANSI output as an SVG terminal screenshot at path.
+func writeScreenshot(path, ansi string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = render.WriteTerminalSVG(file, ansi)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// clipboardCommands are native clipboard tools, tried in order.
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+}
+
+// errNoClipboard means neither a terminal nor a clipboard tool was available.
+var errNoClipboard = errors.New("no clipboard available (need a terminal with OSC 52, or pbcopy, wl-copy, xclip, or xsel)")
+
+// copyToClipboard copies text to the system clipboard. It sends an OSC 52
+// sequence when stderr is a terminal (works over SSH in most terminals) and
+// also pipes text to the native clipboard tools on PATH, in order, until one
+// succeeds. It fails only when nothing copied the text.
+func copyToClipboard(text string) error {
+	copied := false
+	if term.IsTerminal(int(os.Stderr.Fd())) {
+		writeOSC52(os.Stderr, text, os.Getenv("TMUX") != "")
+		copied = true
+	}
+
+	var errs []error
+	for _, args := range clipboardCommands {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", args[0], err))
+			continue
+		}
+		return nil
+	}
+
+	switch {
+	case copied:
+		return nil
+	case len(errs) > 0:
+		return errors.Join(errs...)
+	default:
+		return errNoClipboard
+	}
+}
+
+// writeOSC52 writes the OSC 52 "set clipboard" sequence for text.
+// Inside tmux the sequence is wrapped in a DCS passthrough.
+func writeOSC52(w io.Writer, text string, tmux bool) {
+	seq := "\033]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if tmux {
+		seq = "\033Ptmux;\033" + seq + "\033\\"
+	}
+	fmt.Fprint(w, seq)
+}
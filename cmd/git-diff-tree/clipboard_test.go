@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"golang.org/x/term"
+)
+
+func TestWriteOSC52(t *testing.T) {
+	var buf bytes.Buffer
+	writeOSC52(&buf, "hi", false)
+	if got, want := buf.String(), "\033]52;c;aGk=\a"; got != want {
+		t.Errorf("writeOSC52() = %q, want %q", got, want)
+	}
+
+	// tmux passes the sequence through a DCS with its ESC doubled
+	buf.Reset()
+	writeOSC52(&buf, "hi", true)
+	if got, want := buf.String(), "\033Ptmux;\033\033]52;c;aGk=\a\033\\"; got != want {
+		t.Errorf("writeOSC52(tmux) = %q, want %q", got, want)
+	}
+}
+
+func TestCopyToClipboard_Fallback(t *testing.T) {
+	for _, tool := range []string{"true", "false"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not installed", tool)
+		}
+	}
+	if term.IsTerminal(int(os.Stderr.Fd())) {
+		t.Skip("stderr is a terminal, so OSC 52 always copies")
+	}
+	saved := clipboardCommands
+	t.Cleanup(func() { clipboardCommands = saved })
+
+	// A failing tool falls through to the next one
+	clipboardCommands = [][]string{{"no-such-clipboard-tool"}, {"false"}, {"true"}}
+	if err := copyToClipboard("text"); err != nil {
+		t.Errorf("copyToClipboard() = %v, want nil once a later tool succeeds", err)
+	}
+
+	clipboardCommands = [][]string{{"false"}}
+	if err := copyToClipboard("text"); err == nil || !strings.HasPrefix(err.Error(), "false:") {
+		t.Errorf("copyToClipboard() = %v, want the failing tool's error", err)
+	}
+
+	clipboardCommands = [][]string{{"no-such-clipboard-tool"}}
+	if err := copyToClipboard("text"); !errors.Is(err, errNoClipboard) {
+		t.Errorf("copyToClipboard() = %v, want errNoClipboard", err)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kylesnowschwartz/diff-viz/config"
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+// explainConfig prints --explain-config: each of mode's settings, the layer
+// that set it, and whether the mode uses it at all. An auto width shows the
+// terminal width it detects.
+func explainConfig(w io.Writer, cfg *config.Config, mode string, cliFlags *config.ModeConfig) {
+	settings := cfg.Explain(mode, cliFlags)
+	info, _ := render.LookupMode(mode)
+
+	values := make([]string, len(settings))
+	keyWidth, valueWidth := 0, 0
+	for i, s := range settings {
+		values[i] = s.Value
+		if s.Key == "width" && s.Value == "auto" {
+			values[i] = fmt.Sprintf("auto (%d columns)", getTerminalWidth(config.DefaultWidth, true))
+		}
+		keyWidth = max(keyWidth, len(s.Key))
+		valueWidth = max(valueWidth, len(values[i]))
+	}
+
+	fmt.Fprintf(w, "%s mode settings:\n", mode)
+	for i, s := range settings {
+		line := fmt.Sprintf("  %-*s  %-*s  %s", keyWidth, s.Key, valueWidth, values[i], s.Source)
+		if !info.Supports(s.Key) {
+			line += " (unused by " + mode + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+// runFileHistory charts one file's changes over its last n commits,
+// following renames (-m filehistory PATH).
+func runFileHistory(w io.Writer, args []string, n int, useColor, verbose bool) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "error: filehistory mode requires one PATH argument")
+		os.Exit(1)
+	}
+	path, err := diff.RepoPath(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	commits, warnings, err := diff.FileHistory(path, n)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	printWarnings(warnings, verbose)
+
+	render.NewFileTimelineRenderer(w, useColor).RenderTimeline(shownPath(path), redactCommits(commits))
+}
+
+// shownPath returns p as outputs show it: hashed under --redact-paths, else
+// quoted like git when it holds control characters.
+func shownPath(p string) string {
+	if redactPaths {
+		return diff.RedactPath(p)
+	}
+	return diff.QuotePath(p)
+}
+
+// redactCommits hashes commit paths and drops subjects under
+// --redact-paths; otherwise it returns commits unchanged.
+func redactCommits(commits []diff.FileCommit) []diff.FileCommit {
+	if !redactPaths {
+		return commits
+	}
+	redacted := make([]diff.FileCommit, len(commits))
+	for i, c := range commits {
+		c.Path = diff.RedactPath(c.Path)
+		c.Subject = ""
+		redacted[i] = c
+	}
+	return redacted
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/config"
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+// runHelpPreview ends --help with each of modes rendered on
+// render.PreviewStats, so modes can be compared without a repository or
+// changes. Output goes to stderr with the usage, in the colors a real run
+// would use (--no-color, --color-profile, and the config file's settings),
+// and each preview is followed by a legend of its colors.
+func runHelpPreview(modes []string, configPath, profileName string, flags renderFlags) {
+	for _, m := range modes {
+		if !render.IsValidMode(m) {
+			fmt.Fprintf(os.Stderr, "unknown mode: %s (valid: %s)\n", m, strings.Join(render.ModeNames(), ", "))
+			os.Exit(1)
+		}
+	}
+
+	// Help should show even with a broken config or flag; fall back to defaults
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		cfg = nil
+	}
+	profile := render.DetectColorProfile()
+	if p, err := render.ParseColorProfile(profileName); err == nil && profileName != "auto" {
+		profile = p
+	}
+	w := render.NewProfileWriter(os.Stderr, profile)
+
+	stats := render.PreviewStats()
+	fmt.Fprintf(w, "\nPreview (sample diff of %d files; -h -m MODE[,MODE] to pick modes):\n", stats.TotalFiles)
+	for _, mode := range modes {
+		opts, err := newRenderOptions(cfg.Resolve(mode, nil), flags)
+		if err != nil {
+			var defaults *config.Config
+			opts, _ = newRenderOptions(defaults.Resolve(mode, nil), flags)
+		}
+		opts.out = w
+		fmt.Fprintf(w, "\n=== %s ===\n", mode)
+		if info, _ := render.LookupMode(mode); info.Standalone {
+			fmt.Fprintf(w, "(no preview: %s charts repository state, not a diff)\n", mode)
+		} else {
+			getRenderer(mode, opts).Render(stats)
+		}
+		writeModeLegend(w, mode, opts)
+	}
+}
+
+// writeModeLegend writes mode's color legend, with the bracket palette the
+// preview actually used.
+func writeModeLegend(w io.Writer, mode string, opts renderOptions) {
+	info, _ := render.LookupMode(mode)
+	roles := info.Colors
+	if mode == "brackets" && len(roles) > 0 {
+		switch {
+		case opts.noRainbow:
+			roles[0].Colors = render.PlainBracketColors
+		case len(opts.bracketColors) > 0:
+			roles[0].Colors = opts.bracketColors
+		}
+	}
+	render.WriteColorLegend(w, roles, opts.useColor)
+}
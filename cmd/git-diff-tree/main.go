@@ -5,10 +5,16 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/kylesnowschwartz/diff-viz/analyze"
 	"github.com/kylesnowschwartz/diff-viz/config"
 	"github.com/kylesnowschwartz/diff-viz/diff"
 	"github.com/kylesnowschwartz/diff-viz/render"
@@ -20,23 +26,57 @@ func usage() string {
 	sb.WriteString(`git-diff-tree - Hierarchical diff visualization
 
 Usage:
-  git-diff-tree [flags] [<commit> [<commit>]]
+  git-diff-tree [view] [flags] [<commit> [<commit>]]
+  git-diff-tree json [flags] [<commit> [<commit>]]   (same as --stats-json)
+  git-diff-tree demo [flags]                         (same as --demo)
+  git-diff-tree baseline TREE [flags]                (same as --stats-json --baseline TREE)
+  git-diff-tree serve [--addr ADDR] [-m MODE] [--format jsonl] [--redact-paths] [<commit> [<commit>]]
+  git-diff-tree hook print|install [--force]
+  git-diff-tree config dump|check FILE|path
+  git-diff-tree config get KEY | set KEY VALUE [--config FILE]
+  git-diff-tree track --label NAME [<commit> [<commit>]]
+  git-diff-tree publish github-pr [--pr N] [<commit> [<commit>]]
 
 Examples:
   git-diff-tree                    Working tree vs HEAD
   git-diff-tree --cached           Staged changes only
   git-diff-tree HEAD~3             Last 3 commits
   git-diff-tree main feature       Compare branches
+  git-diff-tree --upstream         Working tree vs @{upstream}
+  git-diff-tree --stash 0          Changes saved in stash@{0}
+  git-diff-tree --since-release    HEAD vs latest v* tag (--release-match GLOB)
   git-diff-tree -m smart           Compact sparkline view
   git-diff-tree --demo             Show all modes (root..HEAD)
+  git-diff-tree --demo -m smart,icicle --demo-width 60,100
+                                   Demo selected modes at several widths
   git-diff-tree --stats-json       Output raw diff stats as JSON
+  git-diff-tree --badge            Cached one-line summary for shell prompts
   git-diff-tree --config cfg.json  Use config file for mode defaults
   git-diff-tree --dump-defaults    Output default config as JSON template
+  git-diff-tree --fail-over-size L Exit non-zero if diff is larger than L
+  git-diff-tree --quickfix qf.txt  Also write hotspots for :cfile in Vim
+  git-diff-tree --format markdown  Nested list for docs (also: org, asciidoc)
+  git-diff-tree -m tree=tree.txt -m markdown=summary.md -m json=stats.json
+                                   Write several outputs from one stats pass
+  git-diff-tree track --label pr-1 main...HEAD
+                                   Record the range's size in local history
+  git-diff-tree -m trend --label pr-1
+                                   Chart how the tracked range's size evolved
+  git-diff-tree -m stashes         One line per stash: age, dirs, +/- totals
+  git-diff-tree -m branches        One line per branch vs its upstream or main, stalest first
+  git-diff-tree -m filehistory main.go
+                                   One file's changes per commit, oldest first
+  git-diff-tree --conflicts-preview main feature
+                                   Files changed on both sides since merge-base
+  git-diff-tree --intersect main..a main..b
+                                   Files both ranges touched (--union: either)
+  git-diff-tree --patch-file 0001-fix.patch
+                                   A patch from outside the repo (- for stdin)
 
 Modes:
 `)
-	for _, mode := range render.ValidModes {
-		sb.WriteString(fmt.Sprintf("  %-10s %s\n", mode, render.ModeDescriptions[mode]))
+	for _, mode := range render.Modes() {
+		sb.WriteString(fmt.Sprintf("  %-10s %s\n", mode.Name, mode.Description))
 	}
 	sb.WriteString("\nFlags:\n")
 	return sb.String()
@@ -49,31 +89,124 @@ func main() {
 		flag.PrintDefaults()
 	}
 
+	// Subcommands take their own flags
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "track":
+			runTrack(os.Args[2:])
+			return
+		case "publish":
+			runPublish(os.Args[2:])
+			return
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		case "hook":
+			runHook(os.Args[2:])
+			return
+		case "config":
+			runConfig(os.Args[2:])
+			return
+		}
+		os.Args = append(os.Args[:1], expandSubcommand(os.Args[1:])...)
+	}
+
 	// Parse flags
-	mode := flag.String("m", "tree", "Output mode (shorthand)")
-	modeLong := flag.String("mode", "tree", "Output mode: "+strings.Join(render.ValidModes, ", "))
+	var modes modeFlag
+	flag.Var(&modes, "m", "Output `MODE` (shorthand); repeat MODE=FILE to also write outputs to files")
+	flag.Var(&modes, "mode", "Output `MODE`: "+strings.Join(render.ModeNames(), ", ")+"; NAME=FILE writes a mode, outline format, numstat+, slack-blocks, speedscope, or json to FILE (repeatable)")
 	noColor := flag.Bool("no-color", false, "Disable color output")
-	width := flag.Int("width", 100, "Output width in columns (smart, icicle, brackets)")
-	depth := flag.Int("depth", 2, "Hierarchy depth (smart: 1=top-level, 2+=subdir depth; icicle: 0=unlimited)")
-	help := flag.Bool("h", false, "Show help")
+	checkThemeFlag := flag.Bool("check-theme", false, "Check configured colors for contrast against the terminal background (from COLORFGBG) and exit")
+	profileName := flag.String("color-profile", "auto", "Terminal colors: auto (from COLORTERM/TERM), truecolor, 256, 16, or none")
+	width := flag.Int("width", config.DefaultWidth, "Output width in columns (smart, icicle, brackets; default: terminal width)")
+	depth := depthFlag{n: 2}
+	maxPathDepth := flag.Int("max-path-depth", 0, "Fold directories nested more than `N` levels deep into \"…/dir\" entries under level N (tree, icicle, outlines, --export html; 0=off)")
+	rtl := flag.Bool("rtl", false, "Experimental, tree mode only: mirror the tree for right-to-left terminals (right-aligned to --width, stats first)")
+	separator := flag.String("separator", "", "Text between groups in smart and brackets output (default \" │ \"; config: separator)")
+	abbrevCounts := flag.Bool("abbrev-counts", false, "Smart --vertical and brackets: shorten counts of 1000 and more (+12.4k -3.1k); totals and JSON stay exact (config: abbrevCounts)")
+	cleanupThreshold := flag.Int("cleanup-threshold", config.DefaultCleanupThreshold, "Mark directories whose changed lines are at least `PERCENT` deletions in tree and smart output (0 = off; config: cleanupThreshold)")
+	itemSeparator := flag.String("item-separator", "", "Text between items inside a brackets group (default \",\"; config: itemSeparator)")
+	metricName := flag.String("metric", "lines", "What icicle cell widths and bar lengths measure: lines (changed lines) or files (changed files)")
+	unitsName := flag.String("units", "lines", "What tree and topn counts and bars measure: lines, bytes (blob size change, shown as B/KB/MB), or auto (bytes when most changed files are binary)")
+	flag.Var(&depth, "depth", "Show `N` directory levels; deeper paths fold into their ancestor (1=top-level, 0=unlimited, auto=per top-level directory by size; tree, smart, icicle, bars, brackets)")
+	help := flag.Bool("h", false, "Show help, ending with a preview of each mode (or of -m MODE[,MODE]) on a sample diff")
+	flag.BoolVar(help, "help", false, "Same as -h")
 	listModes := flag.Bool("list-modes", false, "List valid modes (for scripting)")
+	listJSON := flag.Bool("json", false, "With --list-modes: print mode metadata as JSON")
 	demo := flag.Bool("demo", false, "Show all visualization modes (compares HEAD to root commit)")
+	serveStdio := flag.Bool("serve-stdio", false, "Answer JSON-RPC requests (getStats, render, watch) on stdin/stdout, one per line, for editor plugins")
 	statsJSON := flag.Bool("stats-json", false, "Output raw diff stats as JSON (for programmatic consumption)")
-	baseline := flag.String("baseline", "", "Baseline tree SHA to compare against (uses current working tree)")
+	noProvenance := flag.Bool("no-provenance", false, "With --stats-json: leave out the provenance block (git commands, git version, repo root, resolved SHAs)")
+	baseline := flag.String("baseline", "", "Compare the working tree (with untracked files) against `TREE-ISH`: a saved tree SHA, branch, tag, or commit")
+	patchFile := flag.String("patch-file", "", "Visualize the unified diff in `FILE` (\"-\" for stdin), such as git format-patch output, instead of the repo's changes; needs no repository")
 	verbose := flag.Bool("v", false, "Print warnings to stderr")
 	verboseLong := flag.Bool("verbose", false, "Print warnings to stderr")
 	expand := flag.Int("expand", -1, "Expansion depth for brackets mode (-1=auto, 0=inline, 1+=expand to depth)")
 	topnCount := flag.Int("count", 5, "Number of files to show in topn mode")
-	topnSort := flag.String("sort", "total", "Sort order for topn mode (total, adds, dels)")
-	configPath := flag.String("config", "", "Path to JSON config file")
+	perDir := flag.Bool("per-dir", false, "Topn: show the top --count files of each top-level directory")
+	compareTo := flag.String("compare-to", "", "Topn: also rank files (and --per-dir directories) in RANGE and show each entry's movement (↑3, ↓1, =, new)")
+	topnSort := flag.String("sort", "total", "Sort order for topn mode (total, adds, dels, funcs)")
+	configPath := flag.String("config", "", "Path to JSON config file (default: git-diff-tree/config.json in the user config directory, if present)")
+	explain := flag.Bool("explain-config", false, "Print the selected mode's settings with the layer each came from (defaults, config, CLI) and exit")
+	autoMode := flag.Bool("auto-mode", false, "Pick the mode from the diff's size and the terminal width")
+	outputProfile := flag.String("profile", "", "Pick the mode from config profile NAME's rules (stdout a terminal or not, terminal width)")
 	dumpDefaults := flag.Bool("dump-defaults", false, "Output default config as JSON")
+	dirsOnly := flag.Bool("dirs-only", false, "Show directories only, never individual files (tree, icicle)")
+	upstream := flag.Bool("upstream", false, "Compare against the current branch's upstream (@{upstream})")
+	pushed := flag.Bool("pushed", false, "Compare against the current branch's push target (@{push})")
+	sinceRelease := flag.Bool("since-release", false, "Compare HEAD against the latest reachable release tag")
+	releaseMatch := flag.String("release-match", "v*", "Tag glob used by --since-release")
+	stashIndex := flag.Int("stash", 0, "Visualize stash entry N (stash@{N} vs its base commit)")
+	multiline := flag.Bool("multiline", false, "Smart mode: one line per top-level directory")
+	vertical := flag.Bool("vertical", false, "Smart mode: one aligned row per directory (name, +adds, -dels, files, bar) for narrow panes")
+	labelPolicy := flag.String("label-depth-policy", "", "Icicle: per-level labels, LEVEL:RULE,... with RULE full, none (legend markers), or max length; \"auto\" = "+render.LabelPolicyAuto)
+	composition := flag.Bool("composition", false, "Split additions into new-file lines (yellow) and edits to existing files (green): smart and bars bars, bars and tree directory counts")
+	scaleLegend := flag.Bool("scale-legend", false, "Smart/topn: explain block shades and bar lengths under the output (see barScale config)")
+	rootGroup := flag.String("root-group-name", config.DefaultRootGroup, "Smart/brackets: group root-level files as NAME (\"\" gives each its own group; brackets default: root)")
+	noRainbow := flag.Bool("no-rainbow", false, "Use a single dim bracket color in brackets mode")
+	quickfixPath := flag.String("quickfix", "", "Also write path:1: +A -D lines in quickfix format to FILE")
+	failOverSize := flag.String("fail-over-size", "", "Exit with status 1 if diff size class exceeds this (XS, S, M, L, XL)")
+	export := flag.String("export", "", "Write the stats for other tools instead of a mode: speedscope (flamegraph JSON, weights = changed lines), html (standalone page, click directories to expand)")
+	format := flag.String("format", "", "Output format instead of a mode: markdown, org, asciidoc (outlines), numstat+, or slack-blocks (Slack Block Kit JSON digest)")
+	label := flag.String("label", "", "History label for trend mode (see: track --label)")
+	demoWidth := flag.String("demo-width", "", "Demo: render width-sensitive modes at each width (e.g., 60,100,160)")
+	ageHeat := flag.Bool("age-heat", false, "Color files by the median age of the lines they replace (tree, topn; runs git blame)")
+	annotate := flag.String("annotate", "", "Comma-separated annotations to add (tree, topn, --stats-json): lang")
+	noPager := flag.Bool("no-pager", false, "Do not pipe long output through $PAGER")
+	capture := flag.String("capture", "", "Also save the output as a terminal screenshot: `svg-term=FILE` writes an SVG with ANSI colors preserved (for docs)")
+	copyOutput := flag.Bool("copy", false, "Also copy the output as plain text to the clipboard (OSC 52, pbcopy, wl-copy, xclip)")
+	var badge badgeFlag
+	flag.Var(&badge, "badge", "Print a one-line working tree summary for shell prompts (FILES +ADDS -DELS DIR; --badge=json for JSON), cached between calls")
+	var relative relativeFlag
+	flag.Var(&relative, "relative", "Show only paths under the current directory (or --relative=PATH), relative to it")
+	maxUntracked := flag.String("max-untracked-size", "", "Skip counting lines in untracked files larger than SIZE, marking them large (default 32MB; \"none\" = no limit)")
+	untracked := flag.Bool("untracked", false, "Add untracked files to any diff, including --cached and ranges; --untracked=false leaves them out (default: only working tree diffs)")
+	maxLines := flag.Int("max-output-lines", 0, "Cap output at N lines, folding the rest into a summary line (0 = no limit)")
+	focusFile := flag.String("file", "", "Focus on the directory containing PATH and show PATH's recent commit history")
+	focus := flag.String("focus", "", "Show only files under `DIR` (repo paths are kept; see --relative to trim them)")
+	fileHistory := flag.Int("file-history", 20, "Number of commits in the --file history sparkline and -m filehistory")
+	conflictsPreview := flag.Bool("conflicts-preview", false, "Show files changed on both BASE and BRANCH since their merge-base (args: BASE BRANCH)")
+	union := flag.Bool("union", false, "Combine the files changed in any of the RANGE args, summing shared files (args: R1 R2 ...)")
+	flag.BoolVar(&redactPaths, "redact-paths", false, "Replace path components with stable short hashes (keeping extensions) for sharing output")
+	intersect := flag.Bool("intersect", false, "Show only the files changed in every RANGE arg, summing their churn (args: R1 R2 ...)")
+	perCommit := flag.Bool("per-commit-average", false, "For commit ranges: end with the number of commits and average changed lines per commit")
 	flag.Parse()
 
 	if *help {
 		flag.Usage()
+		previewModes := render.DiffModeNames()
+		if modes.set {
+			previewModes = strings.Split(modes.mode, ",")
+		}
+		runHelpPreview(previewModes, *configPath, *profileName, renderFlags{useColor: !*noColor, noRainbow: *noRainbow})
 		os.Exit(0)
 	}
 
+	if err := checkActionFlags(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
 	if *dumpDefaults {
 		cfg := config.DefaultConfigJSON()
 		output, _ := json.MarshalIndent(cfg, "", "  ")
@@ -82,36 +215,158 @@ func main() {
 	}
 
 	if *listModes {
-		fmt.Println(strings.Join(render.ValidModes, " "))
+		if *listJSON {
+			printModesJSON()
+		} else {
+			fmt.Println(strings.Join(render.ModeNames(), " "))
+		}
 		os.Exit(0)
 	}
 
-	// Use -m if set, otherwise --mode
-	selectedMode := *modeLong
-	modeExplicitlySet := false
-	if *mode != "tree" {
-		selectedMode = *mode
-		modeExplicitlySet = true
-	} else if *modeLong != "tree" {
-		modeExplicitlySet = true
+	// Load config file (if provided) - needed for demo and regular modes
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
 	}
 
-	// Load config file (if provided) - needed for demo and regular modes
-	cfg, err := config.Load(*configPath)
+	for _, w := range render.CheckConfig(cfg) {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+
+	if *checkThemeFlag {
+		checkTheme(cfg)
+		os.Exit(0)
+	}
+	if !*noColor {
+		for _, w := range themeWarnings(cfg, render.DetectBackground()) {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+		}
+	}
+
+	if *autoMode && (modes.set || *outputProfile != "" || *format != "" || *export != "") {
+		fmt.Fprintln(os.Stderr, "error: --auto-mode cannot be combined with -m MODE, --profile, --format, or --export")
+		os.Exit(1)
+	}
+
+	// A profile picks the mode from where output goes: --profile NAME, or
+	// the config's default profile when no mode or format is given
+	outputProfileName := *outputProfile
+	if outputProfileName == "" && !modes.set && !*autoMode && *format == "" && *export == "" && cfg.HasProfile(config.DefaultProfile) {
+		outputProfileName = config.DefaultProfile
+	}
+	var profileDepth *int
+	if outputProfileName != "" {
+		rule, ok, err := cfg.SelectProfile(outputProfileName, outputContext())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: --profile: %v\n", err)
+			os.Exit(1)
+		}
+		if ok {
+			if _, err := render.ParseOutlineFormat(rule.Mode); err == nil {
+				*format = rule.Mode
+			} else {
+				modes.mode, modes.set = rule.Mode, true
+			}
+			profileDepth = rule.Depth
+		}
+	}
+
+	// -m and --mode share one value; NAME=FILE outputs are collected separately
+	selectedMode := modes.String()
+	modeExplicitlySet := modes.set || *autoMode
+	// With only file outputs, nothing is shown unless a display mode is given
+	display := modeExplicitlySet || len(modes.outputs) == 0
+	active := modes.active(selectedMode, display)
+
+	sizeThresholds, err := cfg.SizeThresholds()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	exclusions, err := cfg.Exclusions()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 
+	untrackedLimit, err := cfg.UntrackedSizeLimit()
+	if err == nil && *maxUntracked != "" {
+		untrackedLimit, err = config.ParseByteSize(*maxUntracked)
+		if err != nil {
+			err = fmt.Errorf("--max-untracked-size: %w", err)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	diff.SetMaxUntrackedSize(untrackedLimit)
+	// Only the provenance block lists git commands; serve and watch loops
+	// would otherwise keep every one
+	diff.SetRecordCommands(*statsJSON && !*noProvenance)
+	if flagWasSet("untracked") {
+		if *untracked {
+			diff.SetIncludeUntracked(diff.UntrackedInclude)
+		} else {
+			diff.SetIncludeUntracked(diff.UntrackedExclude)
+		}
+	}
+
+	// --relative is git diff's: git filters the paths and trims them
+	relativeDir := "."
+	if relative.set {
+		relativeDir, err = diff.RepoPath(relative.path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: --relative: %v\n", err)
+			os.Exit(1)
+		}
+		diff.SetRelative(relativeDir)
+	}
+
+	var maxSize diff.SizeClass
+	if *failOverSize != "" {
+		maxSize, err = diff.ParseSizeClass(*failOverSize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: --fail-over-size: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var outlineFormat render.OutlineFormat
+	numstatPlus := *format == formatNumstatPlus
+	slackBlocks := *format == formatSlackBlocks
+	if *format != "" && !numstatPlus && !slackBlocks {
+		outlineFormat, err = render.ParseOutlineFormat(*format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: --format: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *export != "" && *export != exportSpeedscope && *export != exportHTML {
+		fmt.Fprintf(os.Stderr, "error: --export: unknown format %q (valid: %s, %s)\n", *export, exportSpeedscope, exportHTML)
+		os.Exit(1)
+	}
+	if *cleanupThreshold < 0 || *cleanupThreshold > 100 {
+		fmt.Fprintf(os.Stderr, "error: --cleanup-threshold: want a percentage from 0 to 100, got %d\n", *cleanupThreshold)
+		os.Exit(1)
+	}
+	// Machine-readable output gets no headers or footers
+	rawOutput := numstatPlus || slackBlocks || *export != ""
+
 	// Build CLI flags struct (only for explicitly-set flags)
 	var cliFlags *config.ModeConfig
-	if flagWasSet("width") || flagWasSet("depth") || flagWasSet("expand") || flagWasSet("count") {
+	if flagWasSet("width") || flagWasSet("depth") || flagWasSet("expand") || flagWasSet("count") || flagWasSet("root-group-name") ||
+		flagWasSet("separator") || flagWasSet("item-separator") || flagWasSet("cleanup-threshold") || flagWasSet("abbrev-counts") || profileDepth != nil {
 		cliFlags = &config.ModeConfig{}
+		if profileDepth != nil {
+			cliFlags.Depth = profileDepth // Explicit --depth below still wins
+		}
 		if flagWasSet("width") {
 			cliFlags.Width = width
 		}
-		if flagWasSet("depth") {
-			cliFlags.Depth = depth
+		if flagWasSet("depth") && !depth.auto {
+			cliFlags.Depth = &depth.n
 		}
 		if flagWasSet("expand") {
 			cliFlags.Expand = expand
@@ -119,52 +374,841 @@ func main() {
 		if flagWasSet("count") {
 			cliFlags.N = topnCount
 		}
+		if flagWasSet("root-group-name") {
+			cliFlags.RootGroup = rootGroup
+		}
+		if flagWasSet("separator") {
+			cliFlags.Separator = separator
+		}
+		if flagWasSet("item-separator") {
+			cliFlags.ItemSeparator = itemSeparator
+		}
+		if flagWasSet("cleanup-threshold") {
+			cliFlags.CleanupThreshold = cleanupThreshold
+		}
+		if flagWasSet("abbrev-counts") {
+			cliFlags.AbbrevCounts = abbrevCounts
+		}
+	}
+
+	colorProfile = render.DetectColorProfile()
+	if *profileName != "auto" {
+		colorProfile, err = render.ParseColorProfile(*profileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: --color-profile: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	iciclePolicy, err := render.ParseLabelPolicy(*labelPolicy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: --label-depth-policy: %v\n", err)
+		os.Exit(1)
+	}
+
+	capturePath, err := parseCapture(*capture)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: --capture: %v\n", err)
+		os.Exit(1)
+	}
+
+	metric, err := render.ParseMetric(*metricName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: --metric: %v\n", err)
+		os.Exit(1)
+	}
+	units, err := render.ParseUnits(*unitsName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: --units: %v\n", err)
+		os.Exit(1)
+	}
+
+	// CLI-only render settings shared by all modes
+	var annotations []string
+	if *annotate != "" {
+		annotations = strings.Split(*annotate, ",")
+	}
+
+	flags := renderFlags{
+		ageHeat:      *ageHeat,
+		annotations:  annotations,
+		useColor:     !*noColor,
+		topnSort:     *topnSort,
+		perDir:       *perDir,
+		noRainbow:    *noRainbow,
+		dirsOnly:     *dirsOnly,
+		multiline:    *multiline,
+		vertical:     *vertical,
+		composition:  *composition,
+		scaleLegend:  *scaleLegend,
+		autoDepth:    depth.auto,
+		maxPathDepth: *maxPathDepth,
+		metric:       metric,
+		rtl:          *rtl,
+		labelPolicy:  iciclePolicy,
+	}
+
+	// serve-stdio reports git errors per request and a patch file is
+	// self-contained; everything else needs a repo
+	if !*serveStdio && *patchFile == "" {
+		requireRepo()
+	}
+	if redactPaths {
+		setRedactKey()
 	}
 
 	if *demo {
+		modes := render.DiffModeNames()
 		if modeExplicitlySet {
-			if !render.IsValidMode(selectedMode) {
-				fmt.Fprintf(os.Stderr, "unknown mode: %s (valid: %s)\n", selectedMode, strings.Join(render.ValidModes, ", "))
+			// Comma-separated modes filter the demo (e.g., -m smart,icicle)
+			modes = strings.Split(selectedMode, ",")
+			for _, m := range modes {
+				if !render.IsDiffMode(m) {
+					fmt.Fprintf(os.Stderr, "unknown demo mode: %s (valid: %s)\n", m, strings.Join(render.DiffModeNames(), ", "))
+					os.Exit(1)
+				}
+			}
+		}
+		var widths []int
+		if *demoWidth != "" {
+			widths, err = parseWidths(*demoWidth)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: --demo-width: %v\n", err)
 				os.Exit(1)
 			}
-			runDemoSingleMode(selectedMode, !*noColor, cfg, cliFlags, *topnSort)
-		} else {
-			runDemo(!*noColor, cfg, cliFlags, *topnSort)
 		}
+		runDemo(modes, widths, cfg, cliFlags, flags)
 		return
 	}
 
 	// Resolve verbose flag
 	showWarnings := *verbose || *verboseLong
 
+	if *serveStdio {
+		runServeStdio(os.Stdin, os.Stdout, cfg, sizeThresholds, showWarnings)
+		return
+	}
+
+	if badge.set {
+		runBadge(badge, flag.Args(), showWarnings)
+		return
+	}
+
+	shortcuts := revisionShortcuts{
+		upstream: *upstream, pushed: *pushed, stash: flagWasSet("stash"), stashIndex: *stashIndex,
+		sinceRelease: *sinceRelease, releaseMatch: *releaseMatch,
+	}
+
 	// Handle --stats-json mode (raw stats for programmatic consumption)
 	if *statsJSON {
-		outputStatsJSON(*baseline, showWarnings)
+		diffArgs := flag.Args()
+		if *baseline == "" && *patchFile == "" {
+			diffArgs, err = resolveDiffArgs(diffArgs, shortcuts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		stats := outputStatsJSON(diffArgs, *baseline, *patchFile, relativeDir, showWarnings, sizeThresholds, depth.jsonDirDepth(), annotations, !*noProvenance)
+		checkSizeLimit(stats, sizeThresholds, maxSize)
 		return
 	}
 
 	// Validate mode
 	if !render.IsValidMode(selectedMode) {
-		fmt.Fprintf(os.Stderr, "unknown mode: %s (valid: %s)\n", selectedMode, strings.Join(render.ValidModes, ", "))
+		fmt.Fprintf(os.Stderr, "unknown mode: %s (valid: %s)\n", selectedMode, strings.Join(render.ModeNames(), ", "))
 		os.Exit(1)
 	}
+	if *explain {
+		explainConfig(os.Stdout, cfg, selectedMode, cliFlags)
+		os.Exit(0)
+	}
+
+	// Standalone modes chart history, stashes, or branches rather than a diff
+	if info, _ := render.LookupMode(selectedMode); info.Standalone {
+		for _, w := range ignoredFlags([]string{selectedMode}, flags.rtl) {
+			fmt.Fprintln(os.Stderr, "warning: "+w)
+		}
+		if len(modes.outputs) > 0 {
+			fmt.Fprintf(os.Stderr, "warning: -m NAME=FILE outputs are not written in %s mode\n", selectedMode)
+		}
+		view := newViewOutput(true, *noPager, *maxLines, *copyOutput, capturePath)
+		switch selectedMode {
+		case "trend":
+			runTrend(view.w, *label, flags.useColor, showWarnings)
+		case "stashes":
+			runStashes(view.w, flags.useColor, showWarnings)
+		case "branches":
+			runBranches(view.w, flags.useColor, showWarnings)
+		case "filehistory":
+			runFileHistory(view.w, flag.Args(), *fileHistory, flags.useColor, showWarnings)
+		}
+		view.Close()
+		return
+	}
+
+	// --auto-mode picks the mode once the stats are in. An outline or raw
+	// format replaces the display mode, leaving only the file outputs.
+	if !*autoMode {
+		rendered := active
+		if outlineFormat != "" || rawOutput {
+			rendered = modes.active(selectedMode, false)
+		}
+		for _, w := range ignoredFlags(activeModes(rendered), flags.rtl) {
+			fmt.Fprintln(os.Stderr, "warning: "+w)
+		}
+		if depth.auto && rendered["icicle"] {
+			fmt.Fprintf(os.Stderr, "warning: --depth auto is not supported in icicle mode; using depth %d\n", cfg.Resolve("icicle", cliFlags).Depth)
+		}
+		// Plain smart output draws bars without counts
+		if flagWasSet("abbrev-counts") && rendered["smart"] && !rendered["brackets"] && !flags.vertical {
+			fmt.Fprintln(os.Stderr, "warning: --abbrev-counts has no effect in smart mode without --vertical")
+		}
+	}
 
 	// Resolve final configuration (config already loaded above)
 	resolved := cfg.Resolve(selectedMode, cliFlags)
 
-	// Get diff stats with remaining args
-	stats, warnings, err := diff.GetAllStats(flag.Args()...)
+	var diffArgs []string
+	var stats *diff.DiffStats
+	var warnings []string
+	if *conflictsPreview && (*union || *intersect) || *union && *intersect {
+		err = fmt.Errorf("--conflicts-preview, --union, and --intersect are mutually exclusive")
+	} else if *patchFile != "" {
+		if len(flag.Args()) > 0 || *baseline != "" || *union || *intersect || *conflictsPreview {
+			err = fmt.Errorf("--patch-file: the patch is the diff; drop the commit args and --baseline, --union, --intersect, --conflicts-preview")
+		} else {
+			stats, warnings, err = getPatchStats(*patchFile, relativeDir)
+		}
+	} else if *conflictsPreview {
+		stats, warnings, err = getConflictsPreview(flag.Args())
+	} else if *union || *intersect {
+		stats, warnings, err = getCombinedStats(flag.Args(), *intersect)
+	} else if *baseline != "" {
+		stats, diffArgs, warnings, err = getBaselineStats(*baseline)
+	} else {
+		diffArgs, err = resolveDiffArgs(flag.Args(), shortcuts)
+		if err == nil {
+			// Get diff stats with remaining args
+			stats, warnings, err = diff.GetAllStats(diffArgs...)
+		}
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 	printWarnings(warnings, showWarnings)
 
-	useColor := !*noColor
+	// Preflight: pick or second-guess the mode from the diff and the width
+	if *autoMode {
+		selectedMode = render.AutoMode(stats.TotalFiles, outputContext().Width)
+		resolved = cfg.Resolve(selectedMode, cliFlags)
+		active = modes.active(selectedMode, display)
+	} else if display && outlineFormat == "" && !rawOutput {
+		width := getTerminalWidth(resolved.Width, resolved.WidthAuto)
+		if advice, ok := render.AdviseMode(selectedMode, stats.TotalFiles, width); ok {
+			fmt.Fprintf(os.Stderr, "warning: %s; try -m %s or --auto-mode\n", advice.Reason, advice.Suggest)
+		}
+	}
+
+	// The commit count puts a range's size in context
+	commits := -1
+	if *perCommit && !rawOutput {
+		if *union || *intersect || *conflictsPreview || *baseline != "" || *patchFile != "" {
+			fmt.Fprintln(os.Stderr, "warning: --per-commit-average needs a single commit range")
+		} else if n, ok, err := diff.CommitCount(diffArgs...); err != nil {
+			fmt.Fprintf(os.Stderr, "error: --per-commit-average: %v\n", err)
+			os.Exit(1)
+		} else if !ok {
+			fmt.Fprintln(os.Stderr, "warning: --per-commit-average has no effect without a commit range")
+		} else {
+			commits = n
+		}
+	}
+
+	warnings, err = stats.Enrich(flags.annotations...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: --annotate: %v\n", err)
+		os.Exit(1)
+	}
+	printWarnings(warnings, showWarnings)
+
+	// --file narrows the diff to the file's siblings
+	var focusPath, focusDir string
+	if *focusFile != "" {
+		focusPath, err = diff.RepoPath(*focusFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: --file: %v\n", err)
+			os.Exit(1)
+		}
+		focusDir = path.Dir(focusPath)
+		dir, err := underRelative(focusDir, relativeDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: --file: %v\n", err)
+			os.Exit(1)
+		}
+		stats = stats.InDir(dir)
+	}
+	if *focus != "" {
+		if *focusFile != "" {
+			fmt.Fprintln(os.Stderr, "error: --focus and --file are mutually exclusive")
+			os.Exit(1)
+		}
+		dir, err := diff.RepoPath(*focus)
+		if err == nil {
+			dir, err = underRelative(dir, relativeDir)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: --focus: %v\n", err)
+			os.Exit(1)
+		}
+		stats = stats.InDir(dir)
+	}
+
+	view := newViewOutput(display, *noPager, *maxLines, *copyOutput, capturePath)
+	w := view.w
+
+	if stats.NoHead && !rawOutput {
+		fmt.Fprintf(w, "%s\n\n", noHeadNotice)
+	}
+	if *conflictsPreview && !rawOutput {
+		args := flag.Args()
+		fmt.Fprintf(w, "%d files changed on both %s and %s\n\n", stats.TotalFiles, shownRev(args[0]), shownRev(args[1]))
+	}
+	if (*union || *intersect) && !rawOutput {
+		joiner, verb := " or ", "any of"
+		if *intersect {
+			joiner, verb = " and ", "all of"
+		}
+		var ranges []string
+		for _, arg := range flag.Args() {
+			ranges = append(ranges, shownRev(arg))
+		}
+		fmt.Fprintf(w, "%d files changed in %s %s\n\n", stats.TotalFiles, verb, strings.Join(ranges, joiner))
+	}
+	if focusPath != "" && !rawOutput {
+		fmt.Fprintf(w, "Around %s/ (%s)\n\n", shownPath(focusDir), shownPath(focusPath))
+	}
+
+	// The enrichers below rerun git diff with diffArgs, which cannot name
+	// the several ranges these combine
+	combined := *conflictsPreview || *union || *intersect
+
+	// Blame is slow, so only compute line ages when they will be shown
+	if flags.ageHeat && *patchFile != "" {
+		fmt.Fprintln(os.Stderr, "warning: --age-heat needs the repo's history; ignored with --patch-file")
+	} else if flags.ageHeat && combined {
+		fmt.Fprintln(os.Stderr, "warning: --age-heat needs a single diff; ignored with --conflicts-preview, --union, and --intersect")
+	} else if flags.ageHeat && (active["tree"] || active["topn"]) {
+		warnings, err := diff.ComputeReplacedAges(stats, time.Now(), diffArgs...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		printWarnings(warnings, showWarnings)
+	}
+
+	// Function counts need a full patch, so only compute them when sorting by
+	// them (a patch file's were counted while parsing it)
+	if active["topn"] && render.SortBy(flags.topnSort) == render.SortByFuncs && combined {
+		fmt.Fprintln(os.Stderr, "warning: --sort funcs needs a single diff; ignored with --conflicts-preview, --union, and --intersect")
+	} else if active["topn"] && render.SortBy(flags.topnSort) == render.SortByFuncs && *patchFile == "" {
+		warnings, err := diff.CountFunctionsChanged(stats, diffArgs...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		printWarnings(warnings, showWarnings)
+	}
+
+	var compareStats *diff.DiffStats
+	if *compareTo != "" && active["topn"] {
+		compareStats, err = getCompareStats(*compareTo, render.SortBy(flags.topnSort), showWarnings)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: --compare-to: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	opts, err := newRenderOptions(resolved, flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	opts.sizeClass = stats.SizeClass(sizeThresholds)
+	opts.excluded = stats.Excluded(exclusions)
+	opts.compare = compareStats
+	opts.out = w
+
+	// Tree and topn measure bytes with --units bytes, or --units auto for
+	// mostly binary diffs
+	opts.bytes = anyActive(active, binarySummaryModes) &&
+		(units == render.UnitsBytes || units == render.UnitsAuto && stats.BinaryHeavy())
+
+	// Ranges have no untracked files, so new files come from git's status;
+	// binary summaries need the size deltas, and bytes every file's. A patch
+	// file carries what it can; combined ranges go without.
+	needDetails := opts.bytes || numstatPlus || active[formatNumstatPlus] || active["suggest"] || (flags.composition && anyActive(active, compositionModes))
+	if combined && needDetails {
+		fmt.Fprintln(os.Stderr, "warning: new-file and size details need a single diff; unavailable with --conflicts-preview, --union, and --intersect")
+	} else if *patchFile == "" && !combined && (needDetails ||
+		(outlineFormat == "" && !rawOutput && anyActive(active, binarySummaryModes) && hasBinary(stats))) {
+		addDetails := diff.AddChangeDetails
+		if opts.bytes {
+			addDetails = diff.AddSizeDeltas
+		}
+		warnings, err := addDetails(stats, diffArgs...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		printWarnings(warnings, showWarnings)
+	}
+	if opts.bytes {
+		if _, unsized := stats.InBytes(); len(unsized) > 0 {
+			fmt.Fprintf(os.Stderr, "warning: --units bytes: size change unknown for %d files, counted as 0 bytes\n", len(unsized))
+		}
+	}
+
+	// Redact last of all, so the steps above still see real paths
+	if redactPaths {
+		stats = stats.Redacted()
+		if opts.compare != nil {
+			opts.compare = opts.compare.Redacted()
+		}
+	}
+	if flags.autoDepth {
+		opts.dirDepths = analyze.AutoDepths(stats)
+	}
+
+	// Select renderer based on mode (--format and --export override it);
+	// with only file outputs there is no view to render
+	if display {
+		var renderer render.Renderer
+		if numstatPlus {
+			renderer = render.NewNumstatRenderer(opts.out)
+		} else if slackBlocks {
+			renderer = render.NewSlackBlocksRenderer(opts.out)
+		} else if *export == exportSpeedscope {
+			renderer = render.NewSpeedscopeRenderer(opts.out)
+		} else if *export == exportHTML {
+			page := render.NewHTMLRenderer(opts.out)
+			page.MaxPathDepth = opts.maxPathDepth
+			renderer = page
+		} else if outlineFormat != "" {
+			outline := render.NewOutlineRenderer(opts.out, outlineFormat)
+			outline.MaxPathDepth = opts.maxPathDepth
+			renderer = outline
+		} else {
+			renderer = getRenderer(selectedMode, opts)
+		}
+		// Only the view is in bytes; the outputs below convert their own
+		shown := stats
+		if opts.bytes && binarySummaryModes[selectedMode] && outlineFormat == "" && !rawOutput {
+			shown, _ = stats.InBytes()
+		}
+		renderer.Render(shown)
+		if commits >= 0 {
+			fmt.Fprintf(w, "\n%s\n", perCommitSummary(stats, commits))
+		}
+	}
+
+	if display && focusPath != "" && !rawOutput {
+		commits, warnings, err := diff.FileHistory(focusPath, *fileHistory)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		printWarnings(warnings, showWarnings)
+		fmt.Fprintln(w)
+		render.NewFileHistoryRenderer(w, flags.useColor).RenderHistory(shownPath(focusPath), redactCommits(commits))
+	}
+	view.Close()
+
+	if err := writeOutputs(modes.outputs, stats, opts, cfg, cliFlags, sizeThresholds, depth.jsonDirDepth()); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *quickfixPath != "" {
+		if err := writeQuickfixFile(*quickfixPath, stats); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	// Select renderer based on mode
-	renderer := getRenderer(selectedMode, useColor, resolved.Width, resolved.Depth, resolved.Expand, resolved.N, *topnSort)
-	renderer.Render(stats)
+	checkSizeLimit(stats, sizeThresholds, maxSize)
+}
+
+// perCommitSummary is the --per-commit-average line, e.g.
+// "+412/-88 across 9 commits, avg 55 lines/commit".
+func perCommitSummary(stats *diff.DiffStats, commits int) string {
+	churn := fmt.Sprintf("+%d/-%d", stats.TotalAdd, stats.TotalDel)
+	switch commits {
+	case 0:
+		return churn + " across 0 commits (uncommitted changes only)"
+	case 1:
+		return fmt.Sprintf("%s across 1 commit", churn)
+	}
+	avg := (stats.TotalAdd + stats.TotalDel + commits/2) / commits
+	return fmt.Sprintf("%s across %d commits, avg %d lines/commit", churn, commits, avg)
+}
+
+// loadConfig reads the config file at path, or the discovered one when path
+// is empty (nil when there is none), with the repos section matching the
+// current repository applied.
+func loadConfig(path string) (*config.Config, error) {
+	if path == "" {
+		path = config.Discover()
+	}
+	cfg, err := config.Load(path)
+	if err == nil {
+		err = cfg.CheckRepos()
+	}
+	if err != nil || cfg == nil || len(cfg.Repos) == 0 {
+		return cfg, err
+	}
+	// Outside a repository nothing matches; the diff reports the error
+	repo, err := diff.Repo()
+	if err != nil {
+		return cfg, nil
+	}
+	cfg, _ = cfg.ForRepo(repo)
+	return cfg, nil
+}
+
+// redactPaths is --redact-paths: outputs show paths through diff.RedactPath,
+// and free text that could name things (commit subjects, stash messages) is
+// dropped.
+var redactPaths bool
+
+// shownRev returns a revision as outputs show it: hashed under
+// --redact-paths, else as given.
+func shownRev(rev string) string {
+	if redactPaths {
+		return diff.RedactName(rev)
+	}
+	return rev
+}
+
+// setRedactKey gives --redact-paths its key: $GIT_DIFF_TREE_REDACT_KEY, else
+// the repository's own (see diff.RedactKey). Without either, hashes differ
+// between runs.
+func setRedactKey() {
+	if key := os.Getenv("GIT_DIFF_TREE_REDACT_KEY"); key != "" {
+		diff.SetRedactKey([]byte(key))
+		return
+	}
+	key, err := diff.RedactKey()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: --redact-paths: no stored key (%v); hashes will differ between runs\n", err)
+		return
+	}
+	diff.SetRedactKey(key)
+}
+
+// colorProfile is the terminal color depth output is degraded to
+// (--color-profile, or detected from the environment).
+var colorProfile = render.ProfileTrueColor
+
+// stdout returns standard output with colors degraded to colorProfile.
+func stdout() io.Writer {
+	return render.NewProfileWriter(os.Stdout, colorProfile)
+}
+
+// depthFlag is --depth N, or --depth auto for a depth per top-level
+// directory (see analyze.AutoDepths).
+type depthFlag struct {
+	n    int
+	auto bool
+}
+
+// depthAuto is the JSON dirs depth meaning --depth auto.
+const depthAuto = -1
+
+func (f *depthFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	if f.auto {
+		return "auto"
+	}
+	return strconv.Itoa(f.n)
+}
+
+func (f *depthFlag) Set(s string) error {
+	if s == "auto" {
+		f.auto = true
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("want a number or auto")
+	}
+	f.n, f.auto = n, false
+	return nil
+}
+
+// jsonDirDepth returns the depth of the JSON "dirs" array: 0 (none) unless
+// --depth is explicit, depthAuto for --depth auto.
+func (f *depthFlag) jsonDirDepth() int {
+	switch {
+	case !flagWasSet("depth"):
+		return 0
+	case f.auto:
+		return depthAuto
+	}
+	return f.n
+}
+
+// relativeFlag is --relative[=PATH]: bare, it means the current directory.
+type relativeFlag struct {
+	set  bool
+	path string
+}
+
+func (f *relativeFlag) String() string {
+	if f == nil || !f.set {
+		return ""
+	}
+	return f.path
+}
+
+// Set receives "true" for a bare --relative (see IsBoolFlag).
+func (f *relativeFlag) Set(s string) error {
+	switch s {
+	case "true":
+		f.set, f.path = true, "."
+	case "false":
+		f.set, f.path = false, ""
+	default:
+		f.set, f.path = true, s
+	}
+	return nil
+}
+
+// IsBoolFlag lets --relative appear without a value.
+func (f *relativeFlag) IsBoolFlag() bool { return true }
+
+// underRelative returns repo directory dir as the stats name it under
+// --relative directory rel, failing when dir is outside rel.
+func underRelative(dir, rel string) (string, error) {
+	switch {
+	case rel == ".":
+		return dir, nil
+	case dir == rel:
+		return ".", nil
+	case strings.HasPrefix(dir, rel+"/"):
+		return strings.TrimPrefix(dir, rel+"/"), nil
+	}
+	return "", fmt.Errorf("%s is outside --relative %s", dir, rel)
+}
+
+// exportSpeedscope is the --export value for a speedscope flamegraph profile.
+const exportSpeedscope = "speedscope"
+
+// exportHTML is the --export value for a standalone page with an
+// expandable tree.
+const exportHTML = "html"
+
+// binarySummaryModes end with a by-category count of binary files.
+var binarySummaryModes = map[string]bool{"tree": true, "topn": true}
+
+// hasBinary reports whether stats has any binary files.
+func hasBinary(stats *diff.DiffStats) bool {
+	for _, f := range stats.Files {
+		if f.IsBinary {
+			return true
+		}
+	}
+	return false
+}
+
+// formatNumstatPlus is the --format value for numstat lines with extra
+// status, rename target, and binary size columns.
+const formatNumstatPlus = "numstat+"
+
+// formatSlackBlocks is the --format value for a Slack Block Kit digest.
+const formatSlackBlocks = "slack-blocks"
+
+// modeJSON is the --list-modes --json representation of a mode.
+type modeJSON struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Options     []string       `json:"options"`
+	Defaults    map[string]any `json:"defaults"`
+}
+
+// printModesJSON prints mode metadata for tooling.
+func printModesJSON() {
+	var out []modeJSON
+	for _, m := range render.Modes() {
+		options := m.Options
+		if options == nil {
+			options = []string{}
+		}
+		out = append(out, modeJSON{
+			Name:        m.Name,
+			Description: m.Description,
+			Options:     options,
+			Defaults:    m.DefaultOptions(),
+		})
+	}
+	data, _ := json.MarshalIndent(out, "", "  ")
+	fmt.Println(string(data))
+}
+
+// writeQuickfixFile writes stats in quickfix format to path.
+func writeQuickfixFile(path string, stats *diff.DiffStats) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating quickfix file: %w", err)
+	}
+	if err := render.WriteQuickfix(f, stats); err != nil {
+		f.Close()
+		return fmt.Errorf("writing quickfix file: %w", err)
+	}
+	return f.Close()
+}
+
+// checkSizeLimit exits with status 1 if the diff's size class exceeds maxSize.
+// An empty maxSize disables the check.
+func checkSizeLimit(stats *diff.DiffStats, thresholds []diff.SizeThreshold, maxSize diff.SizeClass) {
+	if maxSize == "" {
+		return
+	}
+	if class := stats.SizeClass(thresholds); class.Rank() > maxSize.Rank() {
+		fmt.Fprintf(os.Stderr, "diff size %s exceeds limit %s (+%d -%d)\n", class, maxSize, stats.TotalAdd, stats.TotalDel)
+		os.Exit(1)
+	}
+}
+
+// revisionShortcuts holds the revision shortcut flags.
+type revisionShortcuts struct {
+	upstream     bool   // --upstream
+	pushed       bool   // --pushed
+	stash        bool   // --stash was given
+	stashIndex   int    // Its entry number
+	sinceRelease bool   // --since-release
+	releaseMatch string // --release-match
+}
+
+// resolveDiffArgs expands revision shortcut flags into git diff args and
+// validates positional revisions. Shortcuts are mutually exclusive with
+// positional arguments.
+func resolveDiffArgs(args []string, shortcuts revisionShortcuts) ([]string, error) {
+	var shortcut []string
+	var err error
+	count := 0
+
+	if shortcuts.upstream {
+		count++
+		shortcut, err = resolveShortcutRef("@{upstream}")
+	}
+	if shortcuts.pushed {
+		count++
+		shortcut, err = resolveShortcutRef("@{push}")
+	}
+	if shortcuts.stash {
+		count++
+		shortcut, err = diff.StashArgs(shortcuts.stashIndex)
+	}
+	if shortcuts.sinceRelease {
+		count++
+		var tag string
+		if tag, err = diff.LatestTag(shortcuts.releaseMatch); err == nil {
+			shortcut = []string{tag, "HEAD"}
+		}
+	}
+
+	if count > 1 {
+		return nil, fmt.Errorf("--upstream, --pushed, --stash, and --since-release are mutually exclusive")
+	}
+	if count == 1 {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("revision shortcuts cannot be combined with revision arguments")
+		}
+		return shortcut, err
+	}
+
+	// Before the first commit, "HEAD" still means the working tree (see GetAllStats)
+	if len(args) == 1 && args[0] == "HEAD" {
+		if hasHead, err := diff.HasHead(); err == nil && !hasHead {
+			return args, nil
+		}
+	}
+
+	// Fail fast on typos instead of rendering "No changes"
+	if err := diff.ValidateRevisions(args...); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// getConflictsPreview returns the files changed on both sides of a
+// BASE/BRANCH pair since their merge-base, with each side's churn summed.
+// These are the likeliest conflict hotspots for a merge or rebase.
+func getConflictsPreview(args []string) (*diff.DiffStats, []string, error) {
+	if len(args) != 2 {
+		return nil, nil, fmt.Errorf("--conflicts-preview requires exactly two revisions: BASE BRANCH")
+	}
+	if err := diff.ValidateRevisions(args...); err != nil {
+		return nil, nil, err
+	}
+	base, branch := args[0], args[1]
+
+	baseSide, warnings, err := diff.GetDiffStats(branch + "..." + base)
+	if err != nil {
+		return nil, warnings, err
+	}
+	branchSide, branchWarnings, err := diff.GetDiffStats(base + "..." + branch)
+	warnings = append(warnings, branchWarnings...)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	return diff.Intersect(baseSide, branchSide), warnings, nil
+}
+
+// getCombinedStats diffs each range in args and combines the results: the
+// union sums every file, the intersection keeps only files every range
+// touched.
+func getCombinedStats(args []string, intersect bool) (*diff.DiffStats, []string, error) {
+	flagName, combine := "--union", diff.Union
+	if intersect {
+		flagName, combine = "--intersect", diff.Intersect
+	}
+	if len(args) < 2 {
+		return nil, nil, fmt.Errorf("%s requires at least two ranges: R1 R2", flagName)
+	}
+	if err := diff.ValidateRevisions(args...); err != nil {
+		return nil, nil, err
+	}
+
+	var combined *diff.DiffStats
+	var warnings []string
+	for _, rng := range args {
+		stats, rangeWarnings, err := diff.GetAllStats(rng)
+		warnings = append(warnings, rangeWarnings...)
+		if err != nil {
+			return nil, warnings, err
+		}
+		if combined == nil {
+			combined = stats
+		} else {
+			combined = combine(combined, stats)
+		}
+	}
+	return combined, warnings, nil
+}
+
+// resolveShortcutRef resolves rev to a single-element diff args slice.
+func resolveShortcutRef(rev string) ([]string, error) {
+	sha, err := diff.ResolveRef(rev)
+	if err != nil {
+		return nil, err
+	}
+	return []string{sha}, nil
 }
 
 // printWarnings outputs warnings to stderr if verbose mode is enabled.
@@ -177,27 +1221,31 @@ func printWarnings(warnings []string, verbose bool) {
 	}
 }
 
-// outputStatsJSON outputs raw diff stats as JSON.
+// outputStatsJSON outputs raw diff stats as JSON and returns the stats.
 // This provides a stable interface for programmatic consumers
 // without requiring Go import coupling.
-func outputStatsJSON(baseline string, verbose bool) {
+// diffArgs are the git diff args, used unless baseline or patchFile is set.
+// dirDepth > 0 adds a "dirs" array aggregated at that depth; provenance adds
+// a block recording how the numbers were computed.
+func outputStatsJSON(diffArgs []string, baseline, patchFile, relativeDir string, verbose bool, sizeThresholds []diff.SizeThreshold, dirDepth int, annotations []string, provenance bool) *diff.DiffStats {
 	var stats *diff.DiffStats
 	var warnings []string
 	var err error
 
-	if baseline != "" {
-		currentTree, err := diff.CaptureCurrentTree()
+	if patchFile != "" {
+		stats, warnings, err = getPatchStats(patchFile, relativeDir)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error capturing tree: %v\n", err)
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
 			os.Exit(1)
 		}
-		stats, warnings, err = diff.GetTreeDiffStats(baseline, currentTree)
+	} else if baseline != "" {
+		stats, _, warnings, err = getBaselineStats(baseline)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "error: %v\n", err)
 			os.Exit(1)
 		}
 	} else {
-		stats, warnings, err = diff.GetAllStats()
+		stats, warnings, err = diff.GetAllStats(diffArgs...)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "error: %v\n", err)
 			os.Exit(1)
@@ -205,16 +1253,134 @@ func outputStatsJSON(baseline string, verbose bool) {
 	}
 	printWarnings(warnings, verbose)
 
-	output, err := json.Marshal(stats.ToJSON())
+	warnings, err = stats.Enrich(annotations...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: --annotate: %v\n", err)
+		os.Exit(1)
+	}
+	printWarnings(warnings, verbose)
+
+	if redactPaths {
+		stats = stats.Redacted()
+	}
+	statsJSON := buildStatsJSON(stats, sizeThresholds, dirDepth)
+	// A patch file's numbers come from no git command or revision
+	if provenance && patchFile == "" {
+		revs := diffArgs
+		if baseline != "" {
+			revs = []string{baseline}
+		}
+		p := diff.Provenance(revs...)
+		if redactPaths {
+			// Commands can name files (blame for --annotate), the root is a
+			// path, and refs are keyed by branch names
+			p.Commands, p.RepoRoot = nil, ""
+			refs := make(map[string]string, len(p.Refs))
+			for rev, sha := range p.Refs {
+				refs[shownRev(rev)] = sha
+			}
+			p.Refs = refs
+		}
+		statsJSON.Provenance = &p
+	}
+	output, err := json.Marshal(statsJSON)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 	fmt.Println(string(output))
+	return stats
+}
+
+// marshalStatsJSON encodes stats as --stats-json prints them.
+// dirDepth > 0 adds a "dirs" array aggregated at that depth; depthAuto
+// aggregates each top-level directory at its analyze.AutoDepths depth.
+func marshalStatsJSON(stats *diff.DiffStats, sizeThresholds []diff.SizeThreshold, dirDepth int) ([]byte, error) {
+	return json.Marshal(buildStatsJSON(stats, sizeThresholds, dirDepth))
+}
+
+// buildStatsJSON converts stats to the --stats-json shape; see marshalStatsJSON.
+func buildStatsJSON(stats *diff.DiffStats, sizeThresholds []diff.SizeThreshold, dirDepth int) diff.StatsJSON {
+	statsJSON := stats.ToJSON()
+	statsJSON.Totals.Size = string(stats.SizeClass(sizeThresholds))
+	if dirDepth == depthAuto {
+		statsJSON.Dirs = stats.DirStatsWithDepths(analyze.AutoDepths(stats), 1)
+	} else if dirDepth > 0 {
+		statsJSON.Dirs = stats.DirStats(dirDepth)
+	}
+	return statsJSON
+}
+
+// getCompareStats returns the stats topn ranks rng by for --compare-to,
+// with function counts when sorting by them. Returns an error if rng is
+// an option or names a revision that does not resolve.
+func getCompareStats(rng string, sortBy render.SortBy, verbose bool) (*diff.DiffStats, error) {
+	if strings.HasPrefix(rng, "-") {
+		return nil, fmt.Errorf("%s is not a revision range", rng)
+	}
+	if err := diff.ValidateRevisions(rng); err != nil {
+		return nil, err
+	}
+	stats, warnings, err := diff.GetDiffStats(rng)
+	if err != nil {
+		return nil, err
+	}
+	printWarnings(warnings, verbose)
+	if sortBy == render.SortByFuncs {
+		warnings, err := diff.CountFunctionsChanged(stats, rng)
+		if err != nil {
+			return nil, err
+		}
+		printWarnings(warnings, verbose)
+	}
+	return stats, nil
+}
+
+// getPatchStats parses the unified diff in path ("-" for stdin) for
+// --patch-file, keeping the files under relativeDir (see --relative).
+func getPatchStats(path, relativeDir string) (*diff.DiffStats, []string, error) {
+	in := os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("--patch-file: %w", err)
+		}
+		defer f.Close()
+		in = f
+	}
+	stats, warnings, err := diff.ParsePatch(in)
+	if err != nil {
+		return nil, warnings, fmt.Errorf("--patch-file: %w", err)
+	}
+	// git applies --relative itself; a patch's paths are trimmed here
+	return stats.Relative(relativeDir), warnings, nil
+}
+
+// getBaselineStats compares the baseline tree-ish (a tree SHA, branch, tag,
+// or commit) with a snapshot of the working tree (untracked files included
+// unless --untracked=false) and returns the diff args for the same
+// comparison, so later git diff calls see the same change.
+func getBaselineStats(baseline string) (*diff.DiffStats, []string, []string, error) {
+	baseTree, err := diff.ResolveTree(baseline)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("--baseline: %w", err)
+	}
+	currentTree, err := diff.CaptureCurrentTree()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("capturing tree: %w", err)
+	}
+	stats, warnings, err := diff.GetTreeDiffStats(baseTree, currentTree)
+	return stats, []string{baseTree, currentTree}, warnings, err
 }
 
 // getDemoStats returns diff stats for root..HEAD (used by demo modes).
+// Before the first commit it falls back to the files in the working tree.
 func getDemoStats() (*diff.DiffStats, error) {
+	if hasHead, err := diff.HasHead(); err == nil && !hasHead {
+		stats, _, err := diff.GetAllStats()
+		return stats, err
+	}
+
 	out, err := exec.Command("git", "rev-list", "--max-parents=0", "HEAD").Output()
 	if err != nil {
 		return nil, fmt.Errorf("could not find root commit: %w", err)
@@ -228,27 +1394,28 @@ func getDemoStats() (*diff.DiffStats, error) {
 	return stats, nil
 }
 
-// runDemoSingleMode shows a single visualization mode using root..HEAD diff.
-func runDemoSingleMode(mode string, useColor bool, cfg *config.Config, cliFlags *config.ModeConfig, topnSort string) {
-	stats, err := getDemoStats()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
+// noHeadNotice heads output for repositories without commits.
+const noHeadNotice = "No commits yet (HEAD is unborn): showing all files as new"
 
-	if stats.TotalFiles == 0 {
-		fmt.Println("No changes to display (root..HEAD is empty)")
-		return
-	}
+// widthModes lists modes whose layout depends on the output width.
+var widthModes = map[string]bool{"smart": true, "icicle": true, "bars": true, "brackets": true}
+
+// compositionModes lists modes that draw --composition splits.
+var compositionModes = map[string]bool{"smart": true, "bars": true, "tree": true}
 
-	resolved := cfg.Resolve(mode, cliFlags)
-	fmt.Printf("=== %s ===\n", mode)
-	renderer := getRenderer(mode, useColor, resolved.Width, resolved.Depth, resolved.Expand, resolved.N, topnSort)
-	renderer.Render(stats)
+// anyActive reports whether any mode in set is active.
+func anyActive(active, set map[string]bool) bool {
+	for m := range set {
+		if active[m] {
+			return true
+		}
+	}
+	return false
 }
 
-// runDemo shows all visualization modes using root..HEAD diff.
-func runDemo(useColor bool, cfg *config.Config, cliFlags *config.ModeConfig, topnSort string) {
+// runDemo shows the given visualization modes using root..HEAD diff.
+// When widths is non-empty, width-sensitive modes render once per width.
+func runDemo(modes []string, widths []int, cfg *config.Config, cliFlags *config.ModeConfig, flags renderFlags) {
 	stats, err := getDemoStats()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
@@ -259,58 +1426,256 @@ func runDemo(useColor bool, cfg *config.Config, cliFlags *config.ModeConfig, top
 		fmt.Println("No changes to display (root..HEAD is empty)")
 		return
 	}
+	if stats.NoHead {
+		fmt.Printf("%s\n\n", noHeadNotice)
+	}
 
-	for i, mode := range render.ValidModes {
-		if i > 0 {
-			fmt.Println()
+	first := true
+	for _, mode := range modes {
+		opts, err := newRenderOptions(cfg.Resolve(mode, cliFlags), flags)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
 		}
-		resolved := cfg.Resolve(mode, cliFlags)
-		fmt.Printf("=== %s ===\n", mode)
-		renderer := getRenderer(mode, useColor, resolved.Width, resolved.Depth, resolved.Expand, resolved.N, topnSort)
-		renderer.Render(stats)
+
+		if len(widths) == 0 || !widthModes[mode] {
+			if !first {
+				fmt.Println()
+			}
+			first = false
+			fmt.Printf("=== %s ===\n", mode)
+			getRenderer(mode, opts).Render(stats)
+			continue
+		}
+
+		for _, w := range widths {
+			if !first {
+				fmt.Println()
+			}
+			first = false
+			opts.width, opts.widthAuto = w, false
+			fmt.Printf("=== %s (width %d) ===\n", mode, w)
+			getRenderer(mode, opts).Render(stats)
+		}
+	}
+}
+
+// parseWidths parses a comma-separated list of positive widths (e.g., "60,100,160").
+func parseWidths(s string) ([]int, error) {
+	var widths []int
+	for _, part := range strings.Split(s, ",") {
+		w, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || w <= 0 {
+			return nil, fmt.Errorf("invalid width %q (want positive integers, e.g. 60,100,160)", part)
+		}
+		widths = append(widths, w)
 	}
+	return widths, nil
+}
+
+// outputContext reports whether stdout is a terminal and how wide, for
+// matching config profile rules.
+func outputContext() config.OutputContext {
+	fd := int(os.Stdout.Fd())
+	ctx := config.OutputContext{TTY: term.IsTerminal(fd)}
+	if width, _, err := term.GetSize(fd); ctx.TTY && err == nil {
+		ctx.Width = width
+	}
+	return ctx
 }
 
 // getTerminalWidth returns the terminal width to use for rendering.
-// Priority: flag value (if not default) > terminal detection > default (100).
-func getTerminalWidth(flagWidth int) int {
-	if flagWidth != 100 { // User explicitly set via flag
-		return flagWidth
+// Explicit widths (CLI flag or config) are used as-is; auto widths detect
+// the terminal and fall back to the resolved width (default 100).
+func getTerminalWidth(width int, auto bool) int {
+	if !auto {
+		return width
 	}
 	// Try to detect terminal width
-	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
-		return width
+	if detected, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && detected > 0 {
+		return detected
 	}
-	return 100 // sensible default for modern terminals
-}
-
-func getRenderer(mode string, useColor bool, width, depth, expand, topnCount int, topnSort string) render.Renderer {
-	switch mode {
-	case "tree":
-		return render.NewTreeRenderer(os.Stdout, useColor)
-	case "smart":
-		r := render.NewSmartSparklineRenderer(os.Stdout, useColor)
-		r.MaxDepth = depth
-		r.Width = getTerminalWidth(width)
-		return r
-	case "topn":
-		r := render.NewTopNRenderer(os.Stdout, useColor, topnCount)
-		r.SortBy = render.SortBy(topnSort)
-		return r
-	case "icicle":
-		r := render.NewIcicleRenderer(os.Stdout, useColor)
-		r.Width = getTerminalWidth(width)
-		r.MaxDepth = depth
-		return r
-	case "brackets":
-		r := render.NewBracketsRenderer(os.Stdout, useColor)
-		r.Width = getTerminalWidth(width)
-		r.ExpandDepth = expand
-		return r
-	default:
-		// Should never reach here if isValidMode was called first
-		return render.NewTreeRenderer(os.Stdout, useColor)
+	return width
+}
+
+// renderFlags holds CLI-only settings that apply to every mode.
+type renderFlags struct {
+	useColor     bool
+	topnSort     string
+	perDir       bool               // Topn: top files per top-level directory
+	noRainbow    bool               // Single dim bracket color
+	dirsOnly     bool               // Stop expansion at directory level
+	multiline    bool               // Smart mode: one line per top-level directory
+	vertical     bool               // Smart mode: one aligned row per group
+	composition  bool               // Smart mode: split bars by new vs existing files
+	labelPolicy  render.LabelPolicy // Icicle: per-depth label shortening
+	annotations  []string           // Enricher keys to run and display
+	ageHeat      bool               // Color files by replaced-line age
+	scaleLegend  bool               // Smart/topn: explain bar shades and lengths
+	autoDepth    bool               // --depth auto: pick a depth per top-level directory
+	maxPathDepth int                // Fold directories nested deeper than this (0 = off)
+	metric       render.Metric      // Icicle/bars: size by changed lines or files
+	rtl          bool               // Tree: mirrored right-to-left layout
+	bytes        bool               // Tree/topn: counts are bytes (see diff.DiffStats.InBytes)
+}
+
+// renderOptions holds the resolved settings used to construct a renderer.
+type renderOptions struct {
+	renderFlags
+	width         int
+	widthAuto     bool // Detect terminal width, falling back to width
+	depth         int
+	expand        int
+	topnCount     int
+	bracketColors []string // ANSI codes; nil uses renderer default
+	barStyle      render.BarStyle
+	rootGroup     string              // Smart/brackets: virtual group name for root files
+	rootGroupSort bool                // Brackets: sort the root group by total
+	separator     string              // Smart/brackets: between groups ("" = renderer default)
+	itemSeparator string              // Brackets: between items in a group ("" = renderer default)
+	cleanupRatio  float64             // Tree/smart: deletion share marking a directory (<0 = off)
+	abbrevCounts  bool                // Smart/brackets: "+12.4k" instead of exact counts
+	out           io.Writer           // Render destination (stdout or pager buffer)
+	sizeClass     diff.SizeClass      // Optional size label for summary lines
+	excluded      diff.ExcludedTotals // Config exclude categories, for summary lines
+	compare       *diff.DiffStats     // Topn: earlier range for ranking movement
+	dirDepths     diff.DirDepths      // --depth auto: per-top-level-directory depths
+}
+
+// newRenderOptions builds renderOptions from a resolved mode config and CLI flags.
+// Returns an error if configured colors or other option values are invalid.
+func newRenderOptions(resolved config.ResolvedConfig, flags renderFlags) (renderOptions, error) {
+	opts := renderOptions{
+		renderFlags:   flags,
+		out:           stdout(),
+		width:         resolved.Width,
+		widthAuto:     resolved.WidthAuto,
+		depth:         resolved.Depth,
+		expand:        resolved.Expand,
+		topnCount:     resolved.N,
+		rootGroup:     resolved.RootGroup,
+		rootGroupSort: resolved.RootGroupSort,
+	}
+	parsed, err := render.OptionsFromConfig(resolved)
+	if err != nil {
+		return opts, err
+	}
+	opts.barStyle = parsed.Bar
+	opts.bracketColors = parsed.BracketColors
+	opts.separator, opts.itemSeparator = parsed.Separator, parsed.ItemSeparator
+	opts.cleanupRatio = parsed.CleanupRatio
+	opts.abbrevCounts = parsed.AbbrevCounts
+	return opts, nil
+}
+
+func getRenderer(mode string, opts renderOptions) render.Renderer {
+	r, err := render.NewRenderer(mode, opts.out, render.Options{
+		UseColor:      opts.useColor,
+		Width:         getTerminalWidth(opts.width, opts.widthAuto),
+		Depth:         opts.depth,
+		Expand:        opts.expand,
+		N:             opts.topnCount,
+		RootGroup:     opts.rootGroup,
+		RootGroupSort: opts.rootGroupSort,
+		Bar:           opts.barStyle,
+		BracketColors: opts.bracketColors,
+		SizeClass:     opts.sizeClass,
+		Excluded:      opts.excluded,
+		Compare:       opts.compare,
+		DirDepths:     opts.dirDepths,
+		SortBy:        render.SortBy(opts.topnSort),
+		PerDir:        opts.perDir,
+		NoRainbow:     opts.noRainbow,
+		DirsOnly:      opts.dirsOnly,
+		Multiline:     opts.multiline,
+		Vertical:      opts.vertical,
+		Composition:   opts.composition,
+		LabelPolicy:   opts.labelPolicy,
+		Annotations:   opts.annotations,
+		AgeHeat:       opts.ageHeat,
+		ScaleLegend:   opts.scaleLegend,
+		MaxPathDepth:  opts.maxPathDepth,
+		Metric:        opts.metric,
+		RTL:           opts.rtl,
+		Bytes:         opts.bytes,
+		Separator:     opts.separator,
+		ItemSeparator: opts.itemSeparator,
+		CleanupRatio:  opts.cleanupRatio,
+		AbbrevCounts:  opts.abbrevCounts,
+	})
+	if err != nil {
+		// Should never reach here if IsDiffMode was called first
+		return render.NewTreeRenderer(opts.out, opts.useColor)
+	}
+	return r
+}
+
+// optionFlag is a CLI flag that overrides a mode option. Flags with no
+// config option list the modes that honor them instead.
+type optionFlag struct {
+	flag   string
+	option string   // Mode option (see render.ModeInfo.Options)
+	modes  []string // Modes honoring a flag without a config option
+}
+
+// optionFlags lists the flags ignoredFlags checks against the modes.
+var optionFlags = []optionFlag{
+	{flag: "width", option: render.OptionWidth},
+	{flag: "depth", option: render.OptionDepth},
+	{flag: "expand", option: render.OptionExpand},
+	{flag: "count", option: render.OptionN},
+	{flag: "root-group-name", option: render.OptionRootGroup},
+	{flag: "separator", option: render.OptionSeparator},
+	{flag: "item-separator", option: render.OptionItemSeparator},
+	{flag: "cleanup-threshold", option: render.OptionCleanupThreshold},
+	{flag: "abbrev-counts", option: render.OptionAbbrevCounts},
+	{flag: "composition", modes: []string{"tree", "smart", "bars"}},
+	{flag: "multiline", modes: []string{"smart"}},
+	{flag: "vertical", modes: []string{"smart"}},
+	{flag: "metric", modes: []string{"icicle", "bars"}},
+	{flag: "label-depth-policy", modes: []string{"icicle"}},
+	{flag: "dirs-only", modes: []string{"tree", "icicle"}},
+	{flag: "max-path-depth", modes: []string{"tree", "icicle"}},
+	{flag: "rtl", modes: []string{"tree"}},
+	{flag: "no-rainbow", modes: []string{"brackets"}},
+	{flag: "per-dir", modes: []string{"topn"}},
+	{flag: "compare-to", modes: []string{"topn"}},
+	{flag: "sort", modes: []string{"topn"}},
+	{flag: "scale-legend", modes: []string{"smart", "topn"}},
+	{flag: "age-heat", modes: []string{"tree", "topn"}},
+	{flag: "annotate", modes: []string{"tree", "topn"}},
+	{flag: "units", modes: []string{"tree", "topn"}},
+}
+
+// ignoredFlags describes explicitly-set option flags that none of modes
+// honors. rtl is --rtl, which makes tree mode honor --width.
+func ignoredFlags(modes []string, rtl bool) []string {
+	if len(modes) == 0 {
+		return nil
+	}
+	where := modes[0] + " mode"
+	if len(modes) > 1 {
+		where = strings.Join(modes, ", ") + " modes"
+	}
+	var ignored []string
+	for _, f := range optionFlags {
+		if flagWasSet(f.flag) && !slices.ContainsFunc(modes, func(mode string) bool { return f.honoredBy(mode, rtl) }) {
+			ignored = append(ignored, fmt.Sprintf("--%s has no effect in %s", f.flag, where))
+		}
+	}
+	return ignored
+}
+
+// honoredBy reports whether mode honors the flag. rtl is as for ignoredFlags.
+func (f optionFlag) honoredBy(mode string, rtl bool) bool {
+	if f.option == render.OptionWidth && rtl && mode == "tree" {
+		return true
+	}
+	if f.option != "" {
+		info, _ := render.LookupMode(mode)
+		return info.Supports(f.option)
 	}
+	return slices.Contains(f.modes, mode)
 }
 
 // flagWasSet returns true if the flag was explicitly provided on command line.
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+// chdirTestRepo creates an empty git repository, makes it the working
+// directory for the test, and returns a helper that runs git in it.
+func chdirTestRepo(t *testing.T) func(args ...string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	for key, value := range map[string]string{
+		"GIT_AUTHOR_NAME": "test", "GIT_AUTHOR_EMAIL": "test@example.com",
+		"GIT_COMMITTER_NAME": "test", "GIT_COMMITTER_EMAIL": "test@example.com",
+		"GIT_CONFIG_GLOBAL": "/dev/null", "GIT_CONFIG_NOSYSTEM": "1",
+	} {
+		t.Setenv(key, value)
+	}
+	t.Chdir(t.TempDir())
+
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	return git
+}
+
+// TestMain runs main instead of the tests when runCLI re-executes the test
+// binary.
+func TestMain(m *testing.M) {
+	if os.Getenv("GIT_DIFF_TREE_TEST_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runCLI runs git-diff-tree with args in the working directory and returns
+// its stdout, its stderr, and its exit error.
+func runCLI(t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "GIT_DIFF_TREE_TEST_MAIN=1")
+	var stdout, stderr strings.Builder
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	return stdout.String(), stderr.String(), err
+}
+
+// runJSON runs git-diff-tree with args and decodes its stats JSON.
+func runJSON(t *testing.T, args ...string) diff.StatsJSON {
+	t.Helper()
+	stdout, stderr, err := runCLI(t, args...)
+	if err != nil {
+		t.Fatalf("git-diff-tree %v: %v\n%s", args, err, stderr)
+	}
+	var stats diff.StatsJSON
+	if err := json.Unmarshal([]byte(stdout), &stats); err != nil {
+		t.Fatalf("git-diff-tree %v output %q: %v", args, stdout, err)
+	}
+	return stats
+}
+
+// statsPaths returns the file paths in stats.
+func statsPaths(stats diff.StatsJSON) []string {
+	var paths []string
+	for _, f := range stats.Files {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
+
+func TestJSONSubcommand(t *testing.T) {
+	git := chdirTestRepo(t)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(name, []byte(name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", name)
+		git("commit", "-q", "-m", "Add "+name)
+	}
+	if err := os.WriteFile("a.txt", []byte("changed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	head := git("rev-parse", "HEAD")
+
+	if got := statsPaths(runJSON(t, "json", "--no-provenance")); !reflect.DeepEqual(got, []string{"a.txt"}) {
+		t.Errorf("json files = %v, want the working tree's a.txt", got)
+	}
+	stats := runJSON(t, "json", "HEAD~1", "HEAD")
+	if got := statsPaths(stats); !reflect.DeepEqual(got, []string{"b.txt"}) {
+		t.Errorf("json HEAD~1 HEAD files = %v, want the range's b.txt", got)
+	}
+	if stats.Provenance == nil || stats.Provenance.Refs["HEAD"] != head {
+		t.Errorf("json HEAD~1 HEAD provenance = %+v, want HEAD resolved to %s", stats.Provenance, head)
+	}
+	if got := statsPaths(runJSON(t, "--stats-json", "--no-provenance", "HEAD~1", "HEAD")); !reflect.DeepEqual(got, []string{"b.txt"}) {
+		t.Errorf("--stats-json HEAD~1 HEAD files = %v, want the range's b.txt", got)
+	}
+
+	// A typo fails instead of falling back to the working tree
+	_, stderr, err := runCLI(t, "json", "mian")
+	if err == nil || !strings.Contains(stderr, "mian") {
+		t.Errorf("json mian: err = %v, stderr = %q, want a bad revision error", err, stderr)
+	}
+}
+
+func TestJSONSubcommand_Shortcuts(t *testing.T) {
+	git := chdirTestRepo(t)
+	if err := os.WriteFile("a.txt", []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "Add a")
+	if err := os.WriteFile("a.txt", []byte("stashed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("stash", "-q")
+
+	if got := statsPaths(runJSON(t, "json", "--no-provenance", "--stash", "0")); !reflect.DeepEqual(got, []string{"a.txt"}) {
+		t.Errorf("json --stash 0 files = %v, want the stash's a.txt", got)
+	}
+	if _, _, err := runCLI(t, "json", "--upstream"); err == nil {
+		t.Error("json --upstream without an upstream should fail")
+	}
+	if _, _, err := runCLI(t, "json", "--stash", "0", "HEAD"); err == nil {
+		t.Error("json --stash with a revision argument should fail")
+	}
+}
+
+func TestJSONSubcommand_SinceRelease(t *testing.T) {
+	git := chdirTestRepo(t)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(name, []byte(name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", name)
+		git("commit", "-q", "-m", "Add "+name)
+		if name == "a.txt" {
+			git("tag", "v1.0.0")
+		}
+	}
+
+	stats := runJSON(t, "json", "--since-release")
+	if got := statsPaths(stats); !reflect.DeepEqual(got, []string{"b.txt"}) {
+		t.Errorf("json --since-release files = %v, want b.txt added since v1.0.0", got)
+	}
+	if stats.Provenance == nil || stats.Provenance.Refs["v1.0.0"] == "" {
+		t.Errorf("json --since-release provenance = %+v, want v1.0.0 resolved", stats.Provenance)
+	}
+	if _, _, err := runCLI(t, "json", "--since-release", "--release-match", "release-*"); err == nil {
+		t.Error("json --since-release with no matching tag should fail")
+	}
+}
+
+func TestRedactPaths_Revisions(t *testing.T) {
+	git := chdirTestRepo(t)
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", name)
+		git("commit", "-q", "-m", "Change "+name)
+	}
+	write("a.txt", "a\n")
+	git("branch", "-M", "trunk")
+	git("checkout", "-q", "-b", "secret-topic")
+	write("a.txt", "topic\n")
+	git("checkout", "-q", "trunk")
+	write("a.txt", "trunk\n")
+
+	for _, args := range [][]string{
+		{"--redact-paths", "--no-color", "--conflicts-preview", "trunk", "secret-topic"},
+		{"--redact-paths", "--no-color", "--union", "trunk~1..trunk", "trunk~1..secret-topic"},
+		{"json", "--redact-paths", "trunk", "secret-topic"},
+	} {
+		stdout, stderr, err := runCLI(t, args...)
+		if err != nil {
+			t.Fatalf("git-diff-tree %v: %v\n%s", args, err, stderr)
+		}
+		if strings.Contains(stdout, "secret-topic") || strings.Contains(stdout, "a.txt") {
+			t.Errorf("git-diff-tree %v output names a branch or path:\n%s", args, stdout)
+		}
+	}
+
+	// The key stays in the repository, so runs redact alike
+	first, _, _ := runCLI(t, "json", "--redact-paths", "--no-provenance", "trunk", "secret-topic")
+	second, _, _ := runCLI(t, "json", "--redact-paths", "--no-provenance", "trunk", "secret-topic")
+	if first != second {
+		t.Errorf("redacted runs differ:\n%s\n%s", first, second)
+	}
+	if _, err := os.Stat(".git/" + diff.RedactKeyFile); err != nil {
+		t.Errorf("redaction key not stored: %v", err)
+	}
+}
+
+func TestResolveDiffArgs(t *testing.T) {
+	git := chdirTestRepo(t)
+
+	// Before the first commit, HEAD passes through as the working tree
+	if got, err := resolveDiffArgs([]string{"HEAD"}, revisionShortcuts{}); err != nil || !reflect.DeepEqual(got, []string{"HEAD"}) {
+		t.Errorf("no commits: got %q, %v; want [HEAD]", got, err)
+	}
+
+	if err := os.WriteFile("a.txt", []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "one")
+	git("tag", "v1.0.0")
+	base := git("rev-parse", "HEAD")
+	git("branch", "base")
+	git("commit", "-q", "--allow-empty", "-m", "two")
+	git("branch", "--set-upstream-to=base")
+	if err := os.WriteFile("a.txt", []byte("two\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("stash", "-q")
+	head := git("rev-parse", "HEAD")
+	stash := git("rev-parse", "stash@{0}")
+
+	tests := []struct {
+		name      string
+		args      []string
+		shortcuts revisionShortcuts
+		want      []string
+		wantErr   string
+	}{
+		{name: "positional", args: []string{"base..HEAD"}, want: []string{"base..HEAD"}},
+		{name: "upstream", shortcuts: revisionShortcuts{upstream: true}, want: []string{base}},
+		{name: "stash", shortcuts: revisionShortcuts{stash: true}, want: []string{head, stash}},
+		{name: "since release", shortcuts: revisionShortcuts{sinceRelease: true, releaseMatch: "v*"}, want: []string{"v1.0.0", "HEAD"}},
+		{name: "typo", args: []string{"bsae"}, wantErr: `unknown revision "bsae" (did you mean: base?)`},
+		{name: "missing stash entry", shortcuts: revisionShortcuts{stash: true, stashIndex: 2}, wantErr: "only has 1 entries"},
+		{name: "no push target", shortcuts: revisionShortcuts{pushed: true}, wantErr: "cannot resolve @{push}"},
+		{name: "two shortcuts", shortcuts: revisionShortcuts{upstream: true, stash: true}, wantErr: "mutually exclusive"},
+		{name: "shortcut and args", args: []string{"HEAD"}, shortcuts: revisionShortcuts{upstream: true}, wantErr: "cannot be combined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveDiffArgs(tt.args, tt.shortcuts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := resolveDiffArgs([]string{"bsae"}, revisionShortcuts{}); !errors.Is(err, diff.ErrBadRevision) {
+		t.Errorf("typo err = %v, want ErrBadRevision", err)
+	}
+}
+
+func TestGetCompareStats(t *testing.T) {
+	git := chdirTestRepo(t)
+	if err := os.WriteFile("a.txt", []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "Add a")
+
+	if _, err := getCompareStats("mian..HEAD", render.SortByTotal, false); !errors.Is(err, diff.ErrBadRevision) {
+		t.Errorf("typo err = %v, want ErrBadRevision", err)
+	}
+	if _, err := getCompareStats("--output=out.txt", render.SortByTotal, false); err == nil {
+		t.Error("option as range: want an error")
+	}
+	if _, err := os.Stat("out.txt"); err == nil {
+		t.Error("option as range reached git diff")
+	}
+}
+
+func TestOptionFlagHonoredBy(t *testing.T) {
+	modes := (&modeFlag{outputs: []modeOutput{{"smart", "s.txt"}, {outputJSON, "s.json"}, {"tree", "t.txt"}}}).active("tree", false)
+	if got := activeModes(modes); !reflect.DeepEqual(got, []string{"smart", "tree"}) {
+		t.Errorf("activeModes() = %v, want [smart tree]", got)
+	}
+
+	multiline := optionFlag{flag: "multiline", modes: []string{"smart"}}
+	if !multiline.honoredBy("smart", false) || multiline.honoredBy("tree", false) {
+		t.Error("--multiline: want honored by smart only")
+	}
+	width := optionFlag{flag: "width", option: render.OptionWidth}
+	if width.honoredBy("tree", false) || !width.honoredBy("tree", true) || !width.honoredBy("smart", false) {
+		t.Error("--width: want honored by smart, and by tree only with --rtl")
+	}
+}
+
+func TestParseWidths(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []int
+		wantErr bool
+	}{
+		{in: "60", want: []int{60}},
+		{in: "60,100,160", want: []int{60, 100, 160}},
+		{in: " 60 , 100 ", want: []int{60, 100}},
+		{in: "", wantErr: true},
+		{in: "60,,100", wantErr: true},
+		{in: "60,", wantErr: true},
+		{in: "wide", wantErr: true},
+		{in: "60,1e2", wantErr: true},
+		{in: "0", wantErr: true},
+		{in: "60,-100", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseWidths(tt.in)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseWidths(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestDemo_Filtered(t *testing.T) {
+	git := chdirTestRepo(t)
+	git("commit", "-q", "--allow-empty", "-m", "root")
+	if err := os.WriteFile("a.txt", []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "a")
+
+	stdout, stderr, err := runCLI(t, "--demo", "-m", "smart,tree", "--demo-width", "60,100")
+	if err != nil {
+		t.Fatalf("--demo: %v\n%s", err, stderr)
+	}
+	var headers []string
+	for _, line := range strings.Split(stdout, "\n") {
+		if strings.HasPrefix(line, "=== ") {
+			headers = append(headers, line)
+		}
+	}
+	// Only the requested modes, with widths applied to width-sensitive ones
+	want := []string{"=== smart (width 60) ===", "=== smart (width 100) ===", "=== tree ==="}
+	if !reflect.DeepEqual(headers, want) {
+		t.Errorf("demo headers = %q, want %q\n%s", headers, want, stdout)
+	}
+	if !strings.Contains(stdout, "a.txt") {
+		t.Errorf("demo output missing a.txt:\n%s", stdout)
+	}
+
+	if _, _, err := runCLI(t, "--demo", "--demo-width", "60,0"); err == nil {
+		t.Error("--demo-width 60,0 succeeded, want an error")
+	}
+}
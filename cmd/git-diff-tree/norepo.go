@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// exitNotARepo is the exit status outside a git repository, so shell prompts
+// and editor hooks can tell "nothing to diff here" from a failure.
+const exitNotARepo = 3
+
+// requireRepo exits with exitNotARepo and a hint on how to get going when the
+// working directory is not in a git repository. Other git failures fall
+// through to the diff, which reports them as before.
+func requireRepo() {
+	if _, err := diff.HasHead(); !errors.Is(err, diff.ErrNotARepo) {
+		return
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = "."
+	}
+	fmt.Fprint(os.Stderr, notARepoMessage(cwd, diff.RepoAbove(cwd)))
+	os.Exit(exitNotARepo)
+}
+
+// notARepoMessage explains that cwd is outside a repository. above is the
+// nearest repository found by walking up ("" if none).
+func notARepoMessage(cwd, above string) string {
+	msg := fmt.Sprintf("git-diff-tree: %s is not in a git repository\n", cwd)
+	if above != "" {
+		return msg + fmt.Sprintf("  %s has a .git directory, but git did not search that far up\n"+
+			"  (filesystem boundary or GIT_CEILING_DIRECTORIES). Run from there:\n\n    cd %s && git-diff-tree\n", above, above)
+	}
+	return msg + "  No repository in any parent directory either. cd into a repository,\n" +
+		"  or start tracking this directory with: git init\n"
+}
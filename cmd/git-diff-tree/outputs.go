@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/config"
+	"github.com/kylesnowschwartz/diff-viz/diff"
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+// modeFlag collects -m/--mode values. A plain value selects the display
+// mode (the last one wins); NAME=FILE values add file outputs rendered from
+// the same stats.
+type modeFlag struct {
+	mode    string
+	set     bool
+	outputs []modeOutput
+}
+
+// modeOutput is one -m NAME=FILE: a mode, outline format, numstat+,
+// slack-blocks, speedscope, html, or json.
+type modeOutput struct {
+	name string
+	path string
+}
+
+func (f *modeFlag) String() string {
+	if f == nil || !f.set {
+		return "tree"
+	}
+	return f.mode
+}
+
+func (f *modeFlag) Set(s string) error {
+	name, path, ok := strings.Cut(s, "=")
+	if !ok {
+		f.mode, f.set = s, true
+		return nil
+	}
+	if path == "" {
+		return fmt.Errorf("%s=: missing file name", name)
+	}
+	if !isOutputName(name) {
+		return fmt.Errorf("unknown output %q (valid: %s)", name, strings.Join(outputNames(), ", "))
+	}
+	f.outputs = append(f.outputs, modeOutput{name: name, path: path})
+	return nil
+}
+
+// outputJSON is the -m NAME=FILE name for --stats-json output.
+const outputJSON = "json"
+
+// outputNames lists the names -m NAME=FILE accepts.
+func outputNames() []string {
+	names := render.DiffModeNames()
+	for _, f := range render.OutlineFormats {
+		names = append(names, string(f))
+	}
+	return append(names, formatNumstatPlus, formatSlackBlocks, exportSpeedscope, exportHTML, outputJSON)
+}
+
+func isOutputName(name string) bool {
+	for _, n := range outputNames() {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// active reports which outputs this run renders: the display mode (when
+// shown) and every file output, so mode-specific analysis runs once for all.
+func (f *modeFlag) active(display string, shown bool) map[string]bool {
+	active := make(map[string]bool, len(f.outputs)+1)
+	if shown {
+		active[display] = true
+	}
+	for _, o := range f.outputs {
+		active[o.name] = true
+	}
+	return active
+}
+
+// activeModes returns the diff modes in active, sorted.
+func activeModes(active map[string]bool) []string {
+	var modes []string
+	for name := range active {
+		if render.IsDiffMode(name) {
+			modes = append(modes, name)
+		}
+	}
+	sort.Strings(modes)
+	return modes
+}
+
+// writeOutputs renders stats to each -m NAME=FILE output without color.
+// base carries the display mode's settings; each mode re-resolves its own
+// config. dirDepth > 0 adds a "dirs" array to json outputs.
+func writeOutputs(outputs []modeOutput, stats *diff.DiffStats, base renderOptions, cfg *config.Config, cliFlags *config.ModeConfig, sizeThresholds []diff.SizeThreshold, dirDepth int) error {
+	for _, o := range outputs {
+		if err := writeOutput(o, stats, base, cfg, cliFlags, sizeThresholds, dirDepth); err != nil {
+			return fmt.Errorf("%s=%s: %w", o.name, o.path, err)
+		}
+	}
+	return nil
+}
+
+func writeOutput(o modeOutput, stats *diff.DiffStats, base renderOptions, cfg *config.Config, cliFlags *config.ModeConfig, sizeThresholds []diff.SizeThreshold, dirDepth int) error {
+	file, err := os.Create(o.path)
+	if err != nil {
+		return err
+	}
+
+	if o.name == outputJSON {
+		output, err := marshalStatsJSON(stats, sizeThresholds, dirDepth)
+		if err == nil {
+			_, err = fmt.Fprintln(file, string(output))
+		}
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}
+
+	var renderer render.Renderer
+	if format, err := render.ParseOutlineFormat(o.name); err == nil {
+		outline := render.NewOutlineRenderer(file, format)
+		outline.MaxPathDepth = base.maxPathDepth
+		renderer = outline
+	} else if o.name == formatNumstatPlus {
+		renderer = render.NewNumstatRenderer(file)
+	} else if o.name == formatSlackBlocks {
+		renderer = render.NewSlackBlocksRenderer(file)
+	} else if o.name == exportSpeedscope {
+		renderer = render.NewSpeedscopeRenderer(file)
+	} else if o.name == exportHTML {
+		page := render.NewHTMLRenderer(file)
+		page.MaxPathDepth = base.maxPathDepth
+		renderer = page
+	} else {
+		flags := base.renderFlags
+		flags.useColor = false
+		opts, err := newRenderOptions(cfg.Resolve(o.name, cliFlags), flags)
+		if err != nil {
+			file.Close()
+			return err
+		}
+		opts.widthAuto = false // Files have no terminal to measure
+		opts.sizeClass = base.sizeClass
+		opts.excluded = base.excluded
+		opts.compare = base.compare
+		opts.dirDepths = base.dirDepths
+		opts.out = file
+		renderer = getRenderer(o.name, opts)
+		if opts.bytes && binarySummaryModes[o.name] {
+			stats, _ = stats.InBytes()
+		}
+	}
+	renderer.Render(stats)
+	return file.Close()
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// pagedOutput buffers a render when stdout is a terminal so output taller
+// than the screen can be sent through a pager, the way git does.
+type pagedOutput struct {
+	buf     bytes.Buffer
+	enabled bool
+}
+
+// newPagedOutput returns a pagedOutput; paging is off when disabled is set
+// or stdout is not a terminal.
+func newPagedOutput(disabled bool) *pagedOutput {
+	return &pagedOutput{enabled: !disabled && term.IsTerminal(int(os.Stdout.Fd()))}
+}
+
+// Writer returns where renderers should write.
+func (p *pagedOutput) Writer() io.Writer {
+	if p.enabled {
+		return &p.buf
+	}
+	return os.Stdout
+}
+
+// Flush writes buffered output, through the pager if it exceeds the
+// terminal height (see writePaged).
+func (p *pagedOutput) Flush() {
+	if !p.enabled {
+		return
+	}
+	defer p.buf.Reset()
+
+	_, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		height = 0
+	}
+	writePaged(os.Stdout, p.buf.Bytes(), height, pagerCommand())
+}
+
+// writePaged writes out to w, through pager when out has at least height
+// lines (height 0 never pages). Falls back to writing w directly if the
+// pager cannot run.
+func writePaged(w io.Writer, out []byte, height int, pager string) {
+	if height <= 0 || bytes.Count(out, []byte("\n")) < height || pager == "" || pager == "cat" {
+		w.Write(out)
+		return
+	}
+
+	cmd := exec.Command("sh", "-c", pager)
+	cmd.Stdin = bytes.NewReader(out)
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(cmd.Env, "LESS=FRX") // Same defaults git uses
+	}
+	if os.Getenv("LV") == "" {
+		cmd.Env = append(cmd.Env, "LV=-c")
+	}
+	if err := cmd.Run(); err != nil {
+		// sh exits 127 when the pager command is not found; other exit
+		// statuses mean the pager ran (and may have shown the output)
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() == 127 {
+			w.Write(out) // Pager missing; don't lose output
+		}
+	}
+}
+
+// pagerCommand resolves the pager with git's precedence:
+// $GIT_PAGER, then core.pager, then $PAGER, then "less".
+func pagerCommand() string {
+	if pager, ok := os.LookupEnv("GIT_PAGER"); ok {
+		return pager
+	}
+	if out, err := exec.Command("git", "config", "--get", "core.pager").Output(); err == nil {
+		return strings.TrimSpace(string(out))
+	}
+	if pager, ok := os.LookupEnv("PAGER"); ok {
+		return pager
+	}
+	return "less"
+}
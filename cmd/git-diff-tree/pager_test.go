@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPagerCommand(t *testing.T) {
+	git := chdirTestRepo(t)
+	unset := func(key string) {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+
+	unset("GIT_PAGER")
+	unset("PAGER")
+	if got := pagerCommand(); got != "less" {
+		t.Errorf("pagerCommand() = %q, want less", got)
+	}
+	t.Setenv("PAGER", "more")
+	if got := pagerCommand(); got != "more" {
+		t.Errorf("pagerCommand() with $PAGER = %q, want more", got)
+	}
+	git("config", "core.pager", "most")
+	if got := pagerCommand(); got != "most" {
+		t.Errorf("pagerCommand() with core.pager = %q, want most over $PAGER", got)
+	}
+	t.Setenv("GIT_PAGER", "less -S")
+	if got := pagerCommand(); got != "less -S" {
+		t.Errorf("pagerCommand() with $GIT_PAGER = %q, want less -S over core.pager", got)
+	}
+	t.Setenv("GIT_PAGER", "")
+	if got := pagerCommand(); got != "" {
+		t.Errorf("pagerCommand() with empty $GIT_PAGER = %q, want empty over core.pager", got)
+	}
+}
+
+func TestWritePaged(t *testing.T) {
+	const out = "one\ntwo\nthree\n"
+	const pager = "sed 's/^/paged: /'"
+	tests := []struct {
+		name   string
+		height int
+		pager  string
+		want   string
+	}{
+		{"shorter than the screen", 4, pager, out},
+		{"as tall as the screen", 3, pager, "paged: one\npaged: two\npaged: three\n"},
+		{"unknown height", 0, pager, out},
+		{"cat", 1, "cat", out},
+		{"missing pager", 1, "no-such-pager-for-git-diff-tree", out},
+		{"pager fails after reading", 1, "cat >/dev/null; exit 1", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			writePaged(&b, []byte(out), tt.height, tt.pager)
+			if b.String() != tt.want {
+				t.Errorf("writePaged() wrote %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
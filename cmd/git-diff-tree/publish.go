@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+	"github.com/kylesnowschwartz/diff-viz/publish"
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+// runPublish implements "git-diff-tree publish github-pr [flags] [<range>...]":
+// it renders the markdown outline and keeps one marker-tagged PR comment current.
+func runPublish(args []string) {
+	if len(args) == 0 || args[0] != "github-pr" {
+		fmt.Fprintln(os.Stderr, "usage: git-diff-tree publish github-pr [--pr N] [--repo owner/name] [<commit> [<commit>]]")
+		os.Exit(1)
+	}
+
+	fs := flag.NewFlagSet("publish github-pr", flag.ExitOnError)
+	pr := fs.Int("pr", publish.PRFromRef(os.Getenv("GITHUB_REF")), "Pull request number (default: from $GITHUB_REF)")
+	repo := fs.String("repo", os.Getenv("GITHUB_REPOSITORY"), "Repository as owner/name (default: $GITHUB_REPOSITORY)")
+	apiURL := fs.String("api-url", envOr("GITHUB_API_URL", publish.DefaultGitHubAPI), "GitHub API base URL")
+	author := fs.String("author", "", "Login whose marker comment to update (default: the token's user, or any bot for app tokens)")
+	timeout := fs.Duration("timeout", 30*time.Second, "Overall timeout for API calls")
+	dryRun := fs.Bool("dry-run", false, "Print the comment body instead of publishing")
+	verbose := fs.Bool("v", false, "Print warnings to stderr")
+	maxLines := fs.Int("max-output-lines", publishMaxLines, "Cap the outline at N lines to stay under comment size limits (0 = no limit)")
+	fs.Parse(args[1:])
+
+	diffArgs := fs.Args()
+	if err := diff.ValidateRevisions(diffArgs...); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	stats, warnings, err := diff.GetAllStats(diffArgs...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	printWarnings(warnings, *verbose)
+
+	body := prCommentBody(stats, diffArgs, *maxLines)
+	if *dryRun {
+		fmt.Print(body)
+		return
+	}
+
+	token := os.Getenv("GITHUB_TOKEN")
+	switch {
+	case token == "":
+		err = fmt.Errorf("GITHUB_TOKEN is not set")
+	case *repo == "":
+		err = fmt.Errorf("--repo or GITHUB_REPOSITORY is required")
+	case *pr <= 0:
+		err = fmt.Errorf("--pr is required outside a pull_request workflow")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	client := &publish.GitHubClient{Token: token, Repo: *repo, BaseURL: *apiURL, Author: *author}
+	result, err := client.UpsertComment(ctx, *pr, body)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		if errors.Is(err, publish.ErrRateLimited) {
+			os.Exit(2) // Distinguish "try later" from hard failures in CI
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("%s diff-viz comment on %s#%d\n", result, *repo, *pr)
+}
+
+// publishMaxLines bounds the PR comment outline. GitHub rejects comments
+// over 65536 characters; outline lines rarely reach 100.
+const publishMaxLines = 500
+
+// prCommentBody renders the markdown comment for a PR, with the outline
+// capped at maxLines (0 = no limit).
+func prCommentBody(stats *diff.DiffStats, diffArgs []string, maxLines int) string {
+	var sb strings.Builder
+	sb.WriteString("### Diff summary")
+	if len(diffArgs) > 0 {
+		sb.WriteString(" (`" + strings.Join(diffArgs, " ") + "`)")
+	}
+	sb.WriteString("\n\n")
+
+	if maxLines <= 0 {
+		render.NewOutlineRenderer(&sb, render.FormatMarkdown).Render(stats)
+		return sb.String()
+	}
+	limit := render.NewLineLimitWriter(&sb, maxLines)
+	render.NewOutlineRenderer(limit, render.FormatMarkdown).Render(stats)
+	limit.Close()
+	return sb.String()
+}
+
+// envOr returns the environment variable key, or fallback when unset.
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
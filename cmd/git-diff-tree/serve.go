@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kylesnowschwartz/diff-viz/config"
+	"github.com/kylesnowschwartz/diff-viz/diff"
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+// formatJSONL is serve's --format for streaming stats snapshots.
+const formatJSONL = "jsonl"
+
+// servePage wraps a plain-text rendering in a minimal HTML document.
+const servePage = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>git-diff-tree</title></head>
+<body><pre>%s</pre></body></html>
+`
+
+// runServe implements "git-diff-tree serve [--addr ADDR] [-m MODE] [--format jsonl [--interval D]] [--redact-paths] [<commit> [<commit>]]":
+// an HTTP server that renders the range fresh on every request.
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "Address to listen on")
+	mode := fs.String("m", "tree", "Output mode")
+	configPath := fs.String("config", "", "Path to JSON config file (default: the discovered one)")
+	verbose := fs.Bool("v", false, "Print warnings to stderr")
+	format := fs.String("format", "", "Also watch the range and write its stats to stdout: jsonl (one compact JSON line whenever they change)")
+	interval := fs.Duration("interval", 2*time.Second, "How often --format jsonl recomputes the stats")
+	fs.BoolVar(&redactPaths, "redact-paths", false, "Replace path components with stable short hashes (keeping extensions) in every endpoint")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, `Usage: git-diff-tree serve [--addr ADDR] [-m MODE] [--format jsonl [--interval D]] [--redact-paths] [<commit> [<commit>]]
+
+Endpoints: / (HTML page; ?offset=N&limit=M renders one page of lines, with
+the full count in X-Total-Lines), /stats.json (--stats-json output), /metrics
+(Prometheus totals), /healthz (ok while the repository is readable)`)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if redactPaths {
+		setRedactKey()
+	}
+
+	if *format != "" && *format != formatJSONL {
+		fmt.Fprintf(os.Stderr, "unknown format: %s (valid: %s)\n", *format, formatJSONL)
+		os.Exit(1)
+	}
+	if !render.IsDiffMode(*mode) {
+		fmt.Fprintf(os.Stderr, "unknown mode: %s (valid: %s)\n", *mode, strings.Join(render.DiffModeNames(), ", "))
+		os.Exit(1)
+	}
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	sizeThresholds, err := cfg.SizeThresholds()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	diffArgs := fs.Args()
+	if err := diff.ValidateRevisions(diffArgs...); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	srv := &server{cfg: cfg, mode: *mode, diffArgs: diffArgs, sizeThresholds: sizeThresholds, verbose: *verbose}
+
+	// --format jsonl streams the stats on its own clock, whether or not
+	// anyone loads a page
+	if *format == formatJSONL {
+		snapshot := func() ([]byte, error) {
+			stats, err := srv.readStats()
+			if err != nil {
+				return nil, err
+			}
+			return marshalStatsJSON(stats, sizeThresholds, 0)
+		}
+		go pollChanges(*interval, nil, snapshot, func(line []byte) {
+			fmt.Println(string(line))
+		})
+	}
+
+	fmt.Fprintf(os.Stderr, "serving %s mode on http://%s\n", *mode, *addr)
+	if err := http.ListenAndServe(*addr, srv.handler()); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// server answers serve's endpoints for one range. Every endpoint diffs
+// fresh, so they agree with each other and the page.
+type server struct {
+	cfg            *config.Config
+	mode           string
+	diffArgs       []string
+	sizeThresholds []diff.SizeThreshold
+	verbose        bool
+}
+
+// readStats diffs the range, redacted with --redact-paths.
+func (s *server) readStats() (*diff.DiffStats, error) {
+	stats, warnings, err := diff.GetAllStats(s.diffArgs...)
+	if err != nil {
+		return nil, err
+	}
+	printWarnings(warnings, s.verbose)
+	if redactPaths {
+		stats = stats.Redacted()
+	}
+	return stats, nil
+}
+
+// getStats is readStats for a handler: a failure is answered with a 500.
+func (s *server) getStats(w http.ResponseWriter) (*diff.DiffStats, bool) {
+	stats, err := s.readStats()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return stats, true
+}
+
+// handler routes the page, /stats.json, /metrics, and /healthz.
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		offset, limit, err := pageParams(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		stats, ok := s.getStats(w)
+		if !ok {
+			return
+		}
+
+		opts, err := newRenderOptions(s.cfg.Resolve(s.mode, nil), renderFlags{})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var buf bytes.Buffer
+		opts.widthAuto = false // No terminal; use the configured width
+		total := render.RenderPage(&buf, stats, offset, limit, func(out io.Writer) render.Renderer {
+			opts.out = out
+			return getRenderer(s.mode, opts)
+		})
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("X-Total-Lines", strconv.Itoa(total))
+		fmt.Fprintf(w, servePage, html.EscapeString(buf.String()))
+	})
+	mux.HandleFunc("/stats.json", func(w http.ResponseWriter, r *http.Request) {
+		stats, ok := s.getStats(w)
+		if !ok {
+			return
+		}
+		output, err := marshalStatsJSON(stats, s.sizeThresholds, 0)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, string(output))
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		stats, ok := s.getStats(w)
+		if !ok {
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writeMetrics(w, stats, stats.SizeClass(s.sizeThresholds))
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := diff.Repo(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+// pollChanges calls snapshot now and then every interval until stop is
+// closed (nil polls forever), passing each result that differs from the
+// previous one to changed. Failed snapshots are skipped.
+func pollChanges(interval time.Duration, stop <-chan struct{}, snapshot func() ([]byte, error), changed func([]byte)) {
+	var last []byte
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if current, err := snapshot(); err == nil && !bytes.Equal(current, last) {
+			last = current
+			changed(current)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pageParams reads the optional offset and limit query parameters of the
+// serve page (0 when absent; a limit of 0 renders to the end).
+func pageParams(r *http.Request) (offset, limit int, err error) {
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"offset", &offset}, {"limit", &limit}} {
+		v := r.URL.Query().Get(p.name)
+		if v == "" {
+			continue
+		}
+		if *p.dst, err = strconv.Atoi(v); err != nil || *p.dst < 0 {
+			return 0, 0, fmt.Errorf("%s: want a non-negative integer, got %q", p.name, v)
+		}
+	}
+	return offset, limit, nil
+}
+
+// writeMetrics writes the diff totals in the Prometheus text format for
+// the serve /metrics endpoint.
+func writeMetrics(w io.Writer, stats *diff.DiffStats, size diff.SizeClass) {
+	gauges := []struct {
+		name, help string
+		value      int
+	}{
+		{"git_diff_tree_files", "Files changed in the served range.", stats.TotalFiles},
+		{"git_diff_tree_additions", "Lines added in the served range.", stats.TotalAdd},
+		{"git_diff_tree_deletions", "Lines deleted in the served range.", stats.TotalDel},
+	}
+	for _, g := range gauges {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", g.name, g.help, g.name, g.name, g.value)
+	}
+	fmt.Fprintf(w, "# HELP git_diff_tree_size Size class of the served range (always 1).\n# TYPE git_diff_tree_size gauge\ngit_diff_tree_size{class=%q} 1\n", size)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestPollChanges(t *testing.T) {
+	snapshots := []struct {
+		data string
+		err  error
+	}{{"a", nil}, {"a", nil}, {"", errors.New("git failed")}, {"b", nil}, {"b", nil}}
+	stop := make(chan struct{})
+	var calls int
+	snapshot := func() ([]byte, error) {
+		s := snapshots[calls]
+		calls++
+		if calls == len(snapshots) {
+			close(stop)
+		}
+		return []byte(s.data), s.err
+	}
+
+	var changed []string
+	pollChanges(time.Millisecond, stop, snapshot, func(b []byte) { changed = append(changed, string(b)) })
+	if !reflect.DeepEqual(changed, []string{"a", "b"}) {
+		t.Errorf("changed = %q, want [a b]", changed)
+	}
+}
+
+func TestServe(t *testing.T) {
+	writeStdioRepo(t) // a.txt grown by two lines
+	srv := httptest.NewServer((&server{mode: "tree"}).handler())
+	defer srv.Close()
+
+	get := func(path string, wantStatus int, wantType string) (string, http.Header) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != wantStatus {
+			t.Errorf("GET %s status = %d, want %d", path, resp.StatusCode, wantStatus)
+		}
+		if got := resp.Header.Get("Content-Type"); !strings.HasPrefix(got, wantType) {
+			t.Errorf("GET %s Content-Type = %q, want %s", path, got, wantType)
+		}
+		return string(body), resp.Header
+	}
+
+	body, header := get("/", http.StatusOK, "text/html")
+	if !strings.Contains(body, "a.txt") || header.Get("X-Total-Lines") == "" {
+		t.Errorf("GET / = %q with X-Total-Lines %q, want a.txt and a line count", body, header.Get("X-Total-Lines"))
+	}
+	get("/?offset=-1", http.StatusBadRequest, "text/plain")
+
+	body, _ = get("/stats.json", http.StatusOK, "application/json")
+	var stats diff.StatsJSON
+	if err := json.Unmarshal([]byte(body), &stats); err != nil {
+		t.Fatalf("GET /stats.json = %q: %v", body, err)
+	}
+	if stats.Totals.FileCount != 1 || stats.Totals.Adds != 2 || stats.Totals.Size != "XS" {
+		t.Errorf("GET /stats.json totals = %+v, want 1 file, +2, XS", stats.Totals)
+	}
+
+	body, _ = get("/metrics", http.StatusOK, "text/plain; version=0.0.4")
+	for _, line := range []string{
+		"# TYPE git_diff_tree_files gauge",
+		"git_diff_tree_files 1",
+		"git_diff_tree_additions 2",
+		"git_diff_tree_deletions 0",
+		`git_diff_tree_size{class="XS"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("GET /metrics missing %q:\n%s", line, body)
+		}
+	}
+
+	body, _ = get("/healthz", http.StatusOK, "text/plain")
+	if body != "ok\n" {
+		t.Errorf("GET /healthz = %q, want ok", body)
+	}
+	t.Chdir(t.TempDir()) // The repository goes away
+	get("/healthz", http.StatusServiceUnavailable, "text/plain")
+}
+
+func TestWriteMetrics(t *testing.T) {
+	var b strings.Builder
+	writeMetrics(&b, &diff.DiffStats{TotalFiles: 3, TotalAdd: 120, TotalDel: 4}, diff.SizeL)
+	want := `# HELP git_diff_tree_files Files changed in the served range.
+# TYPE git_diff_tree_files gauge
+git_diff_tree_files 3
+# HELP git_diff_tree_additions Lines added in the served range.
+# TYPE git_diff_tree_additions gauge
+git_diff_tree_additions 120
+# HELP git_diff_tree_deletions Lines deleted in the served range.
+# TYPE git_diff_tree_deletions gauge
+git_diff_tree_deletions 4
+# HELP git_diff_tree_size Size class of the served range (always 1).
+# TYPE git_diff_tree_size gauge
+git_diff_tree_size{class="L"} 1
+`
+	if b.String() != want {
+		t.Errorf("writeMetrics() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kylesnowschwartz/diff-viz/config"
+	"github.com/kylesnowschwartz/diff-viz/diff"
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+// JSON-RPC 2.0 error codes used by --serve-stdio.
+const (
+	rpcParseError     = -32700
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcServerError    = -32000 // Git or render failure
+)
+
+// rpcDiffOptions are the options a request's args may pass to git diff.
+// Anything else (--output, --ext-diff, --textconv, ...) could write files or
+// run programs on behalf of the client, so it is rejected. Args after "--"
+// are pathspecs and pass through.
+var rpcDiffOptions = []string{
+	"--cached", "--staged", "--merge-base",
+	"-w", "--ignore-all-space", "-b", "--ignore-space-change",
+	"--ignore-blank-lines", "--ignore-cr-at-eol",
+	"-M", "--find-renames", "--no-renames",
+}
+
+// checkArgs rejects options in args that are not in rpcDiffOptions.
+func checkArgs(args []string) *rpcError {
+	for _, arg := range args {
+		if arg == "--" {
+			return nil
+		}
+		if strings.HasPrefix(arg, "-") && !slices.Contains(rpcDiffOptions, arg) {
+			return &rpcError{rpcInvalidParams, fmt.Sprintf("option %s is not allowed", arg)}
+		}
+	}
+	return nil
+}
+
+// rpcRequest is a JSON-RPC request or notification (no ID).
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcMessage is an outgoing response or notification.
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  any             `json:"params,omitempty"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// statsParams selects a diff: Args are passed as to the bare command
+// (e.g., ["HEAD~3"]); Depth > 0 adds the dirs array.
+type statsParams struct {
+	Args  []string `json:"args"`
+	Depth int      `json:"depth"`
+}
+
+// renderParams adds the mode and layout to statsParams. Width 0 uses the
+// mode's configured width.
+type renderParams struct {
+	statsParams
+	Mode  string `json:"mode"`
+	Width int    `json:"width"`
+	Color bool   `json:"color"`
+}
+
+// watchParams polls a diff every IntervalMS (default 1000) and sends a
+// statsChanged notification whenever the stats differ from the last poll.
+// Format "jsonl" sends the stats as a "line" string, the compact JSON line
+// serve --format jsonl writes, instead of a "stats" object.
+type watchParams struct {
+	statsParams
+	IntervalMS int    `json:"intervalMs"`
+	Format     string `json:"format"`
+}
+
+// stdioServer answers JSON-RPC requests, one JSON object per line, for
+// editor plugins that keep one process running.
+type stdioServer struct {
+	cfg        *config.Config
+	thresholds []diff.SizeThreshold
+	verbose    bool
+
+	mu      sync.Mutex // Guards out and watches
+	out     io.Writer
+	watches map[int]chan struct{}
+	nextID  int
+}
+
+// runServeStdio serves requests from r until EOF or a shutdown request.
+func runServeStdio(r io.Reader, w io.Writer, cfg *config.Config, thresholds []diff.SizeThreshold, verbose bool) {
+	s := &stdioServer{cfg: cfg, thresholds: thresholds, verbose: verbose, out: w, watches: make(map[int]chan struct{})}
+	defer s.stopWatches()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var req rpcRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			s.send(rpcMessage{ID: json.RawMessage("null"), Error: &rpcError{rpcParseError, err.Error()}})
+			continue
+		}
+		result, rerr := s.handle(req)
+		if req.ID != nil {
+			s.send(rpcMessage{ID: req.ID, Result: result, Error: rerr})
+		}
+		if req.Method == "shutdown" {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: --serve-stdio: %v\n", err)
+	}
+}
+
+// handle dispatches one request and returns its result or error.
+func (s *stdioServer) handle(req rpcRequest) (any, *rpcError) {
+	switch req.Method {
+	case "getStats":
+		var p statsParams
+		if err := decodeParams(req.Params, &p); err != nil {
+			return nil, err
+		}
+		if err := checkArgs(p.Args); err != nil {
+			return nil, err
+		}
+		stats, err := s.stats(p)
+		if err != nil {
+			return nil, &rpcError{rpcServerError, err.Error()}
+		}
+		return s.statsJSON(stats, p.Depth), nil
+
+	case "render":
+		p := renderParams{Mode: "tree"}
+		if err := decodeParams(req.Params, &p); err != nil {
+			return nil, err
+		}
+		if !render.IsDiffMode(p.Mode) {
+			return nil, &rpcError{rpcInvalidParams, "unknown mode: " + p.Mode}
+		}
+		if err := checkArgs(p.Args); err != nil {
+			return nil, err
+		}
+		stats, err := s.stats(p.statsParams)
+		if err != nil {
+			return nil, &rpcError{rpcServerError, err.Error()}
+		}
+		output, err := s.render(stats, p)
+		if err != nil {
+			return nil, &rpcError{rpcServerError, err.Error()}
+		}
+		return map[string]string{"output": output}, nil
+
+	case "watch":
+		p := watchParams{IntervalMS: 1000}
+		if err := decodeParams(req.Params, &p); err != nil {
+			return nil, err
+		}
+		if err := checkArgs(p.Args); err != nil {
+			return nil, err
+		}
+		if p.Format != "" && p.Format != formatJSONL {
+			return nil, &rpcError{rpcInvalidParams, fmt.Sprintf("unknown format: %s (valid: %s)", p.Format, formatJSONL)}
+		}
+		if err := diff.ValidateRevisions(p.Args...); err != nil {
+			return nil, &rpcError{rpcServerError, err.Error()}
+		}
+		return map[string]int{"watchId": s.watch(p)}, nil
+
+	case "unwatch":
+		var p struct {
+			WatchID int `json:"watchId"`
+		}
+		if err := decodeParams(req.Params, &p); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		if stop, ok := s.watches[p.WatchID]; ok {
+			close(stop)
+			delete(s.watches, p.WatchID)
+		}
+		s.mu.Unlock()
+		return true, nil
+
+	case "shutdown":
+		return true, nil
+
+	default:
+		return nil, &rpcError{rpcMethodNotFound, "unknown method: " + req.Method}
+	}
+}
+
+// decodeParams unmarshals params into v; absent params keep v's defaults.
+func decodeParams(params json.RawMessage, v any) *rpcError {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &rpcError{rpcInvalidParams, err.Error()}
+	}
+	return nil
+}
+
+// stats reads the diff selected by p, redacted with --redact-paths.
+func (s *stdioServer) stats(p statsParams) (*diff.DiffStats, error) {
+	if err := diff.ValidateRevisions(p.Args...); err != nil {
+		return nil, err
+	}
+	stats, warnings, err := diff.GetAllStats(p.Args...)
+	printWarnings(warnings, s.verbose)
+	if err == nil && redactPaths {
+		stats = stats.Redacted()
+	}
+	return stats, err
+}
+
+// statsJSON converts stats to the --stats-json representation.
+func (s *stdioServer) statsJSON(stats *diff.DiffStats, depth int) diff.StatsJSON {
+	result := stats.ToJSON()
+	result.Totals.Size = string(stats.SizeClass(s.thresholds))
+	if depth > 0 {
+		result.Dirs = stats.DirStats(depth)
+	}
+	return result
+}
+
+// render draws stats in p.Mode with the config's defaults for that mode.
+func (s *stdioServer) render(stats *diff.DiffStats, p renderParams) (string, error) {
+	opts, err := newRenderOptions(s.cfg.Resolve(p.Mode, nil), renderFlags{useColor: p.Color})
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	opts.out = &buf
+	opts.widthAuto = false // No terminal; use the requested or configured width
+	if p.Width > 0 {
+		opts.width = p.Width
+	}
+	opts.sizeClass = stats.SizeClass(s.thresholds)
+	getRenderer(p.Mode, opts).Render(stats)
+	return buf.String(), nil
+}
+
+// watch starts polling p in the background and returns the watch ID.
+func (s *stdioServer) watch(p watchParams) int {
+	s.mu.Lock()
+	s.nextID++
+	id := s.nextID
+	stop := make(chan struct{})
+	s.watches[id] = stop
+	s.mu.Unlock()
+
+	interval := time.Duration(max(p.IntervalMS, 100)) * time.Millisecond
+	snapshot := func() ([]byte, error) {
+		stats, err := s.stats(p.statsParams)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(s.statsJSON(stats, p.Depth))
+	}
+	go pollChanges(interval, stop, snapshot, func(encoded []byte) {
+		params := map[string]any{"watchId": id, "stats": json.RawMessage(encoded)}
+		if p.Format == formatJSONL {
+			params = map[string]any{"watchId": id, "line": string(encoded)}
+		}
+		s.send(rpcMessage{Method: "statsChanged", Params: params})
+	})
+	return id
+}
+
+// stopWatches ends every running watch.
+func (s *stdioServer) stopWatches() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, stop := range s.watches {
+		close(stop)
+		delete(s.watches, id)
+	}
+}
+
+// send writes one message as a line of JSON.
+func (s *stdioServer) send(msg rpcMessage) {
+	msg.JSONRPC = "2.0"
+	data, err := json.Marshal(msg)
+	if err != nil {
+		data, _ = json.Marshal(rpcMessage{JSONRPC: "2.0", ID: msg.ID, Error: &rpcError{rpcServerError, err.Error()}})
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.out.Write(append(data, '\n'))
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/config"
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// rpcResponse is an rpcMessage with the result left for the test to decode.
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+// stdioClient sends requests to runServeStdio in one pipe and reads its
+// messages from another, as an editor plugin would.
+type stdioClient struct {
+	t    *testing.T
+	in   *io.PipeWriter
+	out  *bufio.Scanner
+	done chan struct{}
+}
+
+func startServeStdio(t *testing.T) *stdioClient {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &stdioClient{t: t, in: inW, out: bufio.NewScanner(outR), done: make(chan struct{})}
+	go func() {
+		defer close(c.done)
+		runServeStdio(inR, outW, &config.Config{}, nil, false)
+		outW.Close()
+	}()
+	return c
+}
+
+// send writes one request line.
+func (c *stdioClient) send(request string) {
+	c.t.Helper()
+	if _, err := io.WriteString(c.in, request+"\n"); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// call sends request and returns its response, skipping notifications.
+func (c *stdioClient) call(request string) rpcResponse {
+	c.t.Helper()
+	c.send(request)
+	for {
+		if msg := c.next(); msg.Method == "" {
+			return msg
+		}
+	}
+}
+
+// next reads one message.
+func (c *stdioClient) next() rpcResponse {
+	c.t.Helper()
+	if !c.out.Scan() {
+		c.t.Fatal("no message from the server")
+	}
+	var msg rpcResponse
+	if err := json.Unmarshal(c.out.Bytes(), &msg); err != nil {
+		c.t.Fatalf("message %s: %v", c.out.Bytes(), err)
+	}
+	return msg
+}
+
+// shutdown asks the server to exit and waits for it.
+func (c *stdioClient) shutdown() {
+	c.t.Helper()
+	if msg := c.call(`{"jsonrpc":"2.0","id":"bye","method":"shutdown"}`); msg.Error != nil {
+		c.t.Errorf("shutdown error = %+v", msg.Error)
+	}
+	<-c.done
+}
+
+// writeStdioRepo commits a.txt and then grows it by two lines.
+func writeStdioRepo(t *testing.T) {
+	git := chdirTestRepo(t)
+	if err := os.WriteFile("a.txt", []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "Add a")
+	if err := os.WriteFile("a.txt", []byte("a\nb\nc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServeStdio(t *testing.T) {
+	writeStdioRepo(t)
+	c := startServeStdio(t)
+
+	wantError := func(request string, code int) {
+		t.Helper()
+		if msg := c.call(request); msg.Error == nil || msg.Error.Code != code {
+			t.Errorf("%s: error = %+v, want code %d", request, msg.Error, code)
+		}
+	}
+
+	msg := c.call(`{"jsonrpc":"2.0","id":1,"method":"getStats"}`)
+	if string(msg.ID) != "1" || msg.Error != nil {
+		t.Fatalf("getStats = %+v", msg)
+	}
+	var stats diff.StatsJSON
+	if err := json.Unmarshal(msg.Result, &stats); err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Files) != 1 || stats.Files[0].Path != "a.txt" || stats.Files[0].Adds != 2 {
+		t.Errorf("getStats files = %+v, want a.txt +2", stats.Files)
+	}
+
+	// Allowed options and pathspecs after "--" reach git
+	msg = c.call(`{"jsonrpc":"2.0","id":2,"method":"getStats","params":{"args":["--cached","--","--output=a.txt"]}}`)
+	if msg.Error != nil {
+		t.Fatalf("getStats --cached error = %+v", msg.Error)
+	}
+	if err := json.Unmarshal(msg.Result, &stats); err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Files) != 0 {
+		t.Errorf("getStats --cached files = %+v, want none staged", stats.Files)
+	}
+
+	msg = c.call(`{"jsonrpc":"2.0","id":3,"method":"render","params":{"mode":"tree","width":60}}`)
+	var rendered struct{ Output string }
+	if msg.Error != nil {
+		t.Fatalf("render error = %+v", msg.Error)
+	}
+	if err := json.Unmarshal(msg.Result, &rendered); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(rendered.Output, "a.txt") {
+		t.Errorf("render output = %q, want a.txt", rendered.Output)
+	}
+
+	wantError(`{"jsonrpc":"2.0","id":4,"method":"getStats","params":{"args":["--output=/tmp/x"]}}`, rpcInvalidParams)
+	wantError(`{"jsonrpc":"2.0","id":5,"method":"render","params":{"args":["--ext-diff"]}}`, rpcInvalidParams)
+	wantError(`{"jsonrpc":"2.0","id":6,"method":"watch","params":{"args":["--textconv"]}}`, rpcInvalidParams)
+	wantError(`{"jsonrpc":"2.0","id":7,"method":"render","params":{"mode":"trend"}}`, rpcInvalidParams)
+	wantError(`{"jsonrpc":"2.0","id":8,"method":"nope"}`, rpcMethodNotFound)
+	wantError(`not json`, rpcParseError)
+
+	c.shutdown()
+}
+
+func TestServeStdio_RedactPaths(t *testing.T) {
+	writeStdioRepo(t)
+	redactPaths = true
+	t.Cleanup(func() { redactPaths = false })
+	c := startServeStdio(t)
+
+	msg := c.call(`{"jsonrpc":"2.0","id":1,"method":"getStats"}`)
+	var stats diff.StatsJSON
+	if err := json.Unmarshal(msg.Result, &stats); err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Files) != 1 || stats.Files[0].Path != diff.RedactPath("a.txt") {
+		t.Errorf("getStats files = %+v, want a.txt redacted", stats.Files)
+	}
+	c.shutdown()
+}
+
+func TestServeStdio_WatchJSONL(t *testing.T) {
+	writeStdioRepo(t)
+	c := startServeStdio(t)
+
+	// The first notification can beat the response to the watch request
+	c.send(`{"jsonrpc":"2.0","id":1,"method":"watch","params":{"intervalMs":100,"format":"jsonl"}}`)
+	var lines []string
+	nextLine := func() {
+		t.Helper()
+		for {
+			msg := c.next()
+			if msg.Method == "" {
+				if msg.Error != nil {
+					t.Fatalf("watch error = %+v", msg.Error)
+				}
+				continue
+			}
+			var params struct {
+				WatchID int             `json:"watchId"`
+				Line    string          `json:"line"`
+				Stats   json.RawMessage `json:"stats"`
+			}
+			if err := json.Unmarshal(msg.Params, &params); err != nil {
+				t.Fatal(err)
+			}
+			if msg.Method != "statsChanged" || params.WatchID != 1 || params.Stats != nil {
+				t.Fatalf("notification = %s %s, want statsChanged with a line", msg.Method, msg.Params)
+			}
+			lines = append(lines, params.Line)
+			return
+		}
+	}
+
+	nextLine()
+	if err := os.WriteFile("a.txt", []byte("a\nb\nc\nd\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	nextLine()
+	for i, want := range []int{2, 3} {
+		var stats diff.StatsJSON
+		if err := json.Unmarshal([]byte(lines[i]), &stats); err != nil {
+			t.Fatalf("line %d %q: %v", i, lines[i], err)
+		}
+		if strings.Contains(lines[i], "\n") || stats.Totals.Adds != want {
+			t.Errorf("line %d = %q, want one line with %d adds", i, lines[i], want)
+		}
+	}
+
+	if msg := c.call(`{"jsonrpc":"2.0","id":2,"method":"watch","params":{"format":"yaml"}}`); msg.Error == nil || msg.Error.Code != rpcInvalidParams {
+		t.Errorf("watch format yaml: error = %+v, want invalid params", msg.Error)
+	}
+	c.call(`{"jsonrpc":"2.0","id":3,"method":"unwatch","params":{"watchId":1}}`)
+	c.shutdown()
+}
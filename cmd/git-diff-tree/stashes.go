@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+// runStashes prints a one-line summary per stash entry.
+func runStashes(w io.Writer, useColor, verbose bool) {
+	entries, warnings, err := diff.ListStashes()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	printWarnings(warnings, verbose)
+
+	if redactPaths {
+		for i := range entries {
+			entries[i].Message = ""
+			if entries[i].Stats != nil {
+				entries[i].Stats = entries[i].Stats.Redacted()
+			}
+		}
+	}
+	render.NewStashesRenderer(w, useColor).RenderStashes(entries)
+}
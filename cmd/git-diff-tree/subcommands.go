@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/config"
+	"github.com/kylesnowschwartz/diff-viz/diff"
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+// flagSubcommands are subcommands that stand for main flags. Their remaining
+// arguments are parsed exactly like the bare command's, so "json HEAD~3" is
+// "--stats-json HEAD~3" and "baseline TREE" is "--stats-json --baseline TREE".
+var flagSubcommands = map[string][]string{
+	"view":     nil,
+	"json":     {"--stats-json"},
+	"demo":     {"--demo"},
+	"baseline": {"--stats-json", "--baseline"},
+}
+
+// expandSubcommand rewrites args (without the program name) that start with a
+// flag subcommand into the equivalent flags. Other args are returned as-is,
+// so bare "git-diff-tree [range]" keeps working; a branch named like a
+// subcommand needs the view prefix ("git-diff-tree view json").
+func expandSubcommand(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	flags, ok := flagSubcommands[args[0]]
+	if !ok {
+		return args
+	}
+	return append(append([]string(nil), flags...), args[1:]...)
+}
+
+// actionFlags select an output other than a mode; at most one may be given.
+var actionFlags = []string{"demo", "stats-json", "dump-defaults", "list-modes", "serve-stdio"}
+
+// checkActionFlags returns an error when more than one action flag is set.
+func checkActionFlags() error {
+	var set []string
+	for _, name := range actionFlags {
+		if flagWasSet(name) {
+			set = append(set, "--"+name)
+		}
+	}
+	if len(set) > 1 {
+		return fmt.Errorf("%s cannot be combined", strings.Join(set, " and "))
+	}
+	return nil
+}
+
+// runConfig implements "git-diff-tree config dump|check|path|get|set".
+func runConfig(args []string) {
+	fs := flag.NewFlagSet("config", flag.ExitOnError)
+	file := fs.String("config", "", "Config file for get and set (default: the discovered one)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, `Usage:
+  git-diff-tree config dump             Print the default config as a JSON template
+  git-diff-tree config check FILE       Report errors and unused entries in FILE
+  git-diff-tree config path             Print where the config file is looked for
+  git-diff-tree config get KEY          Print a setting (e.g., modes.topn.n)
+  git-diff-tree config set KEY VALUE    Change a setting in the config file
+
+Options (before the subcommand):
+  --config FILE   Config file for get and set (default: the path above)
+
+KEY is dotted; quote names holding dots: repos["github.com/org/repo"].defaults.width.
+VALUE is JSON (20, true, "auto", ["red"]); anything else is taken as a string.`)
+	}
+	fs.Parse(args)
+
+	switch fs.Arg(0) {
+	case "dump":
+		output, _ := json.MarshalIndent(config.DefaultConfigJSON(), "", "  ")
+		fmt.Println(string(output))
+	case "check":
+		if fs.NArg() != 2 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		cfg, err := config.Load(fs.Arg(1))
+		if err == nil {
+			err = checkConfig(cfg)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		warnings := render.CheckConfig(cfg)
+		for _, w := range warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+		}
+		if len(warnings) > 0 {
+			os.Exit(1)
+		}
+		fmt.Printf("%s: ok\n", fs.Arg(1))
+	case "path":
+		if fs.NArg() != 1 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		path, err := configFile(*file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(path)
+	case "get":
+		if fs.NArg() != 2 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		if err := configGet(*file, fs.Arg(1)); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	case "set":
+		if fs.NArg() != 3 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		if err := configSet(*file, fs.Arg(1), fs.Arg(2)); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// checkConfig returns the first error in cfg's settings that Load leaves
+// unchecked.
+func checkConfig(cfg *config.Config) error {
+	if _, err := cfg.SizeThresholds(); err != nil {
+		return err
+	}
+	if _, err := cfg.UntrackedSizeLimit(); err != nil {
+		return err
+	}
+	if _, err := cfg.Exclusions(); err != nil {
+		return err
+	}
+	return cfg.CheckRepos()
+}
+
+// configFile returns path, or the default config file path when it is empty.
+func configFile(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	return config.DefaultPath()
+}
+
+// configGet prints the JSON value of key from the config file, or its
+// built-in default when the file does not set it.
+func configGet(file, key string) error {
+	keyPath, err := config.ParseKey(key)
+	if err == nil {
+		err = config.CheckKey(keyPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	path, err := configFile(file)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	value, ok, err := config.Get(data, keyPath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if !ok {
+		defaults, _ := json.Marshal(config.DefaultConfigJSON())
+		if value, ok, _ = config.Get(defaults, keyPath); !ok {
+			return fmt.Errorf("%s is not set", key)
+		}
+	}
+
+	var out bytes.Buffer
+	if json.Indent(&out, value, "", "  ") != nil {
+		out.Reset()
+		out.Write(value)
+	}
+	fmt.Println(out.String())
+	return nil
+}
+
+// configSet sets key to value in the config file, creating the file if
+// needed. The file is only written when the result still loads and adds no
+// config warnings (such as an unknown mode name).
+func configSet(file, key, value string) error {
+	keyPath, err := config.ParseKey(key)
+	if err == nil {
+		err = config.CheckKey(keyPath)
+	}
+	if err != nil {
+		return err
+	}
+	raw := json.RawMessage(value)
+	if !json.Valid(raw) {
+		raw, _ = json.Marshal(value)
+	}
+
+	path, err := configFile(file)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	before := map[string]bool{}
+	if old, err := config.Parse(data); err == nil && len(data) > 0 {
+		for _, w := range render.CheckConfig(old) {
+			before[w] = true
+		}
+	}
+
+	updated, err := config.Set(data, keyPath, raw)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	cfg, err := config.Parse(updated)
+	if err == nil {
+		err = checkConfig(cfg)
+	}
+	if err != nil {
+		return fmt.Errorf("%s=%s: %w", key, raw, err)
+	}
+	for _, w := range render.CheckConfig(cfg) {
+		if !before[w] {
+			return fmt.Errorf("%s=%s: %s", key, raw, w)
+		}
+	}
+	return writeConfigFile(path, updated)
+}
+
+// writeConfigFile replaces path with data through a temporary file, so an
+// interrupted write never leaves a truncated config. The file keeps its
+// permissions.
+func writeConfigFile(path string, data []byte) error {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(mode)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// commitMsgHook returns a commit-msg hook that runs exe to append diff
+// trailers for the staged changes to the message. exe is an absolute path,
+// since git runs hooks with a PATH that may not include the binary.
+func commitMsgHook(exe string) string {
+	return `#!/bin/sh
+# Added by git-diff-tree hook install: appends Diff-* trailers for staged changes
+` + shellQuote(exe) + ` -m trailers -- --cached | while IFS= read -r t; do
+  git interpret-trailers --in-place --if-exists replace --trailer "$t" "$1"
+done
+`
+}
+
+// hookExecutable returns the running binary's path for the hook, falling
+// back to looking git-diff-tree up on PATH.
+func hookExecutable() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "git-diff-tree"
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return exe
+}
+
+// shellQuote quotes s as one sh word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// runHook implements "git-diff-tree hook print|install [--force]".
+func runHook(args []string) {
+	fs := flag.NewFlagSet("hook", flag.ExitOnError)
+	force := fs.Bool("force", false, "Overwrite an existing commit-msg hook")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, `Usage:
+  git-diff-tree hook print              Print the commit-msg hook script
+  git-diff-tree hook install [--force]  Install it as .git/hooks/commit-msg`)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	switch fs.Arg(0) {
+	case "print":
+		fmt.Print(commitMsgHook(hookExecutable()))
+	case "install":
+		path, err := installHook(commitMsgHook(hookExecutable()), *force)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("installed %s\n", path)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// installHook writes the commit-msg hook script to the repository's hooks
+// directory (honoring core.hooksPath) and returns its path.
+func installHook(script string, force bool) (string, error) {
+	dir, err := diff.GitDirPath("hooks")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "commit-msg")
+	if _, err := os.Stat(path); err == nil && !force {
+		return "", fmt.Errorf("%s already exists (use --force to replace it)", path)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, []byte(script), 0o755)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommitMsgHook(t *testing.T) {
+	git := chdirTestRepo(t)
+
+	// A stand-in binary whose path needs quoting; it echoes its arguments
+	// as a trailer so the test sees how the hook called it
+	exe := filepath.Join(t.TempDir(), "it's here", "git-diff-tree")
+	if err := os.MkdirAll(filepath.Dir(exe), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\necho \"Diff-Args: $*\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := installHook(commitMsgHook(exe), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := installHook(commitMsgHook(exe), false); err == nil {
+		t.Error("installHook() over an existing hook without --force should fail")
+	}
+	if !strings.HasSuffix(filepath.ToSlash(path), ".git/hooks/commit-msg") {
+		t.Errorf("installHook() path = %s, want .git/hooks/commit-msg", path)
+	}
+
+	// Git runs hooks with its own PATH; the hook must not depend on it
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", filepath.Dir(gitPath))
+	if err := os.WriteFile("a.txt", []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "Add a")
+	msg := git("log", "-1", "--format=%B")
+	if !strings.Contains(msg, "Diff-Args: -m trailers -- --cached") {
+		t.Errorf("commit message = %q, want the hook's Diff-Args trailer", msg)
+	}
+}
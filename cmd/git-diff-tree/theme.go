@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kylesnowschwartz/diff-viz/config"
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+// themeColors returns the configured bracketColors as escape sequences, or
+// nil when the config leaves the built-in palette in place. Invalid colors
+// are skipped; newRenderOptions reports them.
+func themeColors(cfg *config.Config) []string {
+	var codes []string
+	for _, c := range cfg.Resolve("brackets", nil).BracketColors {
+		if code, err := render.ParseColor(c); err == nil {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// themeWarnings lists the configured colors that are hard to read on the
+// terminal's background.
+func themeWarnings(cfg *config.Config, bg render.Background) []string {
+	return render.ContrastWarnings("bracketColors", themeColors(cfg), bg)
+}
+
+// checkTheme runs --check-theme: it reports configured colors with too
+// little contrast and exits 1 if there are any.
+func checkTheme(cfg *config.Config) {
+	bg := render.DetectBackground()
+	assumed := ""
+	if !bg.Detected {
+		assumed = " (assumed; COLORFGBG is not set)"
+	}
+
+	codes := themeColors(cfg)
+	if len(codes) == 0 {
+		fmt.Printf("theme: no custom colors configured; background %s%s\n", bg.Name, assumed)
+		return
+	}
+	warnings := themeWarnings(cfg, bg)
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	if len(warnings) > 0 {
+		os.Exit(1)
+	}
+	fmt.Printf("theme: ok (%d colors readable on a %s background%s)\n", len(codes), bg.Name, assumed)
+}
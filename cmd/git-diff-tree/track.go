@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+// runTrack implements "git-diff-tree track --label NAME [<range>...]":
+// it appends the range's current totals to the repository's history file.
+func runTrack(args []string) {
+	fs := flag.NewFlagSet("track", flag.ExitOnError)
+	label := fs.String("label", "", "History label to append to (e.g., pr-1234)")
+	configPath := fs.String("config", "", "Path to JSON config file for size thresholds (default: the discovered one)")
+	verbose := fs.Bool("v", false, "Print warnings to stderr")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: git-diff-tree track --label NAME [<commit> [<commit>]]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *label == "" {
+		fmt.Fprintln(os.Stderr, "error: track requires --label")
+		os.Exit(1)
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	sizeThresholds, err := cfg.SizeThresholds()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	diffArgs := fs.Args()
+	if err := diff.ValidateRevisions(diffArgs...); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	stats, warnings, err := diff.GetAllStats(diffArgs...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	printWarnings(warnings, *verbose)
+
+	path, err := diff.HistoryPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	head, _ := diff.ResolveRef("HEAD") // Empty in a repo without commits
+	entry := diff.NewHistoryEntry(*label, time.Now(), head, diffArgs, stats, sizeThresholds)
+	if err := diff.AppendHistory(path, entry); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("tracked %s: +%d -%d in %d files [%s]\n", *label, entry.Adds, entry.Dels, entry.Files, entry.Size)
+}
+
+// runTrend charts the tracked history for label.
+func runTrend(w io.Writer, label string, useColor, verbose bool) {
+	if label == "" {
+		fmt.Fprintln(os.Stderr, "error: trend mode requires --label")
+		os.Exit(1)
+	}
+
+	path, err := diff.HistoryPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	entries, warnings, err := diff.LoadHistory(path, label)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	printWarnings(warnings, verbose)
+
+	render.NewTrendRenderer(w, useColor).RenderTrend(label, entries)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+// viewOutput is where a run's view goes: the pager (or stdout) in the color
+// profile, capped by --max-output-lines, and teed for --copy and --capture.
+type viewOutput struct {
+	w           io.Writer
+	out         *pagedOutput
+	limit       *render.LineLimitWriter
+	clip        *bytes.Buffer // Color-stripped text for --copy; nil when off
+	screenshot  *bytes.Buffer // Full-color text for --capture; nil when off
+	capturePath string
+}
+
+// newViewOutput builds the writer chain. Nothing is written when shown is
+// false (only NAME=FILE outputs were asked for).
+func newViewOutput(shown, noPager bool, maxLines int, copyOutput bool, capturePath string) *viewOutput {
+	v := &viewOutput{out: newPagedOutput(noPager), capturePath: capturePath}
+	v.w = render.NewProfileWriter(v.out.Writer(), colorProfile)
+	if !shown {
+		v.w = io.Discard
+	}
+
+	if maxLines > 0 {
+		v.limit = render.NewLineLimitWriter(v.w, maxLines)
+		v.w = v.limit
+	}
+	if copyOutput {
+		v.clip = new(bytes.Buffer)
+		v.w = io.MultiWriter(v.w, render.NewStripANSIWriter(v.clip))
+	}
+	if capturePath != "" {
+		v.screenshot = new(bytes.Buffer)
+		v.w = io.MultiWriter(v.w, v.screenshot)
+	}
+	return v
+}
+
+// Close ends the view: it folds lines past the limit, flushes the pager,
+// then saves the --capture screenshot and copies the --copy text.
+func (v *viewOutput) Close() {
+	if v.limit != nil {
+		v.limit.Close()
+	}
+	v.out.Flush()
+
+	if v.screenshot != nil {
+		if err := writeScreenshot(v.capturePath, v.screenshot.String()); err != nil {
+			fmt.Fprintf(os.Stderr, "error: --capture: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if v.clip != nil {
+		if err := copyToClipboard(v.clip.String()); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: --copy: %v\n", err)
+		}
+	}
+}
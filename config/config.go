@@ -4,29 +4,373 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
 )
 
 // Config represents the full configuration file structure.
 type Config struct {
 	Defaults ModeConfig            `json:"defaults,omitempty"`
 	Modes    map[string]ModeConfig `json:"modes,omitempty"`
+	Sizes    map[string]int        `json:"sizes,omitempty"` // Size class -> min changed lines
+
+	// MaxUntrackedSize skips counting lines in larger untracked files
+	// ("64MB", "512K", "none"); see ParseByteSize.
+	MaxUntrackedSize string `json:"maxUntrackedSize,omitempty"`
+
+	// Profiles maps a name to rules that pick the output from where
+	// git-diff-tree runs (see SelectProfile). DefaultProfile applies when no
+	// mode or format is given.
+	Profiles map[string][]ProfileRule `json:"profiles,omitempty"`
+
+	// Exclude maps a category name ("generated", "vendor") to path patterns
+	// (see diff.Exclusion); summaries add totals without those files.
+	Exclude map[string][]string `json:"exclude,omitempty"`
+
+	// Repos maps a remote URL ("github.com/org/monorepo") or a path glob
+	// ("~/work/*") to overrides for matching repositories (see ForRepo).
+	Repos map[string]RepoConfig `json:"repos,omitempty"`
+
+	repo    *RepoConfig // Section chosen by ForRepo, applied over Defaults and Modes
+	repoKey string      // Repos key of repo
+}
+
+// RepoConfig overrides defaults, per-mode settings, and profiles in one
+// repository. Its profiles replace top-level profiles of the same name, so a
+// "default" profile here picks the repository's default mode.
+type RepoConfig struct {
+	Defaults ModeConfig               `json:"defaults,omitempty"`
+	Modes    map[string]ModeConfig    `json:"modes,omitempty"`
+	Profiles map[string][]ProfileRule `json:"profiles,omitempty"`
+}
+
+// ForRepo returns the config with the Repos section matching repo applied,
+// and that section's key ("" when none matches). A key matching one of the
+// repository's remotes wins; otherwise the longest path glob matching its
+// top-level directory does. Remote keys compare without scheme, user, or
+// ".git" suffix, so "github.com/org/repo" matches git@github.com:org/repo.git.
+func (c *Config) ForRepo(repo diff.RepoInfo) (*Config, string) {
+	if c == nil || len(c.Repos) == 0 {
+		return c, ""
+	}
+
+	remotes := make(map[string]bool, len(repo.Remotes))
+	for _, url := range repo.Remotes {
+		remotes[normalizeRemote(url)] = true
+	}
+
+	keys := make([]string, 0, len(c.Repos))
+	for key := range c.Repos {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	match := ""
+	for _, key := range keys {
+		if !isRepoPathKey(key) {
+			if remotes[normalizeRemote(key)] {
+				match = key
+				break
+			}
+			continue
+		}
+		if ok, _ := path.Match(expandHome(key), repo.Root); ok && len(key) > len(match) {
+			match = key
+		}
+	}
+	if match == "" {
+		return c, ""
+	}
+
+	section := c.Repos[match]
+	scoped := *c
+	scoped.repo, scoped.repoKey = &section, match
+	if len(section.Profiles) > 0 {
+		scoped.Profiles = make(map[string][]ProfileRule, len(c.Profiles)+len(section.Profiles))
+		for name, rules := range c.Profiles {
+			scoped.Profiles[name] = rules
+		}
+		for name, rules := range section.Profiles {
+			scoped.Profiles[name] = rules
+		}
+	}
+	return &scoped, match
+}
+
+// CheckRepos reports malformed path globs in Repos.
+func (c *Config) CheckRepos() error {
+	if c == nil {
+		return nil
+	}
+	for key := range c.Repos {
+		if !isRepoPathKey(key) {
+			continue
+		}
+		if _, err := path.Match(key, ""); err != nil {
+			return fmt.Errorf("repos: bad pattern %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// isRepoPathKey reports whether a Repos key is a path glob rather than a
+// remote URL.
+func isRepoPathKey(key string) bool {
+	return strings.HasPrefix(key, "/") || key == "~" || strings.HasPrefix(key, "~/")
+}
+
+// expandHome replaces a leading "~" with the user's home directory.
+func expandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.ToSlash(home) + p[1:]
 }
 
+// normalizeRemote reduces a remote URL to host/path form:
+// "git@github.com:org/repo.git" and "https://github.com/org/repo" both
+// become "github.com/org/repo".
+func normalizeRemote(url string) string {
+	url = strings.ToLower(strings.TrimSpace(url))
+	scheme := false
+	if _, rest, ok := strings.Cut(url, "://"); ok {
+		url, scheme = rest, true
+	}
+	if at := strings.Index(url, "@"); at >= 0 && at < strings.IndexByte(url+"/", '/') {
+		url = url[at+1:]
+	}
+	if colon, slash := strings.Index(url, ":"), strings.IndexByte(url+"/", '/'); colon >= 0 && colon < slash {
+		if scheme {
+			url = url[:colon] + url[slash:] // Drop the port
+		} else {
+			url = url[:colon] + "/" + url[colon+1:] // scp-style "host:path"
+		}
+	}
+	url = strings.TrimSuffix(url, "/")
+	return strings.TrimSuffix(url, ".git")
+}
+
+// DefaultProfile is the profile used when no mode, format, or --profile is given.
+const DefaultProfile = "default"
+
+// ProfileRule picks an output when all of its conditions hold; a rule
+// without conditions always matches. Width conditions never match when
+// stdout is not a terminal.
+type ProfileRule struct {
+	TTY      *bool  `json:"tty,omitempty"`      // stdout is (true) or is not (false) a terminal
+	MinWidth int    `json:"minWidth,omitempty"` // Terminal is at least this many columns
+	MaxWidth int    `json:"maxWidth,omitempty"` // Terminal is at most this many columns
+	Mode     string `json:"mode"`               // Mode or outline format (markdown, org, asciidoc)
+	Depth    *int   `json:"depth,omitempty"`    // Depth for the mode (e.g., 1 for smart's collapsed view)
+}
+
+// OutputContext describes where output goes, for matching profile rules.
+type OutputContext struct {
+	TTY   bool
+	Width int // Terminal columns (0 when unknown)
+}
+
+// Matches reports whether every condition of r holds in ctx.
+func (r ProfileRule) Matches(ctx OutputContext) bool {
+	if r.TTY != nil && *r.TTY != ctx.TTY {
+		return false
+	}
+	if (r.MinWidth > 0 || r.MaxWidth > 0) && (!ctx.TTY || ctx.Width <= 0) {
+		return false
+	}
+	if r.MinWidth > 0 && ctx.Width < r.MinWidth {
+		return false
+	}
+	if r.MaxWidth > 0 && ctx.Width > r.MaxWidth {
+		return false
+	}
+	return true
+}
+
+// HasProfile reports whether the config defines profile name.
+func (c *Config) HasProfile(name string) bool {
+	if c == nil {
+		return false
+	}
+	_, ok := c.Profiles[name]
+	return ok
+}
+
+// SelectProfile returns the first rule of profile name that matches ctx.
+// ok is false when no rule matches; an undefined profile is an error.
+func (c *Config) SelectProfile(name string, ctx OutputContext) (rule ProfileRule, ok bool, err error) {
+	if !c.HasProfile(name) {
+		var names []string
+		if c != nil {
+			for n := range c.Profiles {
+				names = append(names, n)
+			}
+		}
+		if len(names) == 0 {
+			return ProfileRule{}, false, fmt.Errorf("unknown profile %q (config defines no profiles)", name)
+		}
+		sort.Strings(names)
+		return ProfileRule{}, false, fmt.Errorf("unknown profile %q (config defines: %s)", name, strings.Join(names, ", "))
+	}
+	for _, r := range c.Profiles[name] {
+		if r.Matches(ctx) {
+			return r, true, nil
+		}
+	}
+	return ProfileRule{}, false, nil
+}
+
+// WidthAuto is the Width value meaning "detect terminal width".
+// In JSON config files it is written as "width": "auto".
+const WidthAuto = -1
+
 // ModeConfig holds configuration for a single mode or defaults.
 // All fields are pointers to distinguish "not set" from "set to zero".
 type ModeConfig struct {
-	Width  *int `json:"width,omitempty"`
-	Depth  *int `json:"depth,omitempty"`
-	Expand *int `json:"expand,omitempty"`
-	N      *int `json:"n,omitempty"` // TopN-specific
+	Width         *int     `json:"width,omitempty"`
+	Depth         *int     `json:"depth,omitempty"`
+	Expand        *int     `json:"expand,omitempty"`
+	N             *int     `json:"n,omitempty"`             // TopN-specific
+	BracketColors []string `json:"bracketColors,omitempty"` // Brackets-specific SGR codes, e.g. "36"
+	ZeroBar       *string  `json:"zeroBar,omitempty"`       // Smart/topn: "empty", "dot", or "none"
+	BarPadding    *bool    `json:"barPadding,omitempty"`    // Smart/topn: pad bars with empty blocks
+	BarScale      *string  `json:"barScale,omitempty"`      // Smart/topn: "steps", "linear", or "log"
+	RootGroup     *string  `json:"rootGroup,omitempty"`     // Smart/brackets: group name for root-level files ("" disables)
+	RootGroupSort *bool    `json:"rootGroupSort,omitempty"` // Brackets: sort the root group by total instead of placing it last
+
+	Separator        *string `json:"separator,omitempty"`        // Smart/brackets: text between groups ("" keeps " │ ")
+	ItemSeparator    *string `json:"itemSeparator,omitempty"`    // Brackets: text between items in a group ("" keeps ",")
+	CleanupThreshold *int    `json:"cleanupThreshold,omitempty"` // Tree/smart: percent of deleted lines marking a directory as cleanup (0 = off)
+	AbbrevCounts     *bool   `json:"abbrevCounts,omitempty"`     // Smart/brackets: "+12.4k" instead of exact counts
+}
+
+// SetKeys returns the JSON names of fields set in m, in declaration order.
+func (m ModeConfig) SetKeys() []string {
+	var keys []string
+	if m.Width != nil {
+		keys = append(keys, "width")
+	}
+	if m.Depth != nil {
+		keys = append(keys, "depth")
+	}
+	if m.Expand != nil {
+		keys = append(keys, "expand")
+	}
+	if m.N != nil {
+		keys = append(keys, "n")
+	}
+	if m.BracketColors != nil {
+		keys = append(keys, "bracketColors")
+	}
+	if m.ZeroBar != nil {
+		keys = append(keys, "zeroBar")
+	}
+	if m.BarPadding != nil {
+		keys = append(keys, "barPadding")
+	}
+	if m.BarScale != nil {
+		keys = append(keys, "barScale")
+	}
+	if m.RootGroup != nil {
+		keys = append(keys, "rootGroup")
+	}
+	if m.RootGroupSort != nil {
+		keys = append(keys, "rootGroupSort")
+	}
+	if m.Separator != nil {
+		keys = append(keys, "separator")
+	}
+	if m.ItemSeparator != nil {
+		keys = append(keys, "itemSeparator")
+	}
+	if m.CleanupThreshold != nil {
+		keys = append(keys, "cleanupThreshold")
+	}
+	if m.AbbrevCounts != nil {
+		keys = append(keys, "abbrevCounts")
+	}
+	return keys
 }
 
 // ResolvedConfig holds the final resolved values (no pointers, always has values).
 type ResolvedConfig struct {
-	Width  int
-	Depth  int
-	Expand int
-	N      int
+	Width         int  // Fallback width when WidthAuto detection fails
+	WidthAuto     bool // Detect terminal width (hardcoded default or "auto")
+	Depth         int
+	Expand        int
+	N             int
+	BracketColors []string // nil means renderer default
+	ZeroBar       string   // Zero-change bar style ("" means renderer default)
+	BarPadding    bool     // Pad bars to full width with empty blocks
+	BarScale      string   // Bar length scale ("" means renderer default)
+	RootGroup     string   // Virtual group name for root-level files ("" disables)
+	RootGroupSort bool     // Sort the root group among directories by total
+	TinyLines     int      // Changes below the S size class, drawn like zero-change ones with zeroBar dot or none
+
+	Separator        string // Between groups ("" means renderer default)
+	ItemSeparator    string // Between items in a group ("" means renderer default)
+	CleanupThreshold int    // Percent of deleted lines marking a directory as cleanup (0 = off)
+	AbbrevCounts     bool   // Shorten counts of 1000 and more
+}
+
+// modeConfigJSON mirrors ModeConfig with Width accepting a number or "auto".
+type modeConfigJSON struct {
+	modeConfigFields
+	Width json.RawMessage `json:"width,omitempty"`
+}
+
+// modeConfigFields has ModeConfig's fields without its JSON methods.
+type modeConfigFields ModeConfig
+
+// UnmarshalJSON accepts "width" as an integer or the string "auto".
+func (m *ModeConfig) UnmarshalJSON(data []byte) error {
+	var aux modeConfigJSON
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*m = ModeConfig(aux.modeConfigFields)
+	m.Width = nil
+
+	if len(aux.Width) == 0 || string(aux.Width) == "null" {
+		return nil
+	}
+	var width int
+	if err := json.Unmarshal(aux.Width, &width); err == nil {
+		m.Width = &width
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(aux.Width, &s); err == nil && s == "auto" {
+		width = WidthAuto
+		m.Width = &width
+		return nil
+	}
+	return fmt.Errorf("width: want integer or \"auto\", got %s", aux.Width)
+}
+
+// MarshalJSON writes WidthAuto as "auto".
+func (m ModeConfig) MarshalJSON() ([]byte, error) {
+	aux := struct {
+		Width any `json:"width,omitempty"` // First, matching field order
+		modeConfigFields
+	}{modeConfigFields: modeConfigFields(m)}
+	if m.Width != nil {
+		if *m.Width == WidthAuto {
+			aux.Width = "auto"
+		} else {
+			aux.Width = *m.Width
+		}
+	}
+	return json.Marshal(aux)
 }
 
 // Load reads and parses a config file from the given path.
@@ -41,8 +385,13 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("reading config: %w", err)
 	}
 
+	return Parse(data)
+}
+
+// Parse parses config file contents; // and /* */ comments are allowed.
+func Parse(data []byte) (*Config, error) {
 	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
+	if err := json.Unmarshal(StripComments(data), &cfg); err != nil {
 		return nil, fmt.Errorf("parsing config: %w", err)
 	}
 
@@ -50,7 +399,8 @@ func Load(path string) (*Config, error) {
 }
 
 // Resolve combines defaults, config file, and CLI flags for a specific mode.
-// Precedence: global defaults < mode defaults < config.defaults < config.modes[mode] < CLI flags.
+// Precedence: global defaults < mode defaults < config.defaults < config.modes[mode]
+// < repos[match].defaults < repos[match].modes[mode] < CLI flags.
 func (c *Config) Resolve(mode string, cliFlags *ModeConfig) ResolvedConfig {
 	// Start with hardcoded global defaults
 	result := DefaultConfig()
@@ -68,6 +418,14 @@ func (c *Config) Resolve(mode string, cliFlags *ModeConfig) ResolvedConfig {
 		if modeConfig, ok := c.Modes[mode]; ok {
 			result = mergeConfig(result, modeConfig)
 		}
+
+		// Apply the ForRepo section
+		if c.repo != nil {
+			result = mergeConfig(result, c.repo.Defaults)
+			if modeConfig, ok := c.repo.Modes[mode]; ok {
+				result = mergeConfig(result, modeConfig)
+			}
+		}
 	}
 
 	// Apply CLI flags (if provided)
@@ -75,6 +433,13 @@ func (c *Config) Resolve(mode string, cliFlags *ModeConfig) ResolvedConfig {
 		result = mergeConfig(result, *cliFlags)
 	}
 
+	// Invalid sizes are reported where they are loaded; fall back to defaults
+	thresholds, err := c.SizeThresholds()
+	if err != nil {
+		thresholds = nil
+	}
+	result.TinyLines = diff.MinLines(diff.SizeS, thresholds)
+
 	return result
 }
 
@@ -87,7 +452,12 @@ func Resolve(mode string, cliFlags *ModeConfig) ResolvedConfig {
 // mergeConfig overlays src onto base, only replacing non-nil values.
 func mergeConfig(base ResolvedConfig, src ModeConfig) ResolvedConfig {
 	if src.Width != nil {
-		base.Width = *src.Width
+		if *src.Width == WidthAuto {
+			base.WidthAuto = true
+		} else {
+			base.Width = *src.Width
+			base.WidthAuto = false
+		}
 	}
 	if src.Depth != nil {
 		base.Depth = *src.Depth
@@ -98,5 +468,140 @@ func mergeConfig(base ResolvedConfig, src ModeConfig) ResolvedConfig {
 	if src.N != nil {
 		base.N = *src.N
 	}
+	if src.BracketColors != nil {
+		base.BracketColors = src.BracketColors
+	}
+	if src.ZeroBar != nil {
+		base.ZeroBar = *src.ZeroBar
+	}
+	if src.BarPadding != nil {
+		base.BarPadding = *src.BarPadding
+	}
+	if src.BarScale != nil {
+		base.BarScale = *src.BarScale
+	}
+	if src.RootGroup != nil {
+		base.RootGroup = *src.RootGroup
+	}
+	if src.RootGroupSort != nil {
+		base.RootGroupSort = *src.RootGroupSort
+	}
+	if src.Separator != nil {
+		base.Separator = *src.Separator
+	}
+	if src.ItemSeparator != nil {
+		base.ItemSeparator = *src.ItemSeparator
+	}
+	if src.CleanupThreshold != nil {
+		base.CleanupThreshold = *src.CleanupThreshold
+	}
+	if src.AbbrevCounts != nil {
+		base.AbbrevCounts = *src.AbbrevCounts
+	}
 	return base
 }
+
+// UntrackedSizeLimit returns the parsed MaxUntrackedSize for
+// diff.Options.MaxUntrackedSize: 0 when unset (diff's default).
+func (c *Config) UntrackedSizeLimit() (int64, error) {
+	if c == nil || c.MaxUntrackedSize == "" {
+		return 0, nil
+	}
+	n, err := ParseByteSize(c.MaxUntrackedSize)
+	if err != nil {
+		return 0, fmt.Errorf("maxUntrackedSize: %w", err)
+	}
+	return n, nil
+}
+
+// Exclusions returns the Exclude categories sorted by name.
+// Returns an error for malformed patterns.
+func (c *Config) Exclusions() ([]diff.Exclusion, error) {
+	if c == nil || len(c.Exclude) == 0 {
+		return nil, nil
+	}
+	names := make([]string, 0, len(c.Exclude))
+	for name := range c.Exclude {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	exclusions := make([]diff.Exclusion, 0, len(names))
+	for _, name := range names {
+		for _, pattern := range c.Exclude[name] {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("exclude.%s: bad pattern %q", name, pattern)
+			}
+		}
+		exclusions = append(exclusions, diff.Exclusion{Name: name, Patterns: c.Exclude[name]})
+	}
+	return exclusions, nil
+}
+
+// byteUnits maps size suffixes to multipliers (binary, so "1MB" = 1 MiB).
+var byteUnits = []struct {
+	suffix string
+	mult   int64
+}{
+	{"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10},
+	{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+	{"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+}
+
+// ParseByteSize parses a size such as "64MB", "512K", or "1048576" (bytes).
+// Suffixes are case-insensitive binary multiples. "none" returns -1 (no limit).
+func ParseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "none") {
+		return -1, nil
+	}
+	num, mult := s, int64(1)
+	for _, u := range byteUnits {
+		if len(s) > len(u.suffix) && strings.EqualFold(s[len(s)-len(u.suffix):], u.suffix) {
+			num, mult = strings.TrimSpace(s[:len(s)-len(u.suffix)]), u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q (want e.g. 64MB, 512K, or none)", s)
+	}
+	return n * mult, nil
+}
+
+// SizeThresholds returns size classification thresholds with any config
+// overrides applied on top of diff.DefaultSizeThresholds.
+// Returns an error for unknown size labels, negative line counts, or
+// classes that no longer start above the next smaller one.
+func (c *Config) SizeThresholds() ([]diff.SizeThreshold, error) {
+	thresholds := make([]diff.SizeThreshold, len(diff.DefaultSizeThresholds))
+	copy(thresholds, diff.DefaultSizeThresholds)
+	if c == nil || len(c.Sizes) == 0 {
+		return thresholds, nil
+	}
+
+	for label, minLines := range c.Sizes {
+		class, err := diff.ParseSizeClass(label)
+		if err != nil {
+			return nil, fmt.Errorf("sizes: %w", err)
+		}
+		if minLines < 0 {
+			return nil, fmt.Errorf("sizes: %s must be >= 0, got %d", class, minLines)
+		}
+		for i := range thresholds {
+			if thresholds[i].Class == class {
+				thresholds[i].MinLines = minLines
+			}
+		}
+	}
+
+	// First match wins in this descending order, so an override that skips
+	// past a neighbor would hide or swap classes
+	for i := 1; i < len(thresholds); i++ {
+		larger, smaller := thresholds[i-1], thresholds[i]
+		if larger.MinLines <= smaller.MinLines {
+			return nil, fmt.Errorf("sizes: %s (%d lines) must start above %s (%d lines)", larger.Class, larger.MinLines, smaller.Class, smaller.MinLines)
+		}
+	}
+	return thresholds, nil
+}
@@ -1,9 +1,13 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
 )
 
 func TestLoad_EmptyPath(t *testing.T) {
@@ -183,11 +187,11 @@ func TestDefaultsForMode(t *testing.T) {
 		depth int
 		n     int
 	}{
-		{"tree", DefaultDepth, DefaultN},
+		{"tree", 0, DefaultN}, // unlimited
 		{"smart", 3, DefaultN},
 		{"topn", DefaultDepth, 10},
 		{"icicle", 4, DefaultN},
-		{"brackets", DefaultDepth, DefaultN},
+		{"brackets", 0, DefaultN},           // unlimited
 		{"unknown", DefaultDepth, DefaultN}, // Unknown mode uses globals
 	}
 
@@ -207,9 +211,9 @@ func TestDefaultsForMode(t *testing.T) {
 func TestDefaultConfigJSON(t *testing.T) {
 	cfg := DefaultConfigJSON()
 
-	// Check defaults section
-	if cfg.Defaults.Width == nil || *cfg.Defaults.Width != DefaultWidth {
-		t.Errorf("DefaultConfigJSON Defaults.Width: got %v, want %d", cfg.Defaults.Width, DefaultWidth)
+	// Check defaults section (width is terminal-detected by default)
+	if cfg.Defaults.Width == nil || *cfg.Defaults.Width != WidthAuto {
+		t.Errorf("DefaultConfigJSON Defaults.Width: got %v, want %d (auto)", cfg.Defaults.Width, WidthAuto)
 	}
 
 	// Check that mode-specific overrides are present
@@ -223,8 +227,313 @@ func TestDefaultConfigJSON(t *testing.T) {
 		t.Errorf("DefaultConfigJSON Modes[smart].Depth: got %v, want 3", cfg.Modes["smart"].Depth)
 	}
 
-	// Tree mode should not be in Modes (empty config)
-	if _, ok := cfg.Modes["tree"]; ok {
-		t.Error("DefaultConfigJSON should not include empty tree mode")
+	if cfg.Modes["tree"].Depth == nil || *cfg.Modes["tree"].Depth != 0 {
+		t.Errorf("DefaultConfigJSON Modes[tree].Depth: got %v, want 0 (unlimited)", cfg.Modes["tree"].Depth)
+	}
+}
+
+func TestSizeThresholds(t *testing.T) {
+	cfg := &Config{Sizes: map[string]int{"xl": 2000, "L": 300}}
+	thresholds, err := cfg.SizeThresholds()
+	if err != nil {
+		t.Fatalf("SizeThresholds: %v", err)
+	}
+
+	// Overrides apply, remaining classes keep defaults
+	if got := diff.Classify(1000, thresholds); got != diff.SizeL {
+		t.Errorf("Classify(1000) = %s, want L", got)
+	}
+	if got := diff.Classify(2000, thresholds); got != diff.SizeXL {
+		t.Errorf("Classify(2000) = %s, want XL", got)
+	}
+	if got := diff.Classify(150, thresholds); got != diff.SizeM {
+		t.Errorf("Classify(150) = %s, want M", got)
+	}
+
+	// Nil config returns defaults
+	var nilCfg *Config
+	thresholds, err = nilCfg.SizeThresholds()
+	if err != nil {
+		t.Fatalf("nil SizeThresholds: %v", err)
+	}
+	if got := diff.Classify(500, thresholds); got != diff.SizeXL {
+		t.Errorf("nil config Classify(500) = %s, want XL", got)
+	}
+
+	// Unknown labels are rejected
+	bad := &Config{Sizes: map[string]int{"XXL": 5000}}
+	if _, err := bad.SizeThresholds(); err == nil {
+		t.Error("SizeThresholds with unknown label: got nil error, want error")
+	}
+
+	// Classes must stay in order: L past the default XL would swap them
+	for _, sizes := range []map[string]int{{"L": 600}, {"M": 100}, {"S": 0}} {
+		bad = &Config{Sizes: sizes}
+		if _, err := bad.SizeThresholds(); err == nil || !strings.Contains(err.Error(), "must start above") {
+			t.Errorf("SizeThresholds(%v) error = %v, want an ordering error", sizes, err)
+		}
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"1048576", 1 << 20, false},
+		{"512K", 512 << 10, false},
+		{"64MB", 64 << 20, false},
+		{"64 mb", 64 << 20, false},
+		{"2GiB", 2 << 30, false},
+		{"100B", 100, false},
+		{"none", -1, false},
+		{"", 0, true},
+		{"0", 0, true},
+		{"-5MB", 0, true},
+		{"lots", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseByteSize(%q) = %d, %v; want %d, err=%v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	cfg := &Config{MaxUntrackedSize: "8M"}
+	if got, err := cfg.UntrackedSizeLimit(); err != nil || got != 8<<20 {
+		t.Errorf("UntrackedSizeLimit() = %d, %v; want %d", got, err, 8<<20)
+	}
+	if got, err := (*Config)(nil).UntrackedSizeLimit(); err != nil || got != 0 {
+		t.Errorf("nil UntrackedSizeLimit() = %d, %v; want 0 (diff default)", got, err)
+	}
+}
+
+func TestModeConfig_WidthAuto(t *testing.T) {
+	var cfg Config
+	content := `{"defaults": {"width": "auto"}, "modes": {"icicle": {"width": 120}}}`
+	if err := json.Unmarshal([]byte(content), &cfg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if cfg.Defaults.Width == nil || *cfg.Defaults.Width != WidthAuto {
+		t.Errorf("Defaults.Width: got %v, want WidthAuto", cfg.Defaults.Width)
+	}
+
+	// Config width wins over terminal detection
+	resolved := cfg.Resolve("icicle", nil)
+	if resolved.WidthAuto || resolved.Width != 120 {
+		t.Errorf("Resolve icicle: got Width=%d WidthAuto=%v, want 120 without auto", resolved.Width, resolved.WidthAuto)
+	}
+
+	// "auto" keeps detection with the default fallback
+	resolved = cfg.Resolve("smart", nil)
+	if !resolved.WidthAuto || resolved.Width != DefaultWidth {
+		t.Errorf("Resolve smart: got Width=%d WidthAuto=%v, want auto with fallback %d", resolved.Width, resolved.WidthAuto, DefaultWidth)
+	}
+
+	// Explicit CLI width disables detection
+	w := 80
+	resolved = cfg.Resolve("smart", &ModeConfig{Width: &w})
+	if resolved.WidthAuto || resolved.Width != 80 {
+		t.Errorf("Resolve smart with CLI: got Width=%d WidthAuto=%v, want 80", resolved.Width, resolved.WidthAuto)
+	}
+
+	// Round-trip writes "auto"
+	out, err := json.Marshal(cfg.Defaults)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(out) != `{"width":"auto"}` {
+		t.Errorf("Marshal defaults = %s, want {\"width\":\"auto\"}", out)
+	}
+
+	if err := json.Unmarshal([]byte(`{"defaults": {"width": "wide"}}`), &cfg); err == nil {
+		t.Error("Unmarshal invalid width: got nil error, want error")
+	}
+}
+
+func TestModeConfig_BarOptions(t *testing.T) {
+	content := `{"defaults": {"barPadding": false}, "modes": {"topn": {"zeroBar": "dot"}}}`
+	var cfg Config
+	if err := json.Unmarshal([]byte(content), &cfg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if got := Resolve("smart", nil); !got.BarPadding || got.ZeroBar != "" {
+		t.Errorf("default: BarPadding=%v ZeroBar=%q, want true and empty", got.BarPadding, got.ZeroBar)
+	}
+
+	got := cfg.Resolve("topn", nil)
+	if got.BarPadding || got.ZeroBar != "dot" {
+		t.Errorf("topn: BarPadding=%v ZeroBar=%q, want false and dot", got.BarPadding, got.ZeroBar)
+	}
+
+	got = cfg.Resolve("smart", nil)
+	if got.BarPadding || got.ZeroBar != "" {
+		t.Errorf("smart: BarPadding=%v ZeroBar=%q, want false and empty", got.BarPadding, got.ZeroBar)
+	}
+}
+
+func TestResolve_TinyLines(t *testing.T) {
+	if got := Resolve("smart", nil).TinyLines; got != 10 {
+		t.Errorf("default TinyLines = %d, want 10 (the S threshold)", got)
+	}
+	cfg := &Config{Sizes: map[string]int{"S": 25}}
+	if got := cfg.Resolve("topn", nil).TinyLines; got != 25 {
+		t.Errorf("TinyLines with sizes.S = %d, want 25", got)
+	}
+}
+
+func TestSelectProfile(t *testing.T) {
+	var cfg Config
+	data := `{"profiles": {"default": [
+		{"tty": false, "mode": "markdown"},
+		{"maxWidth": 79, "mode": "smart", "depth": 1},
+		{"minWidth": 200, "mode": "icicle"},
+		{"mode": "tree"}
+	]}}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ctx  OutputContext
+		want string
+	}{
+		{OutputContext{TTY: false}, "markdown"},
+		{OutputContext{TTY: true, Width: 60}, "smart"},
+		{OutputContext{TTY: true, Width: 120}, "tree"},
+		{OutputContext{TTY: true, Width: 240}, "icicle"},
+		{OutputContext{TTY: true}, "tree"}, // Unknown width matches no width rule
+	}
+	for _, tt := range tests {
+		rule, ok, err := cfg.SelectProfile(DefaultProfile, tt.ctx)
+		if err != nil || !ok || rule.Mode != tt.want {
+			t.Errorf("SelectProfile(%+v) = %q, %v, %v; want %q", tt.ctx, rule.Mode, ok, err, tt.want)
+		}
+	}
+	if rule, _, _ := cfg.SelectProfile(DefaultProfile, OutputContext{TTY: true, Width: 60}); rule.Depth == nil || *rule.Depth != 1 {
+		t.Errorf("narrow rule depth = %v, want 1", rule.Depth)
+	}
+
+	if _, _, err := cfg.SelectProfile("ci", OutputContext{}); err == nil {
+		t.Error("SelectProfile(ci) should fail for an undefined profile")
+	}
+	var nilCfg *Config
+	if nilCfg.HasProfile(DefaultProfile) {
+		t.Error("nil config should have no profiles")
+	}
+}
+
+func TestForRepo(t *testing.T) {
+	var cfg Config
+	data := `{
+		"defaults": {"depth": 2},
+		"profiles": {"default": [{"mode": "tree"}], "narrow": [{"mode": "smart"}]},
+		"repos": {
+			"github.com/org/monorepo": {
+				"defaults": {"depth": 4},
+				"modes": {"topn": {"n": 5}},
+				"profiles": {"default": [{"mode": "icicle"}]}
+			},
+			"/work/*": {"defaults": {"depth": 1}},
+			"/work/big*": {"defaults": {"depth": 6}}
+		}
+	}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		repo      diff.RepoInfo
+		wantKey   string
+		wantDepth int
+	}{
+		{"ssh remote", diff.RepoInfo{Root: "/work/mono", Remotes: []string{"git@github.com:org/monorepo.git"}}, "github.com/org/monorepo", 4},
+		{"https remote", diff.RepoInfo{Root: "/src/mono", Remotes: []string{"https://GitHub.com/org/monorepo/"}}, "github.com/org/monorepo", 4},
+		{"longest glob", diff.RepoInfo{Root: "/work/bigrepo"}, "/work/big*", 6},
+		{"glob", diff.RepoInfo{Root: "/work/small"}, "/work/*", 1},
+		{"no match", diff.RepoInfo{Root: "/elsewhere/x", Remotes: []string{"git@github.com:org/other.git"}}, "", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scoped, key := cfg.ForRepo(tt.repo)
+			if key != tt.wantKey {
+				t.Errorf("ForRepo() key = %q, want %q", key, tt.wantKey)
+			}
+			if got := scoped.Resolve("tree", nil).Depth; got != tt.wantDepth {
+				t.Errorf("depth = %d, want %d", got, tt.wantDepth)
+			}
+		})
+	}
+
+	scoped, _ := cfg.ForRepo(diff.RepoInfo{Remotes: []string{"ssh://git@github.com:22/org/monorepo"}})
+	if got := scoped.Resolve("topn", nil).N; got != 5 {
+		t.Errorf("repo modes.topn.n = %d, want 5", got)
+	}
+	if rule, _, _ := scoped.SelectProfile(DefaultProfile, OutputContext{}); rule.Mode != "icicle" {
+		t.Errorf("repo default profile mode = %q, want icicle", rule.Mode)
+	}
+	if rule, _, _ := scoped.SelectProfile("narrow", OutputContext{}); rule.Mode != "smart" {
+		t.Errorf("top-level narrow profile mode = %q, want smart", rule.Mode)
+	}
+	if rule, _, _ := cfg.SelectProfile(DefaultProfile, OutputContext{}); rule.Mode != "tree" {
+		t.Errorf("ForRepo modified the receiver's profiles: default = %q", rule.Mode)
+	}
+}
+
+func TestCheckRepos(t *testing.T) {
+	cfg := &Config{Repos: map[string]RepoConfig{"/work/[": {}}}
+	if err := cfg.CheckRepos(); err == nil {
+		t.Error("CheckRepos() accepted a malformed glob")
+	}
+	cfg = &Config{Repos: map[string]RepoConfig{"github.com/[org]/x": {}, "~/work/*": {}}}
+	if err := cfg.CheckRepos(); err != nil {
+		t.Errorf("CheckRepos() = %v", err)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	var cfg Config
+	data := `{
+		"defaults": {"width": 80},
+		"modes": {"topn": {"n": 7}},
+		"repos": {"/work/*": {"modes": {"topn": {"barScale": "log", "separator": " / "}}}}
+	}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	scoped, _ := cfg.ForRepo(diff.RepoInfo{Root: "/work/app"})
+	width := 120
+
+	got := make(map[string]Setting)
+	for _, s := range scoped.Explain("topn", &ModeConfig{Width: &width}) {
+		got[s.Key] = s
+	}
+	want := map[string]Setting{
+		"width":    {Key: "width", Value: "120", Source: "command line"},
+		"n":        {Key: "n", Value: "7", Source: "config modes.topn"},
+		"barScale": {Key: "barScale", Value: "log", Source: `config repos["/work/*"].modes.topn`},
+		"depth":    {Key: "depth", Value: "2", Source: "built-in default"},
+
+		"separator":        {Key: "separator", Value: `" / "`, Source: `config repos["/work/*"].modes.topn`},
+		"cleanupThreshold": {Key: "cleanupThreshold", Value: "90", Source: "built-in default"},
+	}
+	for key, w := range want {
+		if got[key] != w {
+			t.Errorf("%s = %+v, want %+v", key, got[key], w)
+		}
+	}
+
+	for _, s := range cfg.Explain("icicle", nil) {
+		if s.Key == "depth" && s.Source != "built-in icicle default" {
+			t.Errorf("icicle depth source = %q, want built-in icicle default", s.Source)
+		}
+		if s.Key == "width" && s.Source != "config defaults" {
+			t.Errorf("icicle width source = %q, want config defaults", s.Source)
+		}
 	}
 }
@@ -8,25 +8,43 @@ const (
 	DefaultExpand = -1 // auto
 	DefaultN      = 5
 	DefaultMode   = "tree"
+
+	// DefaultRootGroup names the virtual group that aggregates root-level
+	// files in smart mode (rendered as "./").
+	DefaultRootGroup = "."
+
+	// DefaultBracketsRootGroup labels the root-level files group in
+	// brackets mode (rendered as "root:").
+	DefaultBracketsRootGroup = "root"
+
+	// DefaultCleanupThreshold is the percentage of deleted lines at which
+	// tree and smart output mark a directory as cleanup.
+	DefaultCleanupThreshold = 90
 )
 
 // ModeDefaults provides optimized defaults for each render mode.
 // These are applied after global defaults but before config file values.
 var ModeDefaults = map[string]ModeConfig{
-	"tree":     {},                   // uses global defaults
-	"smart":    {Depth: intPtr(3)},   // show individual files by default
-	"topn":     {N: intPtr(10)},      // show more files
-	"icicle":   {Depth: intPtr(4)},   // deeper hierarchy
-	"brackets": {Expand: intPtr(-1)}, // auto
+	"tree":     {Depth: intPtr(0)},                                                                     // unlimited depth
+	"smart":    {Depth: intPtr(3)},                                                                     // show individual files by default
+	"topn":     {N: intPtr(10)},                                                                        // show more files
+	"icicle":   {Depth: intPtr(4)},                                                                     // deeper hierarchy
+	"brackets": {Depth: intPtr(0), Expand: intPtr(-1), RootGroup: stringPtr(DefaultBracketsRootGroup)}, // unlimited depth, auto expand
 }
 
 // DefaultConfig returns the hardcoded global default configuration.
+// Width is auto-detected from the terminal, falling back to DefaultWidth.
 func DefaultConfig() ResolvedConfig {
 	return ResolvedConfig{
-		Width:  DefaultWidth,
-		Depth:  DefaultDepth,
-		Expand: DefaultExpand,
-		N:      DefaultN,
+		Width:      DefaultWidth,
+		WidthAuto:  true,
+		Depth:      DefaultDepth,
+		Expand:     DefaultExpand,
+		N:          DefaultN,
+		BarPadding: true,
+		RootGroup:  DefaultRootGroup,
+
+		CleanupThreshold: DefaultCleanupThreshold,
 	}
 }
 
@@ -43,7 +61,7 @@ func DefaultsForMode(mode string) ResolvedConfig {
 // DefaultConfigJSON returns a Config struct suitable for serializing
 // as a starting template. Includes all built-in mode defaults.
 func DefaultConfigJSON() Config {
-	width := DefaultWidth
+	width := WidthAuto
 	depth := DefaultDepth
 	expand := DefaultExpand
 
@@ -62,20 +80,33 @@ func copyModeDefaults() map[string]ModeConfig {
 	result := make(map[string]ModeConfig, len(ModeDefaults))
 	for k, v := range ModeDefaults {
 		// Skip empty configs
-		if v.Width == nil && v.Depth == nil && v.Expand == nil && v.N == nil {
+		if v.Width == nil && v.Depth == nil && v.Expand == nil && v.N == nil && v.BracketColors == nil &&
+			v.ZeroBar == nil && v.BarPadding == nil && v.BarScale == nil && v.RootGroup == nil && v.RootGroupSort == nil &&
+			v.Separator == nil && v.ItemSeparator == nil && v.CleanupThreshold == nil && v.AbbrevCounts == nil {
 			continue
 		}
 		result[k] = ModeConfig{
-			Width:  copyIntPtr(v.Width),
-			Depth:  copyIntPtr(v.Depth),
-			Expand: copyIntPtr(v.Expand),
-			N:      copyIntPtr(v.N),
+			Width:         copyPtr(v.Width),
+			Depth:         copyPtr(v.Depth),
+			Expand:        copyPtr(v.Expand),
+			N:             copyPtr(v.N),
+			BracketColors: append([]string(nil), v.BracketColors...),
+			ZeroBar:       copyPtr(v.ZeroBar),
+			BarPadding:    copyPtr(v.BarPadding),
+			BarScale:      copyPtr(v.BarScale),
+			RootGroup:     copyPtr(v.RootGroup),
+			RootGroupSort: copyPtr(v.RootGroupSort),
+
+			Separator:        copyPtr(v.Separator),
+			ItemSeparator:    copyPtr(v.ItemSeparator),
+			CleanupThreshold: copyPtr(v.CleanupThreshold),
+			AbbrevCounts:     copyPtr(v.AbbrevCounts),
 		}
 	}
 	return result
 }
 
-func copyIntPtr(p *int) *int {
+func copyPtr[T any](p *T) *T {
 	if p == nil {
 		return nil
 	}
@@ -86,3 +117,7 @@ func copyIntPtr(p *int) *int {
 func intPtr(i int) *int {
 	return &i
 }
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,398 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Config files may hold // line and /* block */ comments. Get and Set work on
+// the file text so that comments, key order, and formatting survive an edit.
+
+// DefaultPath returns where git-diff-tree looks for a config file when
+// --config is not given: git-diff-tree/config.json under the user config
+// directory (e.g., ~/.config on Linux).
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "git-diff-tree", "config.json"), nil
+}
+
+// Discover returns DefaultPath if a file exists there, else "".
+func Discover() string {
+	path, err := DefaultPath()
+	if err != nil {
+		return ""
+	}
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// StripComments replaces comments in JSON text with spaces, keeping newlines
+// so byte offsets and line numbers still match the original.
+func StripComments(data []byte) []byte {
+	out := bytes.Clone(data)
+	inString := false
+	for i := 0; i < len(out); i++ {
+		switch {
+		case inString:
+			if out[i] == '\\' {
+				i++
+			} else if out[i] == '"' {
+				inString = false
+			}
+		case out[i] == '"':
+			inString = true
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			stop := len(out)
+			if end >= 0 {
+				stop = i + 2 + end + 2
+			}
+			for ; i < stop; i++ {
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+			i--
+		}
+	}
+	return out
+}
+
+// ParseKey splits a setting key into its path: "modes.topn.n" is
+// [modes topn n]. Keys holding dots are quoted in brackets, as
+// --explain-config prints them: repos["github.com/org/repo"].defaults.width.
+func ParseKey(key string) ([]string, error) {
+	var path []string
+	for rest := key; rest != ""; {
+		switch {
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "\"]")
+			if end < 0 {
+				return nil, fmt.Errorf("key %q: unterminated [\"...\"]", key)
+			}
+			name, err := strconv.Unquote(rest[1 : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %v", key, err)
+			}
+			path = append(path, name)
+			rest = rest[end+2:]
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("key %q: empty name", key)
+			}
+			path = append(path, rest[:end])
+			rest = rest[end:]
+		}
+		if strings.HasPrefix(rest, ".") {
+			rest = rest[1:]
+			if rest == "" {
+				return nil, fmt.Errorf("key %q: trailing dot", key)
+			}
+		}
+	}
+	if len(path) == 0 {
+		return nil, fmt.Errorf("empty key")
+	}
+	return path, nil
+}
+
+// CheckKey reports whether path names a setting in Config: struct fields by
+// their JSON names, any name under a map (modes, repos, profiles), and lists
+// as a whole.
+func CheckKey(path []string) error {
+	t := reflect.TypeOf(Config{})
+	for i, name := range path {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := jsonField(t, name)
+			if !ok {
+				return fmt.Errorf("unknown setting %q", strings.Join(path[:i+1], "."))
+			}
+			t = field.Type
+		case reflect.Map:
+			t = t.Elem()
+		default:
+			return fmt.Errorf("%s is not an object", strings.Join(path[:i], "."))
+		}
+	}
+	return nil
+}
+
+// jsonField finds the field of struct type t encoded as name.
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.IsExported() && tag == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// Get returns the JSON value at path in config text (comments allowed).
+// ok is false when the key is not set.
+func Get(data []byte, path []string) (value json.RawMessage, ok bool, err error) {
+	text := StripComments(data)
+	start := skipSpace(text, 0)
+	if start == len(text) {
+		return nil, false, nil
+	}
+	for _, name := range path {
+		m, err := findMember(text, start, name)
+		if err != nil || m == nil {
+			return nil, false, err
+		}
+		start = m.valStart
+	}
+	end, err := scanValue(text, start)
+	if err != nil {
+		return nil, false, err
+	}
+	return json.RawMessage(text[start:end]), true, nil
+}
+
+// Set returns config text (comments allowed) with the value at path replaced
+// by value, a JSON literal. Missing objects along the path are added at the
+// end of their parent, indented like their siblings. The rest of the text,
+// comments included, is left as it was.
+func Set(data []byte, path []string, value json.RawMessage) ([]byte, error) {
+	text := StripComments(data)
+	start := skipSpace(text, 0)
+	if start == len(text) {
+		data, text, start = []byte("{}\n"), []byte("{}\n"), 0
+	}
+
+	for i, name := range path {
+		m, err := findMember(text, start, name)
+		if err != nil {
+			return nil, err
+		}
+		if m == nil {
+			return insertMember(data, text, start, path[i:], value)
+		}
+		if i == len(path)-1 {
+			return splice(data, m.valStart, m.valEnd, string(value)), nil
+		}
+		if text[m.valStart] != '{' {
+			return nil, fmt.Errorf("%s is not an object", strings.Join(path[:i+1], "."))
+		}
+		start = m.valStart
+	}
+	return nil, fmt.Errorf("empty key")
+}
+
+// member locates one "key": value pair of an object in config text.
+type member struct {
+	name             string
+	keyStart         int
+	valStart, valEnd int
+}
+
+// findMember returns the member called name of the object at start, or nil.
+func findMember(text []byte, start int, name string) (*member, error) {
+	members, _, err := objectMembers(text, start)
+	if err != nil {
+		return nil, err
+	}
+	for i := range members {
+		if members[i].name == name {
+			return &members[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// objectMembers parses the object at start, returning its members and the
+// offset of its closing brace.
+func objectMembers(text []byte, start int) ([]member, int, error) {
+	if start >= len(text) || text[start] != '{' {
+		return nil, 0, fmt.Errorf("config: expected object at offset %d", start)
+	}
+	var members []member
+	i := skipSpace(text, start+1)
+	for {
+		if i >= len(text) {
+			return nil, 0, fmt.Errorf("config: unterminated object at offset %d", start)
+		}
+		if text[i] == '}' {
+			return members, i, nil
+		}
+		keyEnd, err := scanValue(text, i)
+		if err != nil {
+			return nil, 0, err
+		}
+		var name string
+		if err := json.Unmarshal(text[i:keyEnd], &name); err != nil {
+			return nil, 0, fmt.Errorf("config: bad key at offset %d", i)
+		}
+		colon := skipSpace(text, keyEnd)
+		if colon >= len(text) || text[colon] != ':' {
+			return nil, 0, fmt.Errorf("config: expected ':' at offset %d", colon)
+		}
+		valStart := skipSpace(text, colon+1)
+		valEnd, err := scanValue(text, valStart)
+		if err != nil {
+			return nil, 0, err
+		}
+		members = append(members, member{name: name, keyStart: i, valStart: valStart, valEnd: valEnd})
+
+		i = skipSpace(text, valEnd)
+		if i < len(text) && text[i] == ',' {
+			i = skipSpace(text, i+1)
+		}
+	}
+}
+
+// scanValue returns the offset just past the JSON value at start.
+func scanValue(text []byte, start int) (int, error) {
+	if start >= len(text) {
+		return 0, fmt.Errorf("config: unexpected end of file")
+	}
+	switch text[start] {
+	case '"':
+		for i := start + 1; i < len(text); i++ {
+			switch text[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1, nil
+			}
+		}
+		return 0, fmt.Errorf("config: unterminated string at offset %d", start)
+	case '{', '[':
+		depth := 0
+		for i := start; i < len(text); i++ {
+			switch text[i] {
+			case '"':
+				end, err := scanValue(text, i)
+				if err != nil {
+					return 0, err
+				}
+				i = end - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return i + 1, nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("config: unterminated %c at offset %d", text[start], start)
+	}
+	i := start
+	for i < len(text) && !strings.ContainsRune(",}] \t\r\n", rune(text[i])) {
+		i++
+	}
+	if i == start {
+		return 0, fmt.Errorf("config: expected a value at offset %d", start)
+	}
+	return i, nil
+}
+
+// skipSpace returns the offset of the first non-space byte at or after i.
+func skipSpace(text []byte, i int) int {
+	for i < len(text) && strings.ContainsRune(" \t\r\n", rune(text[i])) {
+		i++
+	}
+	return i
+}
+
+// insertMember adds path (nesting objects for all but the last name) with
+// value to the object at start.
+func insertMember(data, text []byte, start int, path []string, value json.RawMessage) ([]byte, error) {
+	members, closing, err := objectMembers(text, start)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(members) == 0 {
+		outer := lineIndent(data, start)
+		indent := outer + "  "
+		entry := "\n" + indent + nestedMember(path, value, indent) + "\n" + outer
+		return splice(data, start+1, closing, entry), nil
+	}
+
+	last := members[len(members)-1]
+	if !bytes.Contains(text[start:last.valEnd], []byte("\n")) {
+		// One-line object: stay on the line
+		return splice(data, last.valEnd, last.valEnd, ", "+inlineMember(path, value)), nil
+	}
+
+	// Match the last member's indentation, keeping any comment after it on
+	// its line
+	indent := lineIndent(data, last.keyStart)
+	entry := "\n" + indent + nestedMember(path, value, indent)
+	lineEnd := last.valEnd
+	for lineEnd < len(text) && (text[lineEnd] == ' ' || text[lineEnd] == '\t') {
+		lineEnd++
+	}
+	if lineEnd < len(text) && text[lineEnd] == '\n' && lineEnd > last.valEnd {
+		// Only blanks (or a stripped comment) follow the value: the comma
+		// goes after the value and the member after the comment
+		out := splice(data, lineEnd, lineEnd, entry)
+		return splice(out, last.valEnd, last.valEnd, ","), nil
+	}
+	return splice(data, last.valEnd, last.valEnd, ","+entry), nil
+}
+
+// nestedMember formats `"a": {"b": value}` for path [a b], one member per
+// line, with inner lines indented from indent.
+func nestedMember(path []string, value json.RawMessage, indent string) string {
+	key, _ := json.Marshal(path[0])
+	if len(path) == 1 {
+		return string(key) + ": " + string(value)
+	}
+	inner := indent + "  "
+	return string(key) + ": {\n" + inner + nestedMember(path[1:], value, inner) + "\n" + indent + "}"
+}
+
+// inlineMember formats `"a": {"b": value}` for path [a b] on one line.
+func inlineMember(path []string, value json.RawMessage) string {
+	key, _ := json.Marshal(path[0])
+	if len(path) == 1 {
+		return string(key) + ": " + string(value)
+	}
+	return string(key) + ": {" + inlineMember(path[1:], value) + "}"
+}
+
+// lineIndent returns the spaces and tabs that start the line holding offset.
+func lineIndent(data []byte, offset int) string {
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+	end := lineStart
+	for end < len(data) && (data[end] == ' ' || data[end] == '\t') {
+		end++
+	}
+	return string(data[lineStart:end])
+}
+
+// splice replaces data[start:end] with s.
+func splice(data []byte, start, end int, s string) []byte {
+	out := make([]byte, 0, len(data)-(end-start)+len(s))
+	out = append(out, data[:start]...)
+	out = append(out, s...)
+	return append(out, data[end:]...)
+}
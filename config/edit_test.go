@@ -0,0 +1,219 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStripComments(t *testing.T) {
+	in := "{\n  // note\n  \"a\": \"x // y\", /* b\n c */ \"b\": 1\n}"
+	got := string(StripComments([]byte(in)))
+	want := "{\n         \n  \"a\": \"x // y\",     \n      \"b\": 1\n}"
+	if got != want {
+		t.Errorf("StripComments:\ngot  %q\nwant %q", got, want)
+	}
+
+	cfg, err := Parse([]byte(in))
+	if err != nil {
+		t.Fatalf("Parse with comments: %v", err)
+	}
+	if cfg == nil {
+		t.Fatal("Parse: got nil config")
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"separator", []string{"separator"}},
+		{"modes.topn.n", []string{"modes", "topn", "n"}},
+		{`repos["github.com/org/repo"].defaults.width`, []string{"repos", "github.com/org/repo", "defaults", "width"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseKey(tt.key)
+		if err != nil {
+			t.Errorf("ParseKey(%q): %v", tt.key, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+
+	for _, key := range []string{"", "modes.", ".n", `repos["x`} {
+		if _, err := ParseKey(key); err == nil {
+			t.Errorf("ParseKey(%q): want error", key)
+		}
+	}
+}
+
+func TestCheckKey(t *testing.T) {
+	for _, key := range []string{"modes.topn.n", "defaults.width", "maxUntrackedSize", "exclude.generated", `repos["a.b"].modes.tree.depth`} {
+		path, _ := ParseKey(key)
+		if err := CheckKey(path); err != nil {
+			t.Errorf("CheckKey(%q): %v", key, err)
+		}
+	}
+	for _, key := range []string{"modes.topn.count", "widht", "defaults.separator.x"} {
+		path, _ := ParseKey(key)
+		if err := CheckKey(path); err == nil {
+			t.Errorf("CheckKey(%q): want error", key)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	in := `{
+  // Personal defaults
+  "defaults": {"width": 100}, // wide terminal
+  "modes": {
+    "topn": {
+      "n": 5 // keep it short
+    }
+  }
+}
+`
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  string
+	}{
+		{
+			name:  "replace keeps comments",
+			key:   "modes.topn.n",
+			value: "20",
+			want: `{
+  // Personal defaults
+  "defaults": {"width": 100}, // wide terminal
+  "modes": {
+    "topn": {
+      "n": 20 // keep it short
+    }
+  }
+}
+`,
+		},
+		{
+			name:  "add after commented member",
+			key:   "modes.topn.depth",
+			value: "2",
+			want: `{
+  // Personal defaults
+  "defaults": {"width": 100}, // wide terminal
+  "modes": {
+    "topn": {
+      "n": 5, // keep it short
+      "depth": 2
+    }
+  }
+}
+`,
+		},
+		{
+			name:  "add nested objects",
+			key:   "modes.smart.barScale",
+			value: `"log"`,
+			want: `{
+  // Personal defaults
+  "defaults": {"width": 100}, // wide terminal
+  "modes": {
+    "topn": {
+      "n": 5 // keep it short
+    },
+    "smart": {
+      "barScale": "log"
+    }
+  }
+}
+`,
+		},
+		{
+			name:  "inline object",
+			key:   "defaults.depth",
+			value: "3",
+			want: `{
+  // Personal defaults
+  "defaults": {"width": 100, "depth": 3}, // wide terminal
+  "modes": {
+    "topn": {
+      "n": 5 // keep it short
+    }
+  }
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, _ := ParseKey(tt.key)
+			got, err := Set([]byte(in), path, []byte(tt.value))
+			if err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Set(%s, %s):\ngot:\n%s\nwant:\n%s", tt.key, tt.value, got, tt.want)
+			}
+			if _, err := Parse(got); err != nil {
+				t.Errorf("result does not parse: %v", err)
+			}
+		})
+	}
+}
+
+func TestSet_EmptyFile(t *testing.T) {
+	got, err := Set(nil, []string{"modes", "topn", "n"}, []byte("20"))
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	want := "{\n  \"modes\": {\n    \"topn\": {\n      \"n\": 20\n    }\n  }\n}\n"
+	if string(got) != want {
+		t.Errorf("Set on empty file:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestSet_NotAnObject(t *testing.T) {
+	if _, err := Set([]byte(`{"separator": "/"}`), []string{"separator", "x"}, []byte("1")); err == nil {
+		t.Error("Set under a string: want error")
+	}
+}
+
+func TestSet_OneLine(t *testing.T) {
+	got, err := Set([]byte(`{"separator": "/"}`), []string{"modes", "topn", "n"}, []byte("20"))
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if want := `{"separator": "/", "modes": {"topn": {"n": 20}}}`; string(got) != want {
+		t.Errorf("Set on one-line object = %s, want %s", got, want)
+	}
+}
+
+func TestGet(t *testing.T) {
+	in := []byte(`{
+  "modes": {"topn": {"n": 5 /* short */}}, // modes
+  "separator": " // "
+}`)
+	tests := []struct {
+		key    string
+		want   string
+		wantOK bool
+	}{
+		{"modes.topn.n", "5", true},
+		{"separator", `" // "`, true},
+		{"modes.tree.depth", "", false},
+		{"defaults", "", false},
+	}
+	for _, tt := range tests {
+		path, _ := ParseKey(tt.key)
+		got, ok, err := Get(in, path)
+		if err != nil {
+			t.Errorf("Get(%q): %v", tt.key, err)
+			continue
+		}
+		if ok != tt.wantOK || string(got) != tt.want {
+			t.Errorf("Get(%q) = %q, %v; want %q, %v", tt.key, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
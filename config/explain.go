@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Setting is one resolved value and the layer that set it.
+type Setting struct {
+	Key    string // JSON name, as in ModeConfig
+	Value  string
+	Source string // "built-in default", "config modes.tree", "command line", ...
+}
+
+// Explain resolves mode like Resolve and reports where each value came
+// from: the last layer that set it, in Resolve's precedence order.
+func (c *Config) Explain(mode string, cliFlags *ModeConfig) []Setting {
+	sources := make(map[string]string)
+	layer := func(source string, m ModeConfig) {
+		for _, key := range m.SetKeys() {
+			sources[key] = source
+		}
+	}
+
+	if modeConfig, ok := ModeDefaults[mode]; ok {
+		layer("built-in "+mode+" default", modeConfig)
+	}
+	if c != nil {
+		layer("config defaults", c.Defaults)
+		if modeConfig, ok := c.Modes[mode]; ok {
+			layer("config modes."+mode, modeConfig)
+		}
+		if c.repo != nil {
+			prefix := fmt.Sprintf("config repos[%q]", c.repoKey)
+			layer(prefix+".defaults", c.repo.Defaults)
+			if modeConfig, ok := c.repo.Modes[mode]; ok {
+				layer(prefix+".modes."+mode, modeConfig)
+			}
+		}
+	}
+	if cliFlags != nil {
+		layer("command line", *cliFlags)
+	}
+
+	settings := c.Resolve(mode, cliFlags).values()
+	for i := range settings {
+		settings[i].Source = sources[settings[i].Key]
+		if settings[i].Source == "" {
+			settings[i].Source = "built-in default"
+		}
+	}
+	return settings
+}
+
+// values lists r's fields in ModeConfig's order, formatted for display.
+func (r ResolvedConfig) values() []Setting {
+	width := strconv.Itoa(r.Width)
+	if r.WidthAuto {
+		width = "auto"
+	}
+	colors := "renderer default"
+	if r.BracketColors != nil {
+		colors = strings.Join(r.BracketColors, ", ")
+	}
+	orDefault := func(s string) string {
+		if s == "" {
+			return "renderer default"
+		}
+		return s
+	}
+	quoteOrDefault := func(s string) string {
+		if s == "" {
+			return "renderer default"
+		}
+		return strconv.Quote(s)
+	}
+	return []Setting{
+		{Key: "width", Value: width},
+		{Key: "depth", Value: strconv.Itoa(r.Depth)},
+		{Key: "expand", Value: strconv.Itoa(r.Expand)},
+		{Key: "n", Value: strconv.Itoa(r.N)},
+		{Key: "bracketColors", Value: colors},
+		{Key: "zeroBar", Value: orDefault(r.ZeroBar)},
+		{Key: "barPadding", Value: strconv.FormatBool(r.BarPadding)},
+		{Key: "barScale", Value: orDefault(r.BarScale)},
+		{Key: "rootGroup", Value: strconv.Quote(r.RootGroup)},
+		{Key: "rootGroupSort", Value: strconv.FormatBool(r.RootGroupSort)},
+		{Key: "separator", Value: quoteOrDefault(r.Separator)},
+		{Key: "itemSeparator", Value: quoteOrDefault(r.ItemSeparator)},
+		{Key: "cleanupThreshold", Value: strconv.Itoa(r.CleanupThreshold)},
+		{Key: "abbrevCounts", Value: strconv.FormatBool(r.AbbrevCounts)},
+	}
+}
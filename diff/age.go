@@ -0,0 +1,200 @@
+package diff
+
+import (
+	"bufio"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LineRange is an inclusive range of 1-based line numbers.
+type LineRange struct {
+	Start, End int
+}
+
+// replacedLines records which pre-image lines a file's changes remove.
+type replacedLines struct {
+	OldPath string      // Path in the pre-image (differs for renames)
+	Ranges  []LineRange // Deleted or modified lines
+}
+
+// blameCache memoizes blame timestamps per pre-image revision, path, and
+// ranges, so repeated renders of the same diff don't rerun git blame.
+var blameCache sync.Map // string -> []int64
+
+// ComputeReplacedAges sets FileStat.ReplacedAge to the median age (relative
+// to now) of the lines each file's changes delete or rewrite.
+// Args are passed to git diff the same way as GetDiffStats.
+func ComputeReplacedAges(stats *DiffStats, now time.Time, args ...string) ([]string, error) {
+	return defaultClient.ComputeReplacedAges(stats, now, args...)
+}
+
+// ComputeReplacedAges blames the pre-image lines each file's changes remove
+// (one git blame per file, all ranges batched) and stores their median age.
+// Pure additions and untracked files keep ReplacedAge 0. A file whose blame
+// fails is skipped with a warning.
+func (c *Client) ComputeReplacedAges(stats *DiffStats, now time.Time, args ...string) ([]string, error) {
+	var warnings []string
+
+	base, err := c.preImageRev(args)
+	if err != nil {
+		return c.fail(err, warnings)
+	}
+
+	output, err := c.output(c.patchArgs(args)...)
+	if err != nil {
+		return c.fail(err, warnings)
+	}
+
+	replaced := parseReplacedLines(string(output))
+	for i := range stats.Files {
+		f := &stats.Files[i]
+		r, ok := replaced[f.Path]
+		if !ok {
+			continue
+		}
+		times, err := c.blameTimes(base, r)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("blame %s: %v", r.OldPath, err))
+			continue
+		}
+		if len(times) == 0 {
+			continue
+		}
+		slices.Sort(times)
+		median := time.Unix(times[len(times)/2], 0)
+		f.ReplacedAge = max(now.Sub(median), time.Second) // Nonzero marks "analyzed"
+	}
+	return warnings, nil
+}
+
+// blameTimes returns the committer time of every line in r at rev.
+func (c *Client) blameTimes(rev string, r replacedLines) ([]int64, error) {
+	args := []string{"blame", "--line-porcelain"}
+	for _, lr := range r.Ranges {
+		args = append(args, "-L", fmt.Sprintf("%d,%d", lr.Start, lr.End))
+	}
+	args = append(args, rev, "--", c.cwdPath(r.OldPath))
+
+	key := c.Dir + "\x00" + strings.Join(args, "\x00")
+	if cached, ok := blameCache.Load(key); ok {
+		return cached.([]int64), nil
+	}
+
+	output, err := c.output(args...)
+	if err != nil {
+		return nil, err
+	}
+	times := ParseBlameTimes(string(output))
+	blameCache.Store(key, times)
+	return times, nil
+}
+
+// preImageRev returns the revision holding the "before" side of a diff:
+// HEAD for working-tree and --cached diffs, A for "A B" and "A..B",
+// and the merge-base for "A...B".
+func (c *Client) preImageRev(args []string) (string, error) {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		if a, b, ok := strings.Cut(arg, "..."); ok {
+			out, err := c.output("merge-base", orHead(a), orHead(b))
+			if err != nil {
+				return "", err
+			}
+			return strings.TrimSpace(string(out)), nil
+		}
+		if a, _, ok := strings.Cut(arg, ".."); ok {
+			return orHead(a), nil
+		}
+		return arg, nil
+	}
+	return "HEAD", nil
+}
+
+// orHead returns rev, or "HEAD" for an empty range endpoint.
+func orHead(rev string) string {
+	if rev == "" {
+		return "HEAD"
+	}
+	return rev
+}
+
+// parseReplacedLines parses git diff -U0 output into the pre-image lines
+// each file removes, keyed by post-image path (pre-image path for deletions).
+// Hunk header format: "@@ -start,count +start,count @@".
+func parseReplacedLines(output string) map[string]replacedLines {
+	result := make(map[string]replacedLines)
+	var oldPath, path string
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			oldPath, path = "", ""
+		case strings.HasPrefix(line, "--- a/"):
+			oldPath = strings.TrimPrefix(line, "--- a/")
+			path = oldPath // Kept for deletions
+		case strings.HasPrefix(line, "+++ b/"):
+			path = strings.TrimPrefix(line, "+++ b/")
+		case strings.HasPrefix(line, "@@ ") && oldPath != "":
+			lr, ok := parseOldRange(line)
+			if !ok {
+				continue
+			}
+			r := result[path]
+			r.OldPath = oldPath
+			r.Ranges = append(r.Ranges, lr)
+			result[path] = r
+		}
+	}
+	return result
+}
+
+// parseOldRange extracts the pre-image line range from a hunk header.
+// Returns false for pure insertions (count 0).
+func parseOldRange(header string) (LineRange, bool) {
+	fields := strings.Fields(header)
+	if len(fields) < 2 || !strings.HasPrefix(fields[1], "-") {
+		return LineRange{}, false
+	}
+	startStr, countStr, hasCount := strings.Cut(fields[1][1:], ",")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return LineRange{}, false
+	}
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return LineRange{}, false
+		}
+	}
+	if count == 0 {
+		return LineRange{}, false
+	}
+	return LineRange{Start: start, End: start + count - 1}, true
+}
+
+// ParseBlameTimes extracts the committer time of each line from
+// git blame --line-porcelain output.
+func ParseBlameTimes(output string) []int64 {
+	var times []int64
+	for _, line := range strings.Split(output, "\n") {
+		if rest, ok := strings.CutPrefix(line, "committer-time "); ok {
+			if t, err := strconv.ParseInt(rest, 10, 64); err == nil {
+				times = append(times, t)
+			}
+		}
+	}
+	return times
+}
@@ -0,0 +1,82 @@
+package diff
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseReplacedLines(t *testing.T) {
+	output := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -3,2 +3,4 @@ func main() {
+@@ -10 +12 @@ func helper() {
+@@ -20,0 +22,3 @@ func other() {
+diff --git a/old.go b/new.go
+--- a/old.go
++++ b/new.go
+@@ -5,3 +5 @@
+diff --git a/gone.go b/gone.go
+--- a/gone.go
++++ /dev/null
+@@ -1,4 +0,0 @@
+diff --git a/added.go b/added.go
+--- /dev/null
++++ b/added.go
+@@ -0,0 +1,9 @@
+`
+	got := parseReplacedLines(output)
+	want := map[string]replacedLines{
+		"main.go": {OldPath: "main.go", Ranges: []LineRange{{3, 4}, {10, 10}}},
+		"new.go":  {OldPath: "old.go", Ranges: []LineRange{{5, 7}}},
+		"gone.go": {OldPath: "gone.go", Ranges: []LineRange{{1, 4}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseReplacedLines() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseBlameTimes(t *testing.T) {
+	output := `abc123 3 3 1
+author a
+committer-time 1700000000
+	line three
+def456 4 4 1
+committer-time 1600000000
+committer-time bogus
+	line four
+`
+	got := ParseBlameTimes(output)
+	want := []int64{1700000000, 1600000000}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseBlameTimes() = %v, want %v", got, want)
+	}
+}
+
+func TestClient_ComputeReplacedAges_PrefixConfig(t *testing.T) {
+	for _, config := range []string{"diff.noprefix", "diff.mnemonicPrefix"} {
+		t.Run(config, func(t *testing.T) {
+			client, git := newTestRepo(t)
+			if err := os.WriteFile(client.path("a.txt"), []byte("one\ntwo\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			git("add", "a.txt")
+			git("commit", "-q", "-m", "init")
+			git("config", config, "true")
+			if err := os.WriteFile(client.path("a.txt"), []byte("one\n2\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			stats := &DiffStats{Files: []FileStat{{Path: "a.txt", Additions: 1, Deletions: 1}}}
+			warnings, err := client.ComputeReplacedAges(stats, time.Now().Add(time.Hour))
+			if err != nil || len(warnings) != 0 {
+				t.Fatalf("ComputeReplacedAges() warnings = %v, err = %v", warnings, err)
+			}
+			if stats.Files[0].ReplacedAge == 0 {
+				t.Errorf("ReplacedAge = 0 with %s, want the blamed line's age", config)
+			}
+		})
+	}
+}
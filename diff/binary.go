@@ -0,0 +1,112 @@
+package diff
+
+import (
+	"path"
+	"strings"
+)
+
+// BinaryCategories lists the categories BinaryCategory assigns, in the order
+// summaries show them. "other" covers binary files with unknown extensions.
+var BinaryCategories = []string{"images", "fonts", "archives", "audio", "video", "documents", "other"}
+
+// binaryExtensions maps lowercase file extensions to binary categories.
+var binaryExtensions = map[string]string{
+	".png": "images", ".jpg": "images", ".jpeg": "images", ".gif": "images", ".webp": "images",
+	".bmp": "images", ".ico": "images", ".tif": "images", ".tiff": "images", ".psd": "images",
+	".heic": "images", ".avif": "images",
+	".ttf": "fonts", ".otf": "fonts", ".woff": "fonts", ".woff2": "fonts", ".eot": "fonts",
+	".zip": "archives", ".tar": "archives", ".gz": "archives", ".tgz": "archives", ".bz2": "archives",
+	".xz": "archives", ".zst": "archives", ".7z": "archives", ".rar": "archives", ".jar": "archives",
+	".mp3": "audio", ".wav": "audio", ".ogg": "audio", ".flac": "audio", ".aac": "audio", ".m4a": "audio",
+	".mp4": "video", ".mov": "video", ".webm": "video", ".mkv": "video", ".avi": "video",
+	".pdf": "documents", ".doc": "documents", ".docx": "documents", ".xls": "documents",
+	".xlsx": "documents", ".ppt": "documents", ".pptx": "documents", ".key": "documents",
+}
+
+// BinaryCategory classifies a binary file by extension (of the new path for
+// renames), returning "other" for extensions it does not know.
+func BinaryCategory(p string) string {
+	if newPath, ok := RenameTarget(p); ok {
+		p = newPath
+	}
+	if category, ok := binaryExtensions[strings.ToLower(path.Ext(p))]; ok {
+		return category
+	}
+	return "other"
+}
+
+// BinaryTotals sums the binary files in one category.
+type BinaryTotals struct {
+	Category     string
+	Files        int
+	SizeDelta    int64 // Sum of the files' SizeDelta values that were computed
+	HasSizeDelta bool  // At least one file had its SizeDelta computed
+}
+
+// BinarySummary totals binary files by category, in BinaryCategories order,
+// leaving out categories with no files. Run AddChangeDetails first to
+// include size deltas.
+func (s *DiffStats) BinarySummary() []BinaryTotals {
+	byCategory := make(map[string]*BinaryTotals)
+	for _, f := range s.Files {
+		if !f.IsBinary {
+			continue
+		}
+		category := BinaryCategory(f.Path)
+		t := byCategory[category]
+		if t == nil {
+			t = &BinaryTotals{Category: category}
+			byCategory[category] = t
+		}
+		t.Files++
+		if f.HasSizeDelta {
+			t.SizeDelta += f.SizeDelta
+			t.HasSizeDelta = true
+		}
+	}
+
+	var summary []BinaryTotals
+	for _, category := range BinaryCategories {
+		if t := byCategory[category]; t != nil {
+			summary = append(summary, *t)
+		}
+	}
+	return summary
+}
+
+// BinaryHeavy reports whether binary files make up at least half of the
+// changed files, so line counts say little about the diff's size.
+func (s *DiffStats) BinaryHeavy() bool {
+	binary := 0
+	for _, f := range s.Files {
+		if f.IsBinary {
+			binary++
+		}
+	}
+	return binary > 0 && 2*binary >= len(s.Files)
+}
+
+// InBytes returns a copy of s measured in bytes instead of lines: each
+// file's Additions is how many bytes it grew and Deletions how many it
+// shrank, from SizeDelta. Files without a SizeDelta count as 0 bytes and
+// are returned in unsized. Run AddSizeDeltas first.
+func (s *DiffStats) InBytes() (result *DiffStats, unsized []string) {
+	bytes := *s
+	bytes.Files = make([]FileStat, len(s.Files))
+	bytes.TotalAdd, bytes.TotalDel = 0, 0
+	for i, f := range s.Files {
+		f.Additions, f.Deletions = 0, 0
+		switch {
+		case !f.HasSizeDelta:
+			unsized = append(unsized, f.Path)
+		case f.SizeDelta > 0:
+			f.Additions = int(f.SizeDelta)
+		default:
+			f.Deletions = int(-f.SizeDelta)
+		}
+		bytes.TotalAdd += f.Additions
+		bytes.TotalDel += f.Deletions
+		bytes.Files[i] = f
+	}
+	return &bytes, unsized
+}
@@ -0,0 +1,78 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBinaryCategory(t *testing.T) {
+	tests := map[string]string{
+		"assets/logo.PNG":             "images",
+		"fonts/Inter.woff2":           "fonts",
+		"dist/release.tar.gz":         "archives",
+		"docs/spec.pdf":               "documents",
+		"{old.bin => sprites/a.webp}": "images",
+		"data/model.bin":              "other",
+		"Makefile":                    "other",
+	}
+	for p, want := range tests {
+		if got := BinaryCategory(p); got != want {
+			t.Errorf("BinaryCategory(%q) = %q, want %q", p, got, want)
+		}
+	}
+}
+
+func TestDiffStats_BinarySummary(t *testing.T) {
+	stats := &DiffStats{Files: []FileStat{
+		{Path: "dist/app.zip", IsBinary: true, SizeDelta: 2 << 20, HasSizeDelta: true},
+		{Path: "img/a.png", IsBinary: true, SizeDelta: 100 << 10, HasSizeDelta: true},
+		{Path: "main.go", Additions: 3},
+		{Path: "img/b.jpg", IsBinary: true, SizeDelta: -20 << 10, HasSizeDelta: true},
+		{Path: "blob.dat", IsBinary: true},
+	}}
+
+	want := []BinaryTotals{
+		{Category: "images", Files: 2, SizeDelta: 80 << 10, HasSizeDelta: true},
+		{Category: "archives", Files: 1, SizeDelta: 2 << 20, HasSizeDelta: true},
+		{Category: "other", Files: 1},
+	}
+	if got := stats.BinarySummary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("BinarySummary() = %+v, want %+v", got, want)
+	}
+	if got := (&DiffStats{Files: []FileStat{{Path: "a.go", Additions: 1}}}).BinarySummary(); got != nil {
+		t.Errorf("BinarySummary() without binary files = %+v, want nil", got)
+	}
+}
+
+func TestDiffStats_InBytes(t *testing.T) {
+	stats := &DiffStats{Files: []FileStat{
+		{Path: "img/a.png", IsBinary: true, SizeDelta: 300 << 10, HasSizeDelta: true},
+		{Path: "img/b.png", IsBinary: true, SizeDelta: -2048, HasSizeDelta: true},
+		{Path: "main.go", Additions: 3, Deletions: 1, SizeDelta: 20, HasSizeDelta: true},
+		{Path: "blob.dat", IsBinary: true},
+	}, TotalFiles: 4, TotalAdd: 3, TotalDel: 1}
+
+	got, unsized := stats.InBytes()
+	want := [][2]int{{300 << 10, 0}, {0, 2048}, {20, 0}, {0, 0}}
+	for i, f := range got.Files {
+		if [2]int{f.Additions, f.Deletions} != want[i] {
+			t.Errorf("%s = +%d -%d, want +%d -%d", f.Path, f.Additions, f.Deletions, want[i][0], want[i][1])
+		}
+	}
+	if got.TotalAdd != 300<<10+20 || got.TotalDel != 2048 || got.TotalFiles != 4 {
+		t.Errorf("totals = %d files +%d -%d", got.TotalFiles, got.TotalAdd, got.TotalDel)
+	}
+	if !reflect.DeepEqual(unsized, []string{"blob.dat"}) {
+		t.Errorf("unsized = %q, want [blob.dat]", unsized)
+	}
+	if stats.Files[2].Additions != 3 || stats.TotalAdd != 3 {
+		t.Error("InBytes modified the original stats")
+	}
+
+	if !stats.BinaryHeavy() {
+		t.Error("BinaryHeavy() = false with 3 of 4 files binary")
+	}
+	if (&DiffStats{Files: []FileStat{{Path: "a.go"}, {Path: "b.go"}, {Path: "c.png", IsBinary: true}}}).BinaryHeavy() {
+		t.Error("BinaryHeavy() = true with 1 of 3 files binary")
+	}
+}
@@ -0,0 +1,133 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BranchEntry summarizes one local branch against the branch it tracks.
+type BranchEntry struct {
+	Name         string     // Short branch name (e.g., "feature/login")
+	Base         string     // Upstream, else the main branch; "" when there is none
+	UpstreamGone bool       // The upstream was deleted; Base is the main branch
+	Ahead        int        // Commits on Name not on Base
+	Behind       int        // Commits on Base not on Name
+	Time         time.Time  // Committer date of the branch tip
+	Stats        *DiffStats // Changes since Name forked from Base (Base...Name); nil without a Base or when comparing failed
+}
+
+// Compared reports whether Ahead, Behind, and Stats hold a comparison
+// with Base.
+func (e BranchEntry) Compared() bool {
+	return e.Stats != nil
+}
+
+// ListBranches returns every local branch, least recently active first, with
+// its divergence from its base.
+func ListBranches() ([]BranchEntry, []string, error) {
+	return defaultClient.ListBranches()
+}
+
+// ListBranches compares each local branch with its upstream, or with the
+// main branch (origin/HEAD, else main or master) when it has none or its
+// upstream is gone. The main branch itself has no base unless it tracks
+// one. Git failures listing branches follow the client's FailOpen policy;
+// a branch whose comparison fails is kept uncompared with a warning.
+func (c *Client) ListBranches() ([]BranchEntry, []string, error) {
+	var warnings []string
+	output, err := c.output("for-each-ref", "--format="+branchListFormat, "refs/heads")
+	if err != nil {
+		warnings, err = c.fail(err, warnings)
+		return nil, warnings, err
+	}
+
+	entries, parseWarnings := ParseBranchList(string(output))
+	warnings = append(warnings, parseWarnings...)
+
+	mainBranch := c.mainBranch()
+	for i := range entries {
+		e := &entries[i]
+		if e.Base == "" && mainBranch != e.Name && "origin/"+e.Name != mainBranch {
+			e.Base = mainBranch
+		}
+		if e.Base == "" {
+			continue
+		}
+
+		var ahead, behind int
+		counts, err := c.output("rev-list", "--left-right", "--count", e.Base+"..."+e.Name, "--")
+		if err == nil {
+			_, err = fmt.Sscan(string(counts), &behind, &ahead)
+		}
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: comparing with %s: %v", e.Name, e.Base, err))
+			continue
+		}
+		stats, diffWarnings, err := c.GetDiffStats(e.Base + "..." + e.Name)
+		warnings = append(warnings, diffWarnings...)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %v", e.Name, err))
+			continue
+		}
+		e.Ahead, e.Behind, e.Stats = ahead, behind, stats
+	}
+	return entries, warnings, nil
+}
+
+// mainBranch names the repository's main line: the remote's default branch
+// (origin/HEAD), else a local main or master, else "".
+func (c *Client) mainBranch() string {
+	if out, err := c.output("symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		return strings.TrimSpace(string(out))
+	}
+	for _, name := range []string{"main", "master"} {
+		if _, err := c.output("rev-parse", "--verify", "--quiet", "refs/heads/"+name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// branchListFormat is the for-each-ref format ParseBranchList reads.
+const branchListFormat = "%(refname:short)%00%(upstream:short)%00%(upstream:track)%00%(committerdate:unix)"
+
+// ParseBranchList parses git for-each-ref output in branchListFormat and
+// sorts the branches least recently active first. A branch whose upstream
+// is "[gone]" gets no Base, so it is compared with the main branch.
+// Malformed lines are skipped with a warning.
+func ParseBranchList(output string) ([]BranchEntry, []string) {
+	var entries []BranchEntry
+	var warnings []string
+
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "\x00", 4)
+		if len(parts) != 4 {
+			warnings = append(warnings, fmt.Sprintf("malformed branch list line: %q", line))
+			continue
+		}
+		secs, err := strconv.ParseInt(parts[3], 10, 64)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("bad commit date %q for %s", parts[3], parts[0]))
+			continue
+		}
+		e := BranchEntry{Name: parts[0], Base: parts[1], Time: time.Unix(secs, 0)}
+		if parts[2] == "[gone]" {
+			e.Base, e.UpstreamGone = "", true
+		}
+		entries = append(entries, e)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Time.Equal(entries[j].Time) {
+			return entries[i].Time.Before(entries[j].Time)
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, warnings
+}
@@ -0,0 +1,70 @@
+package diff
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseBranchList(t *testing.T) {
+	output := "main\x00origin/main\x00\x001700000000\n" +
+		"garbage\n" +
+		"old\x00origin/old\x00[gone]\x001600000000\n" +
+		"broken\x00\x00\x00soon\n" +
+		"alpha\x00\x00\x001600000000\n"
+
+	entries, warnings := ParseBranchList(output)
+	if len(warnings) != 2 {
+		t.Errorf("got %d warnings, want 2: %v", len(warnings), warnings)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	if len(names) != 3 || names[0] != "alpha" || names[1] != "old" || names[2] != "main" {
+		t.Fatalf("names = %v, want [alpha old main] (oldest first, then by name)", names)
+	}
+	if entries[2].Base != "origin/main" || !entries[2].Time.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("main = %+v", entries[2])
+	}
+	if entries[1].Base != "" || !entries[1].UpstreamGone {
+		t.Errorf("old = %+v, want no base with a gone upstream", entries[1])
+	}
+}
+
+func TestClient_ListBranches_GoneUpstream(t *testing.T) {
+	client, git := newTestRepo(t)
+	if err := os.WriteFile(client.path("a.txt"), []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "Add a")
+	git("branch", "-M", "main")
+	git("checkout", "-q", "-b", "feat")
+	if err := os.WriteFile(client.path("b.txt"), []byte("b\nb\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "b.txt")
+	git("commit", "-q", "-m", "Add b")
+
+	// An upstream whose remote branch no longer exists, as after a merged
+	// pull request's branch is deleted and pruned
+	git("remote", "add", "origin", "/nonexistent")
+	git("config", "branch.feat.remote", "origin")
+	git("config", "branch.feat.merge", "refs/heads/feat")
+
+	entries, warnings, err := client.ListBranches()
+	if err != nil || len(warnings) > 0 {
+		t.Fatalf("ListBranches() = %v, %v", warnings, err)
+	}
+	for _, e := range entries {
+		if e.Name != "feat" {
+			continue
+		}
+		if e.Base != "main" || !e.UpstreamGone || !e.Compared() || e.Ahead != 1 || e.Behind != 0 || e.Stats.TotalAdd != 2 {
+			t.Errorf("feat = %+v, want compared with main: ahead 1, +2", e)
+		}
+		return
+	}
+	t.Errorf("ListBranches() = %+v, want feat", entries)
+}
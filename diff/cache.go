@@ -0,0 +1,153 @@
+package diff
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// statsCacheFile is where CachedWorktreeStats keeps its result, relative to
+// the git directory (per worktree).
+const statsCacheFile = "diff-viz-stats.json"
+
+// statsCache is the cached working tree diff and the fingerprint it is
+// valid for.
+type statsCache struct {
+	Key   string     `json:"key"`
+	Stats *DiffStats `json:"stats"`
+}
+
+// CachedWorktreeStats returns GetAllStats() for the working tree, reusing
+// the last result while the tree is unchanged.
+func CachedWorktreeStats() (*DiffStats, []string, error) {
+	return defaultClient.CachedWorktreeStats()
+}
+
+// CachedWorktreeStats returns GetAllStats() for the working tree, reusing
+// the last result while the tree is unchanged. A hit costs one git status
+// (with optional locks off) instead of a full diff, so shell prompts can call
+// it on every command.
+//
+// The cache is keyed by git status --porcelain=v2 output, which covers HEAD,
+// staged blobs, and the set of changed paths, plus the size and modification
+// time of each changed file, which covers further edits to files that were
+// already dirty. Results with warnings are not cached; failures to read or
+// write the cache fall back to computing the stats.
+func (c *Client) CachedWorktreeStats() (*DiffStats, []string, error) {
+	key, cachePath, err := c.worktreeKey()
+	if err != nil {
+		return c.GetAllStats()
+	}
+	if stats := readStatsCache(cachePath, key); stats != nil {
+		return stats, nil, nil
+	}
+
+	stats, warnings, err := c.GetAllStats()
+	if err == nil && len(warnings) == 0 {
+		writeStatsCache(cachePath, statsCache{Key: key, Stats: stats})
+	}
+	return stats, warnings, err
+}
+
+// worktreeKey fingerprints the working tree and returns the cache file path.
+func (c *Client) worktreeKey() (key, cachePath string, err error) {
+	out, err := c.output("rev-parse", "--show-cdup", "--git-path", statsCacheFile)
+	if err != nil {
+		return "", "", err
+	}
+	lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	if len(lines) != 2 {
+		return "", "", fmt.Errorf("git rev-parse: unexpected output %q", out)
+	}
+	cdup, cachePath := lines[0], c.path(lines[1])
+
+	status, err := c.outputEnv([]string{"GIT_OPTIONAL_LOCKS=0"},
+		"status", "--porcelain=v2", "-z", "--branch", "--untracked-files=all")
+	if err != nil {
+		return "", "", err
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%d %d %q\n", c.IncludeUntracked, c.maxUntrackedSize(), c.Relative)
+	h.Write(status)
+	for _, p := range statusPaths(status) {
+		info, err := os.Lstat(c.path(filepath.Join(cdup, p)))
+		if err != nil {
+			fmt.Fprintf(h, "%s -\n", p)
+			continue
+		}
+		fmt.Fprintf(h, "%s %d %d\n", p, info.Size(), info.ModTime().UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil)), cachePath, nil
+}
+
+// statusPaths returns the current path of each entry in git status
+// --porcelain=v2 -z output. Headers, ignored entries, and the original
+// paths of renames are skipped.
+func statusPaths(status []byte) []string {
+	// Fields before the path: ordinary "1 XY sub mH mI mW hH hI path",
+	// rename "2 ... X<score> path\0orig", unmerged "u ... h1 h2 h3 path",
+	// untracked "? path"
+	fields := map[byte]int{'1': 8, '2': 9, 'u': 10, '?': 1}
+
+	var paths []string
+	entries := bytes.Split(status, []byte{0})
+	for i := 0; i < len(entries); i++ {
+		entry := string(entries[i])
+		if entry == "" {
+			continue
+		}
+		n, ok := fields[entry[0]]
+		if !ok {
+			continue
+		}
+		if parts := strings.SplitN(entry, " ", n+1); len(parts) == n+1 {
+			paths = append(paths, parts[n])
+		}
+		if entry[0] == '2' {
+			i++ // Original path
+		}
+	}
+	return paths
+}
+
+// readStatsCache returns the cached stats if the file holds key, else nil.
+func readStatsCache(path, key string) *DiffStats {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cache statsCache
+	if json.Unmarshal(data, &cache) != nil || cache.Key != key || cache.Stats == nil {
+		return nil
+	}
+	return cache.Stats
+}
+
+// writeStatsCache replaces the cache file, ignoring failures (a read-only
+// repository just never hits the cache).
+func writeStatsCache(path string, cache statsCache) {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), statsCacheFile+".*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
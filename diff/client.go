@@ -0,0 +1,294 @@
+package diff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sentinel errors for classifying git failures. Use errors.Is to test:
+//
+//	if errors.Is(err, diff.ErrNotARepo) { ... }
+var (
+	ErrNotARepo    = errors.New("not a git repository")
+	ErrBadRevision = errors.New("unknown revision")
+	ErrGitMissing  = errors.New("git executable not found")
+	ErrTimeout     = errors.New("git command timed out")
+)
+
+// GitError describes a failed git invocation.
+// Kind is one of the sentinel errors when the failure is recognized, else nil.
+type GitError struct {
+	Args     []string // git arguments (without the binary)
+	Stderr   string   // trimmed stderr output
+	ExitCode int      // process exit code (-1 if it never ran)
+	Kind     error    // ErrNotARepo, ErrBadRevision, ErrGitMissing, ErrTimeout, or nil
+}
+
+// Error formats the failure like the existing fail-open warnings.
+func (e *GitError) Error() string {
+	name := "git"
+	if len(e.Args) > 0 {
+		name = "git " + e.Args[0]
+	}
+	if e.Stderr != "" {
+		return fmt.Sprintf("%s: %s", name, e.Stderr)
+	}
+	if e.Kind != nil {
+		return fmt.Sprintf("%s: %v", name, e.Kind)
+	}
+	return fmt.Sprintf("%s exited with code %d", name, e.ExitCode)
+}
+
+// Unwrap returns the sentinel kind so errors.Is works.
+func (e *GitError) Unwrap() error {
+	return e.Kind
+}
+
+// Options configures how a Client invokes git.
+// The zero value runs "git" from PATH in the process working directory.
+type Options struct {
+	GitPath  string        // git binary path (default "git" from PATH)
+	Dir      string        // working directory for git and file reads (default cwd)
+	Env      []string      // extra environment variables ("KEY=VALUE")
+	Timeout  time.Duration // per-command timeout (0 = no timeout)
+	FailOpen bool          // report git failures as warnings instead of errors
+
+	// MaxUntrackedSize caps the size in bytes of untracked files whose lines
+	// are counted; larger files are marked IsLarge with no additions.
+	// 0 means DefaultMaxUntrackedSize; negative means no limit.
+	MaxUntrackedSize int64
+
+	// IncludeUntracked controls whether GetAllStats adds untracked files.
+	// CaptureCurrentTree leaves them out only for UntrackedExclude.
+	IncludeUntracked UntrackedPolicy
+
+	// Relative limits diffs to this repo-relative directory and reports
+	// paths relative to it, as git diff --relative=DIR does ("" = the whole
+	// repository).
+	Relative string
+
+	// RecordCommands keeps each git command line for Commands and
+	// Provenance. Off by default so long-running servers don't grow the
+	// list forever.
+	RecordCommands bool
+}
+
+// UntrackedPolicy controls whether GetAllStats adds untracked files.
+type UntrackedPolicy int
+
+const (
+	UntrackedAuto    UntrackedPolicy = iota // Only for working tree diffs (no args or just "HEAD")
+	UntrackedInclude                        // Always, including --cached and range diffs
+	UntrackedExclude                        // Never
+)
+
+// DefaultMaxUntrackedSize is the untracked file size limit when
+// Options.MaxUntrackedSize is 0.
+const DefaultMaxUntrackedSize = 32 << 20 // 32 MiB
+
+// Client runs git commands and parses their output into DiffStats.
+//
+// FailOpen controls error policy: when true (the package-level default),
+// git failures are reported as warnings and empty stats are returned;
+// when false, they are returned as *GitError values.
+type Client struct {
+	Options
+
+	mu       sync.Mutex
+	commands []string // Git invocations so far, when RecordCommands is set
+}
+
+// NewClient returns a Client using opts.
+func NewClient(opts Options) *Client {
+	return &Client{Options: opts}
+}
+
+// defaultClient backs the package-level functions (fail-open for compatibility).
+var defaultClient = NewClient(Options{FailOpen: true})
+
+// SetMaxUntrackedSize sets Options.MaxUntrackedSize for the package-level functions.
+func SetMaxUntrackedSize(n int64) {
+	defaultClient.MaxUntrackedSize = n
+}
+
+// SetIncludeUntracked sets Options.IncludeUntracked for the package-level functions.
+func SetIncludeUntracked(p UntrackedPolicy) {
+	defaultClient.IncludeUntracked = p
+}
+
+// SetRecordCommands sets Options.RecordCommands for the package-level functions.
+func SetRecordCommands(record bool) {
+	defaultClient.RecordCommands = record
+}
+
+// SetRelative sets Options.Relative for the package-level functions.
+func SetRelative(dir string) {
+	defaultClient.Relative = dir
+}
+
+// relativeArgs returns the --relative option git diff and git diff-tree
+// take for Options.Relative, if set.
+func (c *Client) relativeArgs() []string {
+	if c.Relative == "" || c.Relative == "." {
+		return nil
+	}
+	return []string{"--relative=" + c.Relative}
+}
+
+// includeUntracked reports whether GetAllStats adds untracked files for args.
+func (c *Client) includeUntracked(args []string) bool {
+	switch c.IncludeUntracked {
+	case UntrackedInclude:
+		return true
+	case UntrackedExclude:
+		return false
+	}
+	return isWorktreeDiff(args)
+}
+
+// isWorktreeDiff reports whether args diff the working tree against HEAD.
+func isWorktreeDiff(args []string) bool {
+	return len(args) == 0 || (len(args) == 1 && args[0] == "HEAD")
+}
+
+// maxUntrackedSize returns the effective untracked file size limit.
+func (c *Client) maxUntrackedSize() int64 {
+	if c.MaxUntrackedSize == 0 {
+		return DefaultMaxUntrackedSize
+	}
+	return c.MaxUntrackedSize
+}
+
+// command builds an exec.Cmd for a git invocation with the client's
+// binary, directory, and environment applied.
+func (c *Client) command(ctx context.Context, args ...string) *exec.Cmd {
+	gitPath := c.GitPath
+	if gitPath == "" {
+		gitPath = "git"
+	}
+	cmd := exec.CommandContext(ctx, gitPath, args...)
+	if c.RecordCommands {
+		c.mu.Lock()
+		c.commands = append(c.commands, strings.Join(append([]string{"git"}, args...), " "))
+		c.mu.Unlock()
+	}
+	cmd.Dir = c.Dir
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	return cmd
+}
+
+// output runs git and returns stdout, or a *GitError on failure.
+func (c *Client) output(args ...string) ([]byte, error) {
+	return c.outputEnv(nil, args...)
+}
+
+// outputEnv runs git with additional environment variables.
+func (c *Client) outputEnv(env []string, args ...string) ([]byte, error) {
+	ctx := context.Background()
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	cmd := c.command(ctx, args...)
+	if len(env) > 0 {
+		cmd.Env = append(cmd.Environ(), env...)
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, &GitError{Args: args, ExitCode: -1, Kind: ErrTimeout}
+		}
+		return nil, newGitError(args, err)
+	}
+	return out, nil
+}
+
+// path resolves a repo-relative path against the client's directory.
+func (c *Client) path(rel string) string {
+	if c.Dir == "" {
+		return rel
+	}
+	return filepath.Join(c.Dir, rel)
+}
+
+// worktreePath returns the location of a path as git diff prints it
+// (repo-relative, or relative to Options.Relative) from the client's
+// directory, which may be below the top level.
+func (c *Client) worktreePath(diffPath string) string {
+	return c.path(c.cwdPath(diffPath))
+}
+
+// cwdPath returns a path as git diff prints it relative to the directory
+// git runs in, for commands such as git blame that take paths from there.
+func (c *Client) cwdPath(diffPath string) string {
+	cdup := ""
+	if out, err := c.output("rev-parse", "--show-cdup"); err == nil {
+		cdup = strings.TrimSpace(string(out))
+	}
+	if len(c.relativeArgs()) > 0 {
+		diffPath = path.Join(c.Relative, diffPath)
+	}
+	return path.Join(cdup, diffPath)
+}
+
+// fail applies the error policy to a git failure.
+// Fail-open: returns the error text as a warning and nil error.
+// Strict: returns the error.
+func (c *Client) fail(err error, warnings []string) ([]string, error) {
+	if c.FailOpen {
+		return append(warnings, err.Error()), nil
+	}
+	return warnings, err
+}
+
+// newGitError converts an exec error into a classified *GitError.
+func newGitError(args []string, err error) *GitError {
+	gitErr := &GitError{Args: args, ExitCode: -1}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		gitErr.ExitCode = exitErr.ExitCode()
+		gitErr.Stderr = strings.TrimSpace(string(exitErr.Stderr))
+	case errors.Is(err, exec.ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		gitErr.Kind = ErrGitMissing
+		return gitErr
+	default:
+		gitErr.Stderr = err.Error()
+	}
+
+	gitErr.Kind = classifyStderr(gitErr.Stderr)
+	return gitErr
+}
+
+// classifyStderr maps well-known git error messages to sentinel errors.
+func classifyStderr(stderr string) error {
+	lower := strings.ToLower(stderr)
+	switch {
+	case strings.Contains(lower, "not a git repository"):
+		return ErrNotARepo
+	case strings.Contains(lower, "unknown revision"),
+		strings.Contains(lower, "bad revision"),
+		strings.Contains(lower, "ambiguous argument"),
+		strings.Contains(lower, "invalid object name"),
+		strings.Contains(lower, "not a valid object name"),
+		strings.Contains(lower, "needed a single revision"):
+		return ErrBadRevision
+	default:
+		return nil
+	}
+}
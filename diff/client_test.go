@@ -0,0 +1,540 @@
+package diff
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassifyStderr(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   error
+	}{
+		{"fatal: not a git repository (or any of the parent directories): .git", ErrNotARepo},
+		{"fatal: ambiguous argument 'mian': unknown revision or path not in the working tree.", ErrBadRevision},
+		{"fatal: bad revision 'nope'", ErrBadRevision},
+		{"fatal: Needed a single revision", ErrBadRevision},
+		{"fatal: something else entirely", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if got := classifyStderr(tt.stderr); got != tt.want {
+			t.Errorf("classifyStderr(%q) = %v, want %v", tt.stderr, got, tt.want)
+		}
+	}
+}
+
+func TestGitError_Is(t *testing.T) {
+	err := error(&GitError{Args: []string{"diff", "--numstat"}, Stderr: "fatal: bad revision 'x'", Kind: ErrBadRevision})
+
+	if !errors.Is(err, ErrBadRevision) {
+		t.Error("errors.Is(err, ErrBadRevision) = false, want true")
+	}
+	if errors.Is(err, ErrNotARepo) {
+		t.Error("errors.Is(err, ErrNotARepo) = true, want false")
+	}
+	if got, want := err.Error(), "git diff: fatal: bad revision 'x'"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestClient_FailPolicy(t *testing.T) {
+	gitErr := &GitError{Args: []string{"diff"}, Stderr: "boom"}
+
+	open := NewClient(Options{FailOpen: true})
+	warnings, err := open.fail(gitErr, nil)
+	if err != nil || len(warnings) != 1 {
+		t.Errorf("fail-open: got warnings=%v err=%v, want 1 warning and nil error", warnings, err)
+	}
+
+	strict := NewClient(Options{})
+	warnings, err = strict.fail(gitErr, nil)
+	if err == nil || len(warnings) != 0 {
+		t.Errorf("strict: got warnings=%v err=%v, want error and no warnings", warnings, err)
+	}
+}
+
+func TestClient_Options(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	// Working directory outside any repo: strict client reports ErrNotARepo
+	dir := t.TempDir()
+	strict := NewClient(Options{Dir: dir, Env: []string{"GIT_CEILING_DIRECTORIES=" + filepath.Dir(dir)}})
+	if _, _, err := strict.GetDiffStats(); !errors.Is(err, ErrNotARepo) {
+		t.Errorf("GetDiffStats outside repo: err = %v, want ErrNotARepo", err)
+	}
+
+	// Missing binary: ErrGitMissing
+	missing := NewClient(Options{GitPath: filepath.Join(dir, "no-such-git")})
+	if _, _, err := missing.GetDiffStats(); !errors.Is(err, ErrGitMissing) {
+		t.Errorf("GetDiffStats with missing git: err = %v, want ErrGitMissing", err)
+	}
+
+	// Fail-open client turns the same failure into a warning
+	open := NewClient(Options{GitPath: filepath.Join(dir, "no-such-git"), FailOpen: true})
+	stats, warnings, err := open.GetDiffStats()
+	if err != nil || stats == nil || len(warnings) != 1 {
+		t.Errorf("fail-open GetDiffStats: stats=%v warnings=%v err=%v", stats, warnings, err)
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	// A fake "git" that sleeps longer than the timeout
+	dir := t.TempDir()
+	script := filepath.Join(dir, "slow-git")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nexec sleep 5\n"), 0755); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	c := NewClient(Options{GitPath: script, Timeout: 50 * time.Millisecond})
+	if _, _, err := c.GetDiffStats(); !errors.Is(err, ErrTimeout) {
+		t.Errorf("GetDiffStats with slow git: err = %v, want ErrTimeout", err)
+	}
+}
+
+// newTestRepo creates an empty git repository in a temp dir and returns a
+// strict client bound to it plus a helper that runs git there.
+func newTestRepo(t *testing.T) (*Client, func(args ...string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	env := []string{
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), env...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	return NewClient(Options{Dir: dir, Env: env}), git
+}
+
+func TestClient_GetAllStats_NoHead(t *testing.T) {
+	client, git := newTestRepo(t)
+	if err := os.WriteFile(client.path("staged.txt"), []byte("a\nb\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(client.path("loose.txt"), []byte("c\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "staged.txt")
+
+	hasHead, err := client.HasHead()
+	if err != nil || hasHead {
+		t.Fatalf("HasHead() = %v, %v; want false, nil", hasHead, err)
+	}
+
+	stats, _, err := client.GetAllStats()
+	if err != nil {
+		t.Fatalf("GetAllStats: %v", err)
+	}
+	if !stats.NoHead || stats.TotalFiles != 2 || stats.TotalAdd != 3 {
+		t.Errorf("GetAllStats() = NoHead %v, %d files, +%d; want true, 2 files, +3",
+			stats.NoHead, stats.TotalFiles, stats.TotalAdd)
+	}
+
+	git("commit", "-q", "-m", "first")
+	if hasHead, _ := client.HasHead(); !hasHead {
+		t.Error("HasHead() = false after first commit")
+	}
+}
+
+func TestClient_GetAllStats_IncludeUntracked(t *testing.T) {
+	client, git := newTestRepo(t)
+	if err := os.WriteFile(client.path("tracked.txt"), []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "tracked.txt")
+	git("commit", "-q", "-m", "first")
+	if err := os.WriteFile(client.path("tracked.txt"), []byte("a\nb\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "tracked.txt")
+	if err := os.WriteFile(client.path("loose.txt"), []byte("c\nd\ne\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		policy    UntrackedPolicy
+		args      []string
+		wantFiles int
+	}{
+		{UntrackedAuto, []string{"HEAD"}, 2},
+		{UntrackedAuto, []string{"--cached"}, 1},
+		{UntrackedInclude, []string{"--cached"}, 2},
+		{UntrackedExclude, []string{"HEAD"}, 1},
+		{UntrackedExclude, nil, 0},
+	}
+	for _, tt := range tests {
+		client.IncludeUntracked = tt.policy
+		stats, _, err := client.GetAllStats(tt.args...)
+		if err != nil {
+			t.Fatalf("policy %d, args %v: %v", tt.policy, tt.args, err)
+		}
+		if stats.TotalFiles != tt.wantFiles {
+			t.Errorf("policy %d, args %v: %d files, want %d", tt.policy, tt.args, stats.TotalFiles, tt.wantFiles)
+		}
+	}
+}
+
+func TestClient_GetAllStats_RangeMarksAdded(t *testing.T) {
+	client, git := newTestRepo(t)
+	if err := os.WriteFile(client.path("old.txt"), []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "old.txt")
+	git("commit", "-q", "-m", "first")
+	if err := os.WriteFile(client.path("old.txt"), []byte("a\nb\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(client.path("new.txt"), []byte("c\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "old.txt", "new.txt")
+	git("commit", "-q", "-m", "second")
+
+	stats, _, err := client.GetAllStats("HEAD~1..HEAD")
+	if err != nil {
+		t.Fatalf("GetAllStats: %v", err)
+	}
+	for _, f := range stats.Files {
+		if want := f.Path == "new.txt"; f.IsNew() != want {
+			t.Errorf("%s: IsNew() = %v, want %v", f.Path, f.IsNew(), want)
+		}
+	}
+	if stats.TotalFiles != 2 {
+		t.Errorf("TotalFiles = %d, want 2", stats.TotalFiles)
+	}
+}
+
+func TestClient_GetAllStats_Relative(t *testing.T) {
+	client, git := newTestRepo(t)
+	for _, dir := range []string{"src", "docs"} {
+		if err := os.MkdirAll(client.path(dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(client.path(name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("src/a.go", "one\ntwo\n")
+	write("docs/b.md", "doc\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "first")
+	write("src/a.go", "one\n2\n")
+	write("docs/b.md", "doc\nmore\n")
+	write("src/new.txt", "x\ny\n")
+	write("top.txt", "z\n")
+
+	// Run from docs/, so paths must be resolved from the top level
+	sub := NewClient(Options{Dir: client.path("docs"), Env: client.Env, Relative: "src"})
+	stats, warnings, err := sub.GetAllStats()
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("GetAllStats() warnings = %v, err = %v", warnings, err)
+	}
+	var paths []string
+	for _, f := range stats.Files {
+		paths = append(paths, f.Path)
+	}
+	if want := []string{"a.go", "new.txt"}; !reflect.DeepEqual(paths, want) || stats.TotalAdd != 3 {
+		t.Fatalf("paths = %q (+%d), want %q (+3)", paths, stats.TotalAdd, want)
+	}
+
+	// Enrichers rerun git with the same --relative and find the files
+	warnings, err = sub.ComputeReplacedAges(stats, time.Now().Add(time.Hour))
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("ComputeReplacedAges() warnings = %v, err = %v", warnings, err)
+	}
+	if stats.Files[0].ReplacedAge == 0 {
+		t.Error("a.go ReplacedAge = 0, want the blamed line's age")
+	}
+	if _, err := sub.AddSizeDeltas(stats); err != nil {
+		t.Fatal(err)
+	}
+	if f := stats.Files[0]; !f.HasSizeDelta || f.SizeDelta != -2 {
+		t.Errorf("a.go size delta = %d (%v), want -2", f.SizeDelta, f.HasSizeDelta)
+	}
+}
+
+func TestClient_Repo(t *testing.T) {
+	client, git := newTestRepo(t)
+	git("remote", "add", "origin", "git@github.com:org/repo.git")
+	git("remote", "add", "fork", "https://example.com/me/repo")
+
+	info, err := client.Repo()
+	if err != nil {
+		t.Fatalf("Repo: %v", err)
+	}
+	wantRoot, _ := filepath.EvalSymlinks(client.path("."))
+	if gotRoot, _ := filepath.EvalSymlinks(info.Root); gotRoot != wantRoot {
+		t.Errorf("Root = %q, want %q", info.Root, wantRoot)
+	}
+	want := []string{"https://example.com/me/repo", "git@github.com:org/repo.git"}
+	if !reflect.DeepEqual(info.Remotes, want) {
+		t.Errorf("Remotes = %q, want %q", info.Remotes, want)
+	}
+}
+
+func TestClient_Provenance(t *testing.T) {
+	client, git := newTestRepo(t)
+	client.RecordCommands = true
+	if err := os.WriteFile(client.path("a.txt"), []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "init")
+
+	if _, _, err := client.GetDiffStats("HEAD"); err != nil {
+		t.Fatal(err)
+	}
+	p := client.Provenance("HEAD~0..")
+
+	if len(p.Commands) == 0 || !slices.Contains(p.Commands, "git diff --numstat HEAD") {
+		t.Errorf("Commands = %q, want the numstat call", p.Commands)
+	}
+	if slices.Contains(p.Commands, "git version") {
+		t.Errorf("Commands = %q, want Provenance's own lookups left out", p.Commands)
+	}
+	if p.GitVersion == "" || p.RepoRoot == "" {
+		t.Errorf("GitVersion = %q, RepoRoot = %q, want both set", p.GitVersion, p.RepoRoot)
+	}
+	if sha := p.Refs["HEAD"]; len(sha) != 40 || p.Refs["HEAD~0"] != sha {
+		t.Errorf("Refs = %v, want HEAD~0 and HEAD at the same SHA", p.Refs)
+	}
+
+	// Without RecordCommands nothing accumulates
+	client.RecordCommands = false
+	before := len(client.Commands())
+	if _, _, err := client.GetDiffStats("HEAD"); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(client.Commands()); got != before {
+		t.Errorf("Commands grew from %d to %d with RecordCommands off", before, got)
+	}
+}
+
+func TestClient_CommitCount(t *testing.T) {
+	client, git := newTestRepo(t)
+	for _, name := range []string{"a", "b", "c"} {
+		if err := os.WriteFile(client.path(name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", name)
+		git("commit", "-q", "-m", name)
+	}
+	git("branch", "-M", "trunk")
+	git("checkout", "-q", "-b", "side", "HEAD~1")
+	git("commit", "-q", "--allow-empty", "-m", "side")
+
+	tests := []struct {
+		args   []string
+		want   int
+		wantOK bool
+	}{
+		{[]string{"HEAD~1..HEAD"}, 1, true},
+		{[]string{"trunk", "side"}, 1, true},
+		{[]string{"trunk...side"}, 1, true},
+		{[]string{"trunk~2"}, 2, true}, // trunk~2..HEAD, HEAD being side
+		{[]string{"--cached"}, 0, false},
+		{nil, 0, false},
+	}
+	for _, tt := range tests {
+		n, ok, err := client.CommitCount(tt.args...)
+		if err != nil || n != tt.want || ok != tt.wantOK {
+			t.Errorf("CommitCount(%q) = %d, %v, %v; want %d, %v", tt.args, n, ok, err, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRepoAbove(t *testing.T) {
+	root := t.TempDir()
+	deep := filepath.Join(root, "repo", "a", "b")
+	if err := os.MkdirAll(deep, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := RepoAbove(deep); strings.HasPrefix(got, root) {
+		t.Errorf("RepoAbove without .git = %q, want none under %q", got, root)
+	}
+	if err := os.Mkdir(filepath.Join(root, "repo", ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := RepoAbove(deep), filepath.Join(root, "repo"); got != want {
+		t.Errorf("RepoAbove = %q, want %q", got, want)
+	}
+}
+
+func TestClient_ListBranches(t *testing.T) {
+	client, git := newTestRepo(t)
+	if err := os.WriteFile(client.path("a.txt"), []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "a")
+	git("branch", "-M", "main")
+	git("checkout", "-q", "-b", "feature")
+	if err := os.WriteFile(client.path("b.txt"), []byte("b\nb\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "b.txt")
+	git("commit", "-q", "-m", "b")
+	git("checkout", "-q", "main")
+	git("commit", "-q", "--allow-empty", "-m", "main moves on")
+
+	entries, warnings, err := client.ListBranches()
+	if err != nil || len(warnings) > 0 {
+		t.Fatalf("ListBranches() error %v, warnings %v", err, warnings)
+	}
+	byName := make(map[string]BranchEntry)
+	for _, e := range entries {
+		byName[e.Name] = e
+	}
+
+	feature := byName["feature"]
+	if feature.Base != "main" || feature.Ahead != 1 || feature.Behind != 1 {
+		t.Errorf("feature = base %q, ahead %d, behind %d; want main, 1, 1", feature.Base, feature.Ahead, feature.Behind)
+	}
+	if feature.Stats == nil || feature.Stats.TotalAdd != 2 || feature.Stats.TotalFiles != 1 {
+		t.Errorf("feature stats = %+v, want +2 in 1 file", feature.Stats)
+	}
+	if main := byName["main"]; main.Base != "" || main.Stats != nil {
+		t.Errorf("main = base %q, stats %+v; want no base", main.Base, main.Stats)
+	}
+}
+
+func TestClient_CachedWorktreeStats(t *testing.T) {
+	client, git := newTestRepo(t)
+	client.RecordCommands = true
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(client.path(name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.txt", "1\n")
+	git("add", "a.txt")
+	git("commit", "-qm", "init")
+
+	write("a.txt", "1\n2\n")
+	write("new file.txt", "x\n")
+	stats, _, err := client.CachedWorktreeStats()
+	if err != nil || stats.TotalFiles != 2 || stats.TotalAdd != 2 {
+		t.Fatalf("first call = %+v, %v; want 2 files, +2", stats, err)
+	}
+
+	// Unchanged tree: served from the cache without running git diff
+	before := len(client.Commands())
+	stats, _, err = client.CachedWorktreeStats()
+	if err != nil || stats.TotalAdd != 2 {
+		t.Fatalf("cached call = %+v, %v; want +2", stats, err)
+	}
+	for _, cmd := range client.Commands()[before:] {
+		if strings.HasPrefix(cmd, "git diff") {
+			t.Errorf("cache hit ran %q", cmd)
+		}
+	}
+
+	// Editing an already-dirty file invalidates the cache
+	write("a.txt", "1\n2\n3\n")
+	stats, _, err = client.CachedWorktreeStats()
+	if err != nil || stats.TotalAdd != 3 {
+		t.Fatalf("after edit = %+v, %v; want +3", stats, err)
+	}
+}
+
+func TestStatusPaths(t *testing.T) {
+	status := "# branch.oid abc\x00" +
+		"1 .M N... 100644 100644 100644 aaa aaa src/a b.go\x00" +
+		"2 R. N... 100644 100644 100644 aaa aaa R100 new.go\x00old.go\x00" +
+		"u UU N... 100644 100644 100644 100644 a b c conflict.go\x00" +
+		"? untracked.txt\x00" +
+		"! ignored.log\x00"
+	want := []string{"src/a b.go", "new.go", "conflict.go", "untracked.txt"}
+	if got := statusPaths([]byte(status)); !reflect.DeepEqual(got, want) {
+		t.Errorf("statusPaths() = %q, want %q", got, want)
+	}
+}
+
+func TestClient_ResolveTree(t *testing.T) {
+	client, git := newTestRepo(t)
+	if err := os.WriteFile(client.path("a.txt"), []byte("1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "a.txt")
+	git("commit", "-qm", "init")
+	git("tag", "-a", "v1", "-m", "release")
+
+	tree, err := client.ResolveTree("HEAD")
+	if err != nil || len(tree) != 40 {
+		t.Fatalf("ResolveTree(HEAD) = %q, %v", tree, err)
+	}
+	branch, err := client.output("symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rev := range []string{"v1", strings.TrimSpace(string(branch)), tree} {
+		if got, err := client.ResolveTree(rev); err != nil || got != tree {
+			t.Errorf("ResolveTree(%q) = %q, %v; want %q", rev, got, err, tree)
+		}
+	}
+	if _, err := client.ResolveTree("no-such-ref"); !errors.Is(err, ErrBadRevision) {
+		t.Errorf("ResolveTree(no-such-ref) error = %v, want ErrBadRevision", err)
+	}
+}
+
+func TestClient_CaptureCurrentTree_Untracked(t *testing.T) {
+	client, git := newTestRepo(t)
+	if err := os.WriteFile(client.path("a.txt"), []byte("1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "a.txt")
+	git("commit", "-qm", "init")
+	git("tag", "v1")
+	if err := os.WriteFile(client.path("new.txt"), []byte("x\ny\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	base, err := client.ResolveTree("v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		policy UntrackedPolicy
+		files  int
+	}{
+		{UntrackedAuto, 1},
+		{UntrackedExclude, 0},
+	} {
+		client.IncludeUntracked = tt.policy
+		current, err := client.CaptureCurrentTree()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats, _, err := client.GetTreeDiffStats(base, current)
+		if err != nil || stats.TotalFiles != tt.files {
+			t.Errorf("policy %d: stats = %+v, %v; want %d files", tt.policy, stats, err, tt.files)
+		}
+	}
+}
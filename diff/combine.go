@@ -0,0 +1,51 @@
+package diff
+
+// Intersect returns the files changed in both a and b, in a's order.
+// Each file's additions and deletions are summed across both sides so
+// overlap hotspots rank by combined churn.
+func Intersect(a, b *DiffStats) *DiffStats {
+	other := make(map[string]FileStat, len(b.Files))
+	for _, f := range b.Files {
+		other[f.Path] = f
+	}
+
+	result := &DiffStats{}
+	for _, f := range a.Files {
+		g, ok := other[f.Path]
+		if !ok {
+			continue
+		}
+		f.Additions += g.Additions
+		f.Deletions += g.Deletions
+		f.IsBinary = f.IsBinary || g.IsBinary
+		result.Files = append(result.Files, f)
+		result.TotalAdd += f.Additions
+		result.TotalDel += f.Deletions
+		result.TotalFiles++
+	}
+	return result
+}
+
+// Union returns the files changed in either a or b: a's files in order, then
+// b's files that a lacks. Files changed on both sides have their additions
+// and deletions summed.
+func Union(a, b *DiffStats) *DiffStats {
+	index := make(map[string]int, len(a.Files)+len(b.Files))
+	result := &DiffStats{}
+	for _, side := range []*DiffStats{a, b} {
+		for _, f := range side.Files {
+			if i, ok := index[f.Path]; ok {
+				result.Files[i].Additions += f.Additions
+				result.Files[i].Deletions += f.Deletions
+				result.Files[i].IsBinary = result.Files[i].IsBinary || f.IsBinary
+			} else {
+				index[f.Path] = len(result.Files)
+				result.Files = append(result.Files, f)
+				result.TotalFiles++
+			}
+			result.TotalAdd += f.Additions
+			result.TotalDel += f.Deletions
+		}
+	}
+	return result
+}
@@ -4,11 +4,15 @@ package diff
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // FileStat represents changes to a single file.
@@ -18,29 +22,84 @@ type FileStat struct {
 	Deletions   int
 	IsBinary    bool
 	IsUntracked bool
+	IsUnmerged  bool // Unresolved merge/rebase conflict
+	IsLarge     bool // Untracked file over the client's MaxUntrackedSize; lines not counted
+
+	FunctionsChanged int           // Set by CountFunctionsChanged (0 until analyzed)
+	ReplacedAge      time.Duration // Median age of replaced lines, set by ComputeReplacedAges (0 = none)
+
+	// Set by AddChangeDetails
+	Status       string // git status letter (A, C, D, M, R, T, U; "" until analyzed)
+	NewPath      string // Post-image path when Path is a numstat rename ("old => new")
+	SizeDelta    int64  // Binary files (all with AddSizeDeltas): post-image minus pre-image size in bytes
+	HasSizeDelta bool   // SizeDelta was computed
+
+	Annotations map[string]string // Set by enrichers (see Enricher)
+}
+
+// IsNew reports whether the file did not exist before the change: untracked,
+// or added according to GetAllStats (ranges and --cached) or AddChangeDetails.
+func (f FileStat) IsNew() bool {
+	return f.IsUntracked || f.Status == "A"
+}
+
+// IsDeleted reports whether the change removed the file (status D, set by
+// AddChangeDetails or a baseline comparison).
+func (f FileStat) IsDeleted() bool {
+	return f.Status == "D"
 }
 
 // FileStatJSON is the JSON-serializable representation of a file's stats.
 type FileStatJSON struct {
-	Path   string `json:"path"`
-	Adds   int    `json:"adds"`
-	Dels   int    `json:"dels"`
-	Binary bool   `json:"binary,omitempty"`
-	New    bool   `json:"new,omitempty"`
+	Path     string `json:"path"`
+	Adds     int    `json:"adds"`
+	Dels     int    `json:"dels"`
+	Binary   bool   `json:"binary,omitempty"`
+	New      bool   `json:"new,omitempty"`
+	Deleted  bool   `json:"deleted,omitempty"`
+	Conflict bool   `json:"conflict,omitempty"` // Unmerged path
+	Large    bool   `json:"large,omitempty"`    // Untracked file too large to count
+	Funcs    int    `json:"funcs,omitempty"`    // Functions changed (when analyzed)
+
+	Annotations map[string]string `json:"annotations,omitempty"` // From enrichers (--annotate)
+}
+
+// DeletedJSON summarizes deleted files; their lines are included in the
+// totals' dels.
+type DeletedJSON struct {
+	FileCount int      `json:"fileCount"`
+	Lines     int      `json:"lines"`
+	Paths     []string `json:"paths"`
 }
 
 // TotalsJSON is the JSON-serializable representation of total stats.
 type TotalsJSON struct {
-	Adds      int `json:"adds"`
-	Dels      int `json:"dels"`
-	FileCount int `json:"fileCount"`
+	Adds      int    `json:"adds"`
+	Dels      int    `json:"dels"`
+	FileCount int    `json:"fileCount"`
+	Size      string `json:"size,omitempty"` // Size class label (XS-XL)
+}
+
+// DirStatJSON is the JSON-serializable representation of a directory aggregate.
+type DirStatJSON struct {
+	Path      string  `json:"path"` // Directory prefix ("." for root files)
+	Adds      int     `json:"adds"`
+	Dels      int     `json:"dels"`
+	NewAdds   int     `json:"newAdds,omitempty"` // Additions in new files (subset of Adds)
+	FileCount int     `json:"fileCount"`
+	Percent   float64 `json:"percent"` // Share of total changes (0-100)
 }
 
 // StatsJSON is the JSON-serializable representation of diff stats.
 // This is the output format for --stats-json flag.
 type StatsJSON struct {
-	Files  []FileStatJSON `json:"files"`
-	Totals TotalsJSON     `json:"totals"`
+	Files   []FileStatJSON `json:"files"`
+	Dirs    []DirStatJSON  `json:"dirs,omitempty"`    // Only with --depth
+	Deleted *DeletedJSON   `json:"deleted,omitempty"` // Only when deletions are known (baseline, AddChangeDetails)
+	Totals  TotalsJSON     `json:"totals"`
+	NoHead  bool           `json:"noHead,omitempty"` // No commits yet; all files are new
+
+	Provenance *ProvenanceJSON `json:"provenance,omitempty"` // Set by the caller (see Client.Provenance)
 }
 
 // ToJSON converts DiffStats to JSON-serializable format.
@@ -48,20 +107,37 @@ func (s *DiffStats) ToJSON() StatsJSON {
 	files := make([]FileStatJSON, len(s.Files))
 	for i, f := range s.Files {
 		files[i] = FileStatJSON{
-			Path:   f.Path,
-			Adds:   f.Additions,
-			Dels:   f.Deletions,
-			Binary: f.IsBinary,
-			New:    f.IsUntracked,
+			Path:     f.Path,
+			Adds:     f.Additions,
+			Dels:     f.Deletions,
+			Binary:   f.IsBinary,
+			New:      f.IsNew(),
+			Deleted:  f.IsDeleted(),
+			Conflict: f.IsUnmerged,
+			Large:    f.IsLarge,
+			Funcs:    f.FunctionsChanged,
+
+			Annotations: f.Annotations,
+		}
+	}
+	var deleted *DeletedJSON
+	if removed := s.DeletedFiles(); len(removed) > 0 {
+		deleted = &DeletedJSON{FileCount: len(removed)}
+		for _, f := range removed {
+			deleted.Lines += f.Deletions
+			deleted.Paths = append(deleted.Paths, f.Path)
 		}
 	}
 	return StatsJSON{
-		Files: files,
+		Files:   files,
+		Deleted: deleted,
 		Totals: TotalsJSON{
 			Adds:      s.TotalAdd,
 			Dels:      s.TotalDel,
 			FileCount: s.TotalFiles,
+			Size:      string(s.SizeClass(nil)),
 		},
+		NoHead: s.NoHead,
 	}
 }
 
@@ -71,28 +147,40 @@ type DiffStats struct {
 	TotalAdd   int
 	TotalDel   int
 	TotalFiles int
+	NoHead     bool // Repository has no commits yet; every file is counted as new
+}
+
+// DeletedFiles returns the files the change removed, in diff order.
+func (s *DiffStats) DeletedFiles() []FileStat {
+	var deleted []FileStat
+	for _, f := range s.Files {
+		if f.IsDeleted() {
+			deleted = append(deleted, f)
+		}
+	}
+	return deleted
 }
 
 // GetDiffStats runs git diff --numstat and parses the output.
 // args are passed directly to git diff (e.g., "HEAD", "--cached", "main..feature").
 // Returns warnings for non-fatal issues (git errors that might indicate problems).
 func GetDiffStats(args ...string) (*DiffStats, []string, error) {
+	return defaultClient.GetDiffStats(args...)
+}
+
+// GetDiffStats runs git diff --numstat and parses the output.
+// Git failures follow the client's FailOpen policy.
+func (c *Client) GetDiffStats(args ...string) (*DiffStats, []string, error) {
 	var warnings []string
-	cmdArgs := append([]string{"diff", "--numstat"}, args...)
-	cmd := exec.Command("git", cmdArgs...)
+	cmdArgs := append(append([]string{"diff", "--numstat"}, c.relativeArgs()...), args...)
 
-	output, err := cmd.Output()
+	output, err := c.output(cmdArgs...)
 	if err != nil {
-		// Check if it's an ExitError with stderr info
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			stderr := strings.TrimSpace(string(exitErr.Stderr))
-			if stderr != "" {
-				warnings = append(warnings, fmt.Sprintf("git diff: %s", stderr))
-			} else {
-				warnings = append(warnings, fmt.Sprintf("git diff exited with code %d", exitErr.ExitCode()))
-			}
-		}
 		// Fail-open: return empty stats with warning
+		warnings, err = c.fail(err, warnings)
+		if err != nil {
+			return nil, warnings, err
+		}
 		return &DiffStats{}, warnings, nil
 	}
 
@@ -103,6 +191,7 @@ func GetDiffStats(args ...string) (*DiffStats, []string, error) {
 
 // ParseNumstat parses git diff --numstat output.
 // Format: "additions\tdeletions\tpath" or "-\t-\tpath" for binary files.
+// Paths pass through NormalizePath, so they are safe to print and compare.
 // Returns warnings for malformed lines (fail-open: skips bad lines, continues parsing).
 func ParseNumstat(output string) (*DiffStats, []string, error) {
 	stats := &DiffStats{}
@@ -121,7 +210,7 @@ func ParseNumstat(output string) (*DiffStats, []string, error) {
 			continue
 		}
 
-		file := FileStat{Path: parts[2]}
+		file := FileStat{Path: NormalizePath(parts[2])}
 
 		if parts[0] == "-" {
 			// Binary file
@@ -130,11 +219,11 @@ func ParseNumstat(output string) (*DiffStats, []string, error) {
 			var err error
 			file.Additions, err = strconv.Atoi(parts[0])
 			if err != nil {
-				warnings = append(warnings, fmt.Sprintf("invalid additions count %q for %s: %v", parts[0], parts[2], err))
+				warnings = append(warnings, fmt.Sprintf("invalid additions count %q for %s: %v", parts[0], file.Path, err))
 			}
 			file.Deletions, err = strconv.Atoi(parts[1])
 			if err != nil {
-				warnings = append(warnings, fmt.Sprintf("invalid deletions count %q for %s: %v", parts[1], parts[2], err))
+				warnings = append(warnings, fmt.Sprintf("invalid deletions count %q for %s: %v", parts[1], file.Path, err))
 			}
 		}
 
@@ -147,26 +236,146 @@ func ParseNumstat(output string) (*DiffStats, []string, error) {
 	return stats, warnings, scanner.Err()
 }
 
+// GetUnmergedPaths returns paths with unresolved conflicts during a merge or rebase.
+func GetUnmergedPaths() ([]string, []string, error) {
+	return defaultClient.GetUnmergedPaths()
+}
+
+// GetUnmergedPaths returns paths with unresolved conflicts during a merge or rebase.
+// Git failures follow the client's FailOpen policy.
+func (c *Client) GetUnmergedPaths() ([]string, []string, error) {
+	var warnings []string
+	output, err := c.output(append([]string{"diff", "--name-only", "--diff-filter=U"}, c.relativeArgs()...)...)
+	if err != nil {
+		warnings, err = c.fail(err, warnings)
+		return nil, warnings, err
+	}
+
+	var paths []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line != "" {
+			paths = append(paths, NormalizePath(line))
+		}
+	}
+	return paths, warnings, nil
+}
+
+// GetAddedPaths returns the paths git diff args reports as added (status A).
+func GetAddedPaths(args ...string) ([]string, []string, error) {
+	return defaultClient.GetAddedPaths(args...)
+}
+
+// GetAddedPaths returns the paths git diff args reports as added (status A).
+// Git failures follow the client's FailOpen policy.
+func (c *Client) GetAddedPaths(args ...string) ([]string, []string, error) {
+	var warnings []string
+	cmdArgs := append(append([]string{"diff", "--name-only", "--diff-filter=A"}, c.relativeArgs()...), args...)
+	output, err := c.output(cmdArgs...)
+	if err != nil {
+		warnings, err = c.fail(err, warnings)
+		return nil, warnings, err
+	}
+
+	var paths []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line != "" {
+			paths = append(paths, NormalizePath(line))
+		}
+	}
+	return paths, warnings, nil
+}
+
+// MarkAdded sets Status "A" on files at the given paths that have no status
+// yet, so IsNew reports them as new.
+func (s *DiffStats) MarkAdded(paths []string) {
+	added := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		added[p] = true
+	}
+	for i := range s.Files {
+		if f := &s.Files[i]; added[f.Path] && f.Status == "" {
+			f.Status = "A"
+		}
+	}
+}
+
+// MarkUnmerged flags the given paths as conflicted. git diff --numstat emits
+// an extra "0 0" entry for each unmerged path; duplicates are merged so each
+// conflicted file appears once.
+func (s *DiffStats) MarkUnmerged(paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	unmerged := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		unmerged[p] = true
+	}
+
+	files := s.Files[:0]
+	index := make(map[string]int)
+	for _, f := range s.Files {
+		if !unmerged[f.Path] {
+			files = append(files, f)
+			continue
+		}
+		if i, ok := index[f.Path]; ok {
+			files[i].Additions += f.Additions
+			files[i].Deletions += f.Deletions
+			continue
+		}
+		f.IsUnmerged = true
+		index[f.Path] = len(files)
+		files = append(files, f)
+	}
+
+	// Unmerged paths absent from numstat still need to surface
+	for _, p := range paths {
+		if _, ok := index[p]; !ok {
+			index[p] = len(files)
+			files = append(files, FileStat{Path: p, IsUnmerged: true})
+		}
+	}
+
+	s.Files = files
+	s.TotalFiles = len(files)
+}
+
 // GetUntrackedFiles returns stats for untracked files (additions only).
 // Returns warnings for git errors and file read failures.
 func GetUntrackedFiles() ([]FileStat, []string, error) {
+	return defaultClient.GetUntrackedFiles()
+}
+
+// GetUntrackedFiles returns stats for untracked files (additions only).
+// Git failures follow the client's FailOpen policy; unreadable files are
+// always warnings.
+func (c *Client) GetUntrackedFiles() ([]FileStat, []string, error) {
+	return c.listNewFiles("--others", "--exclude-standard")
+}
+
+// listNewFiles runs git ls-files with args and returns each listed path as
+// an untracked file whose lines all count as additions. Files are listed
+// from the top level (or Options.Relative) with paths as git diff prints
+// them.
+func (c *Client) listNewFiles(args ...string) ([]FileStat, []string, error) {
 	var warnings []string
-	cmd := exec.Command("git", "ls-files", "--others", "--exclude-standard")
-	output, err := cmd.Output()
+	top, prefix := ":/", ""
+	if len(c.relativeArgs()) > 0 {
+		top, prefix = ":/"+c.Relative, c.Relative+"/"
+	}
+	cmdArgs := append(append([]string{"ls-files", "--full-name"}, args...), top)
+	output, err := c.output(cmdArgs...)
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			stderr := strings.TrimSpace(string(exitErr.Stderr))
-			if stderr != "" {
-				warnings = append(warnings, fmt.Sprintf("git ls-files: %s", stderr))
-			} else {
-				warnings = append(warnings, fmt.Sprintf("git ls-files exited with code %d", exitErr.ExitCode()))
-			}
-		}
 		// Fail-open: return empty with warning
-		return nil, warnings, nil
+		warnings, err = c.fail(err, warnings)
+		return nil, warnings, err
 	}
 
 	var files []FileStat
+	cdup := ""
+	if out, err := c.output("rev-parse", "--show-cdup"); err == nil {
+		cdup = strings.TrimSpace(string(out))
+	}
 	scanner := bufio.NewScanner(bytes.NewReader(output))
 
 	for scanner.Scan() {
@@ -175,13 +384,17 @@ func GetUntrackedFiles() ([]FileStat, []string, error) {
 			continue
 		}
 
-		lines, readErr := countLines(path)
+		lines, readErr := countLines(c.path(filepath.Join(cdup, unquotePath(path))), c.maxUntrackedSize())
 		file := FileStat{
-			Path:        path,
+			Path:        strings.TrimPrefix(NormalizePath(path), prefix),
 			IsUntracked: true,
 		}
-		if readErr != nil {
-			warnings = append(warnings, fmt.Sprintf("could not read %s: %v", path, readErr))
+		switch {
+		case errors.Is(readErr, errTooLarge):
+			file.IsLarge = true
+			warnings = append(warnings, fmt.Sprintf("not counting lines in %s: larger than %d bytes", file.Path, c.maxUntrackedSize()))
+		case readErr != nil:
+			warnings = append(warnings, fmt.Sprintf("could not read %s: %v", file.Path, readErr))
 			// Fail-open: include file but with zero additions
 		}
 		if lines == -1 {
@@ -195,47 +408,141 @@ func GetUntrackedFiles() ([]FileStat, []string, error) {
 	return files, warnings, scanner.Err()
 }
 
-// countLines counts lines in a file (for untracked files).
-// Returns -1 for binary files, or an error if the file cannot be read.
-func countLines(path string) (int, error) {
-	data, err := os.ReadFile(path)
+// errTooLarge means an untracked file is over the size limit and was not read.
+var errTooLarge = errors.New("file too large to count")
+
+// binaryCheckLen is how much of a file is searched for NUL bytes.
+const binaryCheckLen = 8192
+
+// countLines counts lines in a file (for untracked files), reading it in
+// chunks. Returns -1 for binary files, errTooLarge for files over maxSize
+// bytes (maxSize < 0 = no limit), or an error if the file cannot be read.
+// Symlinks are not followed: like git, they count as one line (the target
+// path). Other non-regular files (FIFOs, sockets, devices) count as empty.
+func countLines(path string, maxSize int64) (int, error) {
+	info, err := os.Lstat(path)
 	if err != nil {
 		return 0, err
 	}
-	if len(data) == 0 {
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		return 1, nil
+	case !info.Mode().IsRegular():
 		return 0, nil
+	case maxSize >= 0 && info.Size() > maxSize:
+		return 0, errTooLarge
 	}
-	// Check for binary: look for null bytes in first 8KB
-	checkLen := 8192
-	if len(data) < checkLen {
-		checkLen = len(data)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
 	}
-	if bytes.Contains(data[:checkLen], []byte{0}) {
-		return -1, nil // Binary file
+	defer f.Close()
+
+	// Read at most one byte past the limit, in case the file grew since Lstat
+	var r io.Reader = f
+	if maxSize >= 0 {
+		r = io.LimitReader(f, maxSize+1)
 	}
-	// Count newlines, add 1 if file doesn't end with newline
-	count := bytes.Count(data, []byte{'\n'})
-	if data[len(data)-1] != '\n' {
+
+	buf := make([]byte, 32*1024)
+	var count int
+	var read int64
+	var last byte
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			chunk := buf[:n]
+			// Check for binary: look for null bytes in the first 8KB
+			if read < binaryCheckLen {
+				head := chunk[:min(int64(n), binaryCheckLen-read)]
+				if bytes.IndexByte(head, 0) >= 0 {
+					return -1, nil // Binary file
+				}
+			}
+			count += bytes.Count(chunk, []byte{'\n'})
+			read += int64(n)
+			last = chunk[n-1]
+			if maxSize >= 0 && read > maxSize {
+				return 0, errTooLarge
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// Add 1 if the file doesn't end with a newline
+	if read > 0 && last != '\n' {
 		count++
 	}
 	return count, nil
 }
 
+// GetAllStats returns diff stats including untracked files (see
+// Options.IncludeUntracked). Aggregates warnings from all underlying operations.
+func GetAllStats(args ...string) (*DiffStats, []string, error) {
+	return defaultClient.GetAllStats(args...)
+}
+
 // GetAllStats returns diff stats including untracked files.
 // Aggregates warnings from all underlying operations.
-func GetAllStats(args ...string) (*DiffStats, []string, error) {
-	stats, warnings, err := GetDiffStats(args...)
+// Working tree diffs (no args or just "HEAD") also mark unmerged paths.
+func (c *Client) GetAllStats(args ...string) (*DiffStats, []string, error) {
+	worktree := isWorktreeDiff(args)
+	includeUntracked := c.includeUntracked(args)
+
+	// Before the first commit there is nothing to diff against
+	if worktree {
+		if hasHead, err := c.HasHead(); err == nil && !hasHead {
+			return c.getUnbornStats(includeUntracked)
+		}
+	}
+
+	stats, warnings, err := c.GetDiffStats(args...)
 	if err != nil {
 		return nil, warnings, err
 	}
 
-	// Only include untracked for working tree diffs (no args or just "HEAD")
-	includeUntracked := len(args) == 0 || (len(args) == 1 && args[0] == "HEAD")
+	// Files added by a range or staged change show as new, like untracked ones
+	if len(args) > 0 {
+		added, addedWarnings, err := c.GetAddedPaths(args...)
+		warnings = append(warnings, addedWarnings...)
+		if err != nil {
+			return nil, warnings, err
+		}
+		stats.MarkAdded(added)
+	}
+
+	if worktree {
+		unmerged, unmergedWarnings, err := c.GetUnmergedPaths()
+		warnings = append(warnings, unmergedWarnings...)
+		if err != nil {
+			return nil, warnings, err
+		}
+		stats.MarkUnmerged(unmerged)
+	}
 
 	if includeUntracked {
-		untracked, untrackedWarnings, _ := GetUntrackedFiles()
+		untracked, untrackedWarnings, err := c.GetUntrackedFiles()
 		warnings = append(warnings, untrackedWarnings...)
+		if err != nil {
+			return nil, warnings, err
+		}
+		// An untracked name that normalizes to a tracked path is the same
+		// file under another Unicode form (macOS with core.precomposeUnicode
+		// off); the tracked entry already counts it
+		tracked := make(map[string]bool, len(stats.Files))
+		for _, f := range stats.Files {
+			tracked[f.Path] = true
+		}
 		for _, f := range untracked {
+			if tracked[f.Path] {
+				continue
+			}
 			stats.Files = append(stats.Files, f)
 			stats.TotalAdd += f.Additions
 			stats.TotalFiles++
@@ -245,25 +552,48 @@ func GetAllStats(args ...string) (*DiffStats, []string, error) {
 	return stats, warnings, nil
 }
 
+// getUnbornStats lists every staged (and, with untracked, untracked) file
+// as new, for repositories whose HEAD does not point at a commit yet.
+func (c *Client) getUnbornStats(untracked bool) (*DiffStats, []string, error) {
+	lsArgs := []string{"--cached"}
+	if untracked {
+		lsArgs = append(lsArgs, "--others", "--exclude-standard")
+	}
+	files, warnings, err := c.listNewFiles(lsArgs...)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	stats := &DiffStats{NoHead: true}
+	for _, f := range files {
+		stats.Files = append(stats.Files, f)
+		stats.TotalAdd += f.Additions
+		stats.TotalFiles++
+	}
+	return stats, warnings, nil
+}
+
 // GetTreeDiffStats compares two git tree SHAs using git diff-tree.
 // This is used for comparing against a baseline snapshot.
 // Returns warnings for git command failures.
 func GetTreeDiffStats(baseTree, currentTree string) (*DiffStats, []string, error) {
+	return defaultClient.GetTreeDiffStats(baseTree, currentTree)
+}
+
+// GetTreeDiffStats compares two git tree SHAs using git diff-tree.
+// Git failures follow the client's FailOpen policy; the status enrichment
+// pass is always best-effort.
+func (c *Client) GetTreeDiffStats(baseTree, currentTree string) (*DiffStats, []string, error) {
 	var warnings []string
 
 	// git diff-tree --numstat baseline current
-	cmd := exec.Command("git", "diff-tree", "--numstat", "-r", baseTree, currentTree)
-	output, err := cmd.Output()
+	output, err := c.output(append(append([]string{"diff-tree", "--numstat", "-r"}, c.relativeArgs()...), baseTree, currentTree)...)
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			stderr := strings.TrimSpace(string(exitErr.Stderr))
-			if stderr != "" {
-				warnings = append(warnings, fmt.Sprintf("git diff-tree: %s", stderr))
-			} else {
-				warnings = append(warnings, fmt.Sprintf("git diff-tree exited with code %d", exitErr.ExitCode()))
-			}
-		}
 		// Fail-open: return empty stats with warning
+		warnings, err = c.fail(err, warnings)
+		if err != nil {
+			return nil, warnings, err
+		}
 		return &DiffStats{}, warnings, nil
 	}
 
@@ -273,15 +603,12 @@ func GetTreeDiffStats(baseTree, currentTree string) (*DiffStats, []string, error
 		return nil, warnings, err
 	}
 
-	// Get file status (A=Added, M=Modified) for weighted scoring
-	statusCmd := exec.Command("git", "diff-tree", "-r", "--name-status", "--diff-filter=AM", baseTree, currentTree)
-	statusOutput, statusErr := statusCmd.Output()
+	// Get file status (A=Added, D=Deleted, M=Modified)
+	statusOutput, statusErr := c.output(append(append([]string{"diff-tree", "-r", "--name-status", "--diff-filter=ADM"}, c.relativeArgs()...), baseTree, currentTree)...)
 	if statusErr != nil {
-		if exitErr, ok := statusErr.(*exec.ExitError); ok {
-			stderr := strings.TrimSpace(string(exitErr.Stderr))
-			if stderr != "" {
-				warnings = append(warnings, fmt.Sprintf("git diff-tree --name-status: %s", stderr))
-			}
+		var gitErr *GitError
+		if errors.As(statusErr, &gitErr) && gitErr.Stderr != "" {
+			warnings = append(warnings, fmt.Sprintf("git diff-tree --name-status: %s", gitErr.Stderr))
 		}
 		// Fail-open: skip status enrichment, continue with basic stats
 	}
@@ -293,14 +620,15 @@ func GetTreeDiffStats(baseTree, currentTree string) (*DiffStats, []string, error
 		line := scanner.Text()
 		if len(line) >= 2 && line[1] == '\t' {
 			status := line[0]
-			path := line[2:]
+			path := NormalizePath(line[2:])
 			statusLines[path] = status
 		}
 	}
 
-	// Update file stats with new/modified status
+	// Update file stats with new/deleted/modified status
 	for i := range stats.Files {
 		if status, ok := statusLines[stats.Files[i].Path]; ok {
+			stats.Files[i].Status = string(status)
 			stats.Files[i].IsUntracked = (status == 'A') // Treat "Added" as new file
 		}
 	}
@@ -312,6 +640,13 @@ func GetTreeDiffStats(baseTree, currentTree string) (*DiffStats, []string, error
 // Uses a temporary index file to avoid modifying the real staging area.
 // This matches the bash implementation in git-state.sh.
 func CaptureCurrentTree() (string, error) {
+	return defaultClient.CaptureCurrentTree()
+}
+
+// CaptureCurrentTree returns the SHA of the current working tree, with
+// untracked files unless IncludeUntracked is UntrackedExclude.
+// Uses a temporary index file to avoid modifying the real staging area.
+func (c *Client) CaptureCurrentTree() (string, error) {
 	// Create temp index file
 	tmpIndex, err := os.CreateTemp("", "git-index-*")
 	if err != nil {
@@ -322,39 +657,39 @@ func CaptureCurrentTree() (string, error) {
 	defer os.Remove(tmpIndexPath)
 
 	// Helper to run git commands with GIT_INDEX_FILE set
-	gitWithTempIndex := func(args ...string) *exec.Cmd {
-		cmd := exec.Command("git", args...)
-		cmd.Env = append(os.Environ(), "GIT_INDEX_FILE="+tmpIndexPath)
-		return cmd
+	tempIndexEnv := []string{"GIT_INDEX_FILE=" + tmpIndexPath}
+	gitWithTempIndex := func(args ...string) ([]byte, error) {
+		return c.outputEnv(tempIndexEnv, args...)
 	}
 
 	// Initialize temp index with HEAD tree (or empty if no commits)
-	headRef, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	headRef, err := c.output("rev-parse", "HEAD")
 	if err == nil && len(headRef) > 0 {
-		gitWithTempIndex("read-tree", strings.TrimSpace(string(headRef))).Run()
+		gitWithTempIndex("read-tree", strings.TrimSpace(string(headRef)))
 	} else {
-		gitWithTempIndex("read-tree", "--empty").Run()
+		gitWithTempIndex("read-tree", "--empty")
 	}
 
 	// Add tracked file changes (staged and unstaged)
-	gitWithTempIndex("add", "-u", ".").Run()
+	gitWithTempIndex("add", "-u", ".")
 
 	// Add untracked files (respecting .gitignore)
-	lsCmd := exec.Command("git", "ls-files", "--others", "--exclude-standard")
-	untrackedOutput, _ := lsCmd.Output()
+	var untrackedOutput []byte
+	if c.IncludeUntracked != UntrackedExclude {
+		untrackedOutput, _ = c.output("ls-files", "--others", "--exclude-standard")
+	}
 	if len(untrackedOutput) > 0 {
 		scanner := bufio.NewScanner(bytes.NewReader(untrackedOutput))
 		for scanner.Scan() {
 			path := scanner.Text()
 			if path != "" {
-				gitWithTempIndex("add", path).Run()
+				gitWithTempIndex("add", unquotePath(path))
 			}
 		}
 	}
 
 	// Write tree from temp index
-	writeCmd := gitWithTempIndex("write-tree")
-	output, err := writeCmd.Output()
+	output, err := gitWithTempIndex("write-tree")
 	if err != nil {
 		return "", err
 	}
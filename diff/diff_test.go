@@ -1,6 +1,13 @@
 package diff
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -245,6 +252,34 @@ func TestDiffStats_ToJSON(t *testing.T) {
 	}
 }
 
+func TestDiffStats_ToJSON_Deleted(t *testing.T) {
+	stats := &DiffStats{
+		Files: []FileStat{
+			{Path: "old.go", Deletions: 40, Status: "D"},
+			{Path: "main.go", Additions: 3, Deletions: 1, Status: "M"},
+			{Path: "gone.txt", Deletions: 2, Status: "D"},
+		},
+		TotalAdd: 3, TotalDel: 43, TotalFiles: 3,
+	}
+
+	got := stats.ToJSON()
+	if got.Deleted == nil {
+		t.Fatal("Deleted = nil, want a deleted section")
+	}
+	if got.Deleted.FileCount != 2 || got.Deleted.Lines != 42 {
+		t.Errorf("Deleted = %+v, want 2 files, 42 lines", *got.Deleted)
+	}
+	if !got.Files[0].Deleted || got.Files[1].Deleted {
+		t.Errorf("Files deleted flags = %v, %v; want true, false", got.Files[0].Deleted, got.Files[1].Deleted)
+	}
+
+	// Unknown status (plain numstat) has no deleted section
+	stats = &DiffStats{Files: []FileStat{{Path: "a.go", Deletions: 5}}, TotalDel: 5, TotalFiles: 1}
+	if got := stats.ToJSON(); got.Deleted != nil {
+		t.Errorf("Deleted = %+v, want nil without status", *got.Deleted)
+	}
+}
+
 func TestParseNumstat_Warnings(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -293,3 +328,325 @@ func TestParseNumstat_Warnings(t *testing.T) {
 		})
 	}
 }
+
+func TestDiffStats_DirStats(t *testing.T) {
+	stats := &DiffStats{
+		Files: []FileStat{
+			{Path: "src/lib/a.go", Additions: 30},
+			{Path: "src/lib/deep/b.go", Additions: 10, Deletions: 10},
+			{Path: "src/main.go", Additions: 25, IsUntracked: true},
+			{Path: "README.md", Additions: 25},
+		},
+		TotalAdd:   90,
+		TotalDel:   10,
+		TotalFiles: 4,
+	}
+
+	got := stats.DirStats(2)
+	want := []DirStatJSON{
+		{Path: "src/lib", Adds: 40, Dels: 10, FileCount: 2, Percent: 50},
+		{Path: ".", Adds: 25, FileCount: 1, Percent: 25},
+		{Path: "src", Adds: 25, NewAdds: 25, FileCount: 1, Percent: 25},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("DirStats(2) returned %d dirs, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("DirStats(2)[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// Depth 1 aggregates everything under src
+	got = stats.DirStats(1)
+	if got[0].Path != "src" || got[0].FileCount != 3 {
+		t.Errorf("DirStats(1)[0] = %+v, want src with 3 files", got[0])
+	}
+}
+
+func TestDiffStats_DirStatsWithDepths(t *testing.T) {
+	stats := &DiffStats{Files: []FileStat{{Path: "README.md", Additions: 1}, {Path: "docs/a/guide.md", Additions: 3}}}
+	for i := range 5 {
+		stats.Files = append(stats.Files, FileStat{Path: fmt.Sprintf("cmd/tool/f%d.go", i), Additions: 1})
+	}
+	for i := range 45 {
+		stats.Files = append(stats.Files, FileStat{Path: fmt.Sprintf("internal/pkg%d/x/y.go", i%9), Additions: 2})
+	}
+	stats.TotalFiles = len(stats.Files)
+	depths := DirDepths{"docs": 1, "cmd": 2, "internal": 4}
+
+	if got := depths.For("internal/pkg1/x/y.go", 2); got != 4 {
+		t.Errorf("For(file) = %d, want 4", got)
+	}
+	if got := depths.For("cmd", 9); got != 2 {
+		t.Errorf("For(dir) = %d, want 2", got)
+	}
+	if got := depths.For("README.md", 3); got != 3 {
+		t.Errorf("For(root file) = %d, want fallback 3", got)
+	}
+
+	// Each top-level directory aggregates at its own depth
+	var paths []string
+	for _, d := range stats.DirStatsWithDepths(depths, 1) {
+		paths = append(paths, d.Path)
+	}
+	sort.Strings(paths)
+	if got := strings.Join(paths, ","); !strings.Contains(got, "cmd/tool,") || !strings.Contains(got, "docs,") || !strings.Contains(got, "internal/pkg0/x,") {
+		t.Errorf("DirStatsWithDepths paths = %s", got)
+	}
+}
+
+func TestParseFunctionHeaders(t *testing.T) {
+	output := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -10,0 +11,2 @@ func main() {
++	a()
++	b()
+@@ -20 +22 @@ func main() {
+-	c()
++	d()
+@@ -40,0 +42 @@ func helper(x int) int {
++	return x
+diff --git a/new.txt b/new.txt
+new file mode 100644
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1 @@
++hello
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1,3 +0,0 @@ package old
+-package old
+`
+	got := ParseFunctionHeaders(output)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"main.go", 2}, // main() counted once despite two hunks
+		{"new.txt", 0}, // no function context
+		{"old.go", 1},  // deletions keyed by old path
+	}
+	for _, tt := range tests {
+		if got[tt.path] != tt.want {
+			t.Errorf("ParseFunctionHeaders()[%q] = %d, want %d", tt.path, got[tt.path], tt.want)
+		}
+	}
+}
+
+func TestClient_CountFunctionsChanged_PrefixConfig(t *testing.T) {
+	for _, config := range []string{"diff.noprefix", "diff.mnemonicPrefix"} {
+		t.Run(config, func(t *testing.T) {
+			client, git := newTestRepo(t)
+			src := "package main\n\nfunc main() {\n\ta()\n}\n"
+			if err := os.WriteFile(client.path("main.go"), []byte(src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			git("add", "main.go")
+			git("commit", "-q", "-m", "init")
+			git("config", config, "true")
+			if err := os.WriteFile(client.path("main.go"), []byte(strings.Replace(src, "a()", "b()", 1)), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			stats := &DiffStats{Files: []FileStat{{Path: "main.go", Additions: 1, Deletions: 1}}}
+			if _, err := client.CountFunctionsChanged(stats); err != nil {
+				t.Fatal(err)
+			}
+			if got := stats.Files[0].FunctionsChanged; got != 1 {
+				t.Errorf("FunctionsChanged = %d with %s, want 1", got, config)
+			}
+		})
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	a := &DiffStats{Files: []FileStat{
+		{Path: "src/a.go", Additions: 5, Deletions: 1},
+		{Path: "src/b.go", Additions: 2},
+		{Path: "docs/x.md", Additions: 3},
+	}}
+	b := &DiffStats{Files: []FileStat{
+		{Path: "docs/x.md", Deletions: 4},
+		{Path: "src/a.go", Additions: 1, Deletions: 2},
+		{Path: "other.go", Additions: 9},
+	}}
+
+	got := Intersect(a, b)
+
+	if got.TotalFiles != 2 {
+		t.Fatalf("TotalFiles = %d, want 2", got.TotalFiles)
+	}
+	if got.Files[0].Path != "src/a.go" || got.Files[1].Path != "docs/x.md" {
+		t.Errorf("Files = %v, want src/a.go then docs/x.md", got.Files)
+	}
+	if got.Files[0].Additions != 6 || got.Files[0].Deletions != 3 {
+		t.Errorf("src/a.go = +%d -%d, want +6 -3", got.Files[0].Additions, got.Files[0].Deletions)
+	}
+	if got.TotalAdd != 9 || got.TotalDel != 7 {
+		t.Errorf("totals = +%d -%d, want +9 -7", got.TotalAdd, got.TotalDel)
+	}
+}
+
+func TestUnion(t *testing.T) {
+	a := &DiffStats{Files: []FileStat{
+		{Path: "src/a.go", Additions: 5, Deletions: 1},
+		{Path: "src/b.go", Additions: 2},
+	}}
+	b := &DiffStats{Files: []FileStat{
+		{Path: "other.go", Additions: 9},
+		{Path: "src/a.go", Additions: 1, Deletions: 2},
+	}}
+
+	got := Union(a, b)
+
+	if got.TotalFiles != 3 {
+		t.Fatalf("TotalFiles = %d, want 3", got.TotalFiles)
+	}
+	var paths []string
+	for _, f := range got.Files {
+		paths = append(paths, f.Path)
+	}
+	if strings.Join(paths, " ") != "src/a.go src/b.go other.go" {
+		t.Errorf("Files = %v, want src/a.go src/b.go other.go", paths)
+	}
+	if got.Files[0].Additions != 6 || got.Files[0].Deletions != 3 {
+		t.Errorf("src/a.go = +%d -%d, want +6 -3", got.Files[0].Additions, got.Files[0].Deletions)
+	}
+	if got.TotalAdd != 17 || got.TotalDel != 3 {
+		t.Errorf("totals = +%d -%d, want +17 -3", got.TotalAdd, got.TotalDel)
+	}
+	if a.Files[0].Additions != 5 {
+		t.Errorf("Union modified its input: %+v", a.Files[0])
+	}
+}
+
+func TestDiffStats_MarkUnmerged(t *testing.T) {
+	// numstat during a conflict: "0 0" placeholder plus the real counts
+	stats, _, err := ParseNumstat("0\t0\tsrc/a.go\n4\t0\tsrc/a.go\n1\t1\tdocs/x.md\n")
+	if err != nil {
+		t.Fatalf("ParseNumstat: %v", err)
+	}
+
+	stats.MarkUnmerged([]string{"src/a.go", "gone.go"})
+
+	if stats.TotalFiles != 3 {
+		t.Fatalf("TotalFiles = %d, want 3 (deduped + numstat-less conflict)", stats.TotalFiles)
+	}
+	want := []FileStat{
+		{Path: "src/a.go", Additions: 4, IsUnmerged: true},
+		{Path: "docs/x.md", Additions: 1, Deletions: 1},
+		{Path: "gone.go", IsUnmerged: true},
+	}
+	for i, w := range want {
+		if !reflect.DeepEqual(stats.Files[i], w) {
+			t.Errorf("Files[%d] = %+v, want %+v", i, stats.Files[i], w)
+		}
+	}
+}
+
+func TestDiffStats_Enrich(t *testing.T) {
+	stats := &DiffStats{Files: []FileStat{
+		{Path: "cmd/main.go"},
+		{Path: "web/App.TSX"},
+		{Path: "Makefile"},
+		{Path: "data.bin"},
+	}}
+
+	if _, err := stats.Enrich("lang"); err != nil {
+		t.Fatalf("Enrich(lang): %v", err)
+	}
+
+	want := []string{"Go", "TypeScript", "Make", ""}
+	for i, w := range want {
+		if got := stats.Files[i].Annotations["lang"]; got != w {
+			t.Errorf("%s lang = %q, want %q", stats.Files[i].Path, got, w)
+		}
+	}
+	if stats.Files[3].Annotations != nil {
+		t.Errorf("unannotated file should keep nil map, got %v", stats.Files[3].Annotations)
+	}
+
+	if _, err := stats.Enrich("owners-typo"); err == nil {
+		t.Error("Enrich(unknown) should return an error")
+	}
+}
+
+func TestRelative(t *testing.T) {
+	stats := &DiffStats{
+		Files: []FileStat{
+			{Path: "src/a.go", Additions: 3},
+			{Path: "src/sub/b.go", NewPath: "src/sub/c.go", Additions: 2},
+			{Path: "docs/x.md", Additions: 1},
+		},
+		TotalFiles: 3, TotalAdd: 6,
+	}
+
+	got := stats.Relative("src")
+	if got.TotalFiles != 2 || got.TotalAdd != 5 {
+		t.Errorf("Relative(src) = %d files +%d, want 2 files +5", got.TotalFiles, got.TotalAdd)
+	}
+	if got.Files[0].Path != "a.go" || got.Files[1].Path != "sub/b.go" || got.Files[1].NewPath != "sub/c.go" {
+		t.Errorf("Relative(src) paths = %+v", got.Files)
+	}
+	if stats.Files[0].Path != "src/a.go" {
+		t.Errorf("Relative modified the receiver: %+v", stats.Files[0])
+	}
+}
+
+func TestCountLines(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	big := strings.Repeat("line\n", 20000) // 100000 bytes, spans several reads
+
+	tests := []struct {
+		name    string
+		path    string
+		maxSize int64
+		want    int
+		wantErr error
+	}{
+		{"empty", write("empty", ""), -1, 0, nil},
+		{"trailing newline", write("a.txt", "a\nb\n"), -1, 2, nil},
+		{"no trailing newline", write("b.txt", "a\nb"), -1, 2, nil},
+		{"binary", write("c.bin", "ab\x00cd\n"), -1, -1, nil},
+		{"multi-chunk", write("big.txt", big), -1, 20000, nil},
+		{"at limit", write("limit.txt", "abcd\n"), 5, 1, nil},
+		{"over limit", write("over.txt", big), 1000, 0, errTooLarge},
+	}
+
+	for _, tt := range tests {
+		got, err := countLines(tt.path, tt.maxSize)
+		if !errors.Is(err, tt.wantErr) || got != tt.want {
+			t.Errorf("%s: countLines = %d, %v; want %d, %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCountLines_SymlinkNotFollowed(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(t.TempDir(), "outside.txt")
+	if err := os.WriteFile(target, []byte("1\n2\n3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	got, err := countLines(link, 1)
+	if err != nil || got != 1 {
+		t.Errorf("countLines(symlink) = %d, %v; want 1 (the link itself), nil", got, err)
+	}
+}
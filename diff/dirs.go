@@ -0,0 +1,126 @@
+package diff
+
+import (
+	"math"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DirStats aggregates file stats by directory prefix of at most depth components.
+// Root-level files aggregate under ".". Results are sorted by total changes
+// descending, then by path. depth < 1 is treated as 1.
+func (s *DiffStats) DirStats(depth int) []DirStatJSON {
+	return s.dirStats(func(string) int { return depth })
+}
+
+// DirStatsWithDepths is DirStats with a depth per top-level directory
+// (see analyze.AutoDepths); root-level files and unlisted directories use
+// fallback.
+func (s *DiffStats) DirStatsWithDepths(depths DirDepths, fallback int) []DirStatJSON {
+	return s.dirStats(func(filePath string) int { return depths.For(filePath, fallback) })
+}
+
+func (s *DiffStats) dirStats(depthFor func(filePath string) int) []DirStatJSON {
+	byDir := make(map[string]*DirStatJSON)
+	for _, f := range s.Files {
+		dir := dirAtDepth(f.Path, max(depthFor(f.Path), 1))
+		d := byDir[dir]
+		if d == nil {
+			d = &DirStatJSON{Path: dir}
+			byDir[dir] = d
+		}
+		d.Adds += f.Additions
+		d.Dels += f.Deletions
+		if f.IsNew() {
+			d.NewAdds += f.Additions
+		}
+		d.FileCount++
+	}
+
+	total := s.TotalAdd + s.TotalDel
+	result := make([]DirStatJSON, 0, len(byDir))
+	for _, d := range byDir {
+		if total > 0 {
+			pct := float64(d.Adds+d.Dels) * 100 / float64(total)
+			d.Percent = math.Round(pct*10) / 10
+		}
+		result = append(result, *d)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		ti := result[i].Adds + result[i].Dels
+		tj := result[j].Adds + result[j].Dels
+		if ti != tj {
+			return ti > tj
+		}
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+// InDir returns the files under dir (repo-relative, "." for everything)
+// with totals recomputed. The receiver is not modified.
+func (s *DiffStats) InDir(dir string) *DiffStats {
+	if dir == "." || dir == "" {
+		return s
+	}
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+
+	result := &DiffStats{NoHead: s.NoHead}
+	for _, f := range s.Files {
+		if !strings.HasPrefix(f.Path, prefix) {
+			continue
+		}
+		result.Files = append(result.Files, f)
+		result.TotalAdd += f.Additions
+		result.TotalDel += f.Deletions
+	}
+	result.TotalFiles = len(result.Files)
+	return result
+}
+
+// Relative returns the files under dir with dir/ trimmed from their paths,
+// like git diff --relative. The receiver is not modified.
+func (s *DiffStats) Relative(dir string) *DiffStats {
+	if dir == "." || dir == "" {
+		return s
+	}
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+
+	result := s.InDir(dir) // Files is a fresh slice
+	for i := range result.Files {
+		f := &result.Files[i]
+		f.Path = strings.TrimPrefix(f.Path, prefix)
+		f.NewPath = strings.TrimPrefix(f.NewPath, prefix)
+	}
+	return result
+}
+
+// dirAtDepth returns the directory containing filePath, truncated to depth components.
+// e.g., ("src/lib/utils/a.go", 2) -> "src/lib"; ("README.md", 2) -> ".".
+func dirAtDepth(filePath string, depth int) string {
+	dir := path.Dir(filePath)
+	if dir == "." {
+		return "."
+	}
+	parts := strings.Split(dir, "/")
+	if len(parts) > depth {
+		parts = parts[:depth]
+	}
+	return strings.Join(parts, "/")
+}
+
+// DirDepths maps top-level directory names to the depth their subtree is
+// aggregated at, for diffs that mix small and large directories.
+type DirDepths map[string]int
+
+// For returns the depth for the top-level directory of p (a file path or a
+// directory path), or fallback for root-level files and unlisted directories.
+func (d DirDepths) For(p string, fallback int) int {
+	top, _, _ := strings.Cut(p, "/")
+	if n, ok := d[top]; ok {
+		return n
+	}
+	return fallback
+}
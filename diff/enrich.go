@@ -0,0 +1,106 @@
+package diff
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Enricher attaches one kind of metadata to files as an annotation
+// (e.g., owners, coverage, language). Renderers and JSON output show
+// annotations generically, so new enrichers need no struct changes.
+type Enricher interface {
+	// Key is the annotation key this enricher sets (e.g., "lang").
+	Key() string
+	// Enrich annotates files in place. Per-file problems are returned
+	// as warnings (fail-open) rather than aborting the run.
+	Enrich(files []FileStat) []string
+}
+
+// enrichers holds registered enrichers by key.
+var enrichers = map[string]Enricher{}
+
+// RegisterEnricher makes e available by its key, replacing any existing one.
+func RegisterEnricher(e Enricher) {
+	enrichers[e.Key()] = e
+}
+
+// LookupEnricher returns the enricher registered under key.
+func LookupEnricher(key string) (Enricher, bool) {
+	e, ok := enrichers[key]
+	return e, ok
+}
+
+// EnricherKeys returns registered enricher keys, sorted.
+func EnricherKeys() []string {
+	keys := make([]string, 0, len(enrichers))
+	for k := range enrichers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Enrich runs the enrichers registered under keys over stats.Files.
+// Returns an error for unknown keys; enricher warnings are collected.
+func (s *DiffStats) Enrich(keys ...string) ([]string, error) {
+	var warnings []string
+	for _, key := range keys {
+		e, ok := LookupEnricher(key)
+		if !ok {
+			return warnings, fmt.Errorf("unknown annotation %q (valid: %s)", key, strings.Join(EnricherKeys(), ", "))
+		}
+		warnings = append(warnings, e.Enrich(s.Files)...)
+	}
+	return warnings, nil
+}
+
+// SetAnnotation sets key to value on f, allocating the map on first use.
+func (f *FileStat) SetAnnotation(key, value string) {
+	if f.Annotations == nil {
+		f.Annotations = make(map[string]string)
+	}
+	f.Annotations[key] = value
+}
+
+// LanguageEnricher annotates files with a language name guessed from the
+// file extension ("lang"). Unknown extensions are left unannotated.
+type LanguageEnricher struct{}
+
+// languages maps lowercase extensions (and a few well-known names) to languages.
+var languages = map[string]string{
+	".go": "Go", ".rs": "Rust", ".py": "Python", ".rb": "Ruby",
+	".js": "JavaScript", ".jsx": "JavaScript", ".mjs": "JavaScript",
+	".ts": "TypeScript", ".tsx": "TypeScript", ".java": "Java",
+	".kt": "Kotlin", ".swift": "Swift", ".c": "C", ".h": "C",
+	".cc": "C++", ".cpp": "C++", ".hpp": "C++", ".cs": "C#",
+	".php": "PHP", ".sh": "Shell", ".bash": "Shell", ".zsh": "Shell",
+	".md": "Markdown", ".json": "JSON", ".yaml": "YAML", ".yml": "YAML",
+	".toml": "TOML", ".html": "HTML", ".css": "CSS", ".sql": "SQL",
+	".lua": "Lua", ".ex": "Elixir", ".exs": "Elixir",
+	"Makefile": "Make", "Dockerfile": "Docker", "justfile": "Just",
+	"go.mod": "Go", "go.sum": "Go",
+}
+
+// Key returns "lang".
+func (LanguageEnricher) Key() string { return "lang" }
+
+// Enrich sets "lang" for files with a recognized extension or name.
+func (LanguageEnricher) Enrich(files []FileStat) []string {
+	for i := range files {
+		base := path.Base(files[i].Path)
+		lang, ok := languages[base]
+		if !ok {
+			lang, ok = languages[strings.ToLower(path.Ext(base))]
+		}
+		if ok {
+			files[i].SetAnnotation("lang", lang)
+		}
+	}
+	return nil
+}
+
+func init() {
+	RegisterEnricher(LanguageEnricher{})
+}
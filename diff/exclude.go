@@ -0,0 +1,73 @@
+package diff
+
+import (
+	"path"
+	"strings"
+)
+
+// Exclusion is a named category of paths, such as generated code or vendored
+// dependencies, whose lines summaries can leave out of the reviewable totals.
+//
+// Patterns use path.Match syntax. A pattern with no slash matches the file
+// name in any directory ("*.pb.go"); one ending in a slash matches everything
+// under that directory ("vendor/"); any other pattern matches the whole path.
+type Exclusion struct {
+	Name     string
+	Patterns []string
+}
+
+// Matches reports whether p (a repo-relative path) is in the category.
+func (e Exclusion) Matches(p string) bool {
+	if newPath, ok := RenameTarget(p); ok {
+		p = newPath
+	}
+	for _, pattern := range e.Patterns {
+		switch {
+		case strings.HasSuffix(pattern, "/"):
+			if strings.HasPrefix(p, pattern) {
+				return true
+			}
+		case !strings.Contains(pattern, "/"):
+			if ok, _ := path.Match(pattern, path.Base(p)); ok {
+				return true
+			}
+		default:
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ExcludedTotals sums the files that matched any Exclusion.
+type ExcludedTotals struct {
+	Names []string // Categories that matched at least one file, in rule order
+	Add   int
+	Del   int
+	Files int
+}
+
+// Excluded sums the files matching any of rules. A file counts toward the
+// first category it matches.
+func (s *DiffStats) Excluded(rules []Exclusion) ExcludedTotals {
+	var totals ExcludedTotals
+	matched := make([]bool, len(rules))
+	for _, f := range s.Files {
+		for i, rule := range rules {
+			if rule.Matches(f.Path) {
+				matched[i] = true
+				totals.Add += f.Additions
+				totals.Del += f.Deletions
+				totals.Files++
+				break
+			}
+		}
+	}
+	for i, rule := range rules {
+		if matched[i] {
+			totals.Names = append(totals.Names, rule.Name)
+		}
+	}
+	return totals
+}
@@ -0,0 +1,49 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExclusion_Matches(t *testing.T) {
+	e := Exclusion{Name: "generated", Patterns: []string{"*.pb.go", "vendor/", "docs/api/*.json"}}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"api/v1/service.pb.go", true},
+		{"service.pb.go", true},
+		{"vendor/github.com/x/y.go", true},
+		{"docs/api/openapi.json", true},
+		{"docs/api/v2/openapi.json", false},
+		{"src/vendor/y.go", false},
+		{"main.go", false},
+		{"{old => api}/service.pb.go", true}, // Renames match their new path
+	}
+	for _, tt := range tests {
+		if got := e.Matches(tt.path); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDiffStats_Excluded(t *testing.T) {
+	stats := &DiffStats{Files: []FileStat{
+		{Path: "api/service.pb.go", Additions: 200, Deletions: 40},
+		{Path: "vendor/lib/a.go", Additions: 10},
+		{Path: "main.go", Additions: 5, Deletions: 1},
+	}}
+	rules := []Exclusion{
+		{Name: "generated", Patterns: []string{"*.pb.go", "*.go"}},
+		{Name: "lockfiles", Patterns: []string{"go.sum"}},
+	}
+
+	got := stats.Excluded(rules)
+	want := ExcludedTotals{Names: []string{"generated"}, Add: 215, Del: 41, Files: 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Excluded() = %+v, want %+v", got, want)
+	}
+	if got := stats.Excluded(nil); got.Files != 0 || got.Names != nil {
+		t.Errorf("Excluded(nil) = %+v, want zero", got)
+	}
+}
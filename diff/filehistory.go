@@ -0,0 +1,148 @@
+package diff
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileCommit is one commit's changes to a single file.
+type FileCommit struct {
+	SHA     string // Abbreviated commit hash
+	Time    time.Time
+	Subject string // First line of the commit message
+	Path    string // Numstat path ("old => new" when the commit renamed the file)
+	Adds    int
+	Dels    int
+}
+
+// FileHistory returns the last n commits reachable from HEAD that touched
+// path (repo-relative), oldest first, following renames.
+func FileHistory(path string, n int) ([]FileCommit, []string, error) {
+	return defaultClient.FileHistory(path, n)
+}
+
+// FileHistory returns the last n commits that touched path, oldest first.
+// Git failures follow the client's FailOpen policy.
+func (c *Client) FileHistory(path string, n int) ([]FileCommit, []string, error) {
+	var warnings []string
+	output, err := c.output("log", "-n", strconv.Itoa(n), "--follow", "--format=%x00%h%x00%ct%x00%s", "--numstat", "--", path)
+	if err != nil {
+		warnings, err = c.fail(err, warnings)
+		return nil, warnings, err
+	}
+
+	commits, parseWarnings := ParseFileHistory(string(output))
+	warnings = append(warnings, parseWarnings...)
+	return commits, warnings, nil
+}
+
+// ParseFileHistory parses git log output in the "%x00%h%x00%ct%x00%s" format
+// (the subject is optional) with --numstat for a single path, returning
+// commits oldest first. Binary changes count as zero lines. Subjects and
+// paths pass through SanitizeText. Malformed lines are skipped with a warning.
+func ParseFileHistory(output string) ([]FileCommit, []string) {
+	var commits []FileCommit
+	var warnings []string
+
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "\x00"):
+			parts := strings.SplitN(line[1:], "\x00", 3)
+			if len(parts) < 2 {
+				warnings = append(warnings, fmt.Sprintf("malformed log line: %q", line))
+				continue
+			}
+			secs, err := strconv.ParseInt(parts[1], 10, 64)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("malformed log line: %q", line))
+				continue
+			}
+			commit := FileCommit{SHA: parts[0], Time: time.Unix(secs, 0)}
+			if len(parts) == 3 {
+				commit.Subject = SanitizeText(parts[2])
+			}
+			commits = append(commits, commit)
+		case len(commits) > 0:
+			fields := strings.SplitN(line, "\t", 3)
+			if len(fields) != 3 {
+				warnings = append(warnings, fmt.Sprintf("malformed numstat line: %q", line))
+				continue
+			}
+			last := &commits[len(commits)-1]
+			last.Path = NormalizePath(fields[2])
+			adds, _ := strconv.Atoi(fields[0]) // "-" for binary
+			dels, _ := strconv.Atoi(fields[1])
+			last.Adds += adds
+			last.Dels += dels
+		}
+	}
+
+	// git log lists newest first
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+	return commits, warnings
+}
+
+// RepoPath converts a path relative to the current directory, or an
+// absolute path inside the repository, into a repo-relative, slash-separated
+// path (the form DiffStats uses).
+func RepoPath(p string) (string, error) {
+	return defaultClient.RepoPath(p)
+}
+
+// RepoPath converts a path relative to the client's directory, or an
+// absolute path, into a repo-relative path. Errors wrap ErrNotARepo outside
+// a repository.
+func (c *Client) RepoPath(p string) (string, error) {
+	if filepath.IsAbs(p) {
+		return c.absRepoPath(p)
+	}
+	out, err := c.output("rev-parse", "--show-prefix")
+	if err != nil {
+		return "", err
+	}
+	prefix := strings.TrimSpace(string(out))
+	rel := path.Clean(path.Join(prefix, filepath.ToSlash(p)))
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("%s is outside the repository", p)
+	}
+	return rel, nil
+}
+
+// absRepoPath makes absolute path p relative to the repository root.
+// Symlinks are resolved on both sides, since git reports the root's real
+// path.
+func (c *Client) absRepoPath(p string) (string, error) {
+	out, err := c.output("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	root := filepath.FromSlash(strings.TrimSpace(string(out)))
+	rel, err := filepath.Rel(realPath(root), realPath(p))
+	if err != nil {
+		return "", fmt.Errorf("%s is outside the repository", p)
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("%s is outside the repository", p)
+	}
+	return rel, nil
+}
+
+// realPath resolves symlinks in p. A path that does not exist (a deleted
+// file) keeps its name under its parent's real path.
+func realPath(p string) string {
+	if real, err := filepath.EvalSymlinks(p); err == nil {
+		return real
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(p)); err == nil {
+		return filepath.Join(dir, filepath.Base(p))
+	}
+	return p
+}
@@ -0,0 +1,95 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseFileHistory(t *testing.T) {
+	output := "\x00bbb2222\x001700000100\x00Rename b to a\n\n5\t1\tsrc/{b.go => a.go}\n" +
+		"\x00broken\n" +
+		"\x00aaa1111\x001700000000\n\n-\t-\tsrc/a.go\n"
+
+	commits, warnings := ParseFileHistory(output)
+	if len(commits) != 2 {
+		t.Fatalf("got %d commits, want 2: %+v", len(commits), commits)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("got %d warnings, want 1: %v", len(warnings), warnings)
+	}
+
+	// Oldest first; binary counts as zero lines
+	if commits[0].SHA != "aaa1111" || commits[0].Adds != 0 || commits[0].Dels != 0 {
+		t.Errorf("commits[0] = %+v", commits[0])
+	}
+	if commits[1].SHA != "bbb2222" || commits[1].Adds != 5 || commits[1].Dels != 1 {
+		t.Errorf("commits[1] = %+v", commits[1])
+	}
+	if commits[1].Subject != "Rename b to a" || commits[1].Path != "src/{b.go => a.go}" {
+		t.Errorf("commits[1] subject/path = %q, %q", commits[1].Subject, commits[1].Path)
+	}
+	if commits[0].Subject != "" {
+		t.Errorf("commits[0].Subject = %q, want empty", commits[0].Subject)
+	}
+	if !commits[1].Time.Equal(time.Unix(1700000100, 0)) {
+		t.Errorf("commits[1].Time = %v", commits[1].Time)
+	}
+}
+
+func TestInDir(t *testing.T) {
+	stats := &DiffStats{
+		Files: []FileStat{
+			{Path: "src/a.go", Additions: 3, Deletions: 1},
+			{Path: "src/sub/b.go", Additions: 2},
+			{Path: "srcx/c.go", Additions: 7},
+			{Path: "README.md", Additions: 1},
+		},
+		TotalFiles: 4, TotalAdd: 13, TotalDel: 1,
+	}
+
+	got := stats.InDir("src")
+	if got.TotalFiles != 2 || got.TotalAdd != 5 || got.TotalDel != 1 {
+		t.Errorf("InDir(src) = %d files +%d -%d, want 2 files +5 -1", got.TotalFiles, got.TotalAdd, got.TotalDel)
+	}
+	if stats.InDir(".") != stats {
+		t.Error("InDir(.) should return the receiver")
+	}
+}
+
+func TestClient_RepoPath(t *testing.T) {
+	c, _ := newTestRepo(t)
+	if err := os.MkdirAll(c.path("src"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	sub := NewClient(Options{Dir: c.path("src"), Env: c.Env})
+
+	abs, err := filepath.Abs(c.path("src/main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		client *Client
+		path   string
+		want   string
+	}{
+		{c, "src/main.go", "src/main.go"},
+		{sub, "main.go", "src/main.go"},
+		{sub, "../README.md", "README.md"},
+		{c, abs, "src/main.go"},
+		{sub, abs, "src/main.go"},
+		{sub, filepath.Dir(abs), "src"},
+	}
+	for _, tt := range tests {
+		if got, err := tt.client.RepoPath(tt.path); err != nil || got != tt.want {
+			t.Errorf("RepoPath(%q) from %s = %q, %v; want %q", tt.path, tt.client.Dir, got, err, tt.want)
+		}
+	}
+
+	for _, outside := range []string{"../..", filepath.Dir(filepath.Dir(filepath.Dir(abs)))} {
+		if got, err := sub.RepoPath(outside); err == nil {
+			t.Errorf("RepoPath(%q) = %q, want an outside-the-repository error", outside, got)
+		}
+	}
+}
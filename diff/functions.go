@@ -0,0 +1,82 @@
+package diff
+
+import (
+	"bufio"
+	"strings"
+)
+
+// CountFunctionsChanged estimates how many functions each file's changes touch
+// and stores the result in FileStat.FunctionsChanged.
+// Args are passed to git diff the same way as GetDiffStats.
+func CountFunctionsChanged(stats *DiffStats, args ...string) ([]string, error) {
+	return defaultClient.CountFunctionsChanged(stats, args...)
+}
+
+// CountFunctionsChanged estimates how many functions each file's changes touch
+// using the function context git prints in hunk headers. Untracked files and
+// hunks without a recognizable enclosing function are not counted.
+func (c *Client) CountFunctionsChanged(stats *DiffStats, args ...string) ([]string, error) {
+	var warnings []string
+	output, err := c.output(c.patchArgs(args)...)
+	if err != nil {
+		return c.fail(err, warnings)
+	}
+
+	counts := ParseFunctionHeaders(string(output))
+	for i := range stats.Files {
+		stats.Files[i].FunctionsChanged = counts[stats.Files[i].Path]
+	}
+	return warnings, nil
+}
+
+// patchArgs returns git diff args for zero-context patch output. The a/ and
+// b/ prefixes are explicit so diff.noprefix or diff.mnemonicPrefix cannot
+// change the ---/+++ lines the parsers read.
+func (c *Client) patchArgs(args []string) []string {
+	cmdArgs := []string{"diff", "-U0", "--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/"}
+	return append(append(cmdArgs, c.relativeArgs()...), args...)
+}
+
+// ParseFunctionHeaders parses git diff -p output and returns the number of
+// distinct hunk-header function contexts per file path.
+// Header format: "@@ -a,b +c,d @@ func name(...)".
+func ParseFunctionHeaders(output string) map[string]int {
+	counts := make(map[string]int)
+	seen := make(map[string]bool) // path + "\x00" + context
+	var path string
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			path = ""
+		case strings.HasPrefix(line, "--- a/"):
+			path = strings.TrimPrefix(line, "--- a/") // Kept for deletions
+		case strings.HasPrefix(line, "+++ b/"):
+			path = strings.TrimPrefix(line, "+++ b/")
+		case strings.HasPrefix(line, "@@ ") && path != "":
+			context := hunkContext(line)
+			key := path + "\x00" + context
+			if context == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			counts[path]++
+		}
+	}
+
+	return counts
+}
+
+// hunkContext returns the function context after the closing "@@" of a hunk header.
+func hunkContext(header string) string {
+	rest := strings.TrimPrefix(header, "@@ ")
+	end := strings.Index(rest, " @@")
+	if end < 0 {
+		return ""
+	}
+	return strings.TrimSpace(rest[end+len(" @@"):])
+}
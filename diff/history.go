@@ -0,0 +1,132 @@
+package diff
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// HistoryFile is the file (under the git dir) where tracked snapshots are stored.
+const HistoryFile = "diff-viz/history.jsonl"
+
+// HistoryEntry is one tracked snapshot of a labeled range's size.
+type HistoryEntry struct {
+	Label string    `json:"label"`
+	Time  time.Time `json:"time"`
+	Head  string    `json:"head,omitempty"` // HEAD commit when tracked
+	Range []string  `json:"range,omitempty"`
+	Adds  int       `json:"adds"`
+	Dels  int       `json:"dels"`
+	Files int       `json:"files"`
+	Size  string    `json:"size,omitempty"`
+}
+
+// NewHistoryEntry records stats for label at time t, sized by thresholds
+// (nil for DefaultSizeThresholds).
+func NewHistoryEntry(label string, t time.Time, head string, args []string, stats *DiffStats, thresholds []SizeThreshold) HistoryEntry {
+	return HistoryEntry{
+		Label: label,
+		Time:  t.UTC(),
+		Head:  head,
+		Range: args,
+		Adds:  stats.TotalAdd,
+		Dels:  stats.TotalDel,
+		Files: stats.TotalFiles,
+		Size:  string(stats.SizeClass(thresholds)),
+	}
+}
+
+// AppendHistory appends entry as one JSON line to path, creating parent dirs.
+func AppendHistory(path string, entry HistoryEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating history dir: %w", err)
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening history: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("writing history: %w", err)
+	}
+	return f.Close()
+}
+
+// LoadHistory reads entries for label from path in file order.
+// A missing file yields no entries. Malformed lines are returned as warnings.
+func LoadHistory(path, label string) ([]HistoryEntry, []string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening history: %w", err)
+	}
+	defer f.Close()
+
+	var entries []HistoryEntry
+	var warnings []string
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var e HistoryEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			warnings = append(warnings, fmt.Sprintf("history line %d: %v", lineNum, err))
+			continue
+		}
+		if e.Label == label {
+			entries = append(entries, e)
+		}
+	}
+	return entries, warnings, scanner.Err()
+}
+
+// HistoryPath returns the history file location for the current repository.
+func HistoryPath() (string, error) {
+	return defaultClient.HistoryPath()
+}
+
+// HistoryPath returns the history file location inside the repository's git dir,
+// so tracked history is local and never committed.
+func (c *Client) HistoryPath() (string, error) {
+	out, err := c.output("rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(strings.TrimSpace(string(out)), HistoryFile), nil
+}
+
+// GitDirPath resolves name inside the repository's git dir.
+func GitDirPath(name string) (string, error) {
+	return defaultClient.GitDirPath(name)
+}
+
+// GitDirPath resolves name with git rev-parse --git-path, which honors settings
+// that relocate parts of the git dir (core.hooksPath, worktrees).
+func (c *Client) GitDirPath(name string) (string, error) {
+	out, err := c.output("rev-parse", "--git-path", name)
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSpace(string(out))
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+	return c.path(path), nil
+}
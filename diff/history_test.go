@@ -0,0 +1,59 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistory_AppendAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "history.jsonl")
+	t0 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	entries := []HistoryEntry{
+		NewHistoryEntry("pr-1", t0, "aaa", []string{"main...HEAD"}, &DiffStats{TotalAdd: 10, TotalFiles: 1}, nil),
+		NewHistoryEntry("pr-2", t0, "bbb", nil, &DiffStats{TotalAdd: 99, TotalFiles: 3}, nil),
+		NewHistoryEntry("pr-1", t0.Add(time.Hour), "ccc", nil, &DiffStats{TotalAdd: 40, TotalDel: 5, TotalFiles: 2}, nil),
+	}
+	for _, e := range entries {
+		if err := AppendHistory(path, e); err != nil {
+			t.Fatalf("AppendHistory: %v", err)
+		}
+	}
+
+	// Corrupt line is skipped with a warning
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("{not json\n")
+	f.Close()
+
+	got, warnings, err := LoadHistory(path, "pr-1")
+	if err != nil {
+		t.Fatalf("LoadHistory: %v", err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %v, want 1", warnings)
+	}
+	if len(got) != 2 || got[0].Head != "aaa" || got[1].Head != "ccc" {
+		t.Fatalf("LoadHistory(pr-1) = %+v, want entries aaa, ccc", got)
+	}
+	if got[1].Adds != 40 || got[1].Dels != 5 || got[1].Size != "M" {
+		t.Errorf("entry = %+v, want +40 -5 size M", got[1])
+	}
+
+	missing, _, err := LoadHistory(filepath.Join(t.TempDir(), "none.jsonl"), "pr-1")
+	if err != nil || missing != nil {
+		t.Errorf("LoadHistory(missing) = %v, %v; want nil, nil", missing, err)
+	}
+}
+
+func TestNewHistoryEntry_Thresholds(t *testing.T) {
+	stats := &DiffStats{TotalAdd: 40, TotalDel: 5, TotalFiles: 2}
+	thresholds := []SizeThreshold{{40, SizeXL}, {0, SizeXS}}
+	if got := NewHistoryEntry("pr-1", time.Now(), "", nil, stats, thresholds).Size; got != "XL" {
+		t.Errorf("Size = %q with custom thresholds, want XL", got)
+	}
+	if got := NewHistoryEntry("pr-1", time.Now(), "", nil, stats, nil).Size; got != "M" {
+		t.Errorf("Size = %q with default thresholds, want M", got)
+	}
+}
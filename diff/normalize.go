@@ -0,0 +1,68 @@
+package diff
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizePath returns the canonical form of a path from git output, so
+// the same file compares equal however it reached git-diff-tree:
+//
+//   - git's C-style quoting is undone ("caf\303\251.txt" is café.txt)
+//   - a leading "./", "." segments, and doubled slashes are dropped
+//   - Unicode is composed (NFC), so macOS's decomposed (NFD) names match
+//     the composed names git stores for tracked files
+//   - control characters are escaped with SanitizeText, so the result is
+//     safe to print
+//
+// Each side of a numstat rename ("old => new") is normalized on its own.
+func NormalizePath(p string) string {
+	if sides := strings.Split(p, " => "); len(sides) > 1 {
+		for i, side := range sides {
+			sides[i] = normalizeRawPath(unquotePath(side))
+		}
+		return strings.Join(sides, " => ")
+	}
+	return normalizeRawPath(unquotePath(p))
+}
+
+// normalizeRawPath is NormalizePath for an unquoted path, such as one from
+// -z output.
+func normalizeRawPath(p string) string {
+	return SanitizeText(norm.NFC.String(cleanPath(p)))
+}
+
+// unquotePath undoes git's quoting of unusual paths. Unquoted paths and
+// malformed quoting are returned unchanged.
+func unquotePath(p string) string {
+	if len(p) < 2 || p[0] != '"' || p[len(p)-1] != '"' {
+		return p
+	}
+	// git's escapes (\t, \", \\, \303) are a subset of Go's
+	s, err := strconv.Unquote(p)
+	if err != nil {
+		return p
+	}
+	return s
+}
+
+// cleanPath drops empty and "." segments: "./a//b/./c" is "a/b/c". Unlike
+// path.Clean it keeps ".." segments, which git never emits, as they are.
+func cleanPath(p string) string {
+	if !strings.Contains(p, "//") && !strings.HasPrefix(p, "./") && !strings.Contains(p, "/./") && !strings.HasSuffix(p, "/.") {
+		return p
+	}
+	parts := strings.Split(p, "/")
+	kept := parts[:0]
+	for _, part := range parts {
+		if part != "" && part != "." {
+			kept = append(kept, part)
+		}
+	}
+	if len(kept) == 0 {
+		return p
+	}
+	return strings.Join(kept, "/")
+}
@@ -0,0 +1,68 @@
+package diff
+
+import (
+	"os"
+	"testing"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"src/main.go", "src/main.go"},
+		{"./src//main.go", "src/main.go"},
+		{"src/./main.go", "src/main.go"},
+		{`"caf\303\251.txt"`, "caf\u00e9.txt"},
+		{"cafe\u0301.txt", "caf\u00e9.txt"},
+		{`"cafe\314\201.txt"`, "caf\u00e9.txt"},
+		{`"tab\there"`, `tab\there`},
+		{`"say \"hi\""`, `say "hi"`},
+		{`"caf\303\251.txt" => "d/caf\303\251.txt"`, "caf\u00e9.txt => d/caf\u00e9.txt"},
+		{"src/{a => b}/x.go", "src/{a => b}/x.go"},
+		{`"unterminated`, `"unterminated`},
+	}
+	for _, tt := range tests {
+		if got := NormalizePath(tt.in); got != tt.want {
+			t.Errorf("NormalizePath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if got := NormalizePath(NormalizePath(tt.in)); got != tt.want {
+			t.Errorf("NormalizePath twice (%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestClient_GetAllStats_NormalizesUnicode(t *testing.T) {
+	client, git := newTestRepo(t)
+	nfc, nfd := "caf\u00e9.txt", "cafe\u0301.txt"
+	if err := os.WriteFile(client.path(nfc), []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	if err := os.WriteFile(client.path(nfc), []byte("a\nb\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A decomposed spelling of the tracked name, as macOS can report it
+	if err := os.WriteFile(client.path(nfd), []byte("a\nb\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(client.path("ünïcode.txt"), []byte("x\ny\nz\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, warnings, err := client.GetAllStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("warnings: %v", warnings)
+	}
+	got := make(map[string]int)
+	for _, f := range stats.Files {
+		got[f.Path] = f.Additions
+	}
+	want := map[string]int{nfc: 1, "ünïcode.txt": 3}
+	if len(got) != len(want) || got[nfc] != 1 || got["ünïcode.txt"] != 3 {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
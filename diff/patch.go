@@ -0,0 +1,340 @@
+package diff
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParsePatch parses a unified diff into stats, for patches from outside the
+// repository: git diff or format-patch output (a mailbox of several patches
+// is fine; files changed by more than one are summed), or diff -u output.
+//
+// Hunk lengths come from the "@@" headers, so commit messages, diffstats,
+// and mail signatures around the diffs are skipped. Paths lose their a/ and
+// b/ prefixes (the first path component, as git apply -p1 does) and pass
+// through NormalizePath. New, deleted, and renamed files get a Status (and
+// renames a numstat "old => new" Path); binary files are marked IsBinary,
+// with a SizeDelta when the patch holds full binary literals. Hunk header
+// function contexts fill FunctionsChanged. Malformed hunks are warnings.
+func ParsePatch(r io.Reader) (*DiffStats, []string, error) {
+	p := patchParser{index: make(map[string]int), stats: &DiffStats{}}
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			p.line(strings.TrimRight(line, "\r\n"))
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, p.warnings, err
+		}
+	}
+	p.flush()
+	p.stats.TotalFiles = len(p.stats.Files)
+	return p.stats, p.warnings, nil
+}
+
+// patchFile is the file section ParsePatch is reading.
+type patchFile struct {
+	git              bool   // Started with "diff --git"
+	headerOld        string // Paths from the "diff --git" line
+	headerNew        string
+	oldPath, newPath string // From ---/+++ or rename lines; "/dev/null" for none
+	sawHeaders       bool   // Had its ---/+++ lines
+	status           string
+	binary           bool
+	literals         []int64 // Sizes from "literal N" lines: new, then old
+	add, del         int
+	contexts         map[string]bool // Hunk header function contexts
+}
+
+type patchParser struct {
+	stats    *DiffStats
+	index    map[string]int // Path -> index in stats.Files
+	warnings []string
+
+	file             *patchFile
+	pendingOld       string // A "---" line's path, before its "+++" line
+	binaryPatch      bool   // Inside a "GIT binary patch" section
+	oldLeft, newLeft int    // Hunk lines still expected on each side
+}
+
+func (p *patchParser) line(line string) {
+	if p.oldLeft > 0 || p.newLeft > 0 {
+		if p.hunkLine(line) {
+			return
+		}
+		p.warnings = append(p.warnings, fmt.Sprintf("%s: hunk is shorter than its @@ header", p.name()))
+		p.oldLeft, p.newLeft = 0, 0
+	}
+
+	pendingOld := p.pendingOld
+	p.pendingOld = ""
+
+	switch {
+	case strings.HasPrefix(line, "diff --git "):
+		p.start(true)
+		p.file.headerOld, p.file.headerNew = splitGitHeader(strings.TrimPrefix(line, "diff --git "))
+	case strings.HasPrefix(line, "--- "):
+		// Held until a "+++" line confirms it is not commit message text
+		p.pendingOld = strings.TrimPrefix(line, "--- ")
+	case strings.HasPrefix(line, "+++ ") && pendingOld != "":
+		// Without "diff --git" (diff -u), each ---/+++ pair starts a file
+		if p.file == nil || !p.file.git || p.file.sawHeaders {
+			p.start(false)
+		}
+		p.file.oldPath = patchPath(pendingOld)
+		p.file.newPath = patchPath(strings.TrimPrefix(line, "+++ "))
+		p.file.sawHeaders = true
+	case strings.HasPrefix(line, "@@ ") && p.file != nil:
+		oldLen, newLen, ok := hunkLengths(line)
+		if !ok {
+			p.warnings = append(p.warnings, fmt.Sprintf("%s: malformed hunk header %q", p.name(), line))
+			return
+		}
+		p.oldLeft, p.newLeft = oldLen, newLen
+		if context := hunkContext(line); context != "" {
+			p.file.contexts[context] = true
+		}
+	case p.file == nil:
+		// Mail headers, commit message, diffstat
+	case strings.HasPrefix(line, "new file mode "):
+		p.file.status = "A"
+	case strings.HasPrefix(line, "deleted file mode "):
+		p.file.status = "D"
+	case strings.HasPrefix(line, "rename from "), strings.HasPrefix(line, "copy from "):
+		_, from, _ := strings.Cut(line, " from ")
+		p.file.oldPath = "a/" + unquotePath(from)
+		p.file.status = strings.ToUpper(line[:1])
+	case strings.HasPrefix(line, "rename to "), strings.HasPrefix(line, "copy to "):
+		_, to, _ := strings.Cut(line, " to ")
+		p.file.newPath = "b/" + unquotePath(to)
+	case strings.HasPrefix(line, "Binary files "):
+		p.file.binary = true
+	case line == "GIT binary patch":
+		p.file.binary, p.binaryPatch = true, true
+	case p.binaryPatch && strings.HasPrefix(line, "literal "):
+		if size, err := strconv.ParseInt(strings.TrimPrefix(line, "literal "), 10, 64); err == nil {
+			p.file.literals = append(p.file.literals, size)
+		}
+	}
+}
+
+// hunkLine counts one line of a hunk, reporting false for a line that cannot
+// belong to it.
+func (p *patchParser) hunkLine(line string) bool {
+	switch {
+	case strings.HasPrefix(line, "+") && p.newLeft > 0:
+		p.file.add++
+		p.newLeft--
+	case strings.HasPrefix(line, "-") && p.oldLeft > 0:
+		p.file.del++
+		p.oldLeft--
+	case (line == "" || line[0] == ' ') && p.oldLeft > 0 && p.newLeft > 0:
+		// Mailers can strip the space from empty context lines
+		p.oldLeft--
+		p.newLeft--
+	case strings.HasPrefix(line, `\`):
+		// "\ No newline at end of file"
+	default:
+		return false
+	}
+	return true
+}
+
+// start flushes the current file and begins a new one.
+func (p *patchParser) start(git bool) {
+	p.flush()
+	p.file = &patchFile{git: git, contexts: make(map[string]bool)}
+	p.binaryPatch = false
+}
+
+// flush adds the current file to the stats, summing with an earlier entry
+// for the same path.
+func (p *patchParser) flush() {
+	f := p.file
+	p.file = nil
+	if f == nil {
+		return
+	}
+	if p.oldLeft > 0 || p.newLeft > 0 {
+		p.warnings = append(p.warnings, fmt.Sprintf("%s: patch ends inside a hunk", nameOf(f)))
+		p.oldLeft, p.newLeft = 0, 0
+	}
+
+	oldPath, newPath := f.oldPath, f.newPath
+	if oldPath == "" {
+		oldPath = f.headerOld
+	}
+	if newPath == "" {
+		newPath = f.headerNew
+	}
+	oldPath, newPath = stripPrefix(oldPath, newPath, f.git)
+	if oldPath == "" && newPath == "" {
+		return // A "diff --git" line with nothing after it
+	}
+
+	stat := FileStat{Additions: f.add, Deletions: f.del, IsBinary: f.binary, Status: f.status}
+	switch {
+	case oldPath == "":
+		stat.Path, stat.Status = normalizeRawPath(newPath), "A"
+	case newPath == "":
+		stat.Path, stat.Status = normalizeRawPath(oldPath), "D"
+	case oldPath != newPath:
+		stat.NewPath = normalizeRawPath(newPath)
+		stat.Path = normalizeRawPath(oldPath) + " => " + stat.NewPath
+		if stat.Status == "" {
+			stat.Status = "R"
+		}
+	default:
+		stat.Path = normalizeRawPath(newPath)
+	}
+	stat.FunctionsChanged = len(f.contexts)
+
+	// Forward literal is the new blob, reverse literal the old one
+	switch {
+	case len(f.literals) == 2:
+		stat.SizeDelta, stat.HasSizeDelta = f.literals[0]-f.literals[1], true
+	case len(f.literals) == 1 && stat.Status == "A":
+		stat.SizeDelta, stat.HasSizeDelta = f.literals[0], true
+	case len(f.literals) == 1 && stat.Status == "D":
+		stat.SizeDelta, stat.HasSizeDelta = -f.literals[0], true
+	}
+
+	p.stats.TotalAdd += stat.Additions
+	p.stats.TotalDel += stat.Deletions
+	if i, ok := p.index[stat.Path]; ok {
+		prev := &p.stats.Files[i]
+		prev.Additions += stat.Additions
+		prev.Deletions += stat.Deletions
+		prev.FunctionsChanged += stat.FunctionsChanged
+		prev.IsBinary = prev.IsBinary || stat.IsBinary
+		return
+	}
+	p.index[stat.Path] = len(p.stats.Files)
+	p.stats.Files = append(p.stats.Files, stat)
+}
+
+// name returns the current file's path for warnings.
+func (p *patchParser) name() string {
+	if p.file == nil {
+		return "patch"
+	}
+	return nameOf(p.file)
+}
+
+func nameOf(f *patchFile) string {
+	for _, name := range []string{f.newPath, f.oldPath, f.headerNew, f.headerOld} {
+		if name != "" && name != devNull {
+			return SanitizeText(name)
+		}
+	}
+	return "patch"
+}
+
+// devNull stands for the missing side of an added or deleted file.
+const devNull = "/dev/null"
+
+// patchPath returns the path of a ---/+++ line: unquoted, without the
+// timestamp diff -u appends after a tab.
+func patchPath(s string) string {
+	if strings.HasPrefix(s, `"`) {
+		if end := strings.LastIndex(s, `"`); end > 0 {
+			return unquotePath(s[:end+1])
+		}
+	}
+	path, _, _ := strings.Cut(s, "\t")
+	return path
+}
+
+// splitGitHeader splits the "a/X b/Y" of a "diff --git" line. Unquoted
+// paths with spaces are only split when both sides name the same file;
+// otherwise the ---/+++ or rename lines supply the paths.
+func splitGitHeader(s string) (oldPath, newPath string) {
+	if strings.HasPrefix(s, `"`) {
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+			} else if s[i] == '"' {
+				return unquotePath(s[:i+1]), unquotePath(strings.TrimPrefix(s[i+1:], " "))
+			}
+		}
+		return "", ""
+	}
+	if strings.HasSuffix(s, `"`) {
+		if i := strings.LastIndex(s, ` "`); i >= 0 {
+			return s[:i], unquotePath(s[i+1:])
+		}
+	}
+	if len(s)%2 == 1 {
+		half := (len(s) - 1) / 2
+		a, b := s[:half], s[half+1:]
+		_, aRest, aOK := strings.Cut(a, "/")
+		_, bRest, bOK := strings.Cut(b, "/")
+		if s[half] == ' ' && aOK && bOK && aRest == bRest {
+			return a, b
+		}
+	}
+	return "", ""
+}
+
+// stripPrefix removes the first path component ("a/", "b/") as git apply
+// -p1 does: always for git patches, and for other diffs when both sides
+// have one and it differs (diff -ru old/ new/). "/dev/null" becomes "".
+func stripPrefix(oldPath, newPath string, git bool) (string, string) {
+	if oldPath == devNull {
+		oldPath = ""
+	}
+	if newPath == devNull {
+		newPath = ""
+	}
+	oldDir, oldRest, oldOK := strings.Cut(oldPath, "/")
+	newDir, newRest, newOK := strings.Cut(newPath, "/")
+	switch {
+	case git:
+		if oldOK {
+			oldPath = oldRest
+		}
+		if newOK {
+			newPath = newRest
+		}
+	case oldOK && newOK && oldDir != newDir:
+		oldPath, newPath = oldRest, newRest
+	case oldPath == "" && newOK:
+		newPath = newRest
+	case newPath == "" && oldOK:
+		oldPath = oldRest
+	}
+	return oldPath, newPath
+}
+
+// hunkLengths parses the line counts of "@@ -a,b +c,d @@"; a missing count
+// is 1.
+func hunkLengths(header string) (oldLen, newLen int, ok bool) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, false
+	}
+	oldLen, ok1 := rangeLength(fields[1][1:])
+	newLen, ok2 := rangeLength(fields[2][1:])
+	return oldLen, newLen, ok1 && ok2
+}
+
+// rangeLength returns the length of a hunk range "start,len" or "start".
+func rangeLength(r string) (int, bool) {
+	start, length, found := strings.Cut(r, ",")
+	if _, err := strconv.Atoi(start); err != nil {
+		return 0, false
+	}
+	if !found {
+		return 1, true
+	}
+	n, err := strconv.Atoi(length)
+	return n, err == nil && n >= 0
+}
@@ -0,0 +1,171 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		want         []FileStat
+		wantWarnings int
+	}{
+		{
+			name:  "empty input",
+			input: "",
+		},
+		{
+			name: "git diff",
+			input: `diff --git a/src/main.go b/src/main.go
+index 1111111..2222222 100644
+--- a/src/main.go
++++ b/src/main.go
+@@ -1,3 +1,4 @@ package main
+ package main
+-var x = 1
++var x = 2
++var y = 3
+
+@@ -10,2 +11,2 @@ func run() {
+-	old()
++	new()
+ }
+`,
+			want: []FileStat{{Path: "src/main.go", Additions: 3, Deletions: 2, FunctionsChanged: 2}},
+		},
+		{
+			name: "format-patch mailbox with diffstat and signature",
+			input: `From 0123456789abcdef Mon Sep 17 00:00:00 2001
+From: Dev <dev@example.com>
+Subject: [PATCH 1/2] Add notes
+
+--- a/not/a/file
+---
+ docs/notes.md | 2 ++
+ 1 file changed, 2 insertions(+)
+
+diff --git a/docs/notes.md b/docs/notes.md
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/docs/notes.md
+@@ -0,0 +1,2 @@
++# Notes
++first
+--
+2.40.0
+
+From fedcba9876543210 Mon Sep 17 00:00:00 2001
+Subject: [PATCH 2/2] Extend notes
+
+diff --git a/docs/notes.md b/docs/notes.md
+--- a/docs/notes.md
++++ b/docs/notes.md
+@@ -2 +2,2 @@
+-first
++second
++third
+--
+2.40.0
+`,
+			want: []FileStat{{Path: "docs/notes.md", Status: "A", Additions: 4, Deletions: 1}},
+		},
+		{
+			name: "rename, deletion, and binary",
+			input: `diff --git a/old name.go b/new name.go
+similarity index 90%
+rename from old name.go
+rename to new name.go
+--- a/old name.go
++++ b/new name.go
+@@ -1 +1 @@
+-a
++b
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+--- a/gone.txt
++++ /dev/null
+@@ -1,2 +0,0 @@
+-x
+-y
+diff --git a/logo.png b/logo.png
+index 4444444..5555555 100644
+GIT binary patch
+literal 120
+zcmV
+
+literal 100
+zcmV
+
+diff --git a/icon.png b/icon.png
+new file mode 100644
+Binary files /dev/null and b/icon.png differ
+`,
+			want: []FileStat{
+				{Path: "old name.go => new name.go", NewPath: "new name.go", Status: "R", Additions: 1, Deletions: 1},
+				{Path: "gone.txt", Status: "D", Deletions: 2},
+				{Path: "logo.png", IsBinary: true, SizeDelta: 20, HasSizeDelta: true},
+				{Path: "icon.png", Status: "A", IsBinary: true},
+			},
+		},
+		{
+			name: "diff -ru with timestamps",
+			input: "diff -ru old/a.txt new/a.txt\n" +
+				"--- old/a.txt\t2024-01-01 00:00:00.000000000 +0000\n" +
+				"+++ new/a.txt\t2024-01-02 00:00:00.000000000 +0000\n" +
+				"@@ -1,2 +1,2 @@\n one\n-two\n+2\n" +
+				"--- ./b.txt\n+++ ./b.txt\n@@ -1 +1,2 @@\n one\n+two\n",
+			want: []FileStat{
+				{Path: "a.txt", Additions: 1, Deletions: 1},
+				{Path: "b.txt", Additions: 1},
+			},
+		},
+		{
+			name:  "quoted path",
+			input: "diff --git \"a/caf\\303\\251.txt\" \"b/caf\\303\\251.txt\"\n--- \"a/caf\\303\\251.txt\"\n+++ \"b/caf\\303\\251.txt\"\n@@ -1 +1 @@\n-a\n+b\n",
+			want:  []FileStat{{Path: "café.txt", Additions: 1, Deletions: 1}},
+		},
+		{
+			name:         "truncated hunk",
+			input:        "--- a/x.go\n+++ b/x.go\n@@ -1,3 +1,3 @@\n-a\n+b\n",
+			want:         []FileStat{{Path: "x.go", Additions: 1, Deletions: 1}},
+			wantWarnings: 1,
+		},
+		{
+			name:         "hunk cut short by another file",
+			input:        "diff --git a/x.go b/x.go\n--- a/x.go\n+++ b/x.go\n@@ -1,3 +1,3 @@\n-a\n+b\ndiff --git a/y.go b/y.go\n--- a/y.go\n+++ b/y.go\n@@ -1 +1 @@\n-c\n+d\n",
+			want:         []FileStat{{Path: "x.go", Additions: 1, Deletions: 1}, {Path: "y.go", Additions: 1, Deletions: 1}},
+			wantWarnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, warnings, err := ParsePatch(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("warnings = %q, want %d", warnings, tt.wantWarnings)
+			}
+			if len(stats.Files) != len(tt.want) {
+				t.Fatalf("files = %+v, want %+v", stats.Files, tt.want)
+			}
+			var wantAdd, wantDel int
+			for i, want := range tt.want {
+				if got := stats.Files[i]; !reflect.DeepEqual(got, want) {
+					t.Errorf("file %d = %+v, want %+v", i, got, want)
+				}
+				wantAdd += want.Additions
+				wantDel += want.Deletions
+			}
+			if stats.TotalFiles != len(tt.want) || stats.TotalAdd != wantAdd || stats.TotalDel != wantDel {
+				t.Errorf("totals = %d files +%d -%d, want %d files +%d -%d",
+					stats.TotalFiles, stats.TotalAdd, stats.TotalDel, len(tt.want), wantAdd, wantDel)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package diff
+
+import (
+	"strings"
+)
+
+// ProvenanceJSON records how stats were computed so downstream systems can
+// audit and reproduce them.
+type ProvenanceJSON struct {
+	Commands   []string          `json:"commands,omitempty"`   // Git invocations, in order
+	GitVersion string            `json:"gitVersion,omitempty"` // e.g., "2.43.0"
+	RepoRoot   string            `json:"repoRoot,omitempty"`
+	Refs       map[string]string `json:"refs,omitempty"` // Revision as given -> object SHA
+}
+
+// Commands returns the git command lines the package-level functions have
+// run, oldest first.
+func Commands() []string {
+	return defaultClient.Commands()
+}
+
+// Commands returns the git command lines the client has run, oldest first,
+// or nil unless Options.RecordCommands is set.
+func (c *Client) Commands() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.commands...)
+}
+
+// Provenance describes the stats computed so far with the package-level
+// functions; see Client.Provenance.
+func Provenance(args ...string) ProvenanceJSON {
+	return defaultClient.Provenance(args...)
+}
+
+// Provenance describes the stats computed so far: the git commands run (not
+// counting its own lookups), git's version, the repository root, and the
+// SHA each revision in the git diff args resolved to. A working tree diff
+// (no revisions) records HEAD. Lookups that fail leave their field empty.
+func (c *Client) Provenance(args ...string) ProvenanceJSON {
+	p := ProvenanceJSON{Commands: c.Commands()}
+
+	if out, err := c.output("version"); err == nil {
+		p.GitVersion = strings.TrimPrefix(strings.TrimSpace(string(out)), "git version ")
+	}
+	if out, err := c.output("rev-parse", "--show-toplevel"); err == nil {
+		p.RepoRoot = strings.TrimSpace(string(out))
+	}
+
+	revs := diffRevisions(args)
+	if len(revs) == 0 {
+		revs = []string{"HEAD"}
+	}
+	for _, rev := range revs {
+		out, err := c.output("rev-parse", "--verify", "--quiet", "--end-of-options", rev+"^{object}")
+		if err != nil {
+			continue
+		}
+		if p.Refs == nil {
+			p.Refs = make(map[string]string)
+		}
+		p.Refs[rev] = strings.TrimSpace(string(out))
+	}
+	return p
+}
+
+// diffRevisions returns the revisions named in git diff args: flags and
+// paths after "--" are skipped, and ranges are split, an empty side meaning
+// HEAD.
+func diffRevisions(args []string) []string {
+	var revs []string
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		for _, rev := range splitRange(arg) {
+			if rev == "" {
+				rev = "HEAD"
+			}
+			revs = append(revs, rev)
+		}
+	}
+	return revs
+}
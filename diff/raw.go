@@ -0,0 +1,189 @@
+package diff
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// zeroOID is the object name git --raw prints for an absent or
+// not-yet-hashed (working tree) side of a change.
+const zeroOID = "0000000000000000000000000000000000000000"
+
+// RawChange is one entry of git diff --raw output.
+type RawChange struct {
+	Status  string // Status letter: A, C, D, M, R, T, or U
+	OldPath string // Pre-image path
+	Path    string // Post-image path (differs from OldPath for renames and copies)
+	OldOID  string // Pre-image blob (zeroOID when absent)
+	NewOID  string // Post-image blob (zeroOID when absent or in the working tree)
+}
+
+// AddChangeDetails sets FileStat.Status, NewPath, and (for binary files)
+// SizeDelta from git diff --raw. Args are passed to git diff the same way as
+// GetDiffStats. Untracked files get status "A".
+func AddChangeDetails(stats *DiffStats, args ...string) ([]string, error) {
+	return defaultClient.AddChangeDetails(stats, args...)
+}
+
+// AddChangeDetails matches each numstat entry with its raw entry. Binary blob
+// sizes come from git cat-file, or the file on disk for the working tree side;
+// a size that cannot be read is a warning and leaves SizeDelta unset.
+func (c *Client) AddChangeDetails(stats *DiffStats, args ...string) ([]string, error) {
+	return c.addChangeDetails(stats, false, args)
+}
+
+// AddSizeDeltas is AddChangeDetails with SizeDelta set for text files too,
+// for measuring a diff in bytes (see DiffStats.InBytes).
+func AddSizeDeltas(stats *DiffStats, args ...string) ([]string, error) {
+	return defaultClient.AddSizeDeltas(stats, args...)
+}
+
+// AddSizeDeltas reads every file's blob sizes, as AddChangeDetails does for
+// binary files.
+func (c *Client) AddSizeDeltas(stats *DiffStats, args ...string) ([]string, error) {
+	return c.addChangeDetails(stats, true, args)
+}
+
+// addChangeDetails implements AddChangeDetails, sizing text files as well
+// when allSizes is set.
+func (c *Client) addChangeDetails(stats *DiffStats, allSizes bool, args []string) ([]string, error) {
+	var warnings []string
+	cmdArgs := append(append([]string{"diff", "--raw", "-z", "--no-abbrev"}, c.relativeArgs()...), args...)
+	output, err := c.output(cmdArgs...)
+	if err != nil {
+		return c.fail(err, warnings)
+	}
+
+	changes, parseWarnings := ParseRaw(string(output))
+	warnings = append(warnings, parseWarnings...)
+	// Numstat paths are normalized; -z output is raw
+	byPath := make(map[string]RawChange, len(changes))
+	for _, ch := range changes {
+		byPath[normalizeRawPath(ch.Path)] = ch
+	}
+
+	for i := range stats.Files {
+		f := &stats.Files[i]
+		if f.IsUntracked {
+			f.Status = "A"
+			if f.IsBinary || allSizes {
+				if size, err := c.worktreeSize(f.Path); err == nil {
+					f.SizeDelta, f.HasSizeDelta = size, true
+				}
+			}
+			continue
+		}
+
+		newPath, renamed := RenameTarget(f.Path)
+		ch, ok := byPath[newPath]
+		if !ok {
+			continue
+		}
+		f.Status = ch.Status
+		if renamed {
+			f.NewPath = newPath
+		}
+		if !f.IsBinary && !allSizes {
+			continue
+		}
+		oldSize, err := c.blobSize(ch.OldOID, "")
+		if err == nil {
+			var newSize int64
+			newSize, err = c.blobSize(ch.NewOID, newPathIfPresent(ch))
+			f.SizeDelta, f.HasSizeDelta = newSize-oldSize, err == nil
+		}
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("size of %s: %v", normalizeRawPath(ch.Path), err))
+		}
+	}
+	return warnings, nil
+}
+
+// newPathIfPresent returns the working tree path to stat when the post-image
+// has no blob yet, or "" when the post-image is absent (deletions).
+func newPathIfPresent(ch RawChange) string {
+	if ch.Status == "D" {
+		return ""
+	}
+	return ch.Path
+}
+
+// blobSize returns the size of blob oid. A zero oid is read from worktreePath
+// on disk, or counts as 0 bytes when worktreePath is empty.
+func (c *Client) blobSize(oid, worktreePath string) (int64, error) {
+	if oid == zeroOID || oid == "" {
+		if worktreePath == "" {
+			return 0, nil
+		}
+		return c.worktreeSize(worktreePath)
+	}
+	out, err := c.output("cat-file", "-s", oid)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+}
+
+// worktreeSize returns the size in bytes of a repo-relative file.
+func (c *Client) worktreeSize(path string) (int64, error) {
+	info, err := os.Stat(c.worktreePath(path))
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// ParseRaw parses git diff --raw -z output.
+// Entry format: ":oldmode newmode oldoid newoid STATUS\0path\0" with a second
+// path for renames and copies (status R### or C###).
+// Returns warnings for malformed entries (fail-open: skips them).
+func ParseRaw(output string) ([]RawChange, []string) {
+	var changes []RawChange
+	var warnings []string
+
+	fields := strings.Split(output, "\x00")
+	for i := 0; i < len(fields); i++ {
+		header := fields[i]
+		if header == "" {
+			continue
+		}
+		meta := strings.Fields(strings.TrimPrefix(header, ":"))
+		if !strings.HasPrefix(header, ":") || len(meta) != 5 || i+1 >= len(fields) {
+			warnings = append(warnings, fmt.Sprintf("malformed raw diff entry: %q", header))
+			continue
+		}
+
+		ch := RawChange{Status: meta[4][:1], OldOID: meta[2], NewOID: meta[3]}
+		i++
+		ch.OldPath, ch.Path = fields[i], fields[i]
+		if (ch.Status == "R" || ch.Status == "C") && i+1 < len(fields) {
+			i++
+			ch.Path = fields[i]
+		}
+		changes = append(changes, ch)
+	}
+	return changes, warnings
+}
+
+// RenameTarget returns the post-image path of a numstat rename path, which
+// git prints as "old => new" or with a shared prefix and suffix as
+// "src/{old => new}/file.go". ok is false for paths that are not renames.
+func RenameTarget(path string) (newPath string, ok bool) {
+	if open := strings.Index(path, "{"); open >= 0 {
+		if arrow := strings.Index(path[open:], " => "); arrow >= 0 {
+			if end := strings.Index(path[open+arrow:], "}"); end >= 0 {
+				prefix := path[:open]
+				middle := path[open+arrow+len(" => ") : open+arrow+end]
+				suffix := path[open+arrow+end+1:]
+				// "{ => dir}/" style empty sides leave a doubled slash
+				return strings.TrimPrefix(strings.ReplaceAll(prefix+middle+suffix, "//", "/"), "/"), true
+			}
+		}
+	}
+	if _, after, found := strings.Cut(path, " => "); found {
+		return after, true
+	}
+	return path, false
+}
@@ -0,0 +1,80 @@
+package diff
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseRaw(t *testing.T) {
+	oid := "1111111111111111111111111111111111111111"
+	output := ":100644 100644 " + oid + " " + zeroOID + " M\x00a.go\x00" +
+		":100644 100644 " + oid + " " + oid + " R086\x00old/b.go\x00new/b.go\x00" +
+		":000000 100644 " + zeroOID + " " + oid + " A\x00c.png\x00" +
+		"garbage\x00"
+
+	changes, warnings := ParseRaw(output)
+	want := []RawChange{
+		{Status: "M", OldPath: "a.go", Path: "a.go", OldOID: oid, NewOID: zeroOID},
+		{Status: "R", OldPath: "old/b.go", Path: "new/b.go", OldOID: oid, NewOID: oid},
+		{Status: "A", OldPath: "c.png", Path: "c.png", OldOID: zeroOID, NewOID: oid},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("ParseRaw() =\n%+v\nwant\n%+v", changes, want)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %v, want one for the malformed entry", warnings)
+	}
+}
+
+func TestRenameTarget(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		renamed bool
+	}{
+		{"src/main.go", "src/main.go", false},
+		{"old.go => new.go", "new.go", true},
+		{"src/{old => new}/f.go", "src/new/f.go", true},
+		{"src/{ => sub}/f.go", "src/sub/f.go", true},
+		{"src/{sub => }/f.go", "src/f.go", true},
+		{"{a => b}/f.go", "b/f.go", true},
+	}
+
+	for _, tt := range tests {
+		got, renamed := RenameTarget(tt.path)
+		if got != tt.want || renamed != tt.renamed {
+			t.Errorf("RenameTarget(%q) = (%q, %v), want (%q, %v)", tt.path, got, renamed, tt.want, tt.renamed)
+		}
+	}
+}
+
+func TestClient_AddSizeDeltas(t *testing.T) {
+	client, git := newTestRepo(t)
+	if err := os.WriteFile(client.path("a.txt"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	if err := os.WriteFile(client.path("a.txt"), []byte("hello world\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(client.path("b.txt"), []byte("new\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, _, err := client.GetAllStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnings, err := client.AddSizeDeltas(stats)
+	if err != nil || len(warnings) > 0 {
+		t.Fatalf("AddSizeDeltas: %v %q", err, warnings)
+	}
+	want := map[string]int64{"a.txt": 6, "b.txt": 4}
+	for _, f := range stats.Files {
+		if !f.HasSizeDelta || f.SizeDelta != want[f.Path] {
+			t.Errorf("%s: SizeDelta = %d (computed %v), want %d", f.Path, f.SizeDelta, f.HasSizeDelta, want[f.Path])
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package diff
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// RedactKeyFile is the file in the repository's common git dir holding the
+// key RedactKey returns.
+const RedactKeyFile = "diff-viz-redact-key"
+
+// redactKey is the HMAC key RedactPath hashes names with (see SetRedactKey).
+var (
+	redactMu  sync.Mutex
+	redactKey []byte
+)
+
+// SetRedactKey sets the secret RedactPath hashes names with. Without one,
+// a random key is made on first use: hashes still cannot be reversed by
+// hashing guessed names, but they differ between runs.
+func SetRedactKey(key []byte) {
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	redactKey = append([]byte(nil), key...)
+}
+
+// currentRedactKey returns the key set with SetRedactKey, making a random
+// one if none was set.
+func currentRedactKey() []byte {
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	if redactKey == nil {
+		redactKey = make([]byte, 32)
+		rand.Read(redactKey)
+	}
+	return redactKey
+}
+
+// RedactKey returns the current repository's redaction key; see
+// Client.RedactKey.
+func RedactKey() ([]byte, error) {
+	return defaultClient.RedactKey()
+}
+
+// RedactKey returns the repository's redaction key from RedactKeyFile,
+// creating a random one on first use. It never leaves the machine, so the
+// same repository redacts the same way from run to run while nobody else
+// can recompute its hashes.
+func (c *Client) RedactKey() ([]byte, error) {
+	out, err := c.output("rev-parse", "--git-common-dir")
+	if err != nil {
+		return nil, err
+	}
+	dir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(dir) {
+		dir = c.path(dir)
+	}
+	file := filepath.Join(dir, RedactKeyFile)
+	if key, err := os.ReadFile(file); err == nil && len(bytes.TrimSpace(key)) > 0 {
+		return bytes.TrimSpace(key), nil
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	key := []byte(hex.EncodeToString(raw))
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, fs.ErrExist) {
+		// Another run created it first
+		if key, err = os.ReadFile(file); err != nil {
+			return nil, err
+		}
+		return bytes.TrimSpace(key), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(append(key, '\n')); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return key, f.Close()
+}
+
+// RedactPath replaces each component of p with a short keyed hash of its
+// name (HMAC-SHA256 under the SetRedactKey key), keeping the directory
+// structure and file extension, so "src/api/handler.go" becomes
+// "a4f2c1/9b0e37/5d81fa.go". The same name always hashes the same way under
+// one key, so sibling files still group under one directory.
+// Numstat rename paths keep their "=>" and braces.
+func RedactPath(p string) string {
+	sides := strings.Split(p, " => ")
+	for i, side := range sides {
+		parts := strings.Split(side, "/")
+		for j, part := range parts {
+			parts[j] = redactComponent(part)
+		}
+		sides[i] = strings.Join(parts, "/")
+	}
+	return strings.Join(sides, " => ")
+}
+
+// redactComponent hashes one path component, keeping the braces of a
+// "src/{old => new}" rename and the extension.
+func redactComponent(name string) string {
+	open := strings.HasPrefix(name, "{")
+	name = strings.TrimPrefix(name, "{")
+	closing := strings.HasSuffix(name, "}")
+	name = strings.TrimSuffix(name, "}")
+
+	if name != "" && name != "." && name != ".." {
+		ext := path.Ext(name)
+		if ext == name {
+			ext = "" // Dotfiles like ".env" are all name
+		}
+		name = hashName(name) + ext
+	}
+
+	if open {
+		name = "{" + name
+	}
+	if closing {
+		name += "}"
+	}
+	return name
+}
+
+// RedactName replaces a branch name or revision with a keyed hash like
+// RedactPath's components. Nothing of it is kept: a dot in "v1.2" or
+// "main...topic" is not an extension.
+func RedactName(name string) string {
+	return hashName(name)
+}
+
+// hashName returns a short HMAC-SHA256 of name under the redaction key.
+func hashName(name string) string {
+	mac := hmac.New(sha256.New, currentRedactKey())
+	mac.Write([]byte(name))
+	return hex.EncodeToString(mac.Sum(nil)[:3])
+}
+
+// Redacted returns a copy of the stats with every path passed through
+// RedactPath. The receiver is not modified.
+func (s *DiffStats) Redacted() *DiffStats {
+	result := *s
+	result.Files = make([]FileStat, len(s.Files))
+	for i, f := range s.Files {
+		f.Path = RedactPath(f.Path)
+		if f.NewPath != "" {
+			f.NewPath = RedactPath(f.NewPath)
+		}
+		result.Files[i] = f
+	}
+	return &result
+}
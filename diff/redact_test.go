@@ -0,0 +1,101 @@
+package diff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestRedactPath(t *testing.T) {
+	hash := `[0-9a-f]{6}`
+	tests := []struct {
+		path string
+		want string // Regexp
+	}{
+		{"README.md", `^` + hash + `\.md$`},
+		{"src/api/handler.go", `^` + hash + `/` + hash + `/` + hash + `\.go$`},
+		{".env", `^` + hash + `$`},
+		{"Makefile", `^` + hash + `$`},
+		{"old.go => new.go", `^` + hash + `\.go => ` + hash + `\.go$`},
+		{"src/{old => new}/a.go", `^` + hash + `/\{` + hash + ` => ` + hash + `\}/` + hash + `\.go$`},
+		{"src/{ => lib}/a.go", `^` + hash + `/\{ => ` + hash + `\}/` + hash + `\.go$`},
+	}
+	for _, tt := range tests {
+		got := RedactPath(tt.path)
+		if !regexp.MustCompile(tt.want).MatchString(got) {
+			t.Errorf("RedactPath(%q) = %q, want match for %s", tt.path, got, tt.want)
+		}
+	}
+
+	// Stable: the same component always hashes the same way
+	a, b := RedactPath("src/a.go"), RedactPath("src/b.go")
+	if a[:6] != b[:6] {
+		t.Errorf("src/ redacted differently: %q vs %q", a, b)
+	}
+	if a == b {
+		t.Errorf("distinct files redacted to the same path %q", a)
+	}
+	if RedactPath("src/a.go") != a {
+		t.Error("RedactPath is not deterministic")
+	}
+}
+
+func TestRedactPath_Key(t *testing.T) {
+	t.Cleanup(func() { SetRedactKey(nil) })
+
+	// A plain hash of a guessed name must not match
+	sum := sha256.Sum256([]byte("internal"))
+	SetRedactKey([]byte("one"))
+	one := RedactPath("internal")
+	if one == hex.EncodeToString(sum[:3]) {
+		t.Errorf("RedactPath(internal) = %q, the unkeyed sha256 prefix", one)
+	}
+	SetRedactKey([]byte("two"))
+	if two := RedactPath("internal"); two == one {
+		t.Errorf("RedactPath(internal) = %q under both keys", two)
+	}
+	SetRedactKey([]byte("one"))
+	if again := RedactPath("internal"); again != one {
+		t.Errorf("RedactPath(internal) = %q, then %q under the same key", one, again)
+	}
+
+	// Names keep nothing, not even what looks like an extension
+	if got := RedactName("release/v1.2"); !regexp.MustCompile(`^[0-9a-f]{6}$`).MatchString(got) {
+		t.Errorf("RedactName(release/v1.2) = %q, want one bare hash", got)
+	}
+}
+
+func TestClient_RedactKey(t *testing.T) {
+	client, _ := newTestRepo(t)
+	key, err := client.RedactKey()
+	if err != nil || len(key) != 64 {
+		t.Fatalf("RedactKey() = %q, %v; want 64 hex digits", key, err)
+	}
+	info, err := os.Stat(filepath.Join(client.Dir, ".git", RedactKeyFile))
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("key file = %v, %v; want mode 0600", info, err)
+	}
+	if again, err := client.RedactKey(); err != nil || string(again) != string(key) {
+		t.Errorf("second RedactKey() = %q, %v; want the stored %q", again, err, key)
+	}
+}
+
+func TestDiffStats_Redacted(t *testing.T) {
+	s := &DiffStats{
+		Files:    []FileStat{{Path: "src/a.go", Additions: 3, NewPath: "src/a.go"}},
+		TotalAdd: 3, TotalFiles: 1,
+	}
+	got := s.Redacted()
+	if got.Files[0].Path == "src/a.go" || got.Files[0].NewPath != got.Files[0].Path {
+		t.Errorf("Redacted() file = %+v", got.Files[0])
+	}
+	if got.TotalAdd != 3 || got.TotalFiles != 1 || got.Files[0].Additions != 3 {
+		t.Errorf("Redacted() changed counts: %+v", got)
+	}
+	if s.Files[0].Path != "src/a.go" {
+		t.Errorf("Redacted() modified its receiver: %q", s.Files[0].Path)
+	}
+}
@@ -0,0 +1,70 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RepoInfo identifies the repository a client runs in, for matching
+// per-repository config.
+type RepoInfo struct {
+	Root    string   // Absolute top-level directory, slash-separated
+	Remotes []string // Fetch URLs of the configured remotes
+}
+
+// Repo returns the top-level directory and remote URLs of the current
+// repository.
+func Repo() (RepoInfo, error) {
+	return defaultClient.Repo()
+}
+
+// Repo returns the client's repository identity. Errors wrap ErrNotARepo
+// outside a repository.
+func (c *Client) Repo() (RepoInfo, error) {
+	out, err := c.output("rev-parse", "--show-toplevel")
+	if err != nil {
+		return RepoInfo{}, err
+	}
+	info := RepoInfo{Root: strings.TrimSpace(string(out))}
+
+	out, err = c.output("remote", "-v")
+	if err != nil {
+		return RepoInfo{}, err
+	}
+	info.Remotes = parseRemotes(string(out))
+	return info, nil
+}
+
+// parseRemotes returns the fetch URLs in git remote -v output
+// ("origin\tgit@host:org/repo.git (fetch)").
+func parseRemotes(output string) []string {
+	var urls []string
+	for _, line := range strings.Split(output, "\n") {
+		_, rest, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		if url, ok := strings.CutSuffix(rest, " (fetch)"); ok {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// RepoAbove returns the closest of dir and its ancestors holding a .git
+// entry, or "" if none does. Git stops its own search at filesystem
+// boundaries and GIT_CEILING_DIRECTORIES, so this can find a repository git
+// reported dir is outside of.
+func RepoAbove(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
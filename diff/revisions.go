@@ -0,0 +1,299 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidateRevisions checks that every revision in git diff args resolves.
+// Flags (e.g., "--cached") are skipped, ranges ("a..b", "a...b") are split,
+// and arguments after "--" or naming existing files are treated as paths.
+// Returns an error wrapping ErrBadRevision that names the bad revision and
+// the closest matching refs.
+func ValidateRevisions(args ...string) error {
+	return defaultClient.ValidateRevisions(args...)
+}
+
+// ValidateRevisions checks that every revision in git diff args resolves.
+// Returns ErrNotARepo or ErrGitMissing (wrapped) if git cannot run at all.
+func (c *Client) ValidateRevisions(args ...string) error {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		for _, rev := range splitRange(arg) {
+			if rev == "" {
+				continue
+			}
+			exists, err := c.revisionExists(rev)
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+			// git diff accepts bare paths; don't reject them as revisions
+			if _, err := os.Stat(arg); err == nil {
+				break
+			}
+			return c.badRevisionError(rev)
+		}
+	}
+	return nil
+}
+
+// ResolveRef resolves a revision (e.g., "@{upstream}", "stash@{2}") to a commit SHA.
+// Returns git's error message when the revision cannot be resolved.
+func ResolveRef(rev string) (string, error) {
+	return defaultClient.ResolveRef(rev)
+}
+
+// ResolveRef resolves a revision to a commit SHA.
+// Errors wrap ErrBadRevision unless git itself could not run.
+func (c *Client) ResolveRef(rev string) (string, error) {
+	return c.resolve(rev, "commit")
+}
+
+// ResolveTree resolves a tree-ish (branch, tag, commit, or tree SHA) to the
+// SHA of its tree. Returns git's error message when it cannot be resolved.
+func ResolveTree(rev string) (string, error) {
+	return defaultClient.ResolveTree(rev)
+}
+
+// ResolveTree resolves a tree-ish to a tree SHA.
+// Errors wrap ErrBadRevision unless git itself could not run.
+func (c *Client) ResolveTree(rev string) (string, error) {
+	return c.resolve(rev, "tree")
+}
+
+// resolve peels rev to an object of the given type ("commit", "tree").
+// Without --quiet, git explains failures like a missing upstream or stash
+// entry; its generic "Needed a single revision" is left out.
+func (c *Client) resolve(rev, objType string) (string, error) {
+	out, err := c.output("rev-parse", "--verify", "--end-of-options", rev+"^{"+objType+"}")
+	if err != nil {
+		var gitErr *GitError
+		if errors.As(err, &gitErr) && gitErr.Kind != nil && gitErr.Kind != ErrBadRevision {
+			return "", err
+		}
+		if errors.As(err, &gitErr) {
+			reason := strings.TrimPrefix(gitErr.Stderr, "fatal: ")
+			if reason != "" && reason != "Needed a single revision" {
+				return "", fmt.Errorf("cannot resolve %s: %s: %w", rev, reason, ErrBadRevision)
+			}
+		}
+		return "", fmt.Errorf("cannot resolve %s: %w", rev, ErrBadRevision)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// HasHead reports whether HEAD resolves to a commit. It is false in a new
+// repository before the first commit.
+func HasHead() (bool, error) {
+	return defaultClient.HasHead()
+}
+
+// HasHead reports whether HEAD resolves to a commit.
+// Returns an error only when git cannot run (missing binary, not a repo).
+func (c *Client) HasHead() (bool, error) {
+	return c.revisionExists("HEAD")
+}
+
+// LatestTag returns the most recent tag reachable from HEAD matching glob
+// (e.g., "v*"; empty matches any tag).
+func LatestTag(glob string) (string, error) {
+	return defaultClient.LatestTag(glob)
+}
+
+// LatestTag returns the most recent tag reachable from HEAD matching glob
+// (e.g., "v*"; empty matches any tag). Errors wrap ErrBadRevision when no
+// tag matches.
+func (c *Client) LatestTag(glob string) (string, error) {
+	args := []string{"describe", "--tags", "--abbrev=0"}
+	if glob != "" {
+		args = append(args, "--match", glob)
+	}
+	out, err := c.output(append(args, "HEAD")...)
+	if err != nil {
+		var gitErr *GitError
+		if errors.As(err, &gitErr) && (gitErr.Kind == ErrNotARepo || gitErr.Kind == ErrGitMissing) {
+			return "", err
+		}
+		if glob == "" {
+			return "", fmt.Errorf("no tag reachable from HEAD: %w", ErrBadRevision)
+		}
+		return "", fmt.Errorf("no tag matching %q reachable from HEAD: %w", glob, ErrBadRevision)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// StashArgs returns git diff args comparing stash entry n against its base commit.
+func StashArgs(n int) ([]string, error) {
+	return defaultClient.StashArgs(n)
+}
+
+// StashArgs returns git diff args comparing stash entry n against its base commit.
+func (c *Client) StashArgs(n int) ([]string, error) {
+	ref := fmt.Sprintf("stash@{%d}", n)
+	stash, err := c.ResolveRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	base, err := c.ResolveRef(ref + "^1")
+	if err != nil {
+		return nil, err
+	}
+	return []string{base, stash}, nil
+}
+
+// CommitCount returns how many commits the git diff args span; see
+// Client.CommitCount.
+func CommitCount(args ...string) (n int, ok bool, err error) {
+	return defaultClient.CommitCount(args...)
+}
+
+// CommitCount returns how many commits the git diff args span: those in b
+// but not a for "a..b" and "a b", those since the merge base for "a...b",
+// and those from a to HEAD for a single revision (diffed against the working
+// tree). ok is false when args name no revision, as in a working tree or
+// --cached diff.
+func (c *Client) CommitCount(args ...string) (n int, ok bool, err error) {
+	var revs []string
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			revs = append(revs, arg)
+		}
+	}
+
+	countArgs := []string{"rev-list", "--count"}
+	switch {
+	case len(revs) == 1 && strings.Contains(revs[0], "..."):
+		countArgs = append(countArgs, "--right-only", revs[0])
+	case len(revs) == 1 && strings.Contains(revs[0], ".."):
+		countArgs = append(countArgs, revs[0])
+	case len(revs) == 1:
+		countArgs = append(countArgs, revs[0]+"..HEAD")
+	case len(revs) == 2:
+		countArgs = append(countArgs, revs[0]+".."+revs[1])
+	default:
+		return 0, false, nil
+	}
+
+	out, err := c.output(countArgs...)
+	if err != nil {
+		return 0, true, err
+	}
+	n, err = strconv.Atoi(strings.TrimSpace(string(out)))
+	return n, true, err
+}
+
+// splitRange splits "a..b" or "a...b" into its endpoints.
+// Non-range arguments are returned as a single-element slice.
+func splitRange(arg string) []string {
+	if before, after, ok := strings.Cut(arg, "..."); ok {
+		return []string{before, after}
+	}
+	if before, after, ok := strings.Cut(arg, ".."); ok {
+		return []string{before, after}
+	}
+	return []string{arg}
+}
+
+// revisionExists reports whether git can resolve rev to an object.
+// Returns an error only when git cannot run (missing binary, not a repo).
+func (c *Client) revisionExists(rev string) (bool, error) {
+	_, err := c.output("rev-parse", "--verify", "--quiet", rev+"^{object}")
+	if err == nil {
+		return true, nil
+	}
+	var gitErr *GitError
+	if errors.As(err, &gitErr) && (gitErr.Kind == ErrNotARepo || gitErr.Kind == ErrGitMissing) {
+		return false, err
+	}
+	return false, nil
+}
+
+// badRevisionError builds an error for rev with "did you mean" suggestions.
+func (c *Client) badRevisionError(rev string) error {
+	suggestions := NearestRefs(rev, c.listRefs(), 3)
+	if len(suggestions) == 0 {
+		return fmt.Errorf("%w %q", ErrBadRevision, rev)
+	}
+	return fmt.Errorf("%w %q (did you mean: %s?)", ErrBadRevision, rev, strings.Join(suggestions, ", "))
+}
+
+// listRefs returns short names of local branches, remote branches, and tags.
+// Returns nil if git fails (suggestions are best-effort).
+func (c *Client) listRefs() []string {
+	out, err := c.output("for-each-ref", "--format=%(refname:short)",
+		"refs/heads", "refs/remotes", "refs/tags")
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(out))
+}
+
+// NearestRefs returns up to limit candidates closest to name by edit distance.
+// Only reasonably close matches are returned (distance <= max(2, len(name)/3)).
+func NearestRefs(name string, candidates []string, limit int) []string {
+	type match struct {
+		ref  string
+		dist int
+	}
+
+	maxDist := max(2, len(name)/3)
+	var matches []match
+	for _, c := range candidates {
+		// Compare against the branch part of remote refs too (origin/main -> main)
+		d := levenshtein(name, c)
+		if idx := strings.LastIndex(c, "/"); idx >= 0 {
+			d = min(d, levenshtein(name, c[idx+1:]))
+		}
+		if d <= maxDist {
+			matches = append(matches, match{c, d})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].dist < matches[j].dist
+	})
+
+	var result []string
+	for i := 0; i < len(matches) && i < limit; i++ {
+		result = append(result, matches[i].ref)
+	}
+	return result
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
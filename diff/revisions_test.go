@@ -0,0 +1,152 @@
+package diff
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitRange(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want []string
+	}{
+		{"main", []string{"main"}},
+		{"main..feature", []string{"main", "feature"}},
+		{"main...feature", []string{"main", "feature"}},
+		{"HEAD~3..", []string{"HEAD~3", ""}},
+	}
+
+	for _, tt := range tests {
+		got := splitRange(tt.arg)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitRange(%q) = %q, want %q", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestNearestRefs(t *testing.T) {
+	refs := []string{"main", "maint", "feature", "origin/main", "v1.0.0"}
+
+	got := NearestRefs("mian", refs, 3)
+	if len(got) == 0 || got[0] != "main" {
+		t.Errorf("NearestRefs(mian) = %q, want main first", got)
+	}
+
+	if got := NearestRefs("completely-unrelated", refs, 3); len(got) != 0 {
+		t.Errorf("NearestRefs(unrelated) = %q, want none", got)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"main", "main", 0},
+		{"mian", "main", 2},
+		{"main", "maint", 1},
+		{"", "abc", 3},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLatestTag(t *testing.T) {
+	c, git := newTestRepo(t)
+	git("commit", "-q", "--allow-empty", "-m", "one")
+	git("tag", "v1.0.0")
+	git("commit", "-q", "--allow-empty", "-m", "two")
+	git("tag", "nightly")
+	git("commit", "-q", "--allow-empty", "-m", "three")
+
+	tests := []struct {
+		glob string
+		want string
+	}{
+		{"v*", "v1.0.0"},
+		{"", "nightly"}, // Any tag: the nearest wins
+	}
+	for _, tt := range tests {
+		got, err := c.LatestTag(tt.glob)
+		if err != nil || got != tt.want {
+			t.Errorf("LatestTag(%q) = %q, %v; want %q", tt.glob, got, err, tt.want)
+		}
+	}
+
+	if _, err := c.LatestTag("release-*"); !errors.Is(err, ErrBadRevision) {
+		t.Errorf("LatestTag(no match) err = %v, want ErrBadRevision", err)
+	}
+}
+
+func TestClient_ResolveRef(t *testing.T) {
+	c, git := newTestRepo(t)
+	git("commit", "-q", "--allow-empty", "-m", "one")
+	git("branch", "base")
+
+	head, err := c.ResolveRef("HEAD")
+	if err != nil || len(head) != 40 {
+		t.Fatalf("ResolveRef(HEAD) = %q, %v; want a SHA", head, err)
+	}
+
+	tests := []struct {
+		rev     string
+		wantErr string // Substring of the error; "" for success
+	}{
+		{"base", ""},
+		{"base~0", ""},
+		{"nosuch", "cannot resolve nosuch: unknown revision"}, // No "Needed a single revision"
+		{"@{upstream}", "no upstream configured for branch"},
+		{"stash@{0}", "cannot resolve stash@{0}"},
+	}
+	for _, tt := range tests {
+		got, err := c.ResolveRef(tt.rev)
+		if tt.wantErr == "" {
+			if err != nil || got != head {
+				t.Errorf("ResolveRef(%q) = %q, %v; want %q", tt.rev, got, err, head)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrBadRevision) || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ResolveRef(%q) err = %v, want ErrBadRevision containing %q", tt.rev, err, tt.wantErr)
+		}
+	}
+
+	git("branch", "--set-upstream-to=base")
+	if got, err := c.ResolveRef("@{upstream}"); err != nil || got != head {
+		t.Errorf("ResolveRef(@{upstream}) = %q, %v; want %q", got, err, head)
+	}
+}
+
+func TestClient_StashArgs(t *testing.T) {
+	c, git := newTestRepo(t)
+	if err := os.WriteFile(c.path("a.txt"), []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "one")
+	if err := os.WriteFile(c.path("a.txt"), []byte("two\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("stash", "-q")
+
+	base, _ := c.ResolveRef("HEAD")
+	stash, _ := c.ResolveRef("stash@{0}")
+	got, err := c.StashArgs(0)
+	if err != nil || !reflect.DeepEqual(got, []string{base, stash}) {
+		t.Errorf("StashArgs(0) = %q, %v; want %q", got, err, []string{base, stash})
+	}
+
+	// Git's reason survives in the error
+	_, err = c.StashArgs(3)
+	if !errors.Is(err, ErrBadRevision) || !strings.Contains(err.Error(), "only has 1 entries") {
+		t.Errorf("StashArgs(3) err = %v, want ErrBadRevision with git's reason", err)
+	}
+}
@@ -0,0 +1,97 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// SanitizeText escapes the characters in s that a terminal could act on, so
+// a file name or commit subject cannot inject escape sequences into output:
+// C0 and C1 control characters, DEL, and bytes that are not valid UTF-8
+// become git-style escapes ("\t", "\033"). Text without them is returned
+// unchanged, so paths git already quoted pass through as they are.
+func SanitizeText(s string) string {
+	if !hasControl(s) {
+		return s
+	}
+	var sb strings.Builder
+	writeEscaped(&sb, s, false)
+	return sb.String()
+}
+
+// QuotePath returns p the way git quotes unusual paths in diff output: in
+// double quotes with control characters, quotes, and backslashes escaped
+// ("ren\033[32mgreen" for a name holding ESC). Other paths are returned
+// unchanged.
+func QuotePath(p string) string {
+	if !hasControl(p) && !strings.ContainsAny(p, `"\`) {
+		return p
+	}
+	var sb strings.Builder
+	sb.WriteByte('"')
+	writeEscaped(&sb, p, true)
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// Sanitized returns a copy of the stats with every path passed through
+// SanitizeText, for stats built from sources other than this package's
+// parsers. The receiver is not modified.
+func (s *DiffStats) Sanitized() *DiffStats {
+	result := *s
+	result.Files = make([]FileStat, len(s.Files))
+	for i, f := range s.Files {
+		f.Path = SanitizeText(f.Path)
+		f.NewPath = SanitizeText(f.NewPath)
+		result.Files[i] = f
+	}
+	return &result
+}
+
+// hasControl reports whether s holds anything SanitizeText escapes.
+func hasControl(s string) bool {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if isControl(r, size) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+// isControl reports whether a decoded rune (size bytes long) is a control
+// character or an invalid byte.
+func isControl(r rune, size int) bool {
+	return (r == utf8.RuneError && size == 1) || r < 0x20 || r == 0x7f || (r >= 0x80 && r <= 0x9f)
+}
+
+// controlEscapes are the short forms git uses for common control bytes.
+var controlEscapes = map[byte]string{
+	'\a': `\a`, '\b': `\b`, '\t': `\t`, '\n': `\n`, '\v': `\v`, '\f': `\f`, '\r': `\r`,
+}
+
+// writeEscaped writes s with control characters escaped, and with quotes
+// and backslashes escaped too when quoted is set.
+func writeEscaped(sb *strings.Builder, s string, quoted bool) {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case isControl(r, size):
+			for _, b := range []byte(s[i : i+size]) {
+				if esc, ok := controlEscapes[b]; ok {
+					sb.WriteString(esc)
+				} else {
+					fmt.Fprintf(sb, `\%03o`, b)
+				}
+			}
+		case quoted && (r == '"' || r == '\\'):
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		default:
+			sb.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+}
@@ -0,0 +1,49 @@
+package diff
+
+import "testing"
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"src/main.go", "src/main.go"},
+		{"naïve/日本.go", "naïve/日本.go"},
+		{"evil\x1b[31mred", `evil\033[31mred`},
+		{"tab\there\nline", `tab\there\nline`},
+		{"del\x7f", `del\177`},
+		{"c1\u009b31m", `c1\302\23331m`},
+		{"bad\xffbyte", `bad\377byte`},
+		{`"already\033quoted"`, `"already\033quoted"`},
+	}
+	for _, tt := range tests {
+		if got := SanitizeText(tt.in); got != tt.want {
+			t.Errorf("SanitizeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQuotePath(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"src/main.go", "src/main.go"},
+		{"ren\x1b[32mgreen", `"ren\033[32mgreen"`},
+		{`back\slash`, `"back\\slash"`},
+		{`say "hi"`, `"say \"hi\""`},
+	}
+	for _, tt := range tests {
+		if got := QuotePath(tt.in); got != tt.want {
+			t.Errorf("QuotePath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseNumstat_Sanitizes(t *testing.T) {
+	stats, _, err := ParseNumstat("1\t0\tevil\x1b]0;title\x07.go\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stats.Files[0].Path, `evil\033]0;title\a.go`; got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+}
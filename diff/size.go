@@ -0,0 +1,84 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SizeClass labels the overall magnitude of a diff (XS through XL).
+type SizeClass string
+
+const (
+	SizeXS SizeClass = "XS"
+	SizeS  SizeClass = "S"
+	SizeM  SizeClass = "M"
+	SizeL  SizeClass = "L"
+	SizeXL SizeClass = "XL"
+)
+
+// SizeClasses lists all size classes from smallest to largest.
+var SizeClasses = []SizeClass{SizeXS, SizeS, SizeM, SizeL, SizeXL}
+
+// SizeThreshold maps a minimum changed-line count to a size class.
+type SizeThreshold struct {
+	MinLines int       // Minimum total changes (adds + dels) required
+	Class    SizeClass // Label applied at or above MinLines
+}
+
+// DefaultSizeThresholds follows the common PR size labeler buckets.
+// Ordered descending so first match wins.
+var DefaultSizeThresholds = []SizeThreshold{
+	{500, SizeXL}, {100, SizeL}, {30, SizeM}, {10, SizeS}, {0, SizeXS},
+}
+
+// MinLines returns the changed-line count where class starts, or 0 for a
+// class thresholds lack. Nil thresholds uses defaults.
+func MinLines(class SizeClass, thresholds []SizeThreshold) int {
+	if thresholds == nil {
+		thresholds = DefaultSizeThresholds
+	}
+	for _, t := range thresholds {
+		if t.Class == class {
+			return t.MinLines
+		}
+	}
+	return 0
+}
+
+// Classify returns the size class for a changed-line count.
+// Thresholds must be ordered descending by MinLines; nil uses defaults.
+func Classify(lines int, thresholds []SizeThreshold) SizeClass {
+	if thresholds == nil {
+		thresholds = DefaultSizeThresholds
+	}
+	for _, t := range thresholds {
+		if lines >= t.MinLines {
+			return t.Class
+		}
+	}
+	return SizeXS
+}
+
+// SizeClass returns the size class of the diff using the given thresholds.
+func (s *DiffStats) SizeClass(thresholds []SizeThreshold) SizeClass {
+	return Classify(s.TotalAdd+s.TotalDel, thresholds)
+}
+
+// Rank returns the position of c in SizeClasses (XS=0 ... XL=4), or -1 if unknown.
+func (c SizeClass) Rank() int {
+	for i, sc := range SizeClasses {
+		if sc == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// ParseSizeClass parses a size label case-insensitively (e.g., "l" -> SizeL).
+func ParseSizeClass(s string) (SizeClass, error) {
+	c := SizeClass(strings.ToUpper(strings.TrimSpace(s)))
+	if c.Rank() < 0 {
+		return "", fmt.Errorf("unknown size class %q (valid: XS, S, M, L, XL)", s)
+	}
+	return c, nil
+}
@@ -0,0 +1,51 @@
+package diff
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		lines int
+		want  SizeClass
+	}{
+		{0, SizeXS},
+		{9, SizeXS},
+		{10, SizeS},
+		{29, SizeS},
+		{30, SizeM},
+		{99, SizeM},
+		{100, SizeL},
+		{499, SizeL},
+		{500, SizeXL},
+		{10000, SizeXL},
+	}
+
+	for _, tt := range tests {
+		got := Classify(tt.lines, nil)
+		if got != tt.want {
+			t.Errorf("Classify(%d) = %s, want %s", tt.lines, got, tt.want)
+		}
+	}
+}
+
+func TestParseSizeClass(t *testing.T) {
+	got, err := ParseSizeClass("xl")
+	if err != nil {
+		t.Fatalf("ParseSizeClass(xl) error = %v", err)
+	}
+	if got != SizeXL {
+		t.Errorf("ParseSizeClass(xl) = %s, want XL", got)
+	}
+
+	if _, err := ParseSizeClass("huge"); err == nil {
+		t.Error("ParseSizeClass(huge): got nil error, want error")
+	}
+}
+
+func TestSizeClass_Rank(t *testing.T) {
+	if SizeXS.Rank() >= SizeS.Rank() || SizeL.Rank() >= SizeXL.Rank() {
+		t.Error("size class ranks should increase from XS to XL")
+	}
+	if SizeClass("XXL").Rank() != -1 {
+		t.Error("unknown size class should have rank -1")
+	}
+}
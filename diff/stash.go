@@ -0,0 +1,106 @@
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StashEntry summarizes one entry of git stash list.
+type StashEntry struct {
+	Ref     string     // e.g., "stash@{0}"
+	Message string     // Stash subject (e.g., "WIP on main: abc1234 msg")
+	Time    time.Time  // When the stash was created
+	Stats   *DiffStats // Changes relative to the stash's base commit
+}
+
+// ListStashes returns every stash entry, newest first, with its diff stats.
+func ListStashes() ([]StashEntry, []string, error) {
+	return defaultClient.ListStashes()
+}
+
+// ListStashes returns every stash entry, newest first, with its diff stats.
+// Git failures listing stashes follow the client's FailOpen policy; an entry
+// whose diff fails is kept with empty stats and a warning.
+func (c *Client) ListStashes() ([]StashEntry, []string, error) {
+	var warnings []string
+	output, err := c.output("stash", "list", "--format=%gd%x00%ct%x00%gs")
+	if err != nil {
+		warnings, err = c.fail(err, warnings)
+		return nil, warnings, err
+	}
+
+	entries, parseWarnings := ParseStashList(string(output))
+	warnings = append(warnings, parseWarnings...)
+
+	for i := range entries {
+		stats, statsWarnings, err := c.stashStats(entries[i].Ref)
+		warnings = append(warnings, statsWarnings...)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %v", entries[i].Ref, err))
+			stats = &DiffStats{}
+		}
+		entries[i].Stats = stats
+	}
+	return entries, warnings, nil
+}
+
+// stashStats returns the changes a stash entry holds: tracked changes
+// against its base commit, plus the untracked files that git stash -u keeps
+// in a third parent.
+func (c *Client) stashStats(ref string) (*DiffStats, []string, error) {
+	stats, warnings, err := c.GetDiffStats(ref+"^1", ref)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	hasUntracked, err := c.revisionExists(ref + "^3")
+	if err != nil || !hasUntracked {
+		return stats, warnings, err
+	}
+	// The untracked commit has no parent, so list its whole tree
+	output, err := c.output("diff-tree", "-r", "--root", "--no-commit-id", "--numstat", ref+"^3")
+	if err != nil {
+		return nil, warnings, err
+	}
+	untracked, parseWarnings, err := ParseNumstat(string(output))
+	warnings = append(warnings, parseWarnings...)
+	if err != nil {
+		return nil, warnings, err
+	}
+	for i := range untracked.Files {
+		untracked.Files[i].Status = "A"
+	}
+	return Union(stats, untracked), warnings, nil
+}
+
+// ParseStashList parses git stash list output in the
+// "%gd%x00%ct%x00%gs" format, one entry per line. Messages pass through
+// SanitizeText. Malformed lines are skipped with a warning.
+func ParseStashList(output string) ([]StashEntry, []string) {
+	var entries []StashEntry
+	var warnings []string
+
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) != 3 {
+			warnings = append(warnings, fmt.Sprintf("malformed stash list line: %q", line))
+			continue
+		}
+		secs, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("bad stash timestamp %q for %s", parts[1], parts[0]))
+			continue
+		}
+		entries = append(entries, StashEntry{
+			Ref:     parts[0],
+			Time:    time.Unix(secs, 0),
+			Message: SanitizeText(parts[2]),
+		})
+	}
+	return entries, warnings
+}
@@ -0,0 +1,60 @@
+package diff
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseStashList(t *testing.T) {
+	output := "stash@{0}\x001700000000\x00On main: try lib\n" +
+		"garbage\n" +
+		"stash@{1}\x00notanumber\x00WIP\n" +
+		"stash@{2}\x001690000000\x00WIP on main: abc1234 msg\n"
+
+	entries, warnings := ParseStashList(output)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("got %d warnings, want 2: %v", len(warnings), warnings)
+	}
+
+	if entries[0].Ref != "stash@{0}" || entries[0].Message != "On main: try lib" {
+		t.Errorf("entries[0] = %+v", entries[0])
+	}
+	if !entries[1].Time.Equal(time.Unix(1690000000, 0)) {
+		t.Errorf("entries[1].Time = %v, want %v", entries[1].Time, time.Unix(1690000000, 0))
+	}
+}
+
+func TestClient_ListStashes_Untracked(t *testing.T) {
+	c, git := newTestRepo(t)
+	if err := os.WriteFile(c.path("a.txt"), []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "one")
+	if err := os.WriteFile(c.path("a.txt"), []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(c.path("new.txt"), []byte("x\ny\nz\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("stash", "-q", "-u")
+
+	entries, warnings, err := c.ListStashes()
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("ListStashes() warnings = %v, err = %v", warnings, err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	stats := entries[0].Stats
+	if stats.TotalFiles != 2 || stats.TotalAdd != 4 {
+		t.Fatalf("stats = %+v, want a.txt and untracked new.txt (+4)", stats)
+	}
+	if f := stats.Files[1]; f.Path != "new.txt" || !f.IsNew() {
+		t.Errorf("Files[1] = %+v, want new.txt marked added", f)
+	}
+}
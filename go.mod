@@ -3,6 +3,8 @@ module github.com/kylesnowschwartz/diff-viz
 go 1.25.5
 
 require (
-	golang.org/x/sys v0.39.0 // indirect
-	golang.org/x/term v0.38.0 // indirect
+	golang.org/x/term v0.38.0
+	golang.org/x/text v0.3.8
 )
+
+require golang.org/x/sys v0.39.0 // indirect
@@ -0,0 +1,359 @@
+// Package publish posts rendered diff summaries to code review services.
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Marker identifies the comment owned by diff-viz so reruns update it in place.
+const Marker = "<!-- diff-viz:github-pr -->"
+
+// DefaultGitHubAPI is the public GitHub REST endpoint.
+const DefaultGitHubAPI = "https://api.github.com"
+
+// Result describes what UpsertComment did.
+type Result string
+
+const (
+	Created   Result = "created"
+	Updated   Result = "updated"
+	Unchanged Result = "unchanged" // Existing comment already current; no write made
+)
+
+// ErrRateLimited is returned (wrapped in *RateLimitError) when GitHub
+// refuses a request for rate limiting. Callers should not retry immediately.
+var ErrRateLimited = errors.New("github rate limit exceeded")
+
+// RateLimitError reports when the rate limit resets, if known.
+type RateLimitError struct {
+	Reset time.Time // Zero if GitHub did not say
+}
+
+// Error includes the reset time when available.
+func (e *RateLimitError) Error() string {
+	if e.Reset.IsZero() {
+		return ErrRateLimited.Error()
+	}
+	return fmt.Sprintf("%v (resets at %s)", ErrRateLimited, e.Reset.Format(time.RFC3339))
+}
+
+// Unwrap returns ErrRateLimited so errors.Is works.
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// GitHubClient manages a single marker-tagged comment on pull requests.
+// It makes the minimum number of calls: one identity lookup (skipped when
+// Author is set), one paged listing, then at most one write, skipped
+// entirely when the comment is already current. Server errors and secondary
+// rate limits are retried a few times.
+type GitHubClient struct {
+	Token   string       // API token (e.g., $GITHUB_TOKEN)
+	Repo    string       // "owner/name"
+	BaseURL string       // API root (default DefaultGitHubAPI)
+	HTTP    *http.Client // Default http.DefaultClient
+
+	// Author is the login whose marker comment is updated. Empty means the
+	// token's user, or any bot for GitHub App tokens like Actions'
+	// GITHUB_TOKEN, which cannot look up their own identity.
+	Author string
+
+	// RetryWait is the backoff after a server error, doubling per retry
+	// (default 1s).
+	RetryWait time.Duration
+}
+
+// comment is the subset of the GitHub issue comment payload we use.
+type comment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+	User struct {
+		Login string `json:"login"`
+		Type  string `json:"type"` // "User" or "Bot"
+	} `json:"user"`
+	App *struct {
+		Slug string `json:"slug"`
+	} `json:"performed_via_github_app"`
+}
+
+// maxAttempts bounds tries per request for server errors and secondary
+// rate limits.
+const maxAttempts = 3
+
+// maxRetryWait is the longest rate limit wait a request sits out; longer
+// ones fail with a *RateLimitError.
+const maxRetryWait = time.Minute
+
+// UpsertComment creates or updates the diff-viz comment on pull request pr.
+// body is the rendered content; the marker is prepended automatically.
+func (c *GitHubClient) UpsertComment(ctx context.Context, pr int, body string) (Result, error) {
+	body = Marker + "\n" + body
+
+	existing, err := c.findComment(ctx, pr)
+	if err != nil {
+		return "", err
+	}
+
+	payload := map[string]string{"body": body}
+	if existing == nil {
+		found, err := c.createComment(ctx, pr, payload)
+		if err != nil {
+			return "", err
+		}
+		if found == nil || found.Body == body {
+			return Created, nil
+		}
+		existing = found
+	}
+
+	if existing.Body == body {
+		return Unchanged, nil
+	}
+	path := fmt.Sprintf("/repos/%s/issues/comments/%d", c.Repo, existing.ID)
+	if err := c.do(ctx, http.MethodPatch, path, payload, nil); err != nil {
+		return "", err
+	}
+	return Updated, nil
+}
+
+// createComment posts a new comment on pr. A server error can come after
+// GitHub created the comment, so instead of posting again blindly it looks
+// for the comment first; it returns the one it finds, or nil once its own
+// post succeeds.
+func (c *GitHubClient) createComment(ctx context.Context, pr int, payload any) (*comment, error) {
+	path := fmt.Sprintf("/repos/%s/issues/%d/comments", c.Repo, pr)
+	for attempt := 1; ; attempt++ {
+		err := c.do(ctx, http.MethodPost, path, payload, nil)
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode < 500 || attempt == maxAttempts {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(c.backoff(attempt)):
+		}
+		existing, findErr := c.findComment(ctx, pr)
+		if findErr != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return existing, nil
+		}
+	}
+}
+
+// findComment pages through the PR's comments looking for the marker on
+// one of ours, so a copy of the marker quoted by someone else is left alone.
+func (c *GitHubClient) findComment(ctx context.Context, pr int) (*comment, error) {
+	author, err := c.author(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for page := 1; ; page++ {
+		var comments []comment
+		path := fmt.Sprintf("/repos/%s/issues/%d/comments?per_page=100&page=%d", c.Repo, pr, page)
+		if err := c.do(ctx, http.MethodGet, path, nil, &comments); err != nil {
+			return nil, err
+		}
+		for i := range comments {
+			if strings.HasPrefix(comments[i].Body, Marker) && comments[i].ownedBy(author) {
+				return &comments[i], nil
+			}
+		}
+		if len(comments) < 100 {
+			return nil, nil
+		}
+	}
+}
+
+// author returns the login whose comments findComment considers: Author,
+// else the token's user. It is "" for GitHub App tokens, which get 403 from
+// /user.
+func (c *GitHubClient) author(ctx context.Context) (string, error) {
+	if c.Author != "" {
+		return c.Author, nil
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	err := c.do(ctx, http.MethodGet, "/user", nil, &user)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusForbidden {
+		return "", nil
+	}
+	return user.Login, err
+}
+
+// ownedBy reports whether author posted the comment; with no author, whether
+// a bot or GitHub App did.
+func (cm *comment) ownedBy(author string) bool {
+	if author == "" {
+		return cm.User.Type == "Bot" || cm.App != nil
+	}
+	return strings.EqualFold(cm.User.Login, author)
+}
+
+// StatusError is an API response with a non-2xx status other than a rate
+// limit.
+type StatusError struct {
+	Method, Path string
+	StatusCode   int
+	Status       string // e.g., "404 Not Found"
+	Message      string // Start of the response body
+}
+
+// Error formats the request and GitHub's message.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("github %s %s: %s: %s", e.Method, e.Path, e.Status, e.Message)
+}
+
+// do sends an API request, decoding a JSON response into out if non-nil.
+// Server errors (except on POST, which may have taken effect; see
+// createComment) and secondary rate limits are retried up to maxAttempts
+// times while ctx allows.
+func (c *GitHubClient) do(ctx context.Context, method, path string, in, out any) error {
+	var data []byte
+	if in != nil {
+		var err error
+		if data, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		retry, wait, err := c.send(ctx, method, path, data, out)
+		if !retry || attempt == maxAttempts {
+			return err
+		}
+		if wait == 0 {
+			wait = c.backoff(attempt)
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoff returns the wait after failed attempt number attempt: RetryWait
+// doubled per earlier retry.
+func (c *GitHubClient) backoff(attempt int) time.Duration {
+	wait := c.RetryWait
+	if wait <= 0 {
+		wait = time.Second
+	}
+	return wait << (attempt - 1)
+}
+
+// send makes one attempt at a request. retry reports whether a failure is
+// worth repeating, after wait when the response said how long (0 = backoff).
+func (c *GitHubClient) send(ctx context.Context, method, path string, data []byte, out any) (retry bool, wait time.Duration, err error) {
+	var reqBody io.Reader
+	if data != nil {
+		reqBody = bytes.NewReader(data)
+	}
+
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultGitHubAPI
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(base, "/")+path, reqBody)
+	if err != nil {
+		return false, 0, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false, 0, fmt.Errorf("github %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if rlErr := rateLimitError(resp, msg); rlErr != nil {
+			// A secondary limit passes in about a minute; a spent primary
+			// quota can take an hour
+			wait := time.Minute
+			if !rlErr.Reset.IsZero() {
+				wait = max(time.Until(rlErr.Reset), time.Millisecond)
+			}
+			secondary := resp.Header.Get("X-RateLimit-Remaining") != "0"
+			return secondary && wait <= maxRetryWait, wait, rlErr
+		}
+		return resp.StatusCode >= 500 && method != http.MethodPost, 0, &StatusError{
+			Method: method, Path: path,
+			StatusCode: resp.StatusCode, Status: resp.Status,
+			Message: strings.TrimSpace(string(msg)),
+		}
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return false, 0, fmt.Errorf("github %s %s: decoding response: %w", method, path, err)
+		}
+	}
+	return false, 0, nil
+}
+
+// rateLimitError returns a *RateLimitError if resp (with body, the start of
+// its body) signals primary or secondary rate limiting, else nil.
+func rateLimitError(resp *http.Response, body []byte) *RateLimitError {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+
+	if retry := resp.Header.Get("Retry-After"); retry != "" {
+		if secs, err := strconv.Atoi(retry); err == nil {
+			return &RateLimitError{Reset: time.Now().Add(time.Duration(secs) * time.Second)}
+		}
+		return &RateLimitError{}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		e := &RateLimitError{}
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			e.Reset = time.Unix(reset, 0)
+		}
+		return e
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || bytes.Contains(bytes.ToLower(body), []byte("secondary rate limit")) {
+		return &RateLimitError{}
+	}
+	return nil
+}
+
+// PRFromRef extracts the pull request number from a GitHub Actions ref
+// like "refs/pull/123/merge". Returns 0 if ref is not a PR ref.
+func PRFromRef(ref string) int {
+	rest, ok := strings.CutPrefix(ref, "refs/pull/")
+	if !ok {
+		return 0
+	}
+	num, _, _ := strings.Cut(rest, "/")
+	n, err := strconv.Atoi(num)
+	if err != nil {
+		return 0
+	}
+	return n
+}
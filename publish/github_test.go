@@ -0,0 +1,260 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeGitHub serves the issue comment endpoints from an in-memory list.
+// With no login, the token acts like a GitHub App's: /user is forbidden and
+// comments are posted by a bot.
+type fakeGitHub struct {
+	login    string
+	comments []comment
+	writes   int
+
+	// The next POSTs answer 502 Bad Gateway; lostPosts of them without
+	// creating the comment, the rest after creating it
+	failPosts, lostPosts int
+}
+
+// userComment returns a comment posted by login ("" for the Actions bot).
+func userComment(id int64, login, body string) comment {
+	c := comment{ID: id, Body: body}
+	c.User.Login, c.User.Type = login, "User"
+	if login == "" {
+		c.User.Login, c.User.Type = "github-actions[bot]", "Bot"
+	}
+	return c
+}
+
+func (f *fakeGitHub) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		if f.login == "" {
+			http.Error(w, `{"message": "Resource not accessible by integration"}`, http.StatusForbidden)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"login": f.login})
+	})
+	mux.HandleFunc("GET /repos/o/r/issues/7/comments", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "1" {
+			json.NewEncoder(w).Encode([]comment{})
+			return
+		}
+		json.NewEncoder(w).Encode(f.comments)
+	})
+	mux.HandleFunc("POST /repos/o/r/issues/7/comments", func(w http.ResponseWriter, r *http.Request) {
+		var in comment
+		json.NewDecoder(r.Body).Decode(&in)
+		if f.lostPosts > 0 {
+			f.lostPosts--
+			f.failPosts--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		f.comments = append(f.comments, userComment(int64(len(f.comments)+1), f.login, in.Body))
+		f.writes++
+		if f.failPosts > 0 {
+			f.failPosts--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("PATCH /repos/o/r/issues/comments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var in comment
+		json.NewDecoder(r.Body).Decode(&in)
+		for i := range f.comments {
+			if strconv.FormatInt(f.comments[i].ID, 10) == r.PathValue("id") {
+				f.comments[i].Body = in.Body
+			}
+		}
+		f.writes++
+	})
+	return mux
+}
+
+func TestUpsertComment(t *testing.T) {
+	for _, login := range []string{"ci-user", ""} {
+		t.Run("login "+login, func(t *testing.T) {
+			// Someone quoting the marker does not own the comment
+			fake := &fakeGitHub{login: login, comments: []comment{
+				userComment(1, "reviewer", "LGTM"),
+				userComment(2, "reviewer", Marker+"\nquoted"),
+			}}
+			srv := httptest.NewServer(fake.handler())
+			defer srv.Close()
+
+			c := &GitHubClient{Repo: "o/r", BaseURL: srv.URL, Token: "t"}
+			ctx := context.Background()
+
+			steps := []struct {
+				body       string
+				want       Result
+				wantWrites int
+			}{
+				{"v1", Created, 1},
+				{"v1", Unchanged, 1}, // No write when already current
+				{"v2", Updated, 2},
+			}
+			for _, s := range steps {
+				got, err := c.UpsertComment(ctx, 7, s.body)
+				if err != nil {
+					t.Fatalf("UpsertComment(%q): %v", s.body, err)
+				}
+				if got != s.want || fake.writes != s.wantWrites {
+					t.Errorf("UpsertComment(%q) = %s with %d writes, want %s with %d", s.body, got, fake.writes, s.want, s.wantWrites)
+				}
+			}
+			if len(fake.comments) != 3 || fake.comments[1].Body != Marker+"\nquoted" || fake.comments[2].Body != Marker+"\nv2" {
+				t.Errorf("comments = %+v, want the reviewer's two plus one updated marker comment", fake.comments)
+			}
+		})
+	}
+}
+
+func TestUpsertComment_Author(t *testing.T) {
+	fake := &fakeGitHub{comments: []comment{userComment(1, "release-bot", Marker+"\nold")}}
+	srv := httptest.NewServer(fake.handler())
+	defer srv.Close()
+
+	c := &GitHubClient{Repo: "o/r", BaseURL: srv.URL, Author: "Release-Bot"}
+	if got, err := c.UpsertComment(context.Background(), 7, "new"); err != nil || got != Updated {
+		t.Errorf("UpsertComment with Author = %s, %v; want updated", got, err)
+	}
+}
+
+func TestUpsertComment_CreateFails(t *testing.T) {
+	tests := []struct {
+		name                 string
+		failPosts, lostPosts int
+		wantErr              bool
+	}{
+		{name: "created before the error", failPosts: 1},
+		{name: "lost", failPosts: 1, lostPosts: 1},
+		{name: "gives up", failPosts: maxAttempts, lostPosts: maxAttempts, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeGitHub{login: "ci-user", failPosts: tt.failPosts, lostPosts: tt.lostPosts}
+			srv := httptest.NewServer(fake.handler())
+			defer srv.Close()
+
+			c := &GitHubClient{Repo: "o/r", BaseURL: srv.URL, RetryWait: time.Millisecond}
+			got, err := c.UpsertComment(context.Background(), 7, "v1")
+			if tt.wantErr {
+				if err == nil || len(fake.comments) != 0 {
+					t.Errorf("UpsertComment() = %s, %v with %d comments; want an error and none", got, err, len(fake.comments))
+				}
+				return
+			}
+			if err != nil || got != Created {
+				t.Errorf("UpsertComment() = %s, %v; want created", got, err)
+			}
+			if len(fake.comments) != 1 || fake.comments[0].Body != Marker+"\nv1" {
+				t.Errorf("comments = %+v, want exactly one marker comment", fake.comments)
+			}
+		})
+	}
+}
+
+func TestUpsertComment_Retries(t *testing.T) {
+	tests := []struct {
+		name      string
+		fail      func(w http.ResponseWriter)
+		failures  int
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "server error",
+			fail:      func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+			failures:  2,
+			wantCalls: 3,
+		},
+		{
+			name: "secondary rate limit",
+			fail: func(w http.ResponseWriter) {
+				w.Header().Set("Retry-After", "0")
+				http.Error(w, `{"message": "You have exceeded a secondary rate limit"}`, http.StatusForbidden)
+			},
+			failures:  1,
+			wantCalls: 2,
+		},
+		{
+			name:      "gives up",
+			fail:      func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+			failures:  5,
+			wantCalls: maxAttempts,
+			wantErr:   true,
+		},
+		{
+			name:      "client error",
+			fail:      func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) },
+			failures:  5,
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls <= tt.failures {
+					tt.fail(w)
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]string{"login": "ci-user"})
+			}))
+			defer srv.Close()
+
+			c := &GitHubClient{Repo: "o/r", BaseURL: srv.URL, RetryWait: time.Millisecond}
+			_, err := c.author(context.Background())
+			if (err != nil) != tt.wantErr || calls != tt.wantCalls {
+				t.Errorf("err = %v after %d calls, want error %v after %d", err, calls, tt.wantErr, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestUpsertComment_RateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "1700000000")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	c := &GitHubClient{Repo: "o/r", BaseURL: srv.URL}
+	_, err := c.UpsertComment(context.Background(), 7, "x")
+	var rlErr *RateLimitError
+	if !errors.Is(err, ErrRateLimited) || !errors.As(err, &rlErr) || rlErr.Reset.Unix() != 1700000000 {
+		t.Errorf("UpsertComment rate limited: err = %v, want RateLimitError resetting at 1700000000", err)
+	}
+}
+
+func TestPRFromRef(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want int
+	}{
+		{"refs/pull/123/merge", 123},
+		{"refs/pull/9/head", 9},
+		{"refs/heads/main", 0},
+		{"refs/pull/abc/merge", 0},
+	}
+	for _, tt := range tests {
+		if got := PRFromRef(tt.ref); got != tt.want {
+			t.Errorf("PRFromRef(%q) = %d, want %d", tt.ref, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package render
+
+import "fmt"
+
+// Readability limits for AdviseMode and AutoMode.
+const (
+	icicleMinWidth     = 60   // Narrower icicles fold most cells into "…+N"
+	barsMinWidth       = 40   // Narrower bars leave no room past the labels
+	treeMaxFiles       = 1000 // Longer trees scroll past anything useful
+	bracketsMaxFiles   = 100  // Brackets puts every file on one line
+	autoTreeMaxFiles   = 40   // Trees up to this size fit on one screen
+	autoIcicleMaxFiles = 400  // Icicles past this size are mostly folded cells
+	autoIcicleWidth    = 100  // Icicles need a wide terminal to label cells
+)
+
+// ModeAdvice explains why a mode would render poorly and suggests another.
+type ModeAdvice struct {
+	Reason  string // e.g., "icicle needs at least 60 columns (output is 48)"
+	Suggest string // Mode that suits the diff and width better
+}
+
+// AdviseMode reports whether mode would be hard to read for a diff of files
+// changed files drawn width columns wide (0 = unknown), and which mode to use
+// instead. ok is false when mode is fine.
+func AdviseMode(mode string, files, width int) (advice ModeAdvice, ok bool) {
+	switch {
+	case mode == "icicle" && width > 0 && width < icicleMinWidth:
+		advice.Reason = fmt.Sprintf("icicle needs at least %d columns (output is %d)", icicleMinWidth, width)
+	case mode == "bars" && width > 0 && width < barsMinWidth:
+		advice.Reason = fmt.Sprintf("bars needs at least %d columns (output is %d)", barsMinWidth, width)
+	case mode == "tree" && files >= treeMaxFiles:
+		advice.Reason = fmt.Sprintf("tree lists all %d files", files)
+	case mode == "brackets" && files > bracketsMaxFiles:
+		advice.Reason = fmt.Sprintf("brackets puts all %d files on one line", files)
+	default:
+		return ModeAdvice{}, false
+	}
+
+	advice.Suggest = AutoMode(files, width)
+	if advice.Suggest == mode {
+		advice.Suggest = "smart"
+	}
+	return advice, true
+}
+
+// AutoMode picks a mode for a diff of files changed files drawn width
+// columns wide (0 = unknown): tree while the list fits on a screen, icicle
+// for mid-sized diffs in wide terminals, and smart's per-directory summary
+// otherwise.
+func AutoMode(files, width int) string {
+	switch {
+	case files <= autoTreeMaxFiles:
+		return "tree"
+	case files <= autoIcicleMaxFiles && width >= autoIcicleWidth:
+		return "icicle"
+	default:
+		return "smart"
+	}
+}
@@ -0,0 +1,51 @@
+package render
+
+import "testing"
+
+func TestAdviseMode(t *testing.T) {
+	tests := []struct {
+		mode        string
+		files       int
+		width       int
+		wantOK      bool
+		wantSuggest string
+	}{
+		{"icicle", 50, 48, true, "smart"},
+		{"icicle", 50, 0, false, ""}, // Unknown width
+		{"icicle", 50, 120, false, ""},
+		{"bars", 5, 30, true, "tree"},
+		{"tree", 1500, 200, true, "smart"},
+		{"tree", 999, 80, false, ""},
+		{"brackets", 300, 120, true, "icicle"},
+		{"topn", 5000, 20, false, ""},
+	}
+	for _, tt := range tests {
+		advice, ok := AdviseMode(tt.mode, tt.files, tt.width)
+		if ok != tt.wantOK || advice.Suggest != tt.wantSuggest {
+			t.Errorf("AdviseMode(%q, %d, %d) = %+v, %v; want suggest %q, %v",
+				tt.mode, tt.files, tt.width, advice, ok, tt.wantSuggest, tt.wantOK)
+		}
+		if ok && advice.Reason == "" {
+			t.Errorf("AdviseMode(%q, %d, %d): empty reason", tt.mode, tt.files, tt.width)
+		}
+	}
+}
+
+func TestAutoMode(t *testing.T) {
+	tests := []struct {
+		files, width int
+		want         string
+	}{
+		{10, 0, "tree"},
+		{40, 200, "tree"},
+		{200, 120, "icicle"},
+		{200, 80, "smart"},
+		{200, 0, "smart"},
+		{2000, 200, "smart"},
+	}
+	for _, tt := range tests {
+		if got := AutoMode(tt.files, tt.width); got != tt.want {
+			t.Errorf("AutoMode(%d, %d) = %q, want %q", tt.files, tt.width, got, tt.want)
+		}
+	}
+}
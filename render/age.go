@@ -0,0 +1,69 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	day  = 24 * time.Hour
+	year = 365 * day
+)
+
+// AgeLevel maps a minimum replaced-line age to a heat color.
+type AgeLevel struct {
+	MinAge time.Duration
+	Color  string
+	Label  string // Legend text
+}
+
+// AgeHeatLevels color files by the median age of the lines they replace.
+// Ordered descending so first match wins; younger code keeps normal colors.
+var AgeHeatLevels = []AgeLevel{
+	{2 * year, "\033[38;5;196m", "2y+"},   // Red: long-stable code rewritten
+	{year, "\033[38;5;208m", "1y+"},       // Orange
+	{180 * day, "\033[38;5;214m", "6mo+"}, // Amber
+	{30 * day, "\033[38;5;229m", "1mo+"},  // Pale yellow
+}
+
+// AgeHeatColor returns the heat color for age, or "" below the lowest level.
+func AgeHeatColor(age time.Duration) string {
+	for _, l := range AgeHeatLevels {
+		if age >= l.MinAge {
+			return l.Color
+		}
+	}
+	return ""
+}
+
+// FormatAge abbreviates a duration as days, months, or years ("5d", "3mo", "2y").
+func FormatAge(age time.Duration) string {
+	switch {
+	case age >= year:
+		return fmt.Sprintf("%dy", int(age/year))
+	case age >= 30*day:
+		return fmt.Sprintf("%dmo", int(age/(30*day)))
+	default:
+		return fmt.Sprintf("%dd", int(age/day))
+	}
+}
+
+// formatAgeSuffix returns a dimmed " age 3y" note, or "" for unanalyzed files.
+func formatAgeSuffix(age time.Duration, color func(string) string) string {
+	if age <= 0 {
+		return ""
+	}
+	return " " + color(ColorDim) + "age " + FormatAge(age) + color(ColorReset)
+}
+
+// writeAgeLegend prints the heat scale used by --age-heat.
+func writeAgeLegend(w io.Writer, color func(string) string) {
+	var parts []string
+	for i := len(AgeHeatLevels) - 1; i >= 0; i-- {
+		l := AgeHeatLevels[i]
+		parts = append(parts, color(l.Color)+"■"+color(ColorReset)+" "+l.Label)
+	}
+	fmt.Fprintf(w, "\nage of replaced lines: %s\n", strings.Join(parts, "  "))
+}
@@ -0,0 +1,28 @@
+package render
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAgeHeat(t *testing.T) {
+	tests := []struct {
+		age       time.Duration
+		wantLabel string
+		wantColor string
+	}{
+		{3 * day, "3d", ""},
+		{45 * day, "1mo", AgeHeatLevels[3].Color},
+		{200 * day, "6mo", AgeHeatLevels[2].Color},
+		{400 * day, "1y", AgeHeatLevels[1].Color},
+		{3 * year, "3y", AgeHeatLevels[0].Color},
+	}
+	for _, tt := range tests {
+		if got := FormatAge(tt.age); got != tt.wantLabel {
+			t.Errorf("FormatAge(%v) = %q, want %q", tt.age, got, tt.wantLabel)
+		}
+		if got := AgeHeatColor(tt.age); got != tt.wantColor {
+			t.Errorf("AgeHeatColor(%v) = %q, want %q", tt.age, got, tt.wantColor)
+		}
+	}
+}
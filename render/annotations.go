@@ -0,0 +1,22 @@
+package render
+
+import "strings"
+
+// formatAnnotations returns " key=value" pairs for the requested keys that
+// are present, dimmed. Keys missing from annotations are skipped.
+func formatAnnotations(annotations map[string]string, keys []string, color func(string) string) string {
+	if len(annotations) == 0 || len(keys) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, k := range keys {
+		if v, ok := annotations[k]; ok {
+			sb.WriteString(" ")
+			sb.WriteString(color(ColorDim))
+			sb.WriteString(k + "=" + v)
+			sb.WriteString(color(ColorReset))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,60 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// Badge is the working tree summary BadgeRenderer prints for shell prompts.
+type Badge struct {
+	Dirty bool   `json:"dirty"`
+	Files int    `json:"files"`
+	Adds  int    `json:"adds"`
+	Dels  int    `json:"dels"`
+	Dir   string `json:"dir,omitempty"` // Top-level directory with the most changed lines ("." for root files)
+}
+
+// NewBadge summarizes stats.
+func NewBadge(stats *diff.DiffStats) Badge {
+	b := Badge{
+		Dirty: stats.TotalFiles > 0,
+		Files: stats.TotalFiles,
+		Adds:  stats.TotalAdd,
+		Dels:  stats.TotalDel,
+	}
+	if dirs := stats.DirStats(1); len(dirs) > 0 {
+		b.Dir = dirs[0].Path
+	}
+	return b
+}
+
+// BadgeRenderer prints a Badge as one line for prompt frameworks: plain
+// "FILES +ADDS -DELS DIR" (nothing when the tree is clean, so prompts can
+// hide the segment), or a JSON object when JSON is set. Output is never
+// colored; prompts apply their own styles.
+type BadgeRenderer struct {
+	JSON bool
+	w    io.Writer
+}
+
+// NewBadgeRenderer creates a badge renderer with plain output.
+func NewBadgeRenderer(w io.Writer) *BadgeRenderer {
+	return &BadgeRenderer{w: w}
+}
+
+// Render outputs the badge for stats.
+func (r *BadgeRenderer) Render(stats *diff.DiffStats) {
+	b := NewBadge(stats)
+	if r.JSON {
+		out, _ := json.Marshal(b)
+		fmt.Fprintln(r.w, string(out))
+		return
+	}
+	if !b.Dirty {
+		return
+	}
+	fmt.Fprintf(r.w, "%d +%d -%d %s\n", b.Files, b.Adds, b.Dels, b.Dir)
+}
@@ -0,0 +1,43 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestBadgeRenderer(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/a.go", Additions: 10, Deletions: 2},
+			{Path: "src/b.go", Additions: 1},
+			{Path: "README.md", Additions: 3},
+		},
+		TotalFiles: 3, TotalAdd: 14, TotalDel: 2,
+	}
+	clean := &diff.DiffStats{}
+
+	tests := []struct {
+		name  string
+		json  bool
+		stats *diff.DiffStats
+		want  string
+	}{
+		{"plain", false, stats, "3 +14 -2 src\n"},
+		{"plain clean", false, clean, ""},
+		{"json", true, stats, `{"dirty":true,"files":3,"adds":14,"dels":2,"dir":"src"}` + "\n"},
+		{"json clean", true, clean, `{"dirty":false,"files":0,"adds":0,"dels":0}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			r := NewBadgeRenderer(&buf)
+			r.JSON = tt.json
+			r.Render(tt.stats)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package render
 
-import "strings"
+import (
+	"fmt"
+	"io"
+	"math/bits"
+	"strings"
+)
 
 // Block characters for bar rendering.
 const (
@@ -133,15 +138,261 @@ func RatioBar(add, del, filled, barWidth int, block string, colorFn func(string)
 	return sb.String()
 }
 
-// Package-level helpers using defaults for backwards compatibility.
-// These match the original function signatures in topn.go.
+// CompositionBar renders a bar split three ways: additions in new files
+// (yellow), additions to existing files (green), and deletions (red).
+// add counts all additions, including newAdd. Parameters otherwise match
+// RatioBar; each non-zero part gets at least one block.
+func CompositionBar(newAdd, add, del, filled, barWidth int, block string, colorFn func(string) string) string {
+	total := add + del
+	if total == 0 {
+		return strings.Repeat(BlockEmpty, barWidth)
+	}
+
+	parts := []struct {
+		lines int
+		color string
+	}{
+		{newAdd, ColorNew},
+		{add - newAdd, ColorAdd},
+		{del, ColorDel},
+	}
+
+	nonZero := 0
+	for _, p := range parts {
+		if p.lines > 0 {
+			nonZero++
+		}
+	}
+	filled = min(max(filled, nonZero), barWidth)
+
+	// Proportional split; the largest part absorbs rounding so blocks sum to filled
+	blocks := make([]int, len(parts))
+	largest, used := 0, 0
+	for i, p := range parts {
+		if p.lines > 0 {
+			blocks[i] = max((p.lines*filled)/total, 1)
+		}
+		used += blocks[i]
+		if p.lines > parts[largest].lines {
+			largest = i
+		}
+	}
+	blocks[largest] += filled - used
+
+	var sb strings.Builder
+	for i, p := range parts {
+		if blocks[i] <= 0 {
+			continue
+		}
+		sb.WriteString(colorFn(p.color))
+		sb.WriteString(strings.Repeat(block, blocks[i]))
+		sb.WriteString(colorFn(ColorReset))
+	}
+	if padding := barWidth - filled; padding > 0 {
+		sb.WriteString(strings.Repeat(BlockEmpty, padding))
+	}
+	return sb.String()
+}
+
+// NewCodeCounts formats additions split into edits to existing files and
+// lines in new files, "+120 ✚300new" (add includes newAdd). Either part is
+// omitted when zero; with no additions at all it returns "+0".
+func NewCodeCounts(add, newAdd int, colorFn func(string) string) string {
+	var parts []string
+	if edits := add - newAdd; edits > 0 || newAdd == 0 {
+		parts = append(parts, fmt.Sprintf("%s+%d%s", colorFn(ColorAdd), edits, colorFn(ColorReset)))
+	}
+	if newAdd > 0 {
+		parts = append(parts, fmt.Sprintf("%s✚%dnew%s", colorFn(ColorNew), newAdd, colorFn(ColorReset)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ZeroBarStyle selects how a bar for an entry with no line changes is drawn
+// (binary files, renames, empty new files).
+type ZeroBarStyle string
+
+const (
+	ZeroBarEmpty ZeroBarStyle = "empty" // Empty blocks (default)
+	ZeroBarDot   ZeroBarStyle = "dot"   // A single "·"
+	ZeroBarNone  ZeroBarStyle = "none"  // No bar at all
+)
+
+// ParseZeroBarStyle validates a zeroBar config value. Empty means ZeroBarEmpty.
+func ParseZeroBarStyle(s string) (ZeroBarStyle, error) {
+	switch ZeroBarStyle(s) {
+	case "", ZeroBarEmpty:
+		return ZeroBarEmpty, nil
+	case ZeroBarDot, ZeroBarNone:
+		return ZeroBarStyle(s), nil
+	}
+	return "", fmt.Errorf("unknown zero bar style %q (valid: empty, dot, none)", s)
+}
+
+// BarScale selects how smart and topn map a change total to a bar length.
+type BarScale string
+
+const (
+	ScaleSteps  BarScale = "steps"  // DefaultThresholds (15/30/50/75/100/150/200/300/400)
+	ScaleLinear BarScale = "linear" // One block per LinearBlockLines lines
+	ScaleLog    BarScale = "log"    // One block per doubling of the total
+)
+
+// LinearBlockLines is the number of changed lines each block stands for
+// under ScaleLinear, so a full 10-block bar means 400+ lines as with ScaleSteps.
+const LinearBlockLines = 40
+
+// ParseBarScale parses a barScale config value. Empty means ScaleSteps.
+func ParseBarScale(s string) (BarScale, error) {
+	switch BarScale(s) {
+	case "", ScaleSteps:
+		return ScaleSteps, nil
+	case ScaleLinear, ScaleLog:
+		return BarScale(s), nil
+	}
+	return "", fmt.Errorf("unknown bar scale %q (valid: steps, linear, log)", s)
+}
+
+// Metric selects what proportional widths (icicle cells, bars) measure.
+type Metric string
+
+const (
+	MetricLines Metric = "lines" // Changed lines (additions + deletions)
+	MetricFiles Metric = "files" // Changed files, so one huge generated file can't dominate
+)
+
+// ParseMetric parses a --metric value. Empty means MetricLines.
+func ParseMetric(s string) (Metric, error) {
+	switch Metric(s) {
+	case "", MetricLines:
+		return MetricLines, nil
+	case MetricFiles:
+		return MetricFiles, nil
+	}
+	return "", fmt.Errorf("unknown metric %q (valid: lines, files)", s)
+}
+
+// Units selects what tree and topn counts and bars measure.
+type Units string
+
+const (
+	UnitsLines Units = "lines" // Changed lines
+	UnitsBytes Units = "bytes" // Blob size change (see diff.DiffStats.InBytes)
+	UnitsAuto  Units = "auto"  // Bytes when most changed files are binary (diff.DiffStats.BinaryHeavy)
+)
+
+// ParseUnits parses a --units value. Empty means UnitsLines.
+func ParseUnits(s string) (Units, error) {
+	switch Units(s) {
+	case "", UnitsLines:
+		return UnitsLines, nil
+	case UnitsBytes, UnitsAuto:
+		return Units(s), nil
+	}
+	return "", fmt.Errorf("unknown units %q (valid: lines, bytes, auto)", s)
+}
+
+// Filled returns the number of filled blocks for total, between 1 and width.
+func (s BarScale) Filled(total, width int) int {
+	var n int
+	switch s {
+	case ScaleLinear:
+		n = total/LinearBlockLines + 1
+	case ScaleLog:
+		n = bits.Len(uint(max(total, 0))) // 1 -> 1, 2-3 -> 2, 4-7 -> 3, ...
+	default:
+		return DefaultBarConfig(width).FilledFor(total)
+	}
+	return min(max(n, 1), width)
+}
+
+// ScaleLegend describes how to read bars drawn with s: the block character
+// for each magnitude and what bar length means.
+func ScaleLegend(s BarScale) string {
+	var shades []string
+	for i := len(DefaultCharLevels) - 1; i >= 0; i-- {
+		l := DefaultCharLevels[i]
+		if i == 0 {
+			shades = append(shades, fmt.Sprintf("%s ≥%d", l.Char, l.MinTotal))
+		} else {
+			shades = append(shades, fmt.Sprintf("%s <%d", l.Char, DefaultCharLevels[i-1].MinTotal))
+		}
+	}
+
+	var length string
+	switch s {
+	case ScaleLinear:
+		length = fmt.Sprintf("1 block per %d lines", LinearBlockLines)
+	case ScaleLog:
+		length = "1 block per doubling (1, 2, 4, 8 … 512+ lines)"
+	default:
+		var steps []string
+		for i := len(DefaultThresholds) - 2; i >= 0; i-- {
+			steps = append(steps, fmt.Sprint(DefaultThresholds[i].MinTotal))
+		}
+		length = "+1 block at " + strings.Join(steps, "/") + " lines"
+	}
+	return strings.Join(shades, "  ") + " lines · length: " + length
+}
+
+// writeScaleLegend prints ScaleLegend under a bar listing.
+func writeScaleLegend(w io.Writer, s BarScale, color func(string) string) {
+	fmt.Fprintf(w, "\n%sbar scale: %s%s\n", color(ColorDim), ScaleLegend(s), color(ColorReset))
+}
 
-// filledFromTotal returns the number of filled bar blocks for a given total.
-// Uses default thresholds with width 10.
-func filledFromTotal(total int) int {
-	return DefaultBarConfig(10).FilledFor(total)
+// BarStyle holds optional bar decorations for smart and topn modes.
+// The zero value matches RatioBar's default output.
+type BarStyle struct {
+	Zero      ZeroBarStyle // Zero-change bars ("" = ZeroBarEmpty)
+	NoPadding bool         // Omit BlockEmpty padding after filled blocks
+	Scale     BarScale     // Total-to-length mapping ("" = ScaleSteps)
+
+	// Tiny changes (fewer lines than this) are drawn like zero-change ones
+	// when Zero is ZeroBarDot or ZeroBarNone; 0 means only zero. An empty bar
+	// would hide them, so ZeroBarEmpty still draws their filled block.
+	Tiny int
 }
 
+// Bar renders a RatioBar with the style applied. Some terminals draw
+// BlockEmpty nearly as dark as filled blocks, so padding can be dropped.
+func (s BarStyle) Bar(add, del, filled, barWidth int, block string, colorFn func(string) string) string {
+	tiny := add+del < s.Tiny && (s.Zero == ZeroBarDot || s.Zero == ZeroBarNone)
+	if add+del == 0 || tiny {
+		switch s.Zero {
+		case ZeroBarDot:
+			return "·"
+		case ZeroBarNone:
+			return ""
+		}
+		if s.NoPadding {
+			return BlockEmpty
+		}
+		return strings.Repeat(BlockEmpty, barWidth)
+	}
+
+	bar := RatioBar(add, del, filled, barWidth, block, colorFn)
+	if s.NoPadding {
+		bar = strings.TrimRight(bar, BlockEmpty) // Padding is always last and uncolored
+	}
+	return bar
+}
+
+// CompositionBar renders a CompositionBar with the style applied.
+func (s BarStyle) CompositionBar(newAdd, add, del, filled, barWidth int, block string, colorFn func(string) string) string {
+	if add+del == 0 {
+		return s.Bar(add, del, filled, barWidth, block, colorFn)
+	}
+
+	bar := CompositionBar(newAdd, add, del, filled, barWidth, block, colorFn)
+	if s.NoPadding {
+		bar = strings.TrimRight(bar, BlockEmpty)
+	}
+	return bar
+}
+
+// Package-level helper using defaults for backwards compatibility.
+// This matches the original function signature in topn.go.
+
 // blockChar returns the appropriate block character based on magnitude.
 // Uses default char levels.
 func blockChar(total int) string {
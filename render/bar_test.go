@@ -169,3 +169,157 @@ func TestRatioBar_CapsAtWidth(t *testing.T) {
 		t.Errorf("expected 10 total blocks (width cap), got %d", totalBlocks)
 	}
 }
+
+func TestBarStyle(t *testing.T) {
+	noColor := func(string) string { return "" }
+
+	tests := []struct {
+		name     string
+		style    BarStyle
+		add, del int
+		filled   int
+		want     string
+	}{
+		{"default zero", BarStyle{}, 0, 0, 0, "░░░░"},
+		{"dot zero", BarStyle{Zero: ZeroBarDot}, 0, 0, 0, "·"},
+		{"none zero", BarStyle{Zero: ZeroBarNone}, 0, 0, 0, ""},
+		{"empty zero no padding", BarStyle{NoPadding: true}, 0, 0, 0, "░"},
+		{"padded", BarStyle{}, 5, 0, 2, "▒▒░░"},
+		{"no padding", BarStyle{NoPadding: true}, 5, 0, 2, "▒▒"},
+		{"no padding split", BarStyle{NoPadding: true}, 1, 1, 2, "▒▒"},
+		{"dot tiny", BarStyle{Zero: ZeroBarDot, Tiny: 10}, 6, 3, 1, "·"},
+		{"none tiny", BarStyle{Zero: ZeroBarNone, Tiny: 10}, 9, 0, 1, ""},
+		{"dot at tiny cutoff", BarStyle{Zero: ZeroBarDot, Tiny: 10}, 10, 0, 1, "▒░░░"},
+		{"empty keeps tiny bars", BarStyle{Tiny: 10}, 3, 0, 1, "▒░░░"},
+	}
+
+	for _, tt := range tests {
+		got := tt.style.Bar(tt.add, tt.del, tt.filled, 4, BlockLight, noColor)
+		if got != tt.want {
+			t.Errorf("%s: Bar(%d, %d) = %q, want %q", tt.name, tt.add, tt.del, got, tt.want)
+		}
+	}
+}
+
+func TestCompositionBar(t *testing.T) {
+	// Tag each segment's color so block counts per segment are visible
+	tag := func(code string) string {
+		switch code {
+		case ColorNew:
+			return "N"
+		case ColorAdd:
+			return "A"
+		case ColorDel:
+			return "D"
+		}
+		return ""
+	}
+	segments := func(bar string) string {
+		return strings.NewReplacer(BlockFull, "#", BlockEmpty, ".").Replace(bar)
+	}
+
+	tests := []struct {
+		newAdd, add, del, filled int
+		want                     string
+	}{
+		{50, 80, 20, 10, "N#####A###D##"},
+		{0, 60, 40, 10, "A######D####"},
+		{100, 100, 0, 4, "N####......"},
+		{1, 2, 97, 3, "N#A#D#......."}, // each part keeps a block
+	}
+
+	for _, tt := range tests {
+		got := segments(CompositionBar(tt.newAdd, tt.add, tt.del, tt.filled, 10, BlockFull, tag))
+		if got != tt.want {
+			t.Errorf("CompositionBar(%d, %d, %d, %d) = %q, want %q", tt.newAdd, tt.add, tt.del, tt.filled, got, tt.want)
+		}
+	}
+}
+
+func TestNewCodeCounts(t *testing.T) {
+	tests := []struct {
+		add, newAdd int
+		want        string
+	}{
+		{120, 0, "+120"},
+		{420, 300, "+120 ✚300new"},
+		{300, 300, "✚300new"},
+		{0, 0, "+0"},
+	}
+	for _, tt := range tests {
+		if got := NewCodeCounts(tt.add, tt.newAdd, noColor); got != tt.want {
+			t.Errorf("NewCodeCounts(%d, %d) = %q, want %q", tt.add, tt.newAdd, got, tt.want)
+		}
+	}
+}
+
+func TestBarScale_Filled(t *testing.T) {
+	tests := []struct {
+		scale BarScale
+		total int
+		want  int
+	}{
+		{ScaleSteps, 0, 1},
+		{ScaleSteps, 49, 3},
+		{ScaleSteps, 400, 10},
+		{"", 100, 6}, // Zero value is ScaleSteps
+		{ScaleLinear, 0, 1},
+		{ScaleLinear, 39, 1},
+		{ScaleLinear, 40, 2},
+		{ScaleLinear, 200, 6},
+		{ScaleLinear, 5000, 10},
+		{ScaleLog, 0, 1},
+		{ScaleLog, 1, 1},
+		{ScaleLog, 3, 2},
+		{ScaleLog, 100, 7},
+		{ScaleLog, 512, 10},
+		{ScaleLog, 100000, 10},
+	}
+
+	for _, tt := range tests {
+		if got := tt.scale.Filled(tt.total, 10); got != tt.want {
+			t.Errorf("%q.Filled(%d) = %d, want %d", tt.scale, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestParseBarScale(t *testing.T) {
+	for in, want := range map[string]BarScale{"": ScaleSteps, "steps": ScaleSteps, "linear": ScaleLinear, "log": ScaleLog} {
+		got, err := ParseBarScale(in)
+		if err != nil || got != want {
+			t.Errorf("ParseBarScale(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseBarScale("sqrt"); err == nil {
+		t.Error("ParseBarScale(sqrt) should fail")
+	}
+}
+
+func TestScaleLegend(t *testing.T) {
+	tests := []struct {
+		scale BarScale
+		want  string
+	}{
+		{ScaleSteps, "▒ <100  ▓ <200  █ ≥200 lines · length: +1 block at 15/30/50/75/100/150/200/300/400 lines"},
+		{ScaleLinear, "1 block per 40 lines"},
+		{ScaleLog, "1 block per doubling"},
+	}
+
+	for _, tt := range tests {
+		if got := ScaleLegend(tt.scale); !strings.Contains(got, tt.want) {
+			t.Errorf("ScaleLegend(%q) = %q, want it to contain %q", tt.scale, got, tt.want)
+		}
+	}
+}
+
+func TestParseMetric(t *testing.T) {
+	for in, want := range map[string]Metric{"": MetricLines, "lines": MetricLines, "files": MetricFiles} {
+		got, err := ParseMetric(in)
+		if err != nil || got != want {
+			t.Errorf("ParseMetric(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseMetric("bytes"); err == nil {
+		t.Error("ParseMetric(bytes) should fail")
+	}
+}
@@ -0,0 +1,143 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+	"github.com/kylesnowschwartz/diff-viz/render/layout"
+)
+
+const (
+	barsMinBarWidth = 10 // Bar columns kept even when names are long
+	barsDefaultWide = 100
+)
+
+// BarsRenderer draws one horizontal bar per directory, scaled so the largest
+// directory fills the available width. Bars split green/red by adds/dels.
+// Format: src/lib  +40  -10  ████████████████████████████████████████
+//
+// MaxDepth sets the directory depth rows aggregate at (root files group
+// under "."). Width is the full line width including name and stats.
+// Composition splits each row's additions into edits and new-file lines.
+type BarsRenderer struct {
+	UseColor    bool
+	MaxDepth    int                 // Directory depth for rows (default 2)
+	DirDepths   diff.DirDepths      // Per-top-level-directory MaxDepth overrides (see analyze.AutoDepths)
+	Width       int                 // Total line width (default 100)
+	SizeClass   diff.SizeClass      // Optional size label appended to summary
+	Excluded    diff.ExcludedTotals // Excluded categories; the summary adds totals without them
+	Composition bool                // Show "+N ✚Mnew" counts and three-part bars
+	Metric      Metric              // What bar lengths measure (default MetricLines)
+	w           io.Writer
+}
+
+// NewBarsRenderer creates a per-directory bar chart renderer.
+func NewBarsRenderer(w io.Writer, useColor bool) *BarsRenderer {
+	return &BarsRenderer{UseColor: useColor, MaxDepth: 2, Width: barsDefaultWide, w: w}
+}
+
+// Render outputs one proportionally scaled bar per directory.
+func (r *BarsRenderer) Render(stats *diff.DiffStats) {
+	if stats.TotalFiles == 0 {
+		fmt.Fprintln(r.w, "No changes")
+		return
+	}
+
+	dirs := stats.DirStats(r.MaxDepth)
+	if r.DirDepths != nil {
+		dirs = stats.DirStatsWithDepths(r.DirDepths, r.MaxDepth)
+	}
+	if r.Metric == MetricFiles {
+		sort.SliceStable(dirs, func(i, j int) bool { return dirs[i].FileCount > dirs[j].FileCount })
+	}
+
+	// Column widths: names and stats align; the bar takes what is left.
+	nameWidth, addWidth, delWidth, maxTotal := 0, 0, 0, 0
+	for _, d := range dirs {
+		nameWidth = max(nameWidth, VisibleWidth(barsLabel(d.Path)))
+		addWidth = max(addWidth, VisibleWidth(r.addCounts(d, r.color)))
+		delWidth = max(delWidth, len(fmt.Sprintf("-%d", d.Dels)))
+		maxTotal = max(maxTotal, r.weight(d))
+	}
+
+	width := r.Width
+	if width <= 0 {
+		width = barsDefaultWide
+	}
+	barWidth := max(width-nameWidth-addWidth-delWidth-5, barsMinBarWidth)
+
+	for _, d := range dirs {
+		r.renderRow(d, nameWidth, addWidth, delWidth, barWidth, maxTotal)
+	}
+
+	fmt.Fprintln(r.w)
+	fmt.Fprintf(r.w, "%s+%d%s %s-%d%s (%d files, %d dirs)%s%s\n",
+		r.color(ColorAdd), stats.TotalAdd, r.color(ColorReset),
+		r.color(ColorDel), stats.TotalDel, r.color(ColorReset),
+		stats.TotalFiles, len(dirs), excludedSuffix(stats, r.Excluded), sizeSuffix(r.SizeClass))
+}
+
+// renderRow writes a single directory row.
+func (r *BarsRenderer) renderRow(d diff.DirStatJSON, nameWidth, addWidth, delWidth, barWidth, maxTotal int) {
+	var sb strings.Builder
+
+	name := barsLabel(d.Path)
+	sb.WriteString(r.color(ColorDir))
+	sb.WriteString(name)
+	sb.WriteString(r.color(ColorReset))
+	sb.WriteString(strings.Repeat(" ", nameWidth-VisibleWidth(name)+2))
+
+	adds := r.addCounts(d, r.color)
+	sb.WriteString(adds)
+	sb.WriteString(strings.Repeat(" ", addWidth-VisibleWidth(adds)+1))
+	sb.WriteString(r.color(ColorDel))
+	sb.WriteString(fmt.Sprintf("%-*s", delWidth, fmt.Sprintf("-%d", d.Dels)))
+	sb.WriteString(r.color(ColorReset))
+	sb.WriteString("  ")
+
+	if filled := layout.Scale(r.weight(d), maxTotal, barWidth, 1); filled > 0 {
+		if r.Composition {
+			sb.WriteString(CompositionBar(d.NewAdds, d.Adds, d.Dels, filled, filled, BlockFull, r.color))
+		} else {
+			sb.WriteString(RatioBar(d.Adds, d.Dels, filled, filled, BlockFull, r.color))
+		}
+	}
+
+	fmt.Fprintln(r.w, strings.TrimRight(sb.String(), " "))
+}
+
+// weight is what a row's bar length is proportional to.
+func (r *BarsRenderer) weight(d diff.DirStatJSON) int {
+	if r.Metric == MetricFiles {
+		return d.FileCount
+	}
+	return d.Adds + d.Dels
+}
+
+// addCounts formats a row's additions, split into edits and new-file lines
+// with Composition.
+func (r *BarsRenderer) addCounts(d diff.DirStatJSON, colorFn func(string) string) string {
+	if r.Composition {
+		return NewCodeCounts(d.Adds, d.NewAdds, colorFn)
+	}
+	return fmt.Sprintf("%s+%d%s", colorFn(ColorAdd), d.Adds, colorFn(ColorReset))
+}
+
+// barsLabel formats a directory path for display ("." for root files).
+func barsLabel(path string) string {
+	if path == "." {
+		return "./"
+	}
+	return path + "/"
+}
+
+// color returns the ANSI code if color is enabled.
+func (r *BarsRenderer) color(code string) string {
+	if r.UseColor {
+		return code
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestBarsRenderer_NoChanges(t *testing.T) {
+	var buf bytes.Buffer
+	NewBarsRenderer(&buf, false).Render(&diff.DiffStats{})
+
+	if got := strings.TrimSpace(buf.String()); got != "No changes" {
+		t.Errorf("expected 'No changes', got %q", got)
+	}
+}
+
+func TestBarsRenderer_ScalesToWidth(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewBarsRenderer(&buf, false)
+	r.Width = 60
+	r.MaxDepth = 1
+	r.Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/a.go", Additions: 80, Deletions: 20},
+			{Path: "src/b.go", Additions: 0, Deletions: 0},
+			{Path: "docs/x.md", Additions: 50},
+			{Path: "README.md", Deletions: 1},
+		},
+		TotalFiles: 4, TotalAdd: 130, TotalDel: 21,
+	})
+
+	lines := strings.Split(buf.String(), "\n")
+	if len(lines) < 3 {
+		t.Fatalf("expected at least 3 rows, got %q", buf.String())
+	}
+
+	// Largest directory first, filling the full width.
+	if !strings.HasPrefix(lines[0], "src/") {
+		t.Errorf("first row = %q, want src/ first", lines[0])
+	}
+	if w := VisibleWidth(lines[0]); w != 60 {
+		t.Errorf("largest row width = %d, want 60", w)
+	}
+
+	// Half the changes, roughly half the bar.
+	full := strings.Count(lines[0], BlockFull)
+	half := strings.Count(lines[1], BlockFull)
+	if !strings.HasPrefix(lines[1], "docs/") || half != full/2 {
+		t.Errorf("docs row = %q (%d blocks), want docs/ with %d blocks", lines[1], half, full/2)
+	}
+
+	// Tiny directories still get a visible bar.
+	if !strings.HasPrefix(lines[2], "./") || strings.Count(lines[2], BlockFull) != 1 {
+		t.Errorf("root row = %q, want ./ with 1 block", lines[2])
+	}
+
+	if !strings.Contains(buf.String(), "(4 files, 3 dirs)") {
+		t.Errorf("missing summary, got %q", buf.String())
+	}
+}
+
+func TestBarsRenderer_Composition(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewBarsRenderer(&buf, false)
+	r.MaxDepth = 1
+	r.Composition = true
+	r.Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/a.go", Additions: 120, Deletions: 5},
+			{Path: "src/new.go", Additions: 300, IsUntracked: true},
+			{Path: "docs/x.md", Additions: 4},
+		},
+		TotalFiles: 3, TotalAdd: 424, TotalDel: 5,
+	})
+
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasPrefix(lines[0], "src/   +120 ✚300new -5") {
+		t.Errorf("src row = %q, want split counts", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "docs/  +4           -0") {
+		t.Errorf("docs row = %q, want counts aligned with src", lines[1])
+	}
+}
+
+func TestBarsRenderer_MetricFiles(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewBarsRenderer(&buf, false)
+	r.Width = 60
+	r.MaxDepth = 1
+	r.Metric = MetricFiles
+	r.Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "gen/big.pb.go", Additions: 5000},
+			{Path: "src/a.go", Additions: 3},
+			{Path: "src/b.go", Additions: 2},
+			{Path: "src/c.go", Deletions: 1},
+			{Path: "src/d.go", Additions: 1},
+		},
+		TotalFiles: 5, TotalAdd: 5006, TotalDel: 1,
+	})
+
+	lines := strings.Split(buf.String(), "\n")
+	// Four small files outweigh one huge one: src/ leads and fills the width.
+	if !strings.HasPrefix(lines[0], "src/") || VisibleWidth(lines[0]) != 60 {
+		t.Fatalf("first row = %q, want src/ at full width", lines[0])
+	}
+	full := strings.Count(lines[0], BlockFull)
+	if !strings.HasPrefix(lines[1], "gen/") || strings.Count(lines[1], BlockFull) != full/4 {
+		t.Errorf("gen row = %q, want gen/ with %d blocks", lines[1], full/4)
+	}
+}
@@ -1,11 +1,13 @@
 package render
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"sort"
 	"strings"
 
+	"github.com/kylesnowschwartz/diff-viz/config"
 	"github.com/kylesnowschwartz/diff-viz/diff"
 )
 
@@ -23,25 +25,36 @@ import (
 //	 1 = top-level dirs on separate lines
 //	 2 = expand to depth 2 with indentation, etc.
 type BracketsRenderer struct {
-	UseColor    bool
-	ShowCounts  bool   // Show +N-M instead of bars
-	MaxBarLen   int    // Max bar characters per file (default 4)
-	Width       int    // Max line width before wrapping (default 100)
-	Separator   string // Separator between top-level groups (default " │ ")
-	ExpandDepth int    // Expansion depth: -1=auto, 0=inline, 1+=expand to depth
-	w           io.Writer
+	UseColor      bool
+	ShowCounts    bool           // Show +N-M instead of bars
+	AbbrevCounts  bool           // Shorten counts of 1000 and more with AbbrevCount ("+12.4k")
+	MaxBarLen     int            // Max bar characters per file (default 4)
+	Width         int            // Max line width before wrapping (default 100)
+	Separator     string         // Separator between top-level groups (default " │ ")
+	ItemSeparator string         // Separator between items in a group (default ",")
+	ExpandDepth   int            // Expansion depth: -1=auto, 0=inline, 1+=expand to depth
+	MaxDepth      int            // Directory levels to show (0 = unlimited); deeper dirs fold into totals
+	DirDepths     diff.DirDepths // Per-top-level-directory MaxDepth overrides (see analyze.AutoDepths)
+	RootGroup     string         // Label for the root-level files group ("" = one group per file)
+	SortRootGroup bool           // Sort root groups among directories by total (default: last)
+	BracketColors []string       // Bracket color cycle by depth (default DefaultBracketColors)
+	SizeClass     diff.SizeClass // Optional size label appended to the last line
+	w             io.Writer
 }
 
 // NewBracketsRenderer creates a brackets renderer.
 func NewBracketsRenderer(w io.Writer, useColor bool) *BracketsRenderer {
 	return &BracketsRenderer{
-		UseColor:    useColor,
-		ShowCounts:  true, // +N-M is more readable than bars in dense output
-		MaxBarLen:   4,
-		Width:       100,
-		Separator:   " │ ",
-		ExpandDepth: -1, // auto by default
-		w:           w,
+		UseColor:      useColor,
+		ShowCounts:    true, // +N-M is more readable than bars in dense output
+		MaxBarLen:     4,
+		Width:         100,
+		Separator:     " │ ",
+		ItemSeparator: ",",
+		ExpandDepth:   -1, // auto by default
+		RootGroup:     config.DefaultBracketsRootGroup,
+		BracketColors: DefaultBracketColors,
+		w:             w,
 	}
 }
 
@@ -52,92 +65,128 @@ func (r *BracketsRenderer) Render(stats *diff.DiffStats) {
 		return
 	}
 
+	// Buffered so the size label can follow the last line
+	out := r.w
+	var buf bytes.Buffer
+	r.w = &buf
+	r.render(stats)
+	r.w = out
+	writeSizeSuffixed(r.w, buf.Bytes(), r.SizeClass)
+}
+
+// render writes the groups of a non-empty diff.
+func (r *BracketsRenderer) render(stats *diff.DiffStats) {
 	// Build tree from files
 	tree := buildBracketTree(stats.Files)
 
 	// Collapse single-child directory chains for cleaner output
 	collapseSingleChildPaths(tree)
+	if r.DirDepths != nil {
+		for _, node := range tree {
+			foldBracketDepth([]*bracketNode{node}, r.DirDepths.For(node.Name, r.MaxDepth))
+		}
+	} else {
+		foldBracketDepth(tree, r.MaxDepth)
+	}
 
 	// Find max value for scaling bars
 	maxVal := r.findMaxValue(tree)
-
-	// Separate directories from root files
-	var dirNodes []*bracketNode
-	var rootFiles []*bracketNode
-	for _, node := range tree {
-		if node.IsDir {
-			dirNodes = append(dirNodes, node)
-		} else {
-			rootFiles = append(rootFiles, node)
-		}
-	}
+	groups := r.topGroups(tree)
 
 	// Handle explicit expand depth (not auto)
 	if r.ExpandDepth >= 0 {
 		if r.ExpandDepth > 0 {
-			r.renderExpanded(dirNodes, rootFiles, maxVal, r.ExpandDepth)
+			r.renderExpanded(groups, maxVal, r.ExpandDepth)
 		} else {
-			r.renderInline(dirNodes, rootFiles, maxVal)
+			r.renderInline(groups, maxVal)
 		}
 		return
 	}
 
 	// Auto mode: smart per-group width evaluation
-	r.renderSmart(dirNodes, rootFiles, maxVal)
+	r.renderSmart(groups, maxVal)
 }
 
-// renderSmart uses per-group width evaluation.
-// Groups that fit together share a line; wide groups get their own line and may expand.
-func (r *BracketsRenderer) renderSmart(dirs []*bracketNode, rootFiles []*bracketNode, maxVal int) {
-	// Build list of renderable groups with their inline representations
-	type group struct {
-		node        *bracketNode // nil for root files group
-		inline      string       // inline rendered string
-		width       int          // visible width
-		needsExpand bool         // true if too wide even alone
+// bracketGroup is one top-level group: a directory, a root-level file shown
+// on its own, or the root files gathered under the RootGroup label.
+type bracketGroup struct {
+	node  *bracketNode   // Directory or single root file; nil for the root group
+	files []*bracketNode // Root group members
+}
+
+func (g bracketGroup) total() int {
+	if g.node != nil {
+		return g.node.Total()
+	}
+	total := 0
+	for _, f := range g.files {
+		total += f.Total()
+	}
+	return total
+}
+
+// topGroups splits the top level into groups. Directories keep their order
+// (by total); root files follow as one labelled group, or one group each when
+// RootGroup is empty. SortRootGroup sorts root groups into place by total.
+func (r *BracketsRenderer) topGroups(tree []*bracketNode) []bracketGroup {
+	var groups []bracketGroup
+	var rootFiles []*bracketNode
+	for _, node := range tree {
+		if node.IsDir || node.Folded {
+			groups = append(groups, bracketGroup{node: node})
+		} else {
+			rootFiles = append(rootFiles, node)
+		}
 	}
 
-	var groups []group
-	for _, node := range dirs {
-		inline := r.renderNode(node, maxVal, 0, "")
-		w := VisibleWidth(inline)
-		groups = append(groups, group{
-			node:        node,
-			inline:      inline,
-			width:       w,
-			needsExpand: w > r.Width,
-		})
+	if r.RootGroup == "" {
+		for _, f := range rootFiles {
+			groups = append(groups, bracketGroup{node: f})
+		}
+	} else if len(rootFiles) > 0 {
+		groups = append(groups, bracketGroup{files: rootFiles})
 	}
 
-	// Add root files as a group if present
-	if len(rootFiles) > 0 {
-		var rootPart strings.Builder
-		rootPart.WriteString(r.color(ColorFile))
-		rootPart.WriteString("root:")
-		rootPart.WriteString(r.color(ColorReset))
-		for i, f := range rootFiles {
-			rootPart.WriteString(" ")
-			rootPart.WriteString(r.renderNode(f, maxVal, 0, ""))
-			if i < len(rootFiles)-1 {
-				rootPart.WriteString(",")
-			}
+	if r.SortRootGroup {
+		sort.SliceStable(groups, func(i, j int) bool { return groups[i].total() > groups[j].total() })
+	}
+	return groups
+}
+
+// renderGroup renders a top-level group on one line.
+func (r *BracketsRenderer) renderGroup(g bracketGroup, maxVal int) string {
+	if g.node != nil {
+		return r.renderNode(g.node, maxVal, 0, "")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(r.color(ColorFile))
+	sb.WriteString(r.RootGroup + ":")
+	sb.WriteString(r.color(ColorReset))
+	for i, f := range g.files {
+		sb.WriteString(" ")
+		sb.WriteString(r.renderNode(f, maxVal, 0, ""))
+		if i < len(g.files)-1 {
+			sb.WriteString(r.ItemSeparator)
 		}
-		inline := rootPart.String()
-		groups = append(groups, group{
-			node:        nil,
-			inline:      inline,
-			width:       VisibleWidth(inline),
-			needsExpand: false, // root files don't expand
-		})
 	}
+	return sb.String()
+}
 
-	// Render groups with smart line packing
+// renderSmart uses per-group width evaluation.
+// Groups that fit together share a line; wide groups get their own line and may expand.
+func (r *BracketsRenderer) renderSmart(groups []bracketGroup, maxVal int) {
 	sepWidth := VisibleWidth(r.Separator)
 	var currentLine strings.Builder
 	currentWidth := 0
 
 	for i, g := range groups {
-		if g.needsExpand {
+		inline := r.renderGroup(g, maxVal)
+		width := VisibleWidth(inline)
+		// Only directories expand; file groups stay on one line
+		needsExpand := g.node != nil && g.node.IsDir && width > r.Width
+
+		if needsExpand {
 			// Flush current line if any
 			if currentWidth > 0 {
 				fmt.Fprintln(r.w, currentLine.String())
@@ -148,19 +197,19 @@ func (r *BracketsRenderer) renderSmart(dirs []*bracketNode, rootFiles []*bracket
 			fmt.Fprint(r.w, r.renderNodeExpanded(g.node, maxVal, 0, "", 1))
 		} else if currentWidth == 0 {
 			// First group on line
-			currentLine.WriteString(g.inline)
-			currentWidth = g.width
-		} else if currentWidth+sepWidth+g.width <= r.Width {
+			currentLine.WriteString(inline)
+			currentWidth = width
+		} else if currentWidth+sepWidth+width <= r.Width {
 			// Fits on current line
 			currentLine.WriteString(r.Separator)
-			currentLine.WriteString(g.inline)
-			currentWidth += sepWidth + g.width
+			currentLine.WriteString(inline)
+			currentWidth += sepWidth + width
 		} else {
 			// Doesn't fit - start new line
 			fmt.Fprintln(r.w, currentLine.String())
 			currentLine.Reset()
-			currentLine.WriteString(g.inline)
-			currentWidth = g.width
+			currentLine.WriteString(inline)
+			currentWidth = width
 		}
 
 		// Flush on last group
@@ -170,80 +219,23 @@ func (r *BracketsRenderer) renderSmart(dirs []*bracketNode, rootFiles []*bracket
 	}
 }
 
-// calcInlineWidth estimates total width if rendered inline.
-func (r *BracketsRenderer) calcInlineWidth(dirs []*bracketNode, rootFiles []*bracketNode, maxVal int) int {
-	var parts []string
-	for _, node := range dirs {
-		parts = append(parts, r.renderNode(node, maxVal, 0, ""))
-	}
-	if len(rootFiles) > 0 {
-		var rootPart strings.Builder
-		rootPart.WriteString("root:")
-		for i, f := range rootFiles {
-			rootPart.WriteString(" ")
-			rootPart.WriteString(r.renderNode(f, maxVal, 0, ""))
-			if i < len(rootFiles)-1 {
-				rootPart.WriteString(",")
-			}
-		}
-		parts = append(parts, rootPart.String())
-	}
-
-	total := 0
-	sepWidth := VisibleWidth(r.Separator)
-	for i, p := range parts {
-		total += VisibleWidth(p)
-		if i < len(parts)-1 {
-			total += sepWidth
-		}
-	}
-	return total
-}
-
 // renderInline renders using word-wrap at Width (original behavior).
-func (r *BracketsRenderer) renderInline(dirs []*bracketNode, rootFiles []*bracketNode, maxVal int) {
+func (r *BracketsRenderer) renderInline(groups []bracketGroup, maxVal int) {
 	var parts []string
-	for _, node := range dirs {
-		parts = append(parts, r.renderNode(node, maxVal, 0, ""))
-	}
-
-	if len(rootFiles) > 0 {
-		var rootPart strings.Builder
-		rootPart.WriteString(r.color(ColorFile))
-		rootPart.WriteString("root:")
-		rootPart.WriteString(r.color(ColorReset))
-		for i, f := range rootFiles {
-			rootPart.WriteString(" ")
-			rootPart.WriteString(r.renderNode(f, maxVal, 0, ""))
-			if i < len(rootFiles)-1 {
-				rootPart.WriteString(",")
-			}
-		}
-		parts = append(parts, rootPart.String())
+	for _, g := range groups {
+		parts = append(parts, r.renderGroup(g, maxVal))
 	}
-
 	fmt.Fprintln(r.w, r.wrapJoin(parts))
 }
 
 // renderExpanded renders with multi-line expansion at specified depth.
-func (r *BracketsRenderer) renderExpanded(dirs []*bracketNode, rootFiles []*bracketNode, maxVal int, expandDepth int) {
-	for _, node := range dirs {
-		fmt.Fprint(r.w, r.renderNodeExpanded(node, maxVal, 0, "", expandDepth))
-	}
-
-	if len(rootFiles) > 0 {
-		var rootPart strings.Builder
-		rootPart.WriteString(r.color(ColorFile))
-		rootPart.WriteString("root:")
-		rootPart.WriteString(r.color(ColorReset))
-		for i, f := range rootFiles {
-			rootPart.WriteString(" ")
-			rootPart.WriteString(r.renderNode(f, maxVal, 0, ""))
-			if i < len(rootFiles)-1 {
-				rootPart.WriteString(",")
-			}
+func (r *BracketsRenderer) renderExpanded(groups []bracketGroup, maxVal int, expandDepth int) {
+	for _, g := range groups {
+		if g.node != nil && g.node.IsDir {
+			fmt.Fprint(r.w, r.renderNodeExpanded(g.node, maxVal, 0, "", expandDepth))
+		} else {
+			fmt.Fprintln(r.w, r.renderGroup(g, maxVal))
 		}
-		fmt.Fprintln(r.w, rootPart.String())
 	}
 }
 
@@ -258,7 +250,7 @@ func (r *BracketsRenderer) renderNodeExpanded(node *bracketNode, maxVal int, dep
 	}
 
 	// Directory rendering
-	bracketColor := bracketColors[depth%len(bracketColors)]
+	bracketColor := r.bracketColor(depth)
 
 	// Write the directory name (no bracket at depth 0)
 	if depth > 0 {
@@ -295,7 +287,7 @@ func (r *BracketsRenderer) renderNodeExpanded(node *bracketNode, maxVal int, dep
 			sb.WriteString(" ")
 			sb.WriteString(r.renderNode(child, maxVal, depth+1, indent))
 			if i < len(node.Children)-1 {
-				sb.WriteString(",")
+				sb.WriteString(r.ItemSeparator)
 			}
 		}
 		if depth > 0 {
@@ -361,6 +353,7 @@ type bracketNode struct {
 	Del      int
 	IsDir    bool
 	HasNew   bool
+	Folded   bool // Directory past MaxDepth, rendered as a leaf with totals
 	Children []*bracketNode
 }
 
@@ -399,7 +392,7 @@ func buildBracketTree(files []diff.FileStat) []*bracketNode {
 			// Accumulate stats at this level
 			child.Add += f.Additions
 			child.Del += f.Deletions
-			if f.IsUntracked {
+			if f.IsNew() {
 				child.HasNew = true
 			}
 
@@ -425,6 +418,27 @@ func sortBracketTree(node *bracketNode) {
 	}
 }
 
+// foldBracketDepth turns directories at depth levels into leaves carrying
+// their totals (top-level directories are level 1). depth <= 0 is a no-op.
+func foldBracketDepth(nodes []*bracketNode, depth int) {
+	if depth <= 0 {
+		return
+	}
+	for _, node := range nodes {
+		if !node.IsDir {
+			continue
+		}
+		if depth == 1 {
+			if !strings.HasSuffix(node.Name, "/") {
+				node.Name += "/"
+			}
+			node.IsDir, node.Folded, node.Children = false, true, nil
+		} else {
+			foldBracketDepth(node.Children, depth-1)
+		}
+	}
+}
+
 // collapseSingleChildPaths merges directory chains with single children.
 // Example: [cmd [git-diff-tree main.go]] -> [cmd/git-diff-tree/ main.go]
 func collapseSingleChildPaths(nodes []*bracketNode) {
@@ -463,8 +477,8 @@ func (r *BracketsRenderer) findMaxValue(nodes []*bracketNode) int {
 	return max
 }
 
-// Rainbow bracket colors - cycle through these based on depth
-var bracketColors = []string{
+// DefaultBracketColors is the rainbow cycle applied to brackets by depth.
+var DefaultBracketColors = []string{
 	"\033[36m", // Cyan
 	"\033[33m", // Yellow
 	"\033[35m", // Magenta
@@ -472,6 +486,19 @@ var bracketColors = []string{
 	"\033[34m", // Blue
 }
 
+// PlainBracketColors uses a single dim color for all depths (--no-rainbow).
+var PlainBracketColors = []string{ColorDim}
+
+// bracketColor returns the bracket color for a nesting depth.
+// Falls back to DefaultBracketColors when BracketColors is empty.
+func (r *BracketsRenderer) bracketColor(depth int) string {
+	colors := r.BracketColors
+	if len(colors) == 0 {
+		colors = DefaultBracketColors
+	}
+	return colors[depth%len(colors)]
+}
+
 // renderNode recursively renders a node and its children.
 // indent is used for multi-line expanded output.
 func (r *BracketsRenderer) renderNode(node *bracketNode, maxVal int, depth int, indent string) string {
@@ -480,7 +507,7 @@ func (r *BracketsRenderer) renderNode(node *bracketNode, maxVal int, depth int,
 	if node.IsDir {
 		// Directory: [name/ children...] with rainbow brackets
 		// Skip brackets at depth 0 (top-level) to reduce visual noise
-		bracketColor := bracketColors[depth%len(bracketColors)]
+		bracketColor := r.bracketColor(depth)
 		if depth > 0 {
 			sb.WriteString(r.color(bracketColor))
 			sb.WriteString("[")
@@ -501,7 +528,7 @@ func (r *BracketsRenderer) renderNode(node *bracketNode, maxVal int, depth int,
 			sb.WriteString(r.renderNode(child, maxVal, depth+1, indent))
 			// Add comma between children (not after last)
 			if i < len(node.Children)-1 {
-				sb.WriteString(",")
+				sb.WriteString(r.ItemSeparator)
 			}
 		}
 		if depth > 0 {
@@ -515,6 +542,9 @@ func (r *BracketsRenderer) renderNode(node *bracketNode, maxVal int, depth int,
 		if node.HasNew {
 			nameColor = ColorNew
 		}
+		if node.Folded {
+			nameColor = ColorDir
+		}
 		sb.WriteString(r.color(nameColor))
 		sb.WriteString(node.Name)
 		sb.WriteString(r.color(ColorReset))
@@ -524,13 +554,13 @@ func (r *BracketsRenderer) renderNode(node *bracketNode, maxVal int, depth int,
 			if node.Add > 0 {
 				sb.WriteString(" ")
 				sb.WriteString(r.color(ColorAdd))
-				sb.WriteString(fmt.Sprintf("+%d", node.Add))
+				sb.WriteString("+" + formatCount(node.Add, r.AbbrevCounts))
 				sb.WriteString(r.color(ColorReset))
 			}
 			if node.Del > 0 {
 				sb.WriteString(" ")
 				sb.WriteString(r.color(ColorDel))
-				sb.WriteString(fmt.Sprintf("-%d", node.Del))
+				sb.WriteString("-" + formatCount(node.Del, r.AbbrevCounts))
 				sb.WriteString(r.color(ColorReset))
 			}
 		} else {
@@ -0,0 +1,122 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestBracketsRenderer_RootGroup(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/a.go", Additions: 2},
+			{Path: "README.md", Additions: 10},
+			{Path: "go.mod", Additions: 1},
+		},
+		TotalFiles: 3, TotalAdd: 13,
+	}
+
+	tests := []struct {
+		name  string
+		label string
+		sort  bool
+		want  string
+	}{
+		{"default last", "root", false, "src/ a.go +2 │ root: README.md +10, go.mod +1"},
+		{"renamed", "top", false, "src/ a.go +2 │ top: README.md +10, go.mod +1"},
+		{"sorted", "root", true, "root: README.md +10, go.mod +1 │ src/ a.go +2"},
+		{"distributed", "", false, "src/ a.go +2 │ README.md +10 │ go.mod +1"},
+		{"distributed sorted", "", true, "README.md +10 │ src/ a.go +2 │ go.mod +1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			r := NewBracketsRenderer(&buf, false)
+			r.RootGroup = tt.label
+			r.SortRootGroup = tt.sort
+			r.Render(stats)
+			if got := strings.TrimSpace(buf.String()); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBracketsRenderer_Separators(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewBracketsRenderer(&buf, false)
+	r.Separator = " / "
+	r.ItemSeparator = ";"
+	r.Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/a.go", Additions: 3},
+			{Path: "src/b.go", Additions: 2},
+			{Path: "README.md", Additions: 1},
+			{Path: "go.mod", Additions: 1},
+		},
+		TotalFiles: 4, TotalAdd: 7,
+	})
+	if got, want := strings.TrimSpace(buf.String()), "src/ a.go +3; b.go +2 / root: README.md +1; go.mod +1"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestBracketsRenderer_AbbrevCounts(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/gen.go", Additions: 12400, Deletions: 3100},
+			{Path: "src/main.go", Additions: 7},
+		},
+		TotalFiles: 2, TotalAdd: 12407, TotalDel: 3100,
+	}
+	var buf bytes.Buffer
+	r := NewBracketsRenderer(&buf, false)
+	r.AbbrevCounts = true
+	r.Render(stats)
+
+	if want := "src/ gen.go +12.4k -3.1k, main.go +7\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestBracketsRenderer_SizeClass(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewBracketsRenderer(&buf, false)
+	r.ExpandDepth = 1
+	r.SizeClass = diff.SizeM
+	r.Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/a.go", Additions: 30},
+			{Path: "docs/b.md", Additions: 2},
+		},
+		TotalFiles: 2, TotalAdd: 32,
+	})
+	if want := "src/\n  a.go +30\ndocs/\n  b.md +2 [M]\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestAbbrevCount(t *testing.T) {
+	tests := map[int]string{
+		0:         "0",
+		999:       "999",
+		1000:      "1k",
+		1049:      "1k",
+		12400:     "12.4k",
+		-3100:     "-3.1k",
+		99949:     "99.9k",
+		310_000:   "310k",
+		999_499:   "999k",
+		999_600:   "1M",
+		1_234_567: "1.2M",
+		250e6:     "250M",
+	}
+	for n, want := range tests {
+		if got := AbbrevCount(n); got != want {
+			t.Errorf("AbbrevCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// BranchesRenderer prints one line per local branch, least recently active
+// first, so stale branches surface at the top. Like StashesRenderer, it
+// consumes branch entries rather than a single DiffStats.
+// Format: feature/x  main  ↑3 ↓12  +120  -40    3 months ago
+type BranchesRenderer struct {
+	UseColor bool
+	Now      time.Time // Reference time for ages (zero = time.Now)
+	w        io.Writer
+}
+
+// NewBranchesRenderer creates a branch list renderer.
+func NewBranchesRenderer(w io.Writer, useColor bool) *BranchesRenderer {
+	return &BranchesRenderer{UseColor: useColor, w: w}
+}
+
+// RenderBranches outputs one aligned line per branch. Branches without a
+// base show "-" for the comparison columns, and branches whose comparison
+// failed show "?".
+func (r *BranchesRenderer) RenderBranches(entries []diff.BranchEntry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(r.w, "No branches")
+		return
+	}
+
+	now := r.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	nameWidth, baseWidth, divWidth := 0, 0, 0
+	bases := make([]string, len(entries))
+	divergence := make([]string, len(entries))
+	for i, e := range entries {
+		bases[i] = orDash(e.Base)
+		if e.UpstreamGone {
+			bases[i] += " (upstream gone)"
+		}
+		switch {
+		case e.Base == "":
+			divergence[i] = "-"
+		case !e.Compared():
+			divergence[i] = "?"
+		default:
+			divergence[i] = fmt.Sprintf("↑%d ↓%d", e.Ahead, e.Behind)
+		}
+		nameWidth = max(nameWidth, VisibleWidth(e.Name))
+		baseWidth = max(baseWidth, VisibleWidth(bases[i]))
+		divWidth = max(divWidth, VisibleWidth(divergence[i]))
+	}
+
+	for i, e := range entries {
+		base := bases[i]
+		var sb strings.Builder
+		sb.WriteString(e.Name)
+		sb.WriteString(strings.Repeat(" ", nameWidth-VisibleWidth(e.Name)+2))
+		sb.WriteString(r.color(ColorDim))
+		sb.WriteString(base)
+		sb.WriteString(r.color(ColorReset))
+		sb.WriteString(strings.Repeat(" ", baseWidth-VisibleWidth(base)+2))
+		sb.WriteString(divergence[i])
+		sb.WriteString(strings.Repeat(" ", divWidth-VisibleWidth(divergence[i])+2))
+		if e.Compared() {
+			sb.WriteString(fmt.Sprintf("%s+%-5d%s %s-%-5d%s ", r.color(ColorAdd), e.Stats.TotalAdd, r.color(ColorReset), r.color(ColorDel), e.Stats.TotalDel, r.color(ColorReset)))
+		} else {
+			sb.WriteString(fmt.Sprintf("%-6s %-6s ", divergence[i], divergence[i]))
+		}
+		sb.WriteString(r.color(ColorDim))
+		sb.WriteString(RelativeAge(e.Time, now))
+		sb.WriteString(r.color(ColorReset))
+		fmt.Fprintln(r.w, sb.String())
+	}
+}
+
+// color returns the ANSI code if color is enabled.
+func (r *BranchesRenderer) color(code string) string {
+	if r.UseColor {
+		return code
+	}
+	return ""
+}
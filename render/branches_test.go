@@ -0,0 +1,32 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestBranchesRenderer(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	r := NewBranchesRenderer(&buf, false)
+	r.Now = now
+	r.RenderBranches([]diff.BranchEntry{
+		{
+			Name: "spike", Base: "main", Ahead: 3, Behind: 12, Time: now.Add(-100 * 24 * time.Hour),
+			Stats: &diff.DiffStats{TotalAdd: 120, TotalDel: 40},
+		},
+		{Name: "merged", Base: "main", UpstreamGone: true, Time: now.Add(-30 * 24 * time.Hour)},
+		{Name: "main", Time: now.Add(-2 * time.Hour)},
+	})
+
+	// merged's comparison failed: no ahead/behind or totals, rather than zeros
+	want := "spike   main                  ↑3 ↓12  +120   -40    3 months ago\n" +
+		"merged  main (upstream gone)  ?       ?      ?      1 month ago\n" +
+		"main    -                     -       -      -      2 hours ago\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,188 @@
+// Package bubbletea embeds diff-viz visualizations in bubbletea programs.
+//
+// A Model renders one mode (see render.ModeNames) into its View, reflowing
+// to the window size it receives:
+//
+//	m := bubbletea.New("smart", stats)
+//	// in the parent's Update: m, cmd = m.Update(msg)
+//	// in the parent's View: m.View()
+//
+// Send a StatsMsg to swap in new diff stats (e.g., after a file watcher fires).
+// SetOffset scrolls the view; only the visible page is kept from each render
+// (see render.RenderPage), so huge trees stay cheap to show.
+package bubbletea
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kylesnowschwartz/diff-viz/config"
+	"github.com/kylesnowschwartz/diff-viz/diff"
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+// StatsMsg replaces the stats a Model displays.
+type StatsMsg struct {
+	Stats *diff.DiffStats
+}
+
+// Model is a bubbletea component showing one diff-viz mode.
+// Width and height start at 0 (unbounded) until a tea.WindowSizeMsg or
+// SetSize call; width-aware modes then fill the width and every mode is
+// clipped to the height.
+type Model struct {
+	Mode     string                // Mode name (see render.ModeNames)
+	Config   config.ResolvedConfig // Mode options; Width is taken from the model size
+	UseColor bool
+
+	stats  *diff.DiffStats
+	width  int
+	height int
+	offset int
+}
+
+// New returns a Model for mode with the mode's built-in defaults and color on.
+func New(mode string, stats *diff.DiffStats) Model {
+	return Model{
+		Mode:     mode,
+		Config:   config.DefaultsForMode(mode),
+		UseColor: true,
+		stats:    stats,
+	}
+}
+
+// Init implements tea.Model. The model has no startup command.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model, handling window resizes and StatsMsg.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.SetSize(msg.Width, msg.Height)
+	case StatsMsg:
+		m.stats = msg.Stats
+	}
+	return m, nil
+}
+
+// SetSize sets the area the view must fit (0 = unbounded).
+// Parents laying out several components call this instead of forwarding
+// tea.WindowSizeMsg.
+func (m *Model) SetSize(width, height int) {
+	m.width, m.height = width, height
+}
+
+// SetStats replaces the displayed stats.
+func (m *Model) SetStats(stats *diff.DiffStats) {
+	m.stats = stats
+}
+
+// SetOffset scrolls the view to start at line n of the rendering (0 = top).
+// Offsets past the end show the last page.
+func (m *Model) SetOffset(n int) {
+	m.offset = max(n, 0)
+}
+
+// Offset returns the first rendered line the view shows.
+func (m Model) Offset() int { return m.offset }
+
+// Width returns the current view width (0 = unbounded).
+func (m Model) Width() int { return m.width }
+
+// Height returns the current view height (0 = unbounded).
+func (m Model) Height() int { return m.height }
+
+// View implements tea.Model, rendering the mode to fit the current size.
+func (m Model) View() string {
+	if m.stats == nil {
+		return ""
+	}
+
+	if _, err := m.renderer(io.Discard); err != nil {
+		return err.Error()
+	}
+	page := func(w io.Writer) render.Renderer {
+		r, _ := m.renderer(w)
+		return r
+	}
+
+	var buf bytes.Buffer
+	offset := m.offset
+	total := render.RenderPage(&buf, m.stats, offset, m.height, page)
+	if offset > 0 && offset >= total {
+		// Scrolled past the end (the stats shrank): show the last page
+		offset = max(total-m.height, 0)
+		buf.Reset()
+		render.RenderPage(&buf, m.stats, offset, m.height, page)
+	}
+
+	return m.fit(strings.TrimRight(buf.String(), "\n"), total-offset)
+}
+
+// renderer builds the configured renderer writing to w.
+func (m Model) renderer(w io.Writer) (render.Renderer, error) {
+	opts, err := render.OptionsFromConfig(m.Config)
+	if err != nil {
+		return nil, err
+	}
+	opts.UseColor = m.UseColor
+	if m.width > 0 {
+		opts.Width = m.width
+	}
+	return render.NewRenderer(m.Mode, w, opts)
+}
+
+// fit clips lines to the model width and the line count to its height.
+// remaining counts the rendered lines from the top of view on; when they do
+// not all fit, the last visible line becomes a "… N more lines" marker.
+func (m Model) fit(view string, remaining int) string {
+	lines := strings.Split(view, "\n")
+	if m.height > 0 && remaining > m.height {
+		hidden := remaining - m.height + 1
+		lines = append(lines[:min(m.height-1, len(lines))], fmt.Sprintf("… %d more lines", hidden))
+	}
+	if m.width > 0 {
+		for i, line := range lines {
+			lines[i] = clipLine(line, m.width)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// clipLine truncates s to width visible columns, keeping ANSI escape
+// sequences intact and resetting color if the cut lands inside colored text.
+func clipLine(s string, width int) string {
+	if render.VisibleWidth(s) <= width {
+		return s
+	}
+
+	var sb strings.Builder
+	inEscape, colored := false, false
+	visible := 0
+	for _, r := range s {
+		switch {
+		case r == '\033':
+			inEscape, colored = true, true
+		case inEscape:
+			if r == 'm' {
+				inEscape = false
+			}
+		default:
+			if visible == width {
+				if colored {
+					sb.WriteString(render.ColorReset)
+				}
+				return sb.String()
+			}
+			visible++
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
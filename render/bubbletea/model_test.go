@@ -0,0 +1,84 @@
+package bubbletea
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+	"github.com/kylesnowschwartz/diff-viz/render"
+)
+
+func testStats() *diff.DiffStats {
+	return &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/a.go", Additions: 50, Deletions: 3},
+			{Path: "src/lib/b.go", Additions: 40},
+			{Path: "docs/c.md", Deletions: 7},
+		},
+		TotalFiles: 3, TotalAdd: 90, TotalDel: 10,
+	}
+}
+
+func TestModel_ReflowsToWindowSize(t *testing.T) {
+	for _, mode := range render.ModeNames() {
+		t.Run(mode, func(t *testing.T) {
+			m := New(mode, testStats())
+			m.UseColor = false
+
+			updated, _ := m.Update(tea.WindowSizeMsg{Width: 40, Height: 4})
+			view := updated.View()
+
+			lines := strings.Split(view, "\n")
+			if len(lines) > 4 {
+				t.Errorf("view has %d lines, want <= 4:\n%s", len(lines), view)
+			}
+			for _, line := range lines {
+				if w := render.VisibleWidth(line); w > 40 {
+					t.Errorf("line %q is %d wide, want <= 40", line, w)
+				}
+			}
+		})
+	}
+}
+
+func TestModel_StatsMsg(t *testing.T) {
+	m := New("tree", nil)
+	if m.View() != "" {
+		t.Errorf("View() without stats = %q, want empty", m.View())
+	}
+
+	updated, _ := m.Update(StatsMsg{Stats: testStats()})
+	if !strings.Contains(updated.View(), "b.go") {
+		t.Errorf("View() after StatsMsg missing b.go:\n%s", updated.View())
+	}
+}
+
+func TestModel_SetOffset(t *testing.T) {
+	m := New("tree", testStats())
+	m.UseColor = false
+	m.SetSize(0, 3)
+	m.SetOffset(2)
+
+	// Tree lines: docs/, c.md, src/, a.go, lib/, b.go, "", summary
+	lines := strings.Split(m.View(), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "src/") || lines[2] != "… 4 more lines" {
+		t.Errorf("View() at offset 2 = %q, want src/ first and a 4-line marker", lines)
+	}
+
+	m.SetOffset(100)
+	if view := m.View(); !strings.HasSuffix(view, "in 3 files") {
+		t.Errorf("View() past the end = %q, want the last page", view)
+	}
+}
+
+func TestClipLine(t *testing.T) {
+	colored := render.ColorAdd + "abcdef" + render.ColorReset
+	if got, want := clipLine(colored, 3), render.ColorAdd+"abc"+render.ColorReset; got != want {
+		t.Errorf("clipLine(colored, 3) = %q, want %q", got, want)
+	}
+	if got := clipLine("short", 10); got != "short" {
+		t.Errorf("clipLine(short, 10) = %q, want unchanged", got)
+	}
+}
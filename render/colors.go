@@ -1,13 +1,27 @@
 package render
 
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
 // ANSI color codes for diff visualization.
 const (
-	ColorDir   = "\033[34m"     // Blue for directories
-	ColorFile  = "\033[38;5;8m" // Dark gray for files
-	ColorNew   = "\033[33m"     // Yellow for untracked/new
-	ColorAdd   = "\033[32m"     // Green for additions
-	ColorDel   = "\033[31m"     // Red for deletions
-	ColorReset = "\033[0m"      // Reset to default
+	ColorDir      = "\033[34m"     // Blue for directories
+	ColorFile     = "\033[38;5;8m" // Dark gray for files
+	ColorNew      = "\033[33m"     // Yellow for untracked/new
+	ColorAdd      = "\033[32m"     // Green for additions
+	ColorDel      = "\033[31m"     // Red for deletions
+	ColorDim      = "\033[2m"      // Dim/faint for de-emphasized glyphs
+	ColorConflict = "\033[1;35m"   // Bold magenta for unmerged paths
+	ColorCleanup  = "\033[36m"     // Cyan for deletion-only directories
+	ColorReset    = "\033[0m"      // Reset to default
 )
 
 // ColorFunc returns a function that wraps text in ANSI color codes.
@@ -19,6 +33,18 @@ func ColorFunc(useColor bool) func(string) string {
 	return func(string) string { return "" }
 }
 
+// ParseColor converts a user-supplied color into an ANSI escape sequence.
+// Accepts raw SGR parameters ("36", "38;5;208") or a full escape sequence.
+func ParseColor(s string) (string, error) {
+	if strings.HasPrefix(s, "\033[") {
+		return s, nil
+	}
+	if s == "" || strings.Trim(s, "0123456789;") != "" {
+		return "", fmt.Errorf("invalid color %q (want SGR parameters like \"36\" or \"38;5;208\")", s)
+	}
+	return "\033[" + s + "m", nil
+}
+
 // Separator returns the appropriate separator for output.
 // Returns box-drawing character when colors are enabled, ASCII otherwise.
 func Separator(useColor bool) string {
@@ -28,7 +54,9 @@ func Separator(useColor bool) string {
 	return " | "
 }
 
-// VisibleWidth calculates display width excluding ANSI escape sequences.
+// VisibleWidth calculates display width excluding ANSI escape sequences and
+// zero-width runes (combining marks such as Hebrew and Arabic vowel points,
+// and format characters such as bidi marks).
 // Used for accurate line-width calculations with colored output.
 func VisibleWidth(s string) int {
 	inEscape := false
@@ -44,7 +72,190 @@ func VisibleWidth(s string) int {
 			}
 			continue
 		}
+		if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+			continue
+		}
 		width++
 	}
 	return width
 }
+
+// sizeSuffix formats an optional size class label for summary lines.
+// Returns empty string when no class is set.
+func sizeSuffix(class diff.SizeClass) string {
+	if class == "" {
+		return ""
+	}
+	return " [" + string(class) + "]"
+}
+
+// writeSizeSuffixed writes output to w with sizeSuffix appended to its last
+// line, for modes without a summary line.
+func writeSizeSuffixed(w io.Writer, output []byte, class diff.SizeClass) {
+	if suffix := sizeSuffix(class); suffix != "" && bytes.HasSuffix(output, []byte("\n")) {
+		output = append(bytes.TrimSuffix(output, []byte("\n")), suffix+"\n"...)
+	}
+	w.Write(output)
+}
+
+// excludedSuffix formats the totals without excluded categories for summary
+// lines, e.g. " (excl. generated: +200 -48)"; empty when nothing matched.
+func excludedSuffix(stats *diff.DiffStats, ex diff.ExcludedTotals) string {
+	if ex.Files == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (excl. %s: +%d -%d)", strings.Join(ex.Names, ", "), stats.TotalAdd-ex.Add, stats.TotalDel-ex.Del)
+}
+
+// ConflictMarker flags unmerged paths in file listings.
+const ConflictMarker = "‼"
+
+// writeConflicts prints a separate section listing unmerged paths, if any.
+func writeConflicts(w io.Writer, stats *diff.DiffStats, color func(string) string) {
+	var paths []string
+	for _, f := range stats.Files {
+		if f.IsUnmerged {
+			paths = append(paths, f.Path)
+		}
+	}
+	if len(paths) == 0 {
+		return
+	}
+
+	noun := "conflicts"
+	if len(paths) == 1 {
+		noun = "conflict"
+	}
+	fmt.Fprintf(w, "\n%s%s %d unresolved %s:%s\n", color(ColorConflict), ConflictMarker, len(paths), noun, color(ColorReset))
+	for _, p := range paths {
+		fmt.Fprintf(w, "  %s%s%s\n", color(ColorConflict), p, color(ColorReset))
+	}
+}
+
+// CleanupMarker flags directories whose changes are mostly deletions.
+const CleanupMarker = "✂"
+
+// DefaultCleanupRatio is the share of changed lines that must be deletions
+// for a directory to count as cleanup.
+const DefaultCleanupRatio = 0.9
+
+// IsCleanup reports whether del is at least ratio of the add+del changed
+// lines. A ratio of 0 or less turns the check off.
+func IsCleanup(add, del int, ratio float64) bool {
+	return ratio > 0 && del > 0 && float64(del) >= ratio*float64(add+del)
+}
+
+// DeletedMarker flags deleted files in file listings.
+const DeletedMarker = "✖"
+
+// writeDeleted prints a separate section listing deleted files with the
+// lines (or with bytes set, bytes) each removed, if any.
+func writeDeleted(w io.Writer, stats *diff.DiffStats, bytes bool, color func(string) string) {
+	deleted := stats.DeletedFiles()
+	if len(deleted) == 0 {
+		return
+	}
+
+	lines := 0
+	for _, f := range deleted {
+		lines += f.Deletions
+	}
+	noun := "files"
+	if len(deleted) == 1 {
+		noun = "file"
+	}
+	removed := fmt.Sprintf("-%d lines", lines)
+	if bytes {
+		removed = "-" + FormatBytes(int64(lines))
+	}
+	fmt.Fprintf(w, "\n%s%s %d deleted %s (%s):%s\n", color(ColorDel), DeletedMarker, len(deleted), noun, removed, color(ColorReset))
+	for _, f := range deleted {
+		fmt.Fprintf(w, "  %s%s%s -%s\n", color(ColorDel), f.Path, color(ColorReset), formatAmount(f.Deletions, bytes))
+	}
+}
+
+// writeBinarySummary prints one line counting binary files by category,
+// with their size change when known, if there are any.
+func writeBinarySummary(w io.Writer, stats *diff.DiffStats, color func(string) string) {
+	summary := stats.BinarySummary()
+	if len(summary) == 0 {
+		return
+	}
+
+	parts := make([]string, len(summary))
+	for i, t := range summary {
+		parts[i] = fmt.Sprintf("%s: %d", t.Category, t.Files)
+		if t.HasSizeDelta {
+			parts[i] += " (" + FormatByteDelta(t.SizeDelta) + ")"
+		}
+	}
+	fmt.Fprintf(w, "\n%sbinary:%s %s\n", color(ColorDim), color(ColorReset), strings.Join(parts, ", "))
+}
+
+// FormatByteDelta formats a signed byte count with a binary unit suffix
+// ("+240KB", "-1.5MB", "+12B"), keeping one decimal below 10 units.
+func FormatByteDelta(n int64) string {
+	if n < 0 {
+		return "-" + FormatBytes(-n)
+	}
+	return "+" + FormatBytes(n)
+}
+
+// FormatBytes formats a byte count in the largest binary unit it fills
+// ("240KB", "1.5MB", "12B"), keeping one decimal below 10 units.
+func FormatBytes(n int64) string {
+	units := []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}}
+	for _, u := range units {
+		if n < u.size {
+			continue
+		}
+		value := float64(n) / float64(u.size)
+		if value < 10 {
+			return strings.TrimSuffix(fmt.Sprintf("%.1f", value), ".0") + u.suffix
+		}
+		return fmt.Sprintf("%.0f", value) + u.suffix
+	}
+	return fmt.Sprintf("%dB", n)
+}
+
+// AbbrevCount formats a count with a decimal suffix once it reaches 1000
+// ("950", "12.4k", "310k", "1.2M"), keeping one decimal below 100 units.
+func AbbrevCount(n int) string {
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	if n < 1000 {
+		return sign + strconv.Itoa(n)
+	}
+	value, suffix := float64(n)/1e3, "k"
+	// Rounding can reach the next unit: 999,600 is "1M", not "1000k"
+	if n >= 999_500 {
+		value, suffix = float64(n)/1e6, "M"
+	}
+	if value >= 100 {
+		return sign + fmt.Sprintf("%.0f", value) + suffix
+	}
+	return sign + strings.TrimSuffix(fmt.Sprintf("%.1f", value), ".0") + suffix
+}
+
+// formatCount formats a changed-line count, abbreviated with AbbrevCount
+// when abbrev is set.
+func formatCount(n int, abbrev bool) string {
+	if abbrev {
+		return AbbrevCount(n)
+	}
+	return strconv.Itoa(n)
+}
+
+// formatAmount formats a changed-line count, or a byte count (see
+// diff.DiffStats.InBytes) with FormatBytes when bytes is set.
+func formatAmount(n int, bytes bool) string {
+	if bytes {
+		return FormatBytes(int64(n))
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,28 @@
+package render
+
+import "testing"
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"36", "\033[36m", false},
+		{"38;5;208", "\033[38;5;208m", false},
+		{"\033[35m", "\033[35m", false}, // full escape passed through
+		{"", "", true},
+		{"red", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseColor(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseColor(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseColor(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
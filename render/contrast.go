@@ -0,0 +1,124 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MinContrast is the lowest contrast ratio ContrastWarnings accepts between
+// a foreground color and the background: WCAG's minimum for large text,
+// which terminal glyphs in a monospace font approximate.
+const MinContrast = 3.0
+
+// Background is the terminal background colors are checked against.
+type Background struct {
+	Name     string // "dark" or "light"
+	RGB      [3]int
+	Detected bool // From COLORFGBG; false means dark was assumed
+}
+
+// DetectBackground reads COLORFGBG from the environment.
+func DetectBackground() Background {
+	return BackgroundFor(os.Getenv("COLORFGBG"))
+}
+
+// BackgroundFor reads a COLORFGBG value ("15;0", or "15;default;0"), whose
+// last field is the background's basic color index. Terminals that do not
+// set it are assumed to be dark.
+func BackgroundFor(colorfgbg string) Background {
+	fields := strings.Split(colorfgbg, ";")
+	if index, err := strconv.Atoi(fields[len(fields)-1]); err == nil && index >= 0 && index < 16 {
+		name := "dark"
+		if index == 7 || index >= 9 {
+			name = "light"
+		}
+		return Background{Name: name, RGB: ansi16Colors[index], Detected: true}
+	}
+	return Background{Name: "dark", RGB: ansi16Colors[0]}
+}
+
+// ForegroundRGB returns the approximate foreground color an SGR escape
+// sequence sets: basic colors (30-37, 90-97) use xterm's defaults, and
+// 38;5;N and 38;2;R;G;B are converted. ok is false when the sequence sets
+// no foreground color.
+func ForegroundRGB(code string) (rgb [3]int, ok bool) {
+	params := strings.TrimSuffix(strings.TrimPrefix(code, "\033["), "m")
+	parts := strings.Split(params, ";")
+	for i := 0; i < len(parts); i++ {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			continue
+		}
+		switch {
+		case n >= 30 && n <= 37:
+			rgb, ok = ansi16Colors[n-30], true
+		case n >= 90 && n <= 97:
+			rgb, ok = ansi16Colors[n-90+8], true
+		case n == 38 && i+2 < len(parts) && parts[i+1] == "5":
+			index := atoiByte(parts[i+2])
+			if index < 16 {
+				rgb = ansi16Colors[index]
+			} else {
+				rgb = palette256(index)
+			}
+			ok = true
+			i += 2
+		case n == 38 && i+4 < len(parts) && parts[i+1] == "2":
+			rgb, ok = [3]int{atoiByte(parts[i+2]), atoiByte(parts[i+3]), atoiByte(parts[i+4])}, true
+			i += 4
+		case n == 48 && i+1 < len(parts):
+			// Skip background color arguments
+			if parts[i+1] == "5" {
+				i += 2
+			} else if parts[i+1] == "2" {
+				i += 4
+			}
+		}
+	}
+	return rgb, ok
+}
+
+// ContrastRatio returns the WCAG contrast ratio of two colors, from 1 (same
+// luminance) to 21 (black on white).
+func ContrastRatio(a, b [3]int) float64 {
+	la, lb := luminance(a), luminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// luminance is the WCAG relative luminance of an sRGB color.
+func luminance(rgb [3]int) float64 {
+	var channels [3]float64
+	for i, v := range rgb {
+		c := float64(v) / 255
+		if c <= 0.03928 {
+			channels[i] = c / 12.92
+		} else {
+			channels[i] = math.Pow((c+0.055)/1.055, 2.4)
+		}
+	}
+	return 0.2126*channels[0] + 0.7152*channels[1] + 0.0722*channels[2]
+}
+
+// ContrastWarnings describes the colors in codes (escape sequences, as
+// ParseColor returns) that fall below MinContrast against bg. name labels
+// the setting they came from, such as "bracketColors".
+func ContrastWarnings(name string, codes []string, bg Background) []string {
+	var warnings []string
+	for i, code := range codes {
+		rgb, ok := ForegroundRGB(code)
+		if !ok {
+			continue
+		}
+		if ratio := ContrastRatio(rgb, bg.RGB); ratio < MinContrast {
+			warnings = append(warnings, fmt.Sprintf("%s[%d] %q: contrast %.1f:1 on a %s background is hard to read (want at least %.0f:1)",
+				name, i, strings.TrimSuffix(strings.TrimPrefix(code, "\033["), "m"), ratio, bg.Name, MinContrast))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,82 @@
+package render
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestBackgroundFor(t *testing.T) {
+	tests := []struct {
+		colorfgbg string
+		want      string
+		detected  bool
+	}{
+		{"15;0", "dark", true},
+		{"0;15", "light", true},
+		{"0;default;7", "light", true},
+		{"7;8", "dark", true},
+		{"", "dark", false},
+		{"garbage", "dark", false},
+	}
+
+	for _, tt := range tests {
+		got := BackgroundFor(tt.colorfgbg)
+		if got.Name != tt.want || got.Detected != tt.detected {
+			t.Errorf("BackgroundFor(%q) = %s (detected %v), want %s (detected %v)", tt.colorfgbg, got.Name, got.Detected, tt.want, tt.detected)
+		}
+	}
+}
+
+func TestForegroundRGB(t *testing.T) {
+	tests := []struct {
+		code   string
+		want   [3]int
+		wantOK bool
+	}{
+		{"\033[31m", [3]int{205, 0, 0}, true},
+		{"\033[1;91m", [3]int{255, 0, 0}, true},
+		{"\033[38;5;8m", [3]int{127, 127, 127}, true},
+		{"\033[38;5;16m", [3]int{0, 0, 0}, true},
+		{"\033[38;2;10;20;30m", [3]int{10, 20, 30}, true},
+		{"\033[48;5;21;33m", [3]int{205, 205, 0}, true},
+		{"\033[2m", [3]int{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ForegroundRGB(tt.code)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ForegroundRGB(%q) = %v, %v; want %v, %v", tt.code, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestContrastRatio(t *testing.T) {
+	black, white := [3]int{0, 0, 0}, [3]int{255, 255, 255}
+	if got := ContrastRatio(white, black); math.Abs(got-21) > 0.01 {
+		t.Errorf("white on black = %.2f, want 21", got)
+	}
+	if got := ContrastRatio(black, white); math.Abs(got-21) > 0.01 {
+		t.Errorf("black on white = %.2f, want 21", got)
+	}
+	if got := ContrastRatio(white, white); got != 1 {
+		t.Errorf("white on white = %.2f, want 1", got)
+	}
+}
+
+func TestContrastWarnings(t *testing.T) {
+	dark := BackgroundFor("15;0")
+	codes := []string{"\033[33m", "\033[38;5;234m", ColorDim}
+	warnings := ContrastWarnings("bracketColors", codes, dark)
+	if len(warnings) != 1 {
+		t.Fatalf("got %d warnings, want 1: %q", len(warnings), warnings)
+	}
+	if !strings.Contains(warnings[0], `bracketColors[1] "38;5;234"`) || !strings.Contains(warnings[0], "dark background") {
+		t.Errorf("warning = %q", warnings[0])
+	}
+
+	light := BackgroundFor("0;15")
+	if warnings := ContrastWarnings("bracketColors", []string{"\033[93m"}, light); len(warnings) != 1 {
+		t.Errorf("bright yellow on light: got %q, want one warning", warnings)
+	}
+}
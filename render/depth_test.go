@@ -0,0 +1,92 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func depthTestStats() *diff.DiffStats {
+	return &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/lib/deep/a.go", Additions: 10, Deletions: 2},
+			{Path: "src/lib/b.go", Additions: 5},
+			{Path: "src/main.go", Additions: 1, Deletions: 1},
+			{Path: "docs/guide.md", Additions: 3},
+		},
+		TotalFiles: 4, TotalAdd: 19, TotalDel: 3,
+	}
+}
+
+func TestTreeRenderer_MaxDepth(t *testing.T) {
+	tests := []struct {
+		depth   int
+		want    []string
+		notWant []string
+	}{
+		{0, []string{"a.go", "b.go", "main.go", "guide.md"}, nil},
+		{1, []string{"src/", "+16 -3", "docs/", "+3"}, []string{"lib/", "main.go", "guide.md"}},
+		{2, []string{"lib/", "+15 -2", "main.go"}, []string{"deep/", "a.go", "b.go"}},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		r := NewTreeRenderer(&buf, false)
+		r.MaxDepth = tt.depth
+		r.Render(depthTestStats())
+		out := buf.String()
+
+		for _, s := range tt.want {
+			if !strings.Contains(out, s) {
+				t.Errorf("depth %d: missing %q in:\n%s", tt.depth, s, out)
+			}
+		}
+		for _, s := range tt.notWant {
+			if strings.Contains(out, s) {
+				t.Errorf("depth %d: unexpected %q in:\n%s", tt.depth, s, out)
+			}
+		}
+	}
+}
+
+func TestBracketsRenderer_MaxDepth(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewBracketsRenderer(&buf, false)
+	r.MaxDepth = 1
+	r.Render(depthTestStats())
+	out := buf.String()
+
+	for _, s := range []string{"src/", "docs/"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("missing folded dir %q in %q", s, out)
+		}
+	}
+	for _, s := range []string{"lib", "main.go", "guide.md"} {
+		if strings.Contains(out, s) {
+			t.Errorf("depth 1 should fold %q, got %q", s, out)
+		}
+	}
+}
+
+func TestTreeRenderer_DirDepths(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewTreeRenderer(&buf, false)
+	r.MaxDepth = 1
+	r.DirDepths = diff.DirDepths{"src": 3}
+	r.Render(depthTestStats())
+	out := buf.String()
+
+	// src keeps three levels; docs falls back to MaxDepth 1
+	for _, s := range []string{"lib/", "deep/", "b.go", "main.go", "docs/ +3"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("missing %q in:\n%s", s, out)
+		}
+	}
+	for _, s := range []string{"a.go", "guide.md"} {
+		if strings.Contains(out, s) {
+			t.Errorf("unexpected %q in:\n%s", s, out)
+		}
+	}
+}
@@ -5,11 +5,23 @@
 //
 // Available renderers:
 //   - TreeRenderer: Indented tree with file stats
-//   - CollapsedRenderer: Single-line summary per directory
 //   - SmartSparklineRenderer: Depth-2 aggregated sparkline
 //   - TopNRenderer: Top N files by change size
+//   - HotPathsRenderer: Subtrees covering most of the churn (analyze.HotPaths)
+//   - HistogramRenderer: Files per change-size bucket (analyze.ChangeHistogram)
 //   - IcicleRenderer: Horizontal icicle chart
+//   - BarsRenderer: Per-directory horizontal bar chart
+//   - StatRenderer: git diff --stat style per-file lines
 //   - BracketsRenderer: Nested brackets visualization
+//   - TrailersRenderer: Git commit-message trailer lines
+//   - SuggestRenderer: One-line commit message or PR title suggestion
+//   - NumstatRenderer: Enriched git diff --numstat lines (--format numstat+)
+//   - HTMLRenderer: Standalone page with an expandable tree (--export html)
 //
-// Use ValidModes and IsValidMode to enumerate and validate mode names.
+// Use Modes, LookupMode, and IsValidMode to enumerate and validate modes.
+//
+// Renderers print paths as they are. Stats from package diff already have
+// control characters escaped (see diff.SanitizeText), so file names cannot
+// inject terminal escape sequences; pass hand-built stats through
+// DiffStats.Sanitized before rendering names from untrusted sources.
 package render
@@ -0,0 +1,219 @@
+package render
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kylesnowschwartz/diff-viz/config"
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// Options configures the renderer NewRenderer builds. Each mode reads the
+// fields it supports and ignores the rest; zero values keep the renderer
+// defaults.
+type Options struct {
+	UseColor bool
+
+	// Mode options (see ModeInfo.Options)
+	Width         int
+	Depth         int
+	Expand        int
+	N             int
+	RootGroup     string
+	RootGroupSort bool
+	Bar           BarStyle
+	BracketColors []string // ANSI codes; nil uses the default palette
+
+	SizeClass    diff.SizeClass      // Summary line size label
+	Excluded     diff.ExcludedTotals // Config exclude categories, for summary lines
+	Compare      *diff.DiffStats     // Topn: earlier range for ranking movement
+	DirDepths    diff.DirDepths      // Per-top-level-directory depths (--depth auto)
+	SortBy       SortBy              // Topn ranking
+	PerDir       bool                // Topn: top files per top-level directory
+	NoRainbow    bool                // Brackets: single dim bracket color
+	DirsOnly     bool                // Stop expansion at directory level
+	Multiline    bool                // Smart: one line per top-level directory
+	Vertical     bool                // Smart: one aligned row per group
+	Composition  bool                // Split bars by new vs existing files
+	LabelPolicy  LabelPolicy         // Icicle: per-depth label shortening
+	Annotations  []string            // Enricher keys to display
+	AgeHeat      bool                // Color files by replaced-line age
+	ScaleLegend  bool                // Explain bar shades and lengths
+	MaxPathDepth int                 // Fold directories nested deeper than this (0 = off)
+	Metric       Metric              // Icicle/bars: size by changed lines or files
+	RTL          bool                // Tree: mirrored right-to-left layout
+	Bytes        bool                // Counts are bytes (see diff.DiffStats.InBytes)
+
+	Separator     string  // Smart/brackets: between groups ("" = renderer default)
+	ItemSeparator string  // Brackets: between items in a group ("" = renderer default)
+	CleanupRatio  float64 // Deletion share marking a directory (0 = renderer default, <0 = off)
+	AbbrevCounts  bool    // Smart/brackets: "+12.4k" instead of exact counts
+}
+
+// OptionsFromConfig returns the Options a resolved mode config sets.
+// Returns an error if its bar style, colors, or cleanup threshold are invalid.
+func OptionsFromConfig(resolved config.ResolvedConfig) (Options, error) {
+	o := Options{
+		Width:         resolved.Width,
+		Depth:         resolved.Depth,
+		Expand:        resolved.Expand,
+		N:             resolved.N,
+		RootGroup:     resolved.RootGroup,
+		RootGroupSort: resolved.RootGroupSort,
+		Separator:     resolved.Separator,
+		ItemSeparator: resolved.ItemSeparator,
+		AbbrevCounts:  resolved.AbbrevCounts,
+	}
+	if resolved.CleanupThreshold < 0 || resolved.CleanupThreshold > 100 {
+		return o, fmt.Errorf("cleanupThreshold: want a percentage from 0 to 100, got %d", resolved.CleanupThreshold)
+	}
+	o.CleanupRatio = -1
+	if resolved.CleanupThreshold > 0 {
+		o.CleanupRatio = float64(resolved.CleanupThreshold) / 100
+	}
+
+	zero, err := ParseZeroBarStyle(resolved.ZeroBar)
+	if err != nil {
+		return o, fmt.Errorf("zeroBar: %w", err)
+	}
+	scale, err := ParseBarScale(resolved.BarScale)
+	if err != nil {
+		return o, fmt.Errorf("barScale: %w", err)
+	}
+	o.Bar = BarStyle{Zero: zero, NoPadding: !resolved.BarPadding, Scale: scale, Tiny: resolved.TinyLines}
+
+	for _, c := range resolved.BracketColors {
+		code, err := ParseColor(c)
+		if err != nil {
+			return o, fmt.Errorf("bracketColors: %w", err)
+		}
+		o.BracketColors = append(o.BracketColors, code)
+	}
+	return o, nil
+}
+
+// NewRenderer returns the renderer for mode writing to w.
+// Returns an error for unknown modes and for modes that do not render a
+// diff (see ModeInfo.Standalone).
+func NewRenderer(mode string, w io.Writer, o Options) (Renderer, error) {
+	switch mode {
+	case "tree":
+		r := NewTreeRenderer(w, o.UseColor)
+		r.SizeClass = o.SizeClass
+		r.Excluded = o.Excluded
+		r.DirsOnly = o.DirsOnly
+		r.MaxDepth = o.Depth
+		r.DirDepths = o.DirDepths
+		r.Annotations = o.Annotations
+		r.AgeHeat = o.AgeHeat
+		r.Composition = o.Composition
+		r.MaxPathDepth = o.MaxPathDepth
+		r.RTL = o.RTL
+		r.Bytes = o.Bytes
+		r.Width = o.Width
+		if o.CleanupRatio != 0 {
+			r.CleanupRatio = max(o.CleanupRatio, 0)
+		}
+		return r, nil
+	case "smart":
+		r := NewSmartSparklineRenderer(w, o.UseColor)
+		r.SizeClass = o.SizeClass
+		r.MaxDepth = o.Depth
+		r.DirDepths = o.DirDepths
+		r.Width = o.Width
+		r.Multiline = o.Multiline
+		r.Vertical = o.Vertical
+		r.Bar = o.Bar
+		r.ScaleLegend = o.ScaleLegend
+		r.RootGroup = o.RootGroup
+		r.Composition = o.Composition
+		r.Separator = o.Separator
+		r.AbbrevCounts = o.AbbrevCounts
+		if o.CleanupRatio != 0 {
+			r.CleanupRatio = max(o.CleanupRatio, 0)
+		}
+		return r, nil
+	case "topn":
+		r := NewTopNRenderer(w, o.UseColor, o.N)
+		r.SortBy = o.SortBy
+		r.PerDir = o.PerDir
+		r.SizeClass = o.SizeClass
+		r.Excluded = o.Excluded
+		r.Bar = o.Bar
+		r.ScaleLegend = o.ScaleLegend
+		r.Compare = o.Compare
+		r.Annotations = o.Annotations
+		r.AgeHeat = o.AgeHeat
+		r.Bytes = o.Bytes
+		return r, nil
+	case "icicle":
+		r := NewIcicleRenderer(w, o.UseColor)
+		r.Width = o.Width
+		r.MaxDepth = o.Depth
+		r.SizeClass = o.SizeClass
+		r.Excluded = o.Excluded
+		r.DirsOnly = o.DirsOnly
+		r.LabelPolicy = o.LabelPolicy
+		r.MaxPathDepth = o.MaxPathDepth
+		r.Metric = o.Metric
+		return r, nil
+	case "hotpaths":
+		r := NewHotPathsRenderer(w, o.UseColor, o.N)
+		r.SizeClass = o.SizeClass
+		r.Excluded = o.Excluded
+		return r, nil
+	case "histogram":
+		r := NewHistogramRenderer(w, o.UseColor)
+		r.SizeClass = o.SizeClass
+		r.Excluded = o.Excluded
+		return r, nil
+	case "stat":
+		r := NewStatRenderer(w, o.UseColor)
+		r.Width = o.Width
+		r.SizeClass = o.SizeClass
+		r.Excluded = o.Excluded
+		return r, nil
+	case "bars":
+		r := NewBarsRenderer(w, o.UseColor)
+		r.Width = o.Width
+		r.MaxDepth = o.Depth
+		r.DirDepths = o.DirDepths
+		r.SizeClass = o.SizeClass
+		r.Excluded = o.Excluded
+		r.Composition = o.Composition
+		r.Metric = o.Metric
+		return r, nil
+	case "brackets":
+		r := NewBracketsRenderer(w, o.UseColor)
+		r.SizeClass = o.SizeClass
+		r.Width = o.Width
+		r.ExpandDepth = o.Expand
+		r.MaxDepth = o.Depth
+		r.DirDepths = o.DirDepths
+		r.RootGroup = o.RootGroup
+		r.SortRootGroup = o.RootGroupSort
+		r.AbbrevCounts = o.AbbrevCounts
+		if o.Separator != "" {
+			r.Separator = o.Separator
+		}
+		if o.ItemSeparator != "" {
+			r.ItemSeparator = o.ItemSeparator
+		}
+		if o.NoRainbow {
+			r.BracketColors = PlainBracketColors
+		} else if len(o.BracketColors) > 0 {
+			r.BracketColors = o.BracketColors
+		}
+		return r, nil
+	case "trailers":
+		r := NewTrailersRenderer(w, o.UseColor)
+		r.SizeClass = o.SizeClass
+		return r, nil
+	case "suggest":
+		return NewSuggestRenderer(w, o.UseColor), nil
+	}
+	if IsValidMode(mode) {
+		return nil, fmt.Errorf("%s mode does not render a diff", mode)
+	}
+	return nil, fmt.Errorf("unknown mode: %s", mode)
+}
@@ -0,0 +1,54 @@
+package render
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/config"
+)
+
+func TestNewRenderer(t *testing.T) {
+	for _, mode := range DiffModeNames() {
+		if _, err := NewRenderer(mode, io.Discard, Options{}); err != nil {
+			t.Errorf("NewRenderer(%s) error = %v", mode, err)
+		}
+	}
+	if _, err := NewRenderer("trend", io.Discard, Options{}); err == nil || !strings.Contains(err.Error(), "does not render a diff") {
+		t.Errorf("NewRenderer(trend) error = %v, want a standalone mode error", err)
+	}
+	if _, err := NewRenderer("treee", io.Discard, Options{}); err == nil || !strings.Contains(err.Error(), "unknown mode") {
+		t.Errorf("NewRenderer(treee) error = %v, want unknown mode", err)
+	}
+}
+
+func TestOptionsFromConfig(t *testing.T) {
+	resolved := config.DefaultsForMode("brackets")
+	resolved.BracketColors = []string{"36"}
+	resolved.ZeroBar = "dot"
+	o, err := OptionsFromConfig(resolved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Bar.Zero != ZeroBarDot || len(o.BracketColors) != 1 || o.Expand != resolved.Expand {
+		t.Errorf("OptionsFromConfig() = %+v", o)
+	}
+	if o.CleanupRatio != DefaultCleanupRatio {
+		t.Errorf("OptionsFromConfig() CleanupRatio = %v, want %v", o.CleanupRatio, DefaultCleanupRatio)
+	}
+
+	resolved.CleanupThreshold = 0
+	if o, _ := OptionsFromConfig(resolved); o.CleanupRatio >= 0 {
+		t.Errorf("OptionsFromConfig(cleanupThreshold 0) CleanupRatio = %v, want off (<0)", o.CleanupRatio)
+	}
+	resolved.CleanupThreshold = 150
+	if _, err := OptionsFromConfig(resolved); err == nil || !strings.HasPrefix(err.Error(), "cleanupThreshold:") {
+		t.Errorf("OptionsFromConfig(cleanupThreshold 150) error = %v, want cleanupThreshold error", err)
+	}
+	resolved.CleanupThreshold = config.DefaultCleanupThreshold
+
+	resolved.BracketColors = []string{"not-a-color"}
+	if _, err := OptionsFromConfig(resolved); err == nil || !strings.HasPrefix(err.Error(), "bracketColors:") {
+		t.Errorf("OptionsFromConfig(bad color) error = %v, want bracketColors error", err)
+	}
+}
@@ -0,0 +1,86 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// FileTimelineRenderer charts one file's changes per commit as a vertical
+// timeline: one row per commit, oldest first, with a bar scaled to the
+// largest commit. Commits that renamed the file show the rename.
+// Like TrendRenderer it consumes commits, not DiffStats.
+type FileTimelineRenderer struct {
+	UseColor bool
+	Width    int // Bar width in characters
+	w        io.Writer
+}
+
+// NewFileTimelineRenderer creates a file timeline renderer.
+func NewFileTimelineRenderer(w io.Writer, useColor bool) *FileTimelineRenderer {
+	return &FileTimelineRenderer{UseColor: useColor, Width: 20, w: w}
+}
+
+// RenderTimeline outputs a header with the totals, then one row per commit.
+func (r *FileTimelineRenderer) RenderTimeline(path string, commits []diff.FileCommit) {
+	if len(commits) == 0 {
+		fmt.Fprintf(r.w, "%s: no commits\n", path)
+		return
+	}
+
+	maxTotal, adds, dels := 0, 0, 0
+	addWidth, delWidth := 0, 0
+	for _, c := range commits {
+		maxTotal = max(maxTotal, c.Adds+c.Dels)
+		adds += c.Adds
+		dels += c.Dels
+		addWidth = max(addWidth, len(fmt.Sprintf("+%d", c.Adds)))
+		delWidth = max(delWidth, len(fmt.Sprintf("-%d", c.Dels)))
+	}
+
+	noun := "commits"
+	if len(commits) == 1 {
+		noun = "commit"
+	}
+	fmt.Fprintf(r.w, "%s: %d %s %s+%d%s %s-%d%s\n", path, len(commits), noun,
+		r.color(ColorAdd), adds, r.color(ColorReset),
+		r.color(ColorDel), dels, r.color(ColorReset))
+
+	for _, c := range commits {
+		filled := 0
+		if total := c.Adds + c.Dels; total > 0 && maxTotal > 0 {
+			filled = max(total*r.Width/maxTotal, 1)
+		}
+
+		var sb strings.Builder
+		sb.WriteString(c.Time.Local().Format("2006-01-02"))
+		sb.WriteString("  ")
+		sb.WriteString(r.color(ColorFile))
+		sb.WriteString(shortSHA(c.SHA))
+		sb.WriteString(r.color(ColorReset))
+		sb.WriteString("  ")
+		sb.WriteString(fmt.Sprintf("%s%*s%s %s%-*s%s ",
+			r.color(ColorAdd), addWidth, fmt.Sprintf("+%d", c.Adds), r.color(ColorReset),
+			r.color(ColorDel), delWidth, fmt.Sprintf("-%d", c.Dels), r.color(ColorReset)))
+		sb.WriteString(RatioBar(c.Adds, c.Dels, filled, r.Width, BlockMedium, r.color))
+		if c.Subject != "" {
+			sb.WriteString("  ")
+			sb.WriteString(c.Subject)
+		}
+		if _, renamed := diff.RenameTarget(c.Path); renamed {
+			sb.WriteString(r.color(ColorDim))
+			sb.WriteString(" (renamed " + c.Path + ")")
+			sb.WriteString(r.color(ColorReset))
+		}
+		fmt.Fprintln(r.w, sb.String())
+	}
+}
+
+func (r *FileTimelineRenderer) color(code string) string {
+	if r.UseColor {
+		return code
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestFileTimelineRenderer(t *testing.T) {
+	day := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	r := NewFileTimelineRenderer(&buf, false)
+	r.Width = 10
+	r.RenderTimeline("src/a.go", []diff.FileCommit{
+		{SHA: "aaa1111", Time: day, Subject: "Add b", Path: "src/b.go", Adds: 40},
+		{SHA: "bbb2222", Time: day.Add(24 * time.Hour), Subject: "Rename b to a", Path: "src/{b.go => a.go}", Adds: 5, Dels: 5},
+	})
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), buf.String())
+	}
+	if lines[0] != "src/a.go: 2 commits +45 -5" {
+		t.Errorf("header = %q", lines[0])
+	}
+	if want := "2025-06-01  aaa1111  +40 -0 ▓▓▓▓▓▓▓▓▓▓  Add b"; lines[1] != want {
+		t.Errorf("row 1 = %q, want %q", lines[1], want)
+	}
+	if want := "2025-06-02  bbb2222   +5 -5 ▓▓░░░░░░░░  Rename b to a (renamed src/{b.go => a.go})"; lines[2] != want {
+		t.Errorf("row 2 = %q, want %q", lines[2], want)
+	}
+}
+
+func TestFileTimelineRenderer_NoCommits(t *testing.T) {
+	var buf bytes.Buffer
+	NewFileTimelineRenderer(&buf, false).RenderTimeline("gone.go", nil)
+	if got := buf.String(); got != "gone.go: no commits\n" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,83 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/analyze"
+	"github.com/kylesnowschwartz/diff-viz/diff"
+	"github.com/kylesnowschwartz/diff-viz/render/layout"
+)
+
+// HistogramRenderer charts how per-file change sizes are distributed
+// (analyze.ChangeHistogram), one bar per size bucket scaled to the fullest
+// bucket, so many small edits read differently from a few large rewrites.
+// Format:  11-50  ████████        4 files  120 lines
+type HistogramRenderer struct {
+	UseColor  bool
+	Width     int                 // Bar width in characters
+	SizeClass diff.SizeClass      // Optional size label appended to summary
+	Excluded  diff.ExcludedTotals // Excluded categories; the summary adds totals without them
+	w         io.Writer
+}
+
+// NewHistogramRenderer creates a change-size histogram renderer.
+func NewHistogramRenderer(w io.Writer, useColor bool) *HistogramRenderer {
+	return &HistogramRenderer{UseColor: useColor, Width: 30, w: w}
+}
+
+// Render outputs a header, one row per bucket, and a summary line.
+func (r *HistogramRenderer) Render(stats *diff.DiffStats) {
+	if stats.TotalFiles == 0 {
+		fmt.Fprintln(r.w, "No changes")
+		return
+	}
+
+	h := analyze.ChangeHistogram(stats)
+	counted := stats.TotalFiles - h.Unchanged
+	if counted == 0 {
+		fmt.Fprintln(r.w, "No line changes")
+	} else {
+		fmt.Fprintf(r.w, "Change size per file (%d %s, median %d lines):\n", counted, plural(counted, "file", "files"), h.Median)
+
+		maxFiles, labelWidth, filesWidth, linesWidth := 0, 0, 0, 0
+		for _, b := range h.Buckets {
+			maxFiles = max(maxFiles, b.Files)
+			labelWidth = max(labelWidth, len(b.Label))
+			filesWidth = max(filesWidth, len(fmt.Sprint(b.Files)))
+			linesWidth = max(linesWidth, len(fmt.Sprint(b.Lines)))
+		}
+		for _, b := range h.Buckets {
+			filled := layout.Scale(b.Files, maxFiles, r.Width, 1)
+			fmt.Fprintf(r.w, "  %*s  %s%s%s%s  %*d %-5s  %*d lines\n",
+				labelWidth, b.Label,
+				r.color(ColorDir), strings.Repeat(BlockFull, filled), r.color(ColorReset), strings.Repeat(" ", r.Width-filled),
+				filesWidth, b.Files, plural(b.Files, "file", "files"), linesWidth, b.Lines)
+		}
+	}
+	if h.Unchanged > 0 && counted > 0 {
+		fmt.Fprintf(r.w, "%s%d %s without line changes not counted%s\n", r.color(ColorDim), h.Unchanged, plural(h.Unchanged, "file", "files"), r.color(ColorReset))
+	}
+
+	fmt.Fprintln(r.w)
+	fmt.Fprintf(r.w, "%s+%d%s %s-%d%s in %d files%s%s\n",
+		r.color(ColorAdd), stats.TotalAdd, r.color(ColorReset),
+		r.color(ColorDel), stats.TotalDel, r.color(ColorReset),
+		stats.TotalFiles, excludedSuffix(stats, r.Excluded), sizeSuffix(r.SizeClass))
+}
+
+func (r *HistogramRenderer) color(code string) string {
+	if r.UseColor {
+		return code
+	}
+	return ""
+}
+
+// plural returns one when n is 1, else many.
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
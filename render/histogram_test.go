@@ -0,0 +1,35 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestHistogramRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewHistogramRenderer(&buf, false)
+	r.Width = 4
+	r.Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "a.go", Additions: 2},
+			{Path: "b.go", Additions: 3, Deletions: 1},
+			{Path: "c.go", Additions: 300},
+			{Path: "logo.png", IsBinary: true},
+		},
+		TotalFiles: 4, TotalAdd: 305, TotalDel: 1,
+	})
+
+	want := "Change size per file (3 files, median 4 lines):\n" +
+		"    1-10  ████  2 files    6 lines\n" +
+		"   11-50        0 files    0 lines\n" +
+		"  51-200        0 files    0 lines\n" +
+		"    201+  ██    1 file   300 lines\n" +
+		"1 file without line changes not counted\n" +
+		"\n" +
+		"+305 -1 in 4 files\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
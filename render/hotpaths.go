@@ -0,0 +1,83 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/analyze"
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// HotPathsRenderer lists the subtrees analyze.HotPaths picks as most
+// deserving of review attention, each with its share of the churn.
+// Format:  72%  src/api/handlers/  +550 -40  (2 files)
+type HotPathsRenderer struct {
+	UseColor  bool
+	N         int                 // Most subtrees to list
+	SizeClass diff.SizeClass      // Optional size label appended to summary
+	Excluded  diff.ExcludedTotals // Excluded categories; the summary adds totals without them
+	w         io.Writer
+}
+
+// NewHotPathsRenderer creates a hot paths renderer listing up to n subtrees.
+func NewHotPathsRenderer(w io.Writer, useColor bool, n int) *HotPathsRenderer {
+	return &HotPathsRenderer{UseColor: useColor, N: n, w: w}
+}
+
+// Render outputs a coverage header, one row per hot path, and a summary line.
+func (r *HotPathsRenderer) Render(stats *diff.DiffStats) {
+	if stats.TotalFiles == 0 {
+		fmt.Fprintln(r.w, "No changes")
+		return
+	}
+
+	hot := analyze.HotPaths(stats, r.N)
+	if len(hot) == 0 {
+		fmt.Fprintln(r.w, "No line changes")
+	} else {
+		covered, pathWidth, addWidth := 0.0, 0, 0
+		for _, h := range hot {
+			covered += h.Percent
+			pathWidth = max(pathWidth, VisibleWidth(h.Path))
+			addWidth = max(addWidth, len(fmt.Sprintf("+%d", h.Adds)))
+		}
+		noun := "paths cover"
+		if len(hot) == 1 {
+			noun = "path covers"
+		}
+		fmt.Fprintf(r.w, "%d hot %s %.0f%% of changed lines:\n", len(hot), noun, covered)
+
+		for _, h := range hot {
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "  %3.0f%%  ", h.Percent)
+			sb.WriteString(r.color(ColorDir))
+			sb.WriteString(h.Path)
+			sb.WriteString(r.color(ColorReset))
+			sb.WriteString(strings.Repeat(" ", pathWidth-VisibleWidth(h.Path)+2))
+			fmt.Fprintf(&sb, "%s%-*s%s %s-%d%s", r.color(ColorAdd), addWidth, fmt.Sprintf("+%d", h.Adds), r.color(ColorReset),
+				r.color(ColorDel), h.Dels, r.color(ColorReset))
+			if strings.HasSuffix(h.Path, "/") {
+				files := "files"
+				if h.Files == 1 {
+					files = "file"
+				}
+				fmt.Fprintf(&sb, "  %s(%d %s)%s", r.color(ColorDim), h.Files, files, r.color(ColorReset))
+			}
+			fmt.Fprintln(r.w, sb.String())
+		}
+	}
+
+	fmt.Fprintln(r.w)
+	fmt.Fprintf(r.w, "%s+%d%s %s-%d%s in %d files%s%s\n",
+		r.color(ColorAdd), stats.TotalAdd, r.color(ColorReset),
+		r.color(ColorDel), stats.TotalDel, r.color(ColorReset),
+		stats.TotalFiles, excludedSuffix(stats, r.Excluded), sizeSuffix(r.SizeClass))
+}
+
+func (r *HotPathsRenderer) color(code string) string {
+	if r.UseColor {
+		return code
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestHotPathsRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	NewHotPathsRenderer(&buf, false, 5).Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/api/a.go", Additions: 50, Deletions: 10},
+			{Path: "src/api/b.go", Additions: 20},
+			{Path: "docs/x.md", Additions: 40},
+		},
+		TotalFiles: 3, TotalAdd: 110, TotalDel: 10,
+	})
+
+	want := "2 hot paths cover 100% of changed lines:\n" +
+		"   67%  src/api/   +70 -10  (2 files)\n" +
+		"   33%  docs/x.md  +40 -0\n" +
+		"\n" +
+		"+110 -10 in 3 files\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHotPathsRenderer_NoLineChanges(t *testing.T) {
+	var buf bytes.Buffer
+	NewHotPathsRenderer(&buf, false, 5).Render(&diff.DiffStats{
+		Files:      []diff.FileStat{{Path: "logo.png", IsBinary: true}},
+		TotalFiles: 1,
+	})
+	if !strings.HasPrefix(buf.String(), "No line changes\n") {
+		t.Errorf("got %q, want a no line changes notice", buf.String())
+	}
+}
@@ -0,0 +1,113 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// HTMLRenderer writes the diff as a standalone HTML page. The full tree is
+// embedded as JSON and drawn by a small script as nested directory rows
+// that expand and collapse on click, so one file serves as both overview
+// (top-level directories open) and drill-down. Output is never colored.
+type HTMLRenderer struct {
+	Title        string // Page title (default "git-diff-tree")
+	MaxPathDepth int    // Fold directories nested deeper than this (see FoldDeepPaths; 0 = off)
+	w            io.Writer
+}
+
+// NewHTMLRenderer creates an HTML page renderer.
+func NewHTMLRenderer(w io.Writer) *HTMLRenderer {
+	return &HTMLRenderer{Title: "git-diff-tree", w: w}
+}
+
+// htmlNode is one directory or file in the embedded tree JSON.
+type htmlNode struct {
+	Name     string      `json:"name"`
+	Path     string      `json:"path"`
+	Add      int         `json:"add"`
+	Del      int         `json:"del"`
+	Dir      bool        `json:"dir,omitempty"`
+	New      bool        `json:"new,omitempty"`
+	Binary   bool        `json:"binary,omitempty"`
+	Children []*htmlNode `json:"children,omitempty"`
+}
+
+// Render writes the page.
+func (r *HTMLRenderer) Render(stats *diff.DiffStats) {
+	root := BuildTreeFromFiles(stats.Files)
+	FoldDeepPaths(root, r.MaxPathDepth)
+	CalcTotals(root)
+	CollapseSingleChildPaths(root)
+
+	// json.Marshal escapes <, >, and &, so the JSON is safe inside <script>
+	data, _ := json.Marshal(toHTMLNode(root))
+	fmt.Fprintf(r.w, htmlPage, html.EscapeString(r.Title), html.EscapeString(r.Title),
+		stats.TotalAdd, stats.TotalDel, stats.TotalFiles, data)
+}
+
+func toHTMLNode(n *TreeNode) *htmlNode {
+	node := &htmlNode{Name: n.Name, Path: n.Path, Add: n.Add, Del: n.Del, Dir: n.IsDir, New: n.IsNew, Binary: n.IsBinary}
+	for _, child := range n.Children {
+		node.Children = append(node.Children, toHTMLNode(child))
+	}
+	return node
+}
+
+// htmlPage takes the title (twice), totals (adds, dels, files), and the
+// tree JSON.
+const htmlPage = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>%s</title>
+<style>
+body { font: 14px/1.5 ui-monospace, SFMono-Regular, Menlo, monospace; margin: 2em; }
+details { margin-left: 1.2em; }
+summary { cursor: pointer; }
+.file { margin-left: 2.4em; }
+.add { color: #1a7f37; } .del { color: #cf222e; } .new { color: #9a6700; }
+.bar { display: inline-block; height: .7em; margin-left: .6em; vertical-align: middle; }
+.bar span { display: inline-block; height: 100%%; }
+.bar .a { background: #2da44e; } .bar .d { background: #cf222e; }
+</style></head>
+<body>
+<h1>%s</h1>
+<p><span class="add">+%d</span> <span class="del">-%d</span> in %d files</p>
+<div id="tree"><noscript>Enable JavaScript to browse the tree.</noscript></div>
+<script type="application/json" id="diff-tree">%s</script>
+<script>
+(function () {
+  var root = JSON.parse(document.getElementById("diff-tree").textContent);
+  var total = Math.max(root.add + root.del, 1);
+  function label(n) {
+    var s = document.createElement("span");
+    var name = n.dir ? n.name + "/" : n.name;
+    s.appendChild(document.createTextNode(name + " "));
+    var a = document.createElement("span"); a.className = "add"; a.textContent = "+" + n.add;
+    var d = document.createElement("span"); d.className = "del"; d.textContent = " -" + n.del;
+    s.appendChild(a); s.appendChild(d);
+    if (n.new) { var m = document.createElement("span"); m.className = "new"; m.textContent = " (new)"; s.appendChild(m); }
+    if (n.binary) { s.appendChild(document.createTextNode(" (binary)")); }
+    var bar = document.createElement("span"); bar.className = "bar";
+    [["a", n.add], ["d", n.del]].forEach(function (p) {
+      var part = document.createElement("span"); part.className = p[0];
+      part.style.width = (p[1] * 300 / total) + "px"; bar.appendChild(part);
+    });
+    s.appendChild(bar);
+    return s;
+  }
+  function build(n, depth) {
+    if (!n.dir) { var f = document.createElement("div"); f.className = "file"; f.appendChild(label(n)); return f; }
+    var el = document.createElement("details");
+    el.open = depth === 0;
+    var sum = document.createElement("summary"); sum.appendChild(label(n)); el.appendChild(sum);
+    (n.children || []).forEach(function (c) { el.appendChild(build(c, depth + 1)); });
+    return el;
+  }
+  var tree = document.getElementById("tree");
+  (root.children || []).forEach(function (c) { tree.appendChild(build(c, 0)); });
+})();
+</script>
+</body></html>
+`
@@ -0,0 +1,67 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestHTMLRenderer(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/lib/a.go", Additions: 5, Deletions: 1},
+			{Path: "src/lib/b.go", Additions: 2},
+			{Path: "docs/</script><b>.md", Additions: 1, IsUntracked: true},
+		},
+		TotalAdd: 8, TotalDel: 1, TotalFiles: 3,
+	}
+	var buf bytes.Buffer
+	NewHTMLRenderer(&buf).Render(stats)
+	page := buf.String()
+
+	if strings.Count(page, "</script>") != 2 {
+		t.Fatalf("path escaped the JSON script element:\n%s", page)
+	}
+	start := strings.Index(page, `id="diff-tree">`) + len(`id="diff-tree">`)
+	end := start + strings.Index(page[start:], "</script>")
+	var root htmlNode
+	if err := json.Unmarshal([]byte(page[start:end]), &root); err != nil {
+		t.Fatalf("embedded tree JSON: %v", err)
+	}
+
+	if root.Add != 8 || root.Del != 1 || len(root.Children) != 2 {
+		t.Fatalf("root = +%d -%d with %d children", root.Add, root.Del, len(root.Children))
+	}
+	docs, src := root.Children[0], root.Children[1]
+	if !docs.Dir || len(docs.Children) != 1 || !docs.Children[0].New {
+		t.Errorf("docs = %+v", docs)
+	}
+	// Single-child chains collapse like the tree view
+	if src.Name != "src/lib" || src.Add != 7 || len(src.Children) != 2 {
+		t.Errorf("src = %+v", src)
+	}
+	if !strings.Contains(page, "+8</span> <span class=\"del\">-1</span> in 3 files") {
+		t.Error("missing totals line")
+	}
+}
+
+func TestHTMLRenderer_MaxPathDepth(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/main/java/com/acme/Tax.java", Additions: 3},
+			{Path: "src/README.md", Additions: 1},
+		},
+		TotalAdd: 4, TotalFiles: 2,
+	}
+	var buf bytes.Buffer
+	r := NewHTMLRenderer(&buf)
+	r.MaxPathDepth = 1
+	r.Render(stats)
+
+	if !strings.Contains(buf.String(), `"name":"…/acme"`) {
+		t.Errorf("deep directory not folded under src:\n%s", buf.String())
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/kylesnowschwartz/diff-viz/diff"
+	"github.com/kylesnowschwartz/diff-viz/render/layout"
 )
 
 // Box-drawing characters for icicle rendering.
@@ -70,6 +71,9 @@ type IcicleCell struct {
 	Start    int    // Pixel position of left edge (0-indexed)
 	End      int    // Pixel position of right edge (exclusive)
 	Children []int  // Indices into next level's cells that are children
+	Other    bool   // Aggregate of siblings dropped for width ("…+N")
+
+	node *TreeNode // Tree node drawn by the cell, for expanding the next level
 }
 
 // Width returns the cell width in characters.
@@ -77,7 +81,13 @@ func (c IcicleCell) Width() int {
 	return c.End - c.Start
 }
 
+// Mixed reports whether the cell has both additions and deletions.
+func (c IcicleCell) Mixed() bool {
+	return c.Add > 0 && c.Del > 0
+}
+
 // Color returns the appropriate color code based on add/del ratio.
+// Mixed cells return ColorDir; formatCentered splits their label instead.
 func (c IcicleCell) Color() string {
 	switch {
 	case c.Add > 0 && c.Del == 0:
@@ -105,8 +115,18 @@ func (c IcicleCell) formatCentered(truncateFn func(string, int) string, colorFn
 
 	var sb strings.Builder
 	sb.WriteString(strings.Repeat(" ", leftPad))
-	sb.WriteString(colorFn(c.Color()))
-	sb.WriteString(label)
+	if c.Mixed() && labelLen >= 2 {
+		// Two-tone label: the green share of runes matches the add ratio
+		runes := []rune(label)
+		split := min(max((labelLen*c.Add+c.Total/2)/c.Total, 1), labelLen-1)
+		sb.WriteString(colorFn(ColorAdd))
+		sb.WriteString(string(runes[:split]))
+		sb.WriteString(colorFn(ColorDel))
+		sb.WriteString(string(runes[split:]))
+	} else {
+		sb.WriteString(colorFn(c.Color()))
+		sb.WriteString(label)
+	}
 	sb.WriteString(colorFn(ColorReset))
 	sb.WriteString(strings.Repeat(" ", rightPad))
 
@@ -117,13 +137,25 @@ func (c IcicleCell) formatCentered(truncateFn func(string, int) string, colorFn
 // Width encodes magnitude, vertical stacking shows hierarchy.
 type IcicleRenderer struct {
 	UseColor     bool
-	Width        int // Total width of the chart
-	MaxDepth     int // Maximum depth levels to render (0 = unlimited)
-	MinCellWidth int // Minimum width per cell (wider = less visual clutter)
+	Width        int                 // Total width of the chart
+	MaxDepth     int                 // Maximum depth levels to render (0 = unlimited)
+	MinCellWidth int                 // Minimum width per cell (wider = less visual clutter)
+	SizeClass    diff.SizeClass      // Optional size label appended to summary
+	Excluded     diff.ExcludedTotals // Excluded categories; the summary adds totals without them
+	DirsOnly     bool                // Stop at directory level (never show files)
+	LabelPolicy  LabelPolicy         // Per-depth label shortening (nil = full labels)
+	MaxPathDepth int                 // Fold directories nested deeper than this (see FoldDeepPaths; 0 = off)
+	Metric       Metric              // What cell widths measure (default MetricLines)
 	w            io.Writer
 	style        BoxStyle
+}
+
+// icicleLayout is the cell structure computed for one Render call.
+// Kept off IcicleRenderer so concurrent renders don't share state.
+type icicleLayout struct {
 	levels       [][]IcicleCell // cells at each depth level
 	droppedCount int            // nodes dropped due to width constraints
+	legend       []string       // "N path" entries for labels hidden by LabelPolicy
 }
 
 // NewIcicleRenderer creates an icicle renderer.
@@ -150,97 +182,102 @@ func (r *IcicleRenderer) Render(stats *diff.DiffStats) {
 	}
 
 	// Build the hierarchical cell structure
-	r.buildLevels(stats)
+	chart := r.buildLevels(stats)
+	levels := chart.levels
 
-	if len(r.levels) == 0 || len(r.levels[0]) == 0 {
+	if len(levels) == 0 || len(levels[0]) == 0 {
 		fmt.Fprintln(r.w, "No changes")
 		return
 	}
 
 	// Render top border
-	r.renderBorder(0, true)
+	r.renderBorder(levels, 0, true)
 
 	// Render each level with separators
-	lastLevel := len(r.levels) - 1
-	for depth := 0; depth < len(r.levels); depth++ {
-		r.renderContentRow(depth)
+	lastLevel := len(levels) - 1
+	for depth := 0; depth < len(levels); depth++ {
+		r.renderContentRow(levels, depth)
 
 		// Render separator between levels
 		if depth < lastLevel {
-			r.renderSeparator(depth, depth+1)
+			r.renderSeparator(levels, depth, depth+1)
 		}
 	}
 
 	// Render stats footer row (aligned to leaf cell columns)
-	leafCells := r.collectLeafCells()
-	r.renderLeafSeparator(lastLevel, leafCells)
-	r.renderStatsFooterFromCells(leafCells)
+	leafCells := collectLeafCells(levels)
+	r.renderLeafSeparator(levels, lastLevel, leafCells)
+	footerLegend := r.renderStatsFooterFromCells(leafCells)
 	r.renderLeafBorder(leafCells)
+	r.renderLegend(append(chart.legend, footerLegend...))
 
 	// Summary line
-	if r.droppedCount > 0 {
-		fmt.Fprintf(r.w, "%s+%d%s %s-%d%s in %d files (%d hidden)\n",
+	if chart.droppedCount > 0 {
+		fmt.Fprintf(r.w, "%s+%d%s %s-%d%s in %d files (%d hidden)%s%s\n",
 			r.color(ColorAdd), stats.TotalAdd, r.color(ColorReset),
 			r.color(ColorDel), stats.TotalDel, r.color(ColorReset),
-			stats.TotalFiles, r.droppedCount)
+			stats.TotalFiles, chart.droppedCount, excludedSuffix(stats, r.Excluded), sizeSuffix(r.SizeClass))
 	} else {
-		fmt.Fprintf(r.w, "%s+%d%s %s-%d%s in %d files\n",
+		fmt.Fprintf(r.w, "%s+%d%s %s-%d%s in %d files%s%s\n",
 			r.color(ColorAdd), stats.TotalAdd, r.color(ColorReset),
 			r.color(ColorDel), stats.TotalDel, r.color(ColorReset),
-			stats.TotalFiles)
+			stats.TotalFiles, excludedSuffix(stats, r.Excluded), sizeSuffix(r.SizeClass))
 	}
 }
 
 // buildLevels constructs the hierarchical cell structure from diff stats.
-func (r *IcicleRenderer) buildLevels(stats *diff.DiffStats) {
+func (r *IcicleRenderer) buildLevels(stats *diff.DiffStats) *icicleLayout {
 	// Build tree first
 	tree := r.buildTree(stats.Files)
 
-	// Calculate total for proportional sizing
-	totalChanges := stats.TotalAdd + stats.TotalDel
-	if totalChanges == 0 {
-		totalChanges = 1
-	}
-
 	// Build levels breadth-first
-	r.levels = make([][]IcicleCell, 0)
+	chart := &icicleLayout{}
 	usableWidth := r.Width - 2 // Account for left/right borders
 
 	// Level 0: root's children with proportional widths
-	level0 := r.buildLevelCells(tree.Children, 0, usableWidth, totalChanges)
+	level0, dropped := r.buildLevelCells(tree.Children, 0, usableWidth)
+	chart.droppedCount += dropped
 	if len(level0) == 0 {
-		return
+		return chart
 	}
-	r.levels = append(r.levels, level0)
+	chart.levels = append(chart.levels, level0)
 
 	// Build subsequent levels breadth-first
 	for depth := 1; r.MaxDepth == 0 || depth < r.MaxDepth; depth++ {
-		prevLevel := r.levels[depth-1]
+		prevLevel := chart.levels[depth-1]
 		var nextLevel []IcicleCell
 
 		for _, cell := range prevLevel {
-			// Find the node for this cell
-			node := FindNode(tree, cell.Path)
+			if cell.Other {
+				continue // Aggregates have no single subtree to expand
+			}
+
+			node := cell.node
 			if node == nil || !node.IsDir || len(node.Children) == 0 {
 				continue
 			}
 
 			// Build children within this cell's bounds
-			childCells := r.buildLevelCells(node.Children, cell.Start, cell.Width(), cell.Total)
+			childCells, dropped := r.buildLevelCells(node.Children, cell.Start, cell.Width())
+			chart.droppedCount += dropped
 			nextLevel = append(nextLevel, childCells...)
 		}
 
 		if len(nextLevel) == 0 {
 			break // No more children to render
 		}
-		r.levels = append(r.levels, nextLevel)
+		chart.levels = append(chart.levels, nextLevel)
 	}
+
+	chart.legend = applyLabelPolicy(chart.levels, r.LabelPolicy, r.truncate)
+	return chart
 }
 
 // buildTree constructs a tree from flat file paths.
 // Uses shared tree utilities, then adds icicle-specific processing.
 func (r *IcicleRenderer) buildTree(files []diff.FileStat) *TreeNode {
 	root := BuildTreeFromFiles(files)
+	FoldDeepPaths(root, r.MaxPathDepth)
 
 	// Calculate totals for directories (needed for proportional sizing)
 	CalcTotals(root)
@@ -248,64 +285,58 @@ func (r *IcicleRenderer) buildTree(files []diff.FileStat) *TreeNode {
 	// Collapse single-child chains (e.g., src/internal/utils/ -> one node)
 	CollapseSingleChildPaths(root)
 
+	// Prune after collapsing so dirs that also held files aren't merged away
+	if r.DirsOnly {
+		PruneFiles(root)
+	}
+
 	return root
 }
 
 // buildLevelCells creates cells for nodes within given bounds.
-// Returns the cells without modifying r.levels.
-func (r *IcicleRenderer) buildLevelCells(nodes []*TreeNode, startPos, availWidth, totalChanges int) []IcicleCell {
+// Returns the cells and how many nodes were folded away or dropped for width.
+func (r *IcicleRenderer) buildLevelCells(nodes []*TreeNode, startPos, availWidth int) ([]IcicleCell, int) {
 	if len(nodes) == 0 || availWidth < 1 {
-		return nil
+		return nil, 0
 	}
 
-	// Filter nodes with changes and sort by total descending
+	// Filter nodes with changes and sort by weight descending
 	sorted := make([]*TreeNode, 0, len(nodes))
 	for _, n := range nodes {
-		if n.Add+n.Del > 0 {
+		if r.weight(n) > 0 {
 			sorted = append(sorted, n)
 		}
 	}
 	if len(sorted) == 0 {
-		return nil
+		return nil, 0
 	}
-	sort.Slice(sorted, func(i, j int) bool {
-		return sorted[i].Add+sorted[i].Del > sorted[j].Add+sorted[j].Del
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return r.weight(sorted[i]) > r.weight(sorted[j])
 	})
 
-	// Calculate widths: reserve minimum for each, then distribute rest proportionally
-	minReserved := len(sorted) * r.MinCellWidth
-	if minReserved > availWidth {
-		// Not enough space for all nodes - take what fits
-		maxNodes := availWidth / r.MinCellWidth
-		if maxNodes == 0 {
-			r.droppedCount += len(sorted)
-			return nil
-		}
-		r.droppedCount += len(sorted) - maxNodes
-		sorted = sorted[:maxNodes]
-		minReserved = len(sorted) * r.MinCellWidth
-	}
-
-	// Calculate proportional widths with minimum guarantee
-	extraWidth := availWidth - minReserved
-	widths := make([]int, len(sorted))
-	for i, node := range sorted {
-		nodeTotal := node.Add + node.Del
-		extra := 0
-		if extraWidth > 0 && totalChanges > 0 {
-			extra = (nodeTotal * extraWidth) / totalChanges
+	// Reserve the minimum for each node, fold what does not fit into a
+	// trailing "…+N" cell so its changes stay visible, and split the rest
+	// proportionally
+	weights := make([]int, len(sorted))
+	for i, n := range sorted {
+		weights[i] = r.weight(n)
+	}
+	alloc := layout.Allocate(weights, availWidth, r.MinCellWidth)
+	if alloc.Widths == nil {
+		return nil, alloc.Folded
+	}
+	var other *TreeNode
+	if alloc.Folded > 0 {
+		dropped := sorted[alloc.Kept:]
+		other = &TreeNode{Name: fmt.Sprintf("…+%d", len(dropped))}
+		for _, n := range dropped {
+			other.Add += n.Add
+			other.Del += n.Del
+			other.Files += n.Files
 		}
-		widths[i] = r.MinCellWidth + extra
-	}
-
-	// Adjust to fill remaining space (avoid gaps)
-	usedWidth := 0
-	for _, w := range widths {
-		usedWidth += w
-	}
-	if usedWidth < availWidth && len(widths) > 0 {
-		widths[0] += availWidth - usedWidth // Give extra to largest
+		sorted = append(sorted[:alloc.Kept], other)
 	}
+	widths := alloc.Widths
 
 	// Build cells
 	cells := make([]IcicleCell, 0, len(sorted))
@@ -326,18 +357,27 @@ func (r *IcicleRenderer) buildLevelCells(nodes []*TreeNode, startPos, availWidth
 			Del:   node.Del,
 			Start: pos,
 			End:   pos + width,
+			Other: node == other,
+			node:  node,
 		})
 
 		pos += width
 	}
 
-	return cells
+	return cells, alloc.Folded
+}
+
+// weight is what a node's cell width is proportional to.
+func (r *IcicleRenderer) weight(n *TreeNode) int {
+	if r.Metric == MetricFiles {
+		return n.Files
+	}
+	return n.Add + n.Del
 }
 
 // renderBorder renders the top or bottom border.
-func (r *IcicleRenderer) renderBorder(levelIdx int, isTop bool) {
-	level := r.levels[levelIdx]
-	boundaries := r.getBoundaries(levelIdx)
+func (r *IcicleRenderer) renderBorder(levels [][]IcicleCell, levelIdx int, isTop bool) {
+	boundaries := r.getBoundaries(levels, levelIdx)
 
 	var sb strings.Builder
 
@@ -369,17 +409,16 @@ func (r *IcicleRenderer) renderBorder(levelIdx int, isTop bool) {
 	}
 
 	fmt.Fprintln(r.w, sb.String())
-	_ = level // silence unused warning
 }
 
 // renderContentRow renders the content row for a level.
-func (r *IcicleRenderer) renderContentRow(levelIdx int) {
-	level := r.levels[levelIdx]
+func (r *IcicleRenderer) renderContentRow(levels [][]IcicleCell, levelIdx int) {
+	level := levels[levelIdx]
 
 	// Get parent boundaries to draw separators in empty regions
 	var parentBoundaries map[int]bool
 	if levelIdx > 0 {
-		parentBoundaries = r.getBoundaries(levelIdx - 1)
+		parentBoundaries = r.getBoundaries(levels, levelIdx-1)
 	}
 
 	var sb strings.Builder
@@ -424,9 +463,9 @@ func (r *IcicleRenderer) renderContentRow(levelIdx int) {
 }
 
 // renderSeparator renders the separator row between two levels.
-func (r *IcicleRenderer) renderSeparator(aboveIdx, belowIdx int) {
-	aboveBoundaries := r.getBoundaries(aboveIdx)
-	belowBoundaries := r.getBoundaries(belowIdx)
+func (r *IcicleRenderer) renderSeparator(levels [][]IcicleCell, aboveIdx, belowIdx int) {
+	aboveBoundaries := r.getBoundaries(levels, aboveIdx)
+	belowBoundaries := r.getBoundaries(levels, belowIdx)
 
 	var sb strings.Builder
 	sb.WriteString(r.style.LeftSep)
@@ -452,8 +491,8 @@ func (r *IcicleRenderer) renderSeparator(aboveIdx, belowIdx int) {
 }
 
 // renderLeafSeparator renders the separator between the last content row and footer.
-func (r *IcicleRenderer) renderLeafSeparator(lastLevelIdx int, leaves []IcicleCell) {
-	aboveBoundaries := r.getBoundaries(lastLevelIdx)
+func (r *IcicleRenderer) renderLeafSeparator(levels [][]IcicleCell, lastLevelIdx int, leaves []IcicleCell) {
+	aboveBoundaries := r.getBoundaries(levels, lastLevelIdx)
 	leafBoundaries := r.getLeafBoundaries(leaves)
 
 	var sb strings.Builder
@@ -479,8 +518,10 @@ func (r *IcicleRenderer) renderLeafSeparator(lastLevelIdx int, leaves []IcicleCe
 	fmt.Fprintln(r.w, sb.String())
 }
 
-// renderStatsFooterFromCells renders the stats row from pre-collected leaf cells.
-func (r *IcicleRenderer) renderStatsFooterFromCells(leaves []IcicleCell) {
+// renderStatsFooterFromCells renders the stats row from pre-collected leaf
+// cells and returns legend entries for stats that only fit as a key.
+func (r *IcicleRenderer) renderStatsFooterFromCells(leaves []IcicleCell) []string {
+	var legend []string
 	var sb strings.Builder
 	sb.WriteString(r.style.Vertical)
 
@@ -492,38 +533,11 @@ func (r *IcicleRenderer) renderStatsFooterFromCells(leaves []IcicleCell) {
 			pos++
 		}
 
-		// Format stats with colors: green for +N, red for -N
-		addPart := fmt.Sprintf("+%d", cell.Add)
-		delPart := ""
-		if cell.Del > 0 {
-			delPart = fmt.Sprintf(" -%d", cell.Del)
-		}
-
-		// Calculate visual width (without ANSI codes)
-		statsLen := utf8.RuneCountInString(addPart + delPart)
-
 		// Center the stats within the cell width (minus 1 for separator)
-		cellWidth := cell.Width()
-		availWidth := cellWidth - 1 // Reserve 1 for separator
-
-		// Build colored stats string
-		var coloredStats strings.Builder
-		coloredStats.WriteString(r.color(ColorAdd))
-		coloredStats.WriteString(addPart)
-		coloredStats.WriteString(r.color(ColorReset))
-		if delPart != "" {
-			coloredStats.WriteString(r.color(ColorDel))
-			coloredStats.WriteString(delPart)
-			coloredStats.WriteString(r.color(ColorReset))
-		}
-
-		// Truncate if needed (rare, just skip coloring)
-		if statsLen > availWidth {
-			plainStats := addPart + delPart
-			plainStats = plainStats[:availWidth]
-			statsLen = availWidth
-			coloredStats.Reset()
-			coloredStats.WriteString(plainStats)
+		availWidth := cell.Width() - 1
+		coloredStats, statsLen, key := r.footerStats(cell, availWidth, len(legend)+1)
+		if key != "" {
+			legend = append(legend, key)
 		}
 
 		padding := availWidth - statsLen
@@ -531,7 +545,7 @@ func (r *IcicleRenderer) renderStatsFooterFromCells(leaves []IcicleCell) {
 		rightPad := padding - leftPad
 
 		sb.WriteString(strings.Repeat(" ", leftPad))
-		sb.WriteString(coloredStats.String())
+		sb.WriteString(coloredStats)
 		sb.WriteString(strings.Repeat(" ", rightPad))
 
 		pos = cell.Start + 1 + availWidth
@@ -551,6 +565,71 @@ func (r *IcicleRenderer) renderStatsFooterFromCells(leaves []IcicleCell) {
 
 	sb.WriteString(r.style.Vertical)
 	fmt.Fprintln(r.w, sb.String())
+	return legend
+}
+
+// footerStatsGlyph stands in for stats when not even a legend key fits.
+const footerStatsGlyph = "▪"
+
+// footerStats formats a leaf cell's stats for width columns, returning the
+// colored text, its visible width, and a legend entry when one is needed.
+// Narrow cells fall back from "+A -D" to the net change ("+N", "-N"), then
+// to a "*N" key (n is its number) whose stats are listed under the chart,
+// then to a single glyph colored by the larger side.
+func (r *IcicleRenderer) footerStats(cell IcicleCell, width, n int) (text string, visible int, legend string) {
+	full := r.color(ColorAdd) + fmt.Sprintf("+%d", cell.Add) + r.color(ColorReset)
+	fullLen := len(fmt.Sprintf("+%d", cell.Add))
+	if cell.Del > 0 {
+		del := fmt.Sprintf(" -%d", cell.Del)
+		full += r.color(ColorDel) + del + r.color(ColorReset)
+		fullLen += len(del)
+	}
+	if fullLen <= width {
+		return full, fullLen, ""
+	}
+
+	net, netColor := fmt.Sprintf("+%d", cell.Add-cell.Del), ColorAdd
+	if cell.Del > cell.Add {
+		net, netColor = fmt.Sprintf("-%d", cell.Del-cell.Add), ColorDel
+	}
+	if len(net) <= width {
+		return r.color(netColor) + net + r.color(ColorReset), len(net), ""
+	}
+
+	if key := fmt.Sprintf("*%d", n); len(key) <= width {
+		name := cell.Path
+		if cell.Other || name == "" {
+			name = cell.Label
+		} else if strings.HasSuffix(cell.Label, "/") {
+			name += "/"
+		}
+		return r.color(ColorDim) + key + r.color(ColorReset), len(key),
+			fmt.Sprintf("%s %s +%d -%d", key, name, cell.Add, cell.Del)
+	}
+
+	if width < 1 {
+		return "", 0, ""
+	}
+	return r.color(netColor) + footerStatsGlyph + r.color(ColorReset), 1, ""
+}
+
+// renderLegend lists the paths behind hidden-label markers, packed into
+// lines no wider than the chart.
+func (r *IcicleRenderer) renderLegend(entries []string) {
+	var line strings.Builder
+	for _, e := range entries {
+		if line.Len() > 0 && utf8.RuneCountInString(line.String())+2+utf8.RuneCountInString(e) > r.Width {
+			fmt.Fprintln(r.w, r.color(ColorDim)+line.String()+r.color(ColorReset))
+			line.Reset()
+		}
+		if line.Len() > 0 {
+			line.WriteString("  ")
+		}
+		line.WriteString(e)
+	}
+	if line.Len() > 0 {
+		fmt.Fprintln(r.w, r.color(ColorDim)+line.String()+r.color(ColorReset))
+	}
 }
 
 // renderLeafBorder renders the bottom border aligned to leaf cells.
@@ -588,16 +667,16 @@ func (r *IcicleRenderer) getLeafBoundaries(leaves []IcicleCell) map[int]bool {
 
 // collectLeafCells returns all leaf cells across all levels.
 // A leaf is a cell that has no children in the next level.
-func (r *IcicleRenderer) collectLeafCells() []IcicleCell {
+func collectLeafCells(levels [][]IcicleCell) []IcicleCell {
 	var leaves []IcicleCell
 
-	for depth := 0; depth < len(r.levels); depth++ {
-		for _, cell := range r.levels[depth] {
+	for depth := 0; depth < len(levels); depth++ {
+		for _, cell := range levels[depth] {
 			isLeaf := true
 
 			// Check if any cell in the next level falls within this cell's bounds
-			if depth+1 < len(r.levels) {
-				for _, child := range r.levels[depth+1] {
+			if depth+1 < len(levels) {
+				for _, child := range levels[depth+1] {
 					if child.Start >= cell.Start && child.Start < cell.End {
 						isLeaf = false
 						break
@@ -620,15 +699,15 @@ func (r *IcicleRenderer) collectLeafCells() []IcicleCell {
 }
 
 // getBoundaries returns a map of pixel positions where vertical lines exist.
-func (r *IcicleRenderer) getBoundaries(levelIdx int) map[int]bool {
+func (r *IcicleRenderer) getBoundaries(levels [][]IcicleCell, levelIdx int) map[int]bool {
 	boundaries := make(map[int]bool)
 
-	if levelIdx >= len(r.levels) {
+	if levelIdx >= len(levels) {
 		return boundaries
 	}
 
 	usableWidth := r.Width - 2 // Account for left/right borders
-	for _, cell := range r.levels[levelIdx] {
+	for _, cell := range levels[levelIdx] {
 		// Mark end position as boundary (between cells)
 		// BUT don't mark the right edge - it's the box border, not an internal separator
 		if cell.End < usableWidth {
@@ -0,0 +1,117 @@
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LabelRule controls how icicle cell labels at one depth level are drawn.
+// The zero value shows full labels (truncated only to the cell width).
+type LabelRule struct {
+	MaxLen int  // Cap labels at this many runes (0 = no cap)
+	Hide   bool // Replace labels with numbered markers listed in a legend
+}
+
+// LabelPolicy maps depth levels (1 = top level) to label rules. A rule
+// applies to its level and every deeper level until the next rule, so
+// "3:6,4:none" caps level 3 at 6 runes and hides labels from level 4 down.
+type LabelPolicy map[int]LabelRule
+
+// LabelPolicyAuto is the preset selected by "auto": short labels at level 3,
+// legend markers from level 4.
+const LabelPolicyAuto = "3:6,4:none"
+
+// ParseLabelPolicy parses a --label-depth-policy value: "auto" or
+// comma-separated LEVEL:RULE pairs where RULE is "full", "none", or a
+// maximum label length. Empty returns a nil policy (full labels everywhere).
+func ParseLabelPolicy(s string) (LabelPolicy, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if s == "auto" {
+		s = LabelPolicyAuto
+	}
+
+	policy := make(LabelPolicy)
+	for _, part := range strings.Split(s, ",") {
+		levelStr, ruleStr, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("label policy %q: want LEVEL:RULE (e.g., 3:6,4:none)", part)
+		}
+		level, err := strconv.Atoi(levelStr)
+		if err != nil || level < 1 {
+			return nil, fmt.Errorf("label policy %q: level must be a positive integer", part)
+		}
+
+		var rule LabelRule
+		switch ruleStr {
+		case "full":
+		case "none":
+			rule.Hide = true
+		default:
+			n, err := strconv.Atoi(ruleStr)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("label policy %q: rule must be full, none, or a length >= 1", part)
+			}
+			rule.MaxLen = n
+		}
+		policy[level] = rule
+	}
+	return policy, nil
+}
+
+// RuleFor returns the rule in effect at level: the rule of the deepest
+// configured level at or above it.
+func (p LabelPolicy) RuleFor(level int) LabelRule {
+	best := 0
+	for l := range p {
+		if l <= level && l > best {
+			best = l
+		}
+	}
+	return p[best]
+}
+
+// applyLabelPolicy rewrites cell labels in levels per policy and returns the
+// legend entries ("1 path/to/file.go") for cells whose labels were hidden.
+// "…+N" aggregate cells keep their labels.
+func applyLabelPolicy(levels [][]IcicleCell, policy LabelPolicy, truncate func(string, int) string) []string {
+	if len(policy) == 0 {
+		return nil
+	}
+
+	var legend []string
+	for depth, level := range levels {
+		rule := policy.RuleFor(depth + 1)
+		if rule == (LabelRule{}) {
+			continue
+		}
+
+		// Number hidden cells left to right so markers read in order
+		order := make([]int, len(level))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool { return level[order[a]].Start < level[order[b]].Start })
+
+		for _, i := range order {
+			cell := &level[i]
+			switch {
+			case cell.Other:
+			case rule.Hide:
+				marker := strconv.Itoa(len(legend) + 1)
+				name := cell.Path
+				if strings.HasSuffix(cell.Label, "/") {
+					name += "/"
+				}
+				legend = append(legend, marker+" "+name)
+				cell.Label = marker
+			case rule.MaxLen > 0:
+				cell.Label = truncate(cell.Label, rule.MaxLen)
+			}
+		}
+	}
+	return legend
+}
@@ -0,0 +1,65 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestParseLabelPolicy(t *testing.T) {
+	policy, err := ParseLabelPolicy("auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		level int
+		want  LabelRule
+	}{
+		{1, LabelRule{}},
+		{2, LabelRule{}},
+		{3, LabelRule{MaxLen: 6}},
+		{4, LabelRule{Hide: true}},
+		{7, LabelRule{Hide: true}}, // deeper levels inherit
+	}
+	for _, tt := range tests {
+		if got := policy.RuleFor(tt.level); got != tt.want {
+			t.Errorf("auto RuleFor(%d) = %+v, want %+v", tt.level, got, tt.want)
+		}
+	}
+
+	if p, err := ParseLabelPolicy(""); err != nil || p != nil {
+		t.Errorf("empty policy = %v, %v; want nil, nil", p, err)
+	}
+	for _, bad := range []string{"3", "0:4", "x:none", "2:0", "2:short"} {
+		if _, err := ParseLabelPolicy(bad); err == nil {
+			t.Errorf("ParseLabelPolicy(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestIcicle_LabelPolicyHidesWithLegend(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewIcicleRenderer(&buf, false)
+	r.Width = 60
+	r.LabelPolicy, _ = ParseLabelPolicy("1:3,2:none")
+	r.Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/parser.go", Additions: 30},
+			{Path: "src/lexer.go", Additions: 20},
+			{Path: "docs/guide.md", Deletions: 10},
+		},
+		TotalFiles: 3, TotalAdd: 50, TotalDel: 10,
+	})
+	out := buf.String()
+
+	for _, want := range []string{"src/", "1 src/parser.go  2 src/lexer.go  3 docs/guide.md"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "parser.go |") || strings.Contains(out, "docs/ ") {
+		t.Errorf("level 2 labels should be markers and level 1 capped at 3 runes:\n%s", out)
+	}
+}
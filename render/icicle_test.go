@@ -0,0 +1,235 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestIcicle_OtherCellAggregatesDropped(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewIcicleRenderer(&buf, false)
+	r.Width = 42 // Room for 3 cells at MinCellWidth 12
+	r.Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "a.go", Additions: 50},
+			{Path: "b.go", Additions: 40},
+			{Path: "c.go", Additions: 3},
+			{Path: "d.go", Additions: 2},
+		},
+		TotalFiles: 4,
+		TotalAdd:   95,
+	})
+
+	got := buf.String()
+	if !strings.Contains(got, "…+2") {
+		t.Errorf("expected aggregate cell '…+2', got:\n%s", got)
+	}
+	if !strings.Contains(got, "+5") {
+		t.Errorf("expected aggregate stats '+5' in footer, got:\n%s", got)
+	}
+	if !strings.Contains(got, "(2 hidden)") {
+		t.Errorf("expected summary to note hidden nodes, got:\n%s", got)
+	}
+}
+
+func TestIcicle_RenderIsRepeatable(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "a.go", Additions: 50},
+			{Path: "b.go", Additions: 40},
+			{Path: "c.go", Additions: 3},
+			{Path: "d.go", Additions: 2},
+		},
+		TotalFiles: 4,
+		TotalAdd:   95,
+	}
+
+	var buf bytes.Buffer
+	r := NewIcicleRenderer(&buf, false)
+	r.Width = 42
+
+	r.Render(stats)
+	first := buf.String()
+	buf.Reset()
+	r.Render(stats)
+
+	if got := buf.String(); got != first {
+		t.Errorf("second Render differs from first:\n%s\nwant:\n%s", got, first)
+	}
+}
+
+func TestRenderers_ConcurrentRender(t *testing.T) {
+	// Run with -race: shared renderers must not mutate themselves in Render.
+	stats := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/a.go", Additions: 50, Deletions: 3},
+			{Path: "src/lib/b.go", Additions: 40},
+			{Path: "docs/c.md", Deletions: 7},
+		},
+		TotalFiles: 3, TotalAdd: 90, TotalDel: 10,
+	}
+	renderers := []Renderer{
+		NewTreeRenderer(io.Discard, true),
+		NewSmartSparklineRenderer(io.Discard, true),
+		NewTopNRenderer(io.Discard, true, 2),
+		NewIcicleRenderer(io.Discard, true),
+		NewBarsRenderer(io.Discard, true),
+		NewBracketsRenderer(io.Discard, true),
+	}
+
+	var wg sync.WaitGroup
+	for _, r := range renderers {
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r.Render(stats)
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+func TestIcicleCell_MixedLabelSplit(t *testing.T) {
+	colorFn := ColorFunc(true)
+	truncate := (&IcicleRenderer{}).truncate
+	tests := []struct {
+		name      string
+		cell      IcicleCell
+		wantGreen string
+		wantRed   string
+	}{
+		{"mostly adds", IcicleCell{Label: "render/", Add: 30, Del: 10, Total: 40}, "rende", "r/"},
+		{"even", IcicleCell{Label: "diff/", Add: 5, Del: 5, Total: 10}, "dif", "f/"},
+		{"tiny add share", IcicleCell{Label: "config/", Add: 1, Del: 99, Total: 100}, "c", "onfig/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := tt.cell.formatCentered(truncate, colorFn, 20, 1)
+			want := ColorAdd + tt.wantGreen + ColorDel + tt.wantRed + ColorReset
+			if !strings.Contains(got, want) {
+				t.Errorf("formatCentered() = %q, want it to contain %q", got, want)
+			}
+		})
+	}
+
+	pure, _ := IcicleCell{Label: "new.go", Add: 3, Total: 3}.formatCentered(truncate, colorFn, 20, 1)
+	if !strings.Contains(pure, ColorAdd+"new.go"+ColorReset) {
+		t.Errorf("pure-add cell = %q, want single green label", pure)
+	}
+}
+
+// BenchmarkIcicle_WideTree renders a 10k-file diff into a wide chart, where
+// each level has many cells to expand.
+func BenchmarkIcicle_WideTree(b *testing.B) {
+	stats := &diff.DiffStats{}
+	for i := 0; i < 10000; i++ {
+		path := fmt.Sprintf("pkg%03d/sub%02d/file%d.go", i%100, (i/100)%10, i)
+		stats.Files = append(stats.Files, diff.FileStat{Path: path, Additions: 1 + i%50, Deletions: i % 7})
+		stats.TotalAdd += 1 + i%50
+		stats.TotalDel += i % 7
+	}
+	stats.TotalFiles = len(stats.Files)
+
+	r := NewIcicleRenderer(io.Discard, false)
+	r.Width = 400
+	r.MinCellWidth = 2
+	r.MaxDepth = 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Render(stats)
+	}
+}
+
+func TestIcicle_MetricFiles(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "gen/big.pb.go", Additions: 5000},
+			{Path: "src/a.go", Additions: 3},
+			{Path: "src/b.go", Additions: 2},
+			{Path: "src/c.go", Additions: 1},
+		},
+		TotalFiles: 4,
+		TotalAdd:   5006,
+	}
+	cellWidth := func(metric Metric, name string) int {
+		var buf bytes.Buffer
+		r := NewIcicleRenderer(&buf, false)
+		r.Width = 80
+		r.MaxDepth = 1
+		r.Metric = metric
+		r.Render(stats)
+		for _, line := range strings.Split(buf.String(), "\n") {
+			for _, cell := range strings.Split(line, "|") {
+				if strings.Contains(cell, name) {
+					return VisibleWidth(cell)
+				}
+			}
+		}
+		t.Fatalf("no %s cell in:\n%s", name, buf.String())
+		return 0
+	}
+
+	if lines, files := cellWidth(MetricLines, "src"), cellWidth(MetricFiles, "src"); files <= lines {
+		t.Errorf("src cell = %d columns by files, want wider than %d by lines", files, lines)
+	}
+	if gen, src := cellWidth(MetricFiles, "gen"), cellWidth(MetricFiles, "src"); src <= 2*gen {
+		t.Errorf("by files, src cell = %d columns, want over twice gen's %d", src, gen)
+	}
+}
+
+func TestIcicle_FooterStatsFallback(t *testing.T) {
+	r := NewIcicleRenderer(io.Discard, false)
+	cell := IcicleCell{Label: "big.go", Path: "src/big.go", Add: 12345, Del: 678}
+	tests := []struct {
+		width      int
+		want       string
+		wantLegend string
+	}{
+		{20, "+12345 -678", ""},
+		{8, "+11667", ""},
+		{4, "*3", "*3 src/big.go +12345 -678"},
+		{1, "▪", ""},
+		{0, "", ""},
+	}
+	for _, tt := range tests {
+		got, visible, legend := r.footerStats(cell, tt.width, 3)
+		if got != tt.want || legend != tt.wantLegend {
+			t.Errorf("footerStats(width %d) = %q, %q; want %q, %q", tt.width, got, legend, tt.want, tt.wantLegend)
+		}
+		if visible != utf8.RuneCountInString(got) {
+			t.Errorf("footerStats(width %d) visible = %d for %q", tt.width, visible, got)
+		}
+	}
+
+	// Net change is negative when deletions dominate
+	if got, _, _ := r.footerStats(IcicleCell{Add: 10, Del: 5000}, 6, 1); got != "-4990" {
+		t.Errorf("footerStats net = %q, want -4990", got)
+	}
+}
+
+func TestIcicle_FooterLegend(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewIcicleRenderer(&buf, false)
+	r.Width = 30
+	r.MinCellWidth = 4
+	r.Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "a.go", Additions: 100000, Deletions: 90000},
+			{Path: "b.go", Additions: 1000, Deletions: 900},
+		},
+		TotalFiles: 2, TotalAdd: 101000, TotalDel: 90900,
+	})
+
+	got := buf.String()
+	if !strings.Contains(got, "*1 b.go +1000 -900") {
+		t.Errorf("want footer legend for the narrow cell, got:\n%s", got)
+	}
+}
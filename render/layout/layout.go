@@ -0,0 +1,89 @@
+// Package layout divides terminal columns among weighted items for renderers
+// that draw magnitude as width (icicle cells, bars).
+package layout
+
+// Allocation is the result of Allocate.
+//
+// Widths holds one width per kept item, in input order, and one more for a
+// trailing fold cell when Folded > 0 (the caller sums the folded items'
+// weights into it). Widths sums to exactly the available width, and every
+// width is at least the minimum; it is nil when not even one cell fits.
+type Allocation struct {
+	Widths []int
+	Kept   int // Leading items that got their own cell
+	Folded int // Items past Kept merged into the fold cell (or dropped when Widths is nil)
+}
+
+// Allocate divides avail columns among items with the given weights, sorted
+// largest first. Each cell gets minWidth, and the columns left over are split
+// in proportion to weight; rounding remainders go to the first (largest) cell
+// so the cells fill avail with no gap.
+//
+// When the items do not all fit at minWidth, the leading ones that do are
+// kept and the rest fold into one trailing cell, so their changes stay
+// visible as a single "…+N" entry.
+func Allocate(weights []int, avail, minWidth int) Allocation {
+	minWidth = max(minWidth, 1)
+	if len(weights) == 0 {
+		return Allocation{}
+	}
+
+	kept, folded := len(weights), 0
+	cellWeights := weights
+	if len(weights)*minWidth > avail {
+		cells := max(avail, 0) / minWidth
+		if cells == 0 {
+			return Allocation{Folded: len(weights)}
+		}
+		kept = cells - 1
+		folded = len(weights) - kept
+		other := 0
+		for _, w := range weights[kept:] {
+			other += w
+		}
+		cellWeights = append(append([]int(nil), weights[:kept]...), other)
+	}
+
+	return Allocation{
+		Widths: Proportional(cellWeights, avail, minWidth),
+		Kept:   kept,
+		Folded: folded,
+	}
+}
+
+// Proportional gives each weight minWidth columns plus a share of the rest
+// in proportion to its weight, with rounding remainders going to the first
+// entry. The widths sum to avail; callers must ensure
+// len(weights)*minWidth <= avail (Allocate does). Negative weights count as 0.
+func Proportional(weights []int, avail, minWidth int) []int {
+	if len(weights) == 0 {
+		return nil
+	}
+
+	total := 0
+	for _, w := range weights {
+		total += max(w, 0)
+	}
+	extra := avail - len(weights)*minWidth
+
+	widths := make([]int, len(weights))
+	used := 0
+	for i, w := range weights {
+		widths[i] = minWidth
+		if extra > 0 && total > 0 {
+			widths[i] += max(w, 0) * extra / total
+		}
+		used += widths[i]
+	}
+	widths[0] += avail - used
+	return widths
+}
+
+// Scale returns the columns a bar for value gets when maxValue fills width,
+// at least minFill for any positive value and never more than width.
+func Scale(value, maxValue, width, minFill int) int {
+	if value <= 0 || maxValue <= 0 || width <= 0 {
+		return 0
+	}
+	return min(max(value*width/maxValue, minFill), width)
+}
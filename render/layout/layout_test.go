@@ -0,0 +1,101 @@
+package layout
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name     string
+		weights  []int
+		avail    int
+		minWidth int
+		want     Allocation
+	}{
+		{"proportional", []int{30, 10}, 40, 10, Allocation{Widths: []int{25, 15}, Kept: 2}},
+		{"remainder to first", []int{1, 1, 1}, 10, 1, Allocation{Widths: []int{4, 3, 3}, Kept: 3}},
+		{"fold overflow", []int{50, 20, 10, 5}, 30, 10, Allocation{Widths: []int{10, 10, 10}, Kept: 2, Folded: 2}},
+		{"only fold cell fits", []int{5, 4}, 15, 10, Allocation{Widths: []int{15}, Kept: 0, Folded: 2}},
+		{"nothing fits", []int{5, 4}, 8, 10, Allocation{Folded: 2}},
+		{"no items", nil, 40, 10, Allocation{}},
+		{"zero weights", []int{0, 0}, 10, 2, Allocation{Widths: []int{8, 2}, Kept: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Allocate(tt.weights, tt.avail, tt.minWidth); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Allocate(%v, %d, %d) = %+v, want %+v", tt.weights, tt.avail, tt.minWidth, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestAllocate_Properties checks invariants over random inputs: widths sum
+// exactly to the available space, respect the minimum, account for every
+// item, and never give a lighter kept item more room than a heavier one.
+func TestAllocate_Properties(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		weights := make([]int, rng.Intn(40))
+		for j := range weights {
+			weights[j] = rng.Intn(1000)
+			if rng.Intn(4) == 0 {
+				weights[j] = rng.Intn(1_000_000)
+			}
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(weights)))
+		avail := rng.Intn(300)
+		minWidth := 1 + rng.Intn(20)
+
+		a := Allocate(weights, avail, minWidth)
+		if a.Kept+a.Folded != len(weights) {
+			t.Fatalf("Allocate(%v, %d, %d): kept %d + folded %d != %d items", weights, avail, minWidth, a.Kept, a.Folded, len(weights))
+		}
+		if a.Widths == nil {
+			if len(weights) > 0 && avail >= minWidth {
+				t.Fatalf("Allocate(%v, %d, %d): no cells although one fits", weights, avail, minWidth)
+			}
+			continue
+		}
+
+		wantCells := a.Kept
+		if a.Folded > 0 {
+			wantCells++
+		}
+		if len(a.Widths) != wantCells {
+			t.Fatalf("Allocate(%v, %d, %d): %d widths, want %d", weights, avail, minWidth, len(a.Widths), wantCells)
+		}
+		sum := 0
+		for j, w := range a.Widths {
+			sum += w
+			if w < minWidth {
+				t.Fatalf("Allocate(%v, %d, %d): width %d below minimum", weights, avail, minWidth, w)
+			}
+			if j > 0 && j < a.Kept && w > a.Widths[j-1] {
+				t.Fatalf("Allocate(%v, %d, %d): widths %v not ordered by weight", weights, avail, minWidth, a.Widths)
+			}
+		}
+		if sum != avail {
+			t.Fatalf("Allocate(%v, %d, %d): widths %v sum to %d, want %d", weights, avail, minWidth, a.Widths, sum, avail)
+		}
+	}
+}
+
+func TestScale(t *testing.T) {
+	tests := []struct {
+		value, maxValue, width, minFill, want int
+	}{
+		{50, 100, 40, 1, 20},
+		{1, 1000, 40, 1, 1},
+		{0, 100, 40, 1, 0},
+		{200, 100, 40, 1, 40},
+		{10, 0, 40, 1, 0},
+	}
+	for _, tt := range tests {
+		if got := Scale(tt.value, tt.maxValue, tt.width, tt.minFill); got != tt.want {
+			t.Errorf("Scale(%d, %d, %d, %d) = %d, want %d", tt.value, tt.maxValue, tt.width, tt.minFill, got, tt.want)
+		}
+	}
+}
@@ -1,23 +1,329 @@
 package render
 
-// ValidModes is the canonical list of available visualization modes.
-var ValidModes = []string{"tree", "smart", "topn", "icicle", "brackets"}
+import (
+	"fmt"
+	"sort"
 
-// ModeDescriptions provides help text for each mode.
-var ModeDescriptions = map[string]string{
-	"tree":     "Indented tree with file stats (default)",
-	"smart":    "Depth-aggregated sparkline (--depth=1 collapsed, 2 subdirs)",
-	"topn":     "Top N files by change size (--count=N, --sort=total|adds|dels)",
-	"icicle":   "Horizontal icicle chart (width = magnitude)",
-	"brackets": "Nested brackets [dir file... file...] (single-line hierarchy)",
+	"github.com/kylesnowschwartz/diff-viz/config"
+)
+
+// Config option names a mode may honor (keys in config.ModeConfig JSON).
+//
+// OptionDepth has one meaning in every mode that supports it: the number of
+// directory levels shown, counting top-level directories as level 1. Anything
+// deeper is folded into its ancestor at that level (smart and bars aggregate,
+// tree and brackets show the folded directory with totals, icicle stops adding
+// rows). 0 means unlimited where a mode allows it.
+const (
+	OptionWidth         = "width"
+	OptionDepth         = "depth"
+	OptionExpand        = "expand"
+	OptionN             = "n"
+	OptionBracketColors = "bracketColors"
+	OptionZeroBar       = "zeroBar"
+	OptionBarPadding    = "barPadding"
+	OptionBarScale      = "barScale"
+	OptionRootGroup     = "rootGroup"
+	OptionRootGroupSort = "rootGroupSort"
+
+	OptionSeparator        = "separator"
+	OptionItemSeparator    = "itemSeparator"
+	OptionCleanupThreshold = "cleanupThreshold"
+	OptionAbbrevCounts     = "abbrevCounts"
+)
+
+// ModeInfo describes a visualization mode.
+// Options lists the config options (and matching CLI flags) the mode honors;
+// the CLI warns when a flag for any other option is given.
+type ModeInfo struct {
+	Name        string
+	Description string
+	Options     []string              // Config options the mode honors
+	Colors      []ColorRole           // What each color in the mode's output marks (none for plain text)
+	Defaults    config.ResolvedConfig // Built-in defaults (globals + config.ModeDefaults)
+
+	// Standalone modes chart repository state rather than a diff (tracked
+	// history, stashes, branches, one file's commits). They have no
+	// Renderer, so demos, previews, serve, and NAME=FILE outputs leave
+	// them out.
+	Standalone bool
+}
+
+// ColorRole is one entry in a mode's color legend. Colors holds more than one
+// code when a role cycles through a palette (brackets by depth).
+type ColorRole struct {
+	Colors  []string
+	Meaning string
+}
+
+// Color roles shared by the mode legends.
+var (
+	roleDir      = ColorRole{[]string{ColorDir}, "directories"}
+	roleFile     = ColorRole{[]string{ColorFile}, "files"}
+	roleNew      = ColorRole{[]string{ColorNew}, "new files"}
+	roleAdd      = ColorRole{[]string{ColorAdd}, "added lines"}
+	roleDel      = ColorRole{[]string{ColorDel}, "deleted lines"}
+	roleDim      = ColorRole{[]string{ColorDim}, "secondary details"}
+	roleConflict = ColorRole{[]string{ColorConflict}, "conflicts"}
+	roleCleanup  = ColorRole{[]string{ColorCleanup}, "cleanup (" + CleanupMarker + ")"}
+)
+
+// modes is the canonical, ordered mode registry.
+var modes = []ModeInfo{
+	{
+		Name:        "tree",
+		Description: "Indented tree with file stats (default)",
+		Options:     []string{OptionDepth, OptionCleanupThreshold},
+		Colors:      []ColorRole{roleDir, roleFile, roleNew, roleAdd, roleDel, roleConflict, roleCleanup},
+	},
+	{
+		Name:        "smart",
+		Description: "Depth-aggregated sparkline (--depth=1 collapsed, 2 subdirs)",
+		Options:     []string{OptionWidth, OptionDepth, OptionZeroBar, OptionBarPadding, OptionBarScale, OptionRootGroup, OptionSeparator, OptionCleanupThreshold, OptionAbbrevCounts},
+		Colors:      []ColorRole{roleDir, roleFile, roleNew, roleAdd, roleDel, roleCleanup},
+	},
+	{
+		Name:        "topn",
+		Description: "Top N files by change size (--count=N, --sort=total|adds|dels|funcs)",
+		Options:     []string{OptionN, OptionZeroBar, OptionBarPadding, OptionBarScale},
+		Colors:      []ColorRole{roleDir, roleNew, roleAdd, roleDel, roleDim, roleConflict},
+	},
+	{
+		Name:        "hotpaths",
+		Description: "Subtrees covering 80% of changed lines, with percentages (--count=N max)",
+		Options:     []string{OptionN},
+		Colors:      []ColorRole{roleDir, roleAdd, roleDel, roleDim},
+	},
+	{
+		Name:        "histogram",
+		Description: "Files per change size (1-10, 11-50, 51-200, 201+ lines): many small edits or a few rewrites",
+		Options:     []string{},
+		Colors:      []ColorRole{{[]string{ColorDir}, "file counts"}, roleAdd, roleDel, roleDim},
+	},
+	{
+		Name:        "icicle",
+		Description: "Horizontal icicle chart (width = magnitude)",
+		Options:     []string{OptionWidth, OptionDepth},
+		Colors:      []ColorRole{roleDir, roleAdd, roleDel, roleDim},
+	},
+	{
+		Name:        "bars",
+		Description: "Horizontal bar per directory at --depth, scaled to width",
+		Options:     []string{OptionWidth, OptionDepth},
+		Colors:      []ColorRole{roleDir, roleAdd, roleDel},
+	},
+	{
+		Name:        "stat",
+		Description: "git diff --stat style: path | count +++--- per file, scaled to width",
+		Options:     []string{OptionWidth},
+		Colors:      []ColorRole{roleAdd, roleDel},
+	},
+	{
+		Name:        "brackets",
+		Description: "Nested brackets [dir file... file...] (single-line hierarchy)",
+		Options:     []string{OptionWidth, OptionDepth, OptionExpand, OptionBracketColors, OptionRootGroup, OptionRootGroupSort, OptionSeparator, OptionItemSeparator, OptionAbbrevCounts},
+		Colors:      []ColorRole{{DefaultBracketColors, "brackets by depth (bracketColors)"}, roleDir, roleFile, roleNew, roleAdd, roleDel},
+	},
+	{
+		Name:        "trailers",
+		Description: "Commit-message trailers (Diff-Files, Diff-Lines, Diff-Dirs) for commit-msg hooks",
+		Options:     []string{},
+	},
+	{
+		Name:        "suggest",
+		Description: "One-line commit message or PR title suggestion (verb scope: subject (+A/-D, N files))",
+		Options:     []string{},
+	},
+	{
+		Name:        "trend",
+		Description: "Size of a tracked range per snapshot (--label NAME; record with: git-diff-tree track)",
+		Options:     []string{},
+		Colors:      []ColorRole{{[]string{ColorAdd}, "added lines, shrinking (▼)"}, {[]string{ColorDel}, "deleted lines, growing (▲)"}},
+		Standalone:  true,
+	},
+	{
+		Name:        "stashes",
+		Description: "One line per stash entry: age, +/- totals, top directories",
+		Options:     []string{},
+		Colors:      []ColorRole{roleDir, roleAdd, roleDel, {[]string{ColorDim}, "ages"}},
+		Standalone:  true,
+	},
+	{
+		Name:        "branches",
+		Description: "One line per local branch vs its upstream or the main branch, stalest first",
+		Options:     []string{},
+		Colors:      []ColorRole{roleAdd, roleDel, roleDim},
+		Standalone:  true,
+	},
+	{
+		Name:        "filehistory",
+		Description: "One file's changes per commit, following renames (args: PATH; --file-history=N commits)",
+		Options:     []string{},
+		Colors:      []ColorRole{{[]string{ColorFile}, "commits"}, roleAdd, roleDel, {[]string{ColorDim}, "renames"}},
+		Standalone:  true,
+	},
+}
+
+// Modes returns metadata for every mode in display order.
+func Modes() []ModeInfo {
+	result := make([]ModeInfo, len(modes))
+	for i, m := range modes {
+		m.Options = append([]string(nil), m.Options...)
+		m.Colors = append([]ColorRole(nil), m.Colors...)
+		m.Defaults = config.DefaultsForMode(m.Name)
+		result[i] = m
+	}
+	return result
+}
+
+// LookupMode returns metadata for the named mode.
+func LookupMode(name string) (ModeInfo, bool) {
+	for _, m := range Modes() {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return ModeInfo{}, false
+}
+
+// ModeNames returns all mode names in display order.
+func ModeNames() []string {
+	names := make([]string, len(modes))
+	for i, m := range modes {
+		names[i] = m.Name
+	}
+	return names
 }
 
 // IsValidMode returns true if mode is a recognized visualization mode.
 func IsValidMode(mode string) bool {
-	for _, m := range ValidModes {
-		if m == mode {
+	_, ok := LookupMode(mode)
+	return ok
+}
+
+// DiffModeNames returns the names of the modes that render a diff (all but
+// the Standalone ones) in display order.
+func DiffModeNames() []string {
+	var names []string
+	for _, m := range modes {
+		if !m.Standalone {
+			names = append(names, m.Name)
+		}
+	}
+	return names
+}
+
+// IsDiffMode returns true if mode is a recognized mode that renders a diff.
+func IsDiffMode(mode string) bool {
+	m, ok := LookupMode(mode)
+	return ok && !m.Standalone
+}
+
+// Supports reports whether the mode honors the named config option.
+func (m ModeInfo) Supports(option string) bool {
+	for _, o := range m.Options {
+		if o == option {
 			return true
 		}
 	}
 	return false
 }
+
+// DefaultOptions returns the default value of each supported option,
+// keyed by option name (width is "auto" when terminal-detected).
+func (m ModeInfo) DefaultOptions() map[string]any {
+	values := make(map[string]any, len(m.Options))
+	for _, o := range m.Options {
+		switch o {
+		case OptionWidth:
+			if m.Defaults.WidthAuto {
+				values[o] = "auto"
+			} else {
+				values[o] = m.Defaults.Width
+			}
+		case OptionDepth:
+			values[o] = m.Defaults.Depth
+		case OptionExpand:
+			values[o] = m.Defaults.Expand
+		case OptionN:
+			values[o] = m.Defaults.N
+		case OptionBracketColors:
+			values[o] = m.Defaults.BracketColors
+		case OptionZeroBar:
+			values[o] = string(ZeroBarEmpty)
+		case OptionBarPadding:
+			values[o] = m.Defaults.BarPadding
+		case OptionBarScale:
+			values[o] = string(ScaleSteps)
+		case OptionRootGroup:
+			values[o] = m.Defaults.RootGroup
+		case OptionRootGroupSort:
+			values[o] = m.Defaults.RootGroupSort
+		case OptionSeparator:
+			values[o] = " │ "
+		case OptionItemSeparator:
+			values[o] = ","
+		case OptionCleanupThreshold:
+			values[o] = m.Defaults.CleanupThreshold
+		case OptionAbbrevCounts:
+			values[o] = m.Defaults.AbbrevCounts
+		}
+	}
+	return values
+}
+
+// CheckConfig reports config entries no mode will use: unknown mode names
+// and options set under modes.<name> that the mode ignores.
+// Returns warnings (sorted for stable output); nil config is valid.
+func CheckConfig(cfg *config.Config) []string {
+	if cfg == nil {
+		return nil
+	}
+
+	warnings := checkModeConfigs("modes", cfg.Modes)
+	warnings = append(warnings, checkProfiles("profiles", cfg.Profiles)...)
+	for key, repo := range cfg.Repos {
+		prefix := fmt.Sprintf("repos[%q]", key)
+		warnings = append(warnings, checkModeConfigs(prefix+".modes", repo.Modes)...)
+		warnings = append(warnings, checkProfiles(prefix+".profiles", repo.Profiles)...)
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// checkModeConfigs warns about unknown modes and unsupported options in a
+// modes map found at prefix.
+func checkModeConfigs(prefix string, modes map[string]config.ModeConfig) []string {
+	var warnings []string
+	for name, mc := range modes {
+		info, ok := LookupMode(name)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("config: unknown mode %q in %s", name, prefix))
+			continue
+		}
+		for _, key := range mc.SetKeys() {
+			if !info.Supports(key) {
+				warnings = append(warnings, fmt.Sprintf("config: %s.%s.%s is ignored (%s supports: %v)", prefix, name, key, name, info.Options))
+			}
+		}
+	}
+	return warnings
+}
+
+// checkProfiles warns about profile rules naming unknown modes.
+func checkProfiles(prefix string, profiles map[string][]config.ProfileRule) []string {
+	var warnings []string
+	for name, rules := range profiles {
+		for i, rule := range rules {
+			if _, err := ParseOutlineFormat(rule.Mode); err == nil || IsDiffMode(rule.Mode) {
+				continue
+			}
+			if IsValidMode(rule.Mode) {
+				warnings = append(warnings, fmt.Sprintf("config: %s.%s[%d]: %s mode does not render a diff", prefix, name, i, rule.Mode))
+			} else {
+				warnings = append(warnings, fmt.Sprintf("config: %s.%s[%d]: unknown mode %q", prefix, name, i, rule.Mode))
+			}
+		}
+	}
+	return warnings
+}
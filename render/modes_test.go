@@ -0,0 +1,83 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/config"
+)
+
+func TestModes(t *testing.T) {
+	names := ModeNames()
+	if len(names) != len(Modes()) || names[0] != "tree" {
+		t.Fatalf("ModeNames() = %v, want tree first and one name per mode", names)
+	}
+
+	topn, ok := LookupMode("topn")
+	if !ok {
+		t.Fatal("LookupMode(topn) not found")
+	}
+	if topn.Defaults.N != 10 {
+		t.Errorf("topn Defaults.N = %d, want 10 (from config.ModeDefaults)", topn.Defaults.N)
+	}
+	if !topn.Supports(OptionN) || topn.Supports(OptionWidth) {
+		t.Errorf("topn Options = %v, want n but not width", topn.Options)
+	}
+	if got := topn.DefaultOptions()[OptionN]; got != 10 {
+		t.Errorf("topn DefaultOptions()[n] = %v, want 10", got)
+	}
+
+	if IsValidMode("collapsed") {
+		t.Error("IsValidMode(collapsed) = true, want false")
+	}
+	if !IsValidMode("trend") || IsDiffMode("trend") || !IsDiffMode("tree") {
+		t.Error("trend should be a valid standalone mode and tree a diff mode")
+	}
+	for _, name := range DiffModeNames() {
+		if name == "trend" || name == "stashes" || name == "branches" || name == "filehistory" {
+			t.Errorf("DiffModeNames() includes standalone mode %s", name)
+		}
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	n, abbrev := 3, true
+	cfg := &config.Config{Modes: map[string]config.ModeConfig{
+		"topn":     {N: &n},                 // supported
+		"tree":     {N: &n},                 // tree ignores n
+		"treee":    {},                      // typo
+		"icicle":   {Depth: &n},             // supported
+		"trend":    {},                      // standalone modes are modes too
+		"brackets": {AbbrevCounts: &abbrev}, // supported
+		"hotpaths": {AbbrevCounts: &abbrev}, // hotpaths prints exact counts
+	}, Profiles: map[string][]config.ProfileRule{
+		"default": {{Mode: "markdown"}, {Mode: "smart"}, {Mode: "collapsed"}, {Mode: "trend"}},
+	}, Repos: map[string]config.RepoConfig{
+		"github.com/org/repo": {
+			Modes:    map[string]config.ModeConfig{"tree": {N: &n}},
+			Profiles: map[string][]config.ProfileRule{"default": {{Mode: "flat"}}},
+		},
+	}}
+
+	want := []string{
+		`config: modes.hotpaths.abbrevCounts is ignored (hotpaths supports: [n])`,
+		`config: modes.tree.n is ignored (tree supports: [depth cleanupThreshold])`,
+		`config: profiles.default[2]: unknown mode "collapsed"`,
+		`config: profiles.default[3]: trend mode does not render a diff`,
+		`config: repos["github.com/org/repo"].modes.tree.n is ignored (tree supports: [depth cleanupThreshold])`,
+		`config: repos["github.com/org/repo"].profiles.default[0]: unknown mode "flat"`,
+		`config: unknown mode "treee" in modes`,
+	}
+	got := CheckConfig(cfg)
+	if len(got) != len(want) {
+		t.Fatalf("CheckConfig() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("CheckConfig()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if CheckConfig(nil) != nil {
+		t.Error("CheckConfig(nil) should return nil")
+	}
+}
@@ -0,0 +1,50 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// NumstatRenderer re-emits git diff --numstat lines with extra tab-separated
+// columns, so scripts reading the first three fields keep working:
+//
+//	adds  dels  path  status  rename-target  binary-size-delta
+//
+// Binary files show "-" for adds and dels like numstat. Missing values
+// (no rename, text file, details not computed) are "-". Run
+// diff.AddChangeDetails first to fill status, rename, and size columns.
+// Output is never colored and has no summary line.
+type NumstatRenderer struct {
+	w io.Writer
+}
+
+// NewNumstatRenderer creates an enriched numstat renderer.
+func NewNumstatRenderer(w io.Writer) *NumstatRenderer {
+	return &NumstatRenderer{w: w}
+}
+
+// Render outputs one line per file.
+func (r *NumstatRenderer) Render(stats *diff.DiffStats) {
+	for _, f := range stats.Files {
+		adds, dels := "-", "-"
+		if !f.IsBinary {
+			adds, dels = fmt.Sprint(f.Additions), fmt.Sprint(f.Deletions)
+		}
+		size := "-"
+		if f.IsBinary && f.HasSizeDelta {
+			size = fmt.Sprintf("%+d", f.SizeDelta)
+		}
+		fmt.Fprintln(r.w, strings.Join([]string{adds, dels, f.Path, orDash(f.Status), orDash(f.NewPath), size}, "\t"))
+	}
+}
+
+// orDash returns s, or "-" when s is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
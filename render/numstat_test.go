@@ -0,0 +1,28 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestNumstatRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	NewNumstatRenderer(&buf).Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "a.go", Additions: 3, Deletions: 1, Status: "M"},
+			{Path: "src/{old => new}/b.go", Status: "R", NewPath: "src/new/b.go"},
+			{Path: "logo.png", IsBinary: true, Status: "M", SizeDelta: -512, HasSizeDelta: true},
+			{Path: "c.go", Additions: 2},
+		},
+	})
+
+	want := "3\t1\ta.go\tM\t-\t-\n" +
+		"0\t0\tsrc/{old => new}/b.go\tR\tsrc/new/b.go\t-\n" +
+		"-\t-\tlogo.png\tM\t-\t-512\n" +
+		"2\t0\tc.go\t-\t-\t-\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%q\nwant:\n%q", buf.String(), want)
+	}
+}
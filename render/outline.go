@@ -0,0 +1,147 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// OutlineFormat selects the markup dialect for OutlineRenderer.
+type OutlineFormat string
+
+const (
+	FormatMarkdown OutlineFormat = "markdown" // GitHub-flavored nested lists
+	FormatOrg      OutlineFormat = "org"      // Emacs Org-mode plain lists
+	FormatAsciiDoc OutlineFormat = "asciidoc" // AsciiDoc nested unordered lists
+)
+
+// OutlineFormats lists the supported outline formats.
+var OutlineFormats = []OutlineFormat{FormatMarkdown, FormatOrg, FormatAsciiDoc}
+
+// ParseOutlineFormat validates a --format value.
+func ParseOutlineFormat(s string) (OutlineFormat, error) {
+	for _, f := range OutlineFormats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown format %q (valid: markdown, org, asciidoc)", s)
+}
+
+// OutlineRenderer renders diff stats as a nested list for review documents.
+// Org and AsciiDoc directories carry anchors (Org targets, AsciiDoc inline
+// anchors) so other sections can link to them. Output is never colored.
+type OutlineRenderer struct {
+	Format       OutlineFormat
+	MaxPathDepth int // Fold directories nested deeper than this (see FoldDeepPaths; 0 = off)
+	w            io.Writer
+}
+
+// NewOutlineRenderer creates an outline renderer for the given format.
+func NewOutlineRenderer(w io.Writer, format OutlineFormat) *OutlineRenderer {
+	return &OutlineRenderer{Format: format, w: w}
+}
+
+// Render outputs the diff stats as a nested list.
+func (r *OutlineRenderer) Render(stats *diff.DiffStats) {
+	if stats.TotalFiles == 0 {
+		fmt.Fprintln(r.w, "No changes")
+		return
+	}
+
+	root := BuildTreeFromFiles(stats.Files)
+	FoldDeepPaths(root, r.MaxPathDepth)
+	CalcTotals(root)
+	CollapseSingleChildPaths(root)
+
+	for _, child := range root.Children {
+		r.renderNode(child, 0)
+	}
+
+	fmt.Fprintln(r.w)
+	fmt.Fprintf(r.w, "+%d -%d in %d files\n", stats.TotalAdd, stats.TotalDel, stats.TotalFiles)
+}
+
+// renderNode outputs one list item and recurses into children.
+func (r *OutlineRenderer) renderNode(node *TreeNode, depth int) {
+	name := r.code(node.Name)
+	if node.IsDir {
+		name = r.anchor(node.Path) + r.code(node.Name+"/")
+	}
+	fmt.Fprintf(r.w, "%s %s %s\n", r.bullet(depth), name, outlineStats(node))
+
+	for _, child := range node.Children {
+		r.renderNode(child, depth+1)
+	}
+}
+
+// bullet returns the list marker for depth.
+// Markdown and Org nest by indentation; AsciiDoc repeats the marker.
+func (r *OutlineRenderer) bullet(depth int) string {
+	if r.Format == FormatAsciiDoc {
+		return strings.Repeat("*", depth+1)
+	}
+	return strings.Repeat("  ", depth) + "-"
+}
+
+// code wraps s in the format's inline-code markup.
+func (r *OutlineRenderer) code(s string) string {
+	if r.Format == FormatOrg {
+		return "~" + s + "~"
+	}
+	return "`" + s + "`"
+}
+
+// anchor returns a link target for a directory path.
+func (r *OutlineRenderer) anchor(path string) string {
+	id := outlineAnchorID(path)
+	switch r.Format {
+	case FormatAsciiDoc:
+		return "[[" + id + "]]"
+	case FormatOrg:
+		return "<<" + id + ">> "
+	default:
+		return "" // Markdown has no portable anchor syntax
+	}
+}
+
+// outlineAnchorID converts a path to an identifier safe for both formats
+// (e.g., "src/lib" -> "diff-src-lib").
+func outlineAnchorID(path string) string {
+	var sb strings.Builder
+	sb.WriteString("diff")
+	for _, part := range strings.Split(path, "/") {
+		sb.WriteByte('-')
+		for _, c := range part {
+			if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' {
+				sb.WriteRune(c)
+			} else {
+				sb.WriteByte('_')
+			}
+		}
+	}
+	return sb.String()
+}
+
+// outlineStats formats node stats without color.
+func outlineStats(node *TreeNode) string {
+	if node.IsBinary {
+		return "(binary)"
+	}
+	if node.IsLarge {
+		return "(large)"
+	}
+	var parts []string
+	if node.Add > 0 {
+		parts = append(parts, fmt.Sprintf("+%d", node.Add))
+	}
+	if node.Del > 0 {
+		parts = append(parts, fmt.Sprintf("-%d", node.Del))
+	}
+	if node.IsNew {
+		parts = append(parts, "(new)")
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,41 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestOutlineRenderer(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/a.go", Additions: 3},
+			{Path: "src/b.go", Deletions: 2},
+		},
+		TotalAdd:   3,
+		TotalDel:   2,
+		TotalFiles: 2,
+	}
+
+	tests := []struct {
+		format OutlineFormat
+		want   []string
+	}{
+		{FormatMarkdown, []string{"- `src/` +3 -2", "  - `a.go` +3", "  - `b.go` -2"}},
+		{FormatOrg, []string{"- <<diff-src>> ~src/~ +3 -2", "  - ~a.go~ +3", "  - ~b.go~ -2"}},
+		{FormatAsciiDoc, []string{"* [[diff-src]]`src/` +3 -2", "** `a.go` +3", "** `b.go` -2"}},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		NewOutlineRenderer(&buf, tt.format).Render(stats)
+		lines := strings.Split(buf.String(), "\n")
+		for i, want := range tt.want {
+			if i >= len(lines) || lines[i] != want {
+				t.Errorf("%s line %d = %q, want %q", tt.format, i, lines[min(i, len(lines)-1)], want)
+			}
+		}
+	}
+}
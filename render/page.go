@@ -0,0 +1,22 @@
+package render
+
+import (
+	"io"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// RenderPage writes lines offset to offset+limit-1 of a rendering of stats
+// to w and returns the total number of lines, so TUIs and web views can show
+// a viewport and size a scrollbar without building the whole output.
+// newRenderer builds the renderer on the writer it is given. A limit <= 0
+// renders through to the end.
+//
+//	total := RenderPage(&buf, stats, top, height, func(w io.Writer) Renderer {
+//		return NewTreeRenderer(w, true)
+//	})
+func RenderPage(w io.Writer, stats *diff.DiffStats, offset, limit int, newRenderer func(io.Writer) Renderer) int {
+	page := NewPageWriter(w, offset, limit)
+	newRenderer(page).Render(stats)
+	return page.Lines()
+}
@@ -2,6 +2,7 @@ package render
 
 import (
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
@@ -16,8 +17,9 @@ type PathSegment struct {
 	Files     []string // List of file paths in this segment
 	Add       int      // Total additions
 	Del       int      // Total deletions
+	NewAdd    int      // Additions in new files (subset of Add)
 	FileCount int      // Number of files
-	HasNew    bool     // Contains untracked/new files
+	HasNew    bool     // Contains new files (see diff.FileStat.IsNew)
 	IsFile    bool     // True if SubPath is a single file (not aggregated dir)
 }
 
@@ -98,6 +100,7 @@ func GroupByDepth(files []diff.FileStat, maxDepth int) map[string][]PathSegment
 
 	for _, f := range files {
 		groupKey, subPath, isFile := ParseDepthPath(f.Path, maxDepth)
+		isNew := f.IsNew()
 
 		if groupMap[groupKey] == nil {
 			groupMap[groupKey] = make(map[string]*PathSegment)
@@ -116,7 +119,8 @@ func GroupByDepth(files []diff.FileStat, maxDepth int) map[string][]PathSegment
 		seg.Add += f.Additions
 		seg.Del += f.Deletions
 		seg.FileCount++
-		if f.IsUntracked {
+		if isNew {
+			seg.NewAdd += f.Additions
 			seg.HasNew = true
 		}
 	}
@@ -144,6 +148,62 @@ func GroupByDepth(files []diff.FileStat, maxDepth int) map[string][]PathSegment
 	return result
 }
 
+// GroupRootFiles merges root-level files (groups keyed by their own filename)
+// in groups returned by GroupByDepth into one virtual group keyed by name.
+// At maxDepth=1 the group is a single aggregate segment keyed and shown as
+// "name/"; deeper, it keeps the individual files under the "name" key.
+// Nothing changes when name is empty, fewer than two root files exist, or a
+// top-level directory is already called name (its group would be overwritten
+// or look the same).
+func GroupRootFiles(groups map[string][]PathSegment, name string, maxDepth int) {
+	if name == "" {
+		return
+	}
+
+	var rootKeys []string
+	for key, segments := range groups {
+		if len(segments) == 1 && segments[0].IsFile && !strings.Contains(segments[0].Files[0], "/") {
+			rootKeys = append(rootKeys, key)
+		}
+	}
+	if len(rootKeys) < 2 {
+		return
+	}
+	if _, taken := groups[name]; taken && !slices.Contains(rootKeys, name) {
+		return
+	}
+
+	aggregate := PathSegment{TopDir: name, SubPath: name + "/"}
+	var files []PathSegment
+	for _, key := range rootKeys {
+		seg := groups[key][0]
+		delete(groups, key)
+
+		aggregate.Files = append(aggregate.Files, seg.Files...)
+		aggregate.Add += seg.Add
+		aggregate.Del += seg.Del
+		aggregate.NewAdd += seg.NewAdd
+		aggregate.FileCount += seg.FileCount
+		aggregate.HasNew = aggregate.HasNew || seg.HasNew
+
+		seg.TopDir = name
+		files = append(files, seg)
+	}
+
+	if maxDepth <= 1 {
+		aggregate.TopDir = aggregate.SubPath
+		groups[aggregate.SubPath] = []PathSegment{aggregate}
+		return
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Total() != files[j].Total() {
+			return files[i].Total() > files[j].Total()
+		}
+		return files[i].SubPath < files[j].SubPath
+	})
+	groups[name] = files
+}
+
 // GroupByTopDir groups files first by top-level dir, then by depth-2 path.
 // Deprecated: Use GroupByDepth with maxDepth=2 instead.
 func GroupByTopDir(files []diff.FileStat) map[string][]PathSegment {
@@ -170,7 +230,7 @@ func GroupByTopDir(files []diff.FileStat) map[string][]PathSegment {
 		seg.Add += f.Additions
 		seg.Del += f.Deletions
 		seg.FileCount++
-		if f.IsUntracked {
+		if f.IsNew() {
 			seg.HasNew = true
 		}
 	}
@@ -182,6 +182,57 @@ func TestGroupByTopDir_RootFiles(t *testing.T) {
 	}
 }
 
+func TestGroupRootFiles(t *testing.T) {
+	files := []diff.FileStat{
+		{Path: "README.md", Additions: 10},
+		{Path: "go.mod", Additions: 5, Deletions: 1},
+		{Path: "src/main.go", Additions: 3},
+	}
+
+	// Depth 1: one aggregate segment keyed "./"
+	groups := GroupByDepth(files, 1)
+	GroupRootFiles(groups, ".", 1)
+	if _, ok := groups["README.md"]; ok {
+		t.Error("README.md still a top-level group")
+	}
+	segs := groups["./"]
+	if len(segs) != 1 || segs[0].SubPath != "./" || segs[0].FileCount != 2 || segs[0].Total() != 16 {
+		t.Errorf("depth 1 root group = %+v, want one ./ segment with 2 files, total 16", segs)
+	}
+	if _, ok := groups["src"]; !ok {
+		t.Error("src group missing")
+	}
+
+	// Depth 2: individual files under the "." key
+	groups = GroupByDepth(files, 2)
+	GroupRootFiles(groups, ".", 2)
+	segs = groups["."]
+	if len(segs) != 2 || segs[0].SubPath != "README.md" || segs[1].SubPath != "go.mod" {
+		t.Errorf("depth 2 root group = %+v, want README.md, go.mod", segs)
+	}
+
+	// Disabled, or a lone root file: unchanged
+	groups = GroupByDepth(files, 2)
+	GroupRootFiles(groups, "", 2)
+	if len(groups) != 3 {
+		t.Errorf("empty name: got %d groups, want 3", len(groups))
+	}
+	groups = GroupByDepth(files[1:], 2)
+	GroupRootFiles(groups, ".", 2)
+	if _, ok := groups["go.mod"]; !ok {
+		t.Error("lone root file should keep its own group")
+	}
+
+	// A real top-level directory with the group's name is left alone
+	for _, depth := range []int{1, 2} {
+		groups = GroupByDepth(files, depth)
+		GroupRootFiles(groups, "src", depth)
+		if len(groups) != 3 || len(groups["src"]) != 1 || groups["src"][0].Files[0] != "src/main.go" {
+			t.Errorf("depth %d, name src: groups = %+v, want src/main.go's group untouched and root files ungrouped", depth, groups)
+		}
+	}
+}
+
 // mockTotaler implements Totaler for testing SortTopDirs.
 type mockTotaler int
 
@@ -0,0 +1,45 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// PreviewStats returns a small synthetic diff for previewing modes without
+// a repository: an edited file, a mostly deleted one (a cleanup), and a new
+// one, each in its own top-level directory.
+func PreviewStats() *diff.DiffStats {
+	files := []diff.FileStat{
+		{Path: "render/tree.go", Additions: 42, Deletions: 7, Status: "M"},
+		{Path: "diff/legacy.go", Additions: 3, Deletions: 28, Status: "M"},
+		{Path: "docs/guide.md", Additions: 25, Status: "A", IsUntracked: true},
+	}
+	stats := &diff.DiffStats{Files: files, TotalFiles: len(files)}
+	for _, f := range files {
+		stats.TotalAdd += f.Additions
+		stats.TotalDel += f.Deletions
+	}
+	return stats
+}
+
+// WriteColorLegend writes roles as one line of colored swatches and their
+// meanings ("■ directories  ■ added lines"). Nothing is written without
+// color or roles, since the swatches would not show anything.
+func WriteColorLegend(w io.Writer, roles []ColorRole, useColor bool) {
+	if !useColor || len(roles) == 0 {
+		return
+	}
+	parts := make([]string, 0, len(roles))
+	for _, role := range roles {
+		var sb strings.Builder
+		for _, code := range role.Colors {
+			sb.WriteString(code + "■" + ColorReset)
+		}
+		sb.WriteString(" " + role.Meaning)
+		parts = append(parts, sb.String())
+	}
+	fmt.Fprintf(w, "%sColors:%s %s\n", ColorDim, ColorReset, strings.Join(parts, "  "))
+}
@@ -0,0 +1,55 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPreviewStats_LegendCoversColors(t *testing.T) {
+	named := []string{ColorDir, ColorFile, ColorNew, ColorAdd, ColorDel, ColorDim, ColorConflict, ColorCleanup}
+	renderers := map[string]func(*bytes.Buffer) Renderer{
+		"tree":      func(b *bytes.Buffer) Renderer { return NewTreeRenderer(b, true) },
+		"smart":     func(b *bytes.Buffer) Renderer { return NewSmartSparklineRenderer(b, true) },
+		"topn":      func(b *bytes.Buffer) Renderer { return NewTopNRenderer(b, true, 5) },
+		"histogram": func(b *bytes.Buffer) Renderer { return NewHistogramRenderer(b, true) },
+		"icicle":    func(b *bytes.Buffer) Renderer { return NewIcicleRenderer(b, true) },
+		"stat":      func(b *bytes.Buffer) Renderer { return NewStatRenderer(b, true) },
+	}
+	stats := PreviewStats()
+	for mode, newRenderer := range renderers {
+		var buf bytes.Buffer
+		newRenderer(&buf).Render(stats)
+
+		info, _ := LookupMode(mode)
+		inLegend := make(map[string]bool)
+		for _, role := range info.Colors {
+			for _, code := range role.Colors {
+				inLegend[code] = true
+			}
+		}
+		for _, code := range named {
+			if strings.Contains(buf.String(), code) && !inLegend[code] {
+				t.Errorf("%s output uses %q, missing from its legend", mode, code)
+			}
+		}
+	}
+}
+
+func TestWriteColorLegend(t *testing.T) {
+	roles := []ColorRole{roleAdd, roleDel}
+
+	var buf bytes.Buffer
+	WriteColorLegend(&buf, roles, false)
+	if buf.Len() != 0 {
+		t.Errorf("without color: got %q, want nothing", buf.String())
+	}
+
+	WriteColorLegend(&buf, roles, true)
+	got := buf.String()
+	for _, want := range []string{ColorAdd + "■" + ColorReset + " added lines", ColorDel + "■" + ColorReset + " deleted lines"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("legend %q missing %q", got, want)
+		}
+	}
+}
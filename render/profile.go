@@ -0,0 +1,253 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ColorProfile is the color depth a terminal supports.
+type ColorProfile int
+
+const (
+	ProfileNone      ColorProfile = iota // No color (TERM=dumb)
+	ProfileANSI                          // 16 colors (SGR 30-37, 90-97)
+	Profile256                           // 256-color palette (38;5;N)
+	ProfileTrueColor                     // 24-bit color (38;2;R;G;B)
+)
+
+// String returns the --color-profile name of p.
+func (p ColorProfile) String() string {
+	switch p {
+	case ProfileNone:
+		return "none"
+	case ProfileANSI:
+		return "16"
+	case Profile256:
+		return "256"
+	default:
+		return "truecolor"
+	}
+}
+
+// ParseColorProfile parses a --color-profile value: none, 16, 256, or truecolor.
+func ParseColorProfile(s string) (ColorProfile, error) {
+	for _, p := range []ColorProfile{ProfileNone, ProfileANSI, Profile256, ProfileTrueColor} {
+		if p.String() == s {
+			return p, nil
+		}
+	}
+	return ProfileANSI, fmt.Errorf("unknown color profile %q (valid: none, 16, 256, truecolor)", s)
+}
+
+// DetectColorProfile reads COLORTERM and TERM from the environment.
+func DetectColorProfile() ColorProfile {
+	return ColorProfileFor(os.Getenv("COLORTERM"), os.Getenv("TERM"))
+}
+
+// ColorProfileFor classifies a terminal from its COLORTERM and TERM values.
+// COLORTERM=truecolor|24bit and TERM names ending in -direct or -truecolor
+// mean 24-bit color; TERM containing 256color means 256 colors; TERM=dumb
+// means none; anything else is assumed to handle the basic 16 colors.
+func ColorProfileFor(colorterm, term string) ColorProfile {
+	switch strings.ToLower(colorterm) {
+	case "truecolor", "24bit":
+		return ProfileTrueColor
+	}
+	switch {
+	case term == "dumb":
+		return ProfileNone
+	case strings.HasSuffix(term, "-direct"), strings.HasSuffix(term, "-truecolor"):
+		return ProfileTrueColor
+	case strings.Contains(term, "256color"):
+		return Profile256
+	}
+	return ProfileANSI
+}
+
+// NewProfileWriter returns a writer that rewrites SGR color sequences written
+// through it to what profile supports: 24-bit colors become the nearest
+// 256-color index, and 256-color indexes the nearest of the 16 basic colors.
+// ProfileNone strips escapes entirely; ProfileTrueColor returns w unchanged.
+func NewProfileWriter(w io.Writer, profile ColorProfile) io.Writer {
+	switch profile {
+	case ProfileTrueColor:
+		return w
+	case ProfileNone:
+		return NewStripANSIWriter(w)
+	}
+	return &profileWriter{w: w, profile: profile}
+}
+
+// profileWriter buffers each escape sequence until it is complete, so
+// sequences split across Write calls are still rewritten.
+type profileWriter struct {
+	w       io.Writer
+	profile ColorProfile
+	pending []byte // Incomplete escape sequence from the previous Write
+}
+
+// Write rewrites complete SGR sequences in p and passes everything else through.
+// Returns len(p) on success so callers see a full write.
+func (pw *profileWriter) Write(p []byte) (int, error) {
+	out := make([]byte, 0, len(p))
+	for _, b := range p {
+		if len(pw.pending) == 0 {
+			if b == 0x1b {
+				pw.pending = append(pw.pending, b)
+			} else {
+				out = append(out, b)
+			}
+			continue
+		}
+
+		pw.pending = append(pw.pending, b)
+		if len(pw.pending) == 2 {
+			if b != '[' {
+				out = append(out, pw.pending...) // Not CSI; pass through
+				pw.pending = pw.pending[:0]
+			}
+			continue
+		}
+		if b >= 0x40 && b <= 0x7e { // CSI final byte
+			seq := string(pw.pending)
+			if b == 'm' {
+				seq = "\033[" + degradeSGR(seq[2:len(seq)-1], pw.profile) + "m"
+			}
+			out = append(out, seq...)
+			pw.pending = pw.pending[:0]
+		}
+	}
+
+	if len(out) > 0 {
+		if _, err := pw.w.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// degradeSGR rewrites extended color parameters in an SGR parameter list
+// ("1;38;5;208") to fit profile.
+func degradeSGR(params string, profile ColorProfile) string {
+	parts := strings.Split(params, ";")
+	var out []string
+	for i := 0; i < len(parts); i++ {
+		if (parts[i] != "38" && parts[i] != "48") || i+1 >= len(parts) {
+			out = append(out, parts[i])
+			continue
+		}
+		prefix := parts[i]
+		bg := prefix == "48"
+
+		var index int
+		switch {
+		case parts[i+1] == "2" && i+4 < len(parts):
+			rgb := [3]int{atoiByte(parts[i+2]), atoiByte(parts[i+3]), atoiByte(parts[i+4])}
+			index = rgbTo256(rgb)
+			i += 4
+		case parts[i+1] == "5" && i+2 < len(parts):
+			index = atoiByte(parts[i+2])
+			i += 2
+		default:
+			out = append(out, parts[i])
+			continue
+		}
+
+		if profile == Profile256 {
+			out = append(out, prefix, "5", strconv.Itoa(index))
+			continue
+		}
+		out = append(out, strconv.Itoa(ansi16(index, bg)))
+	}
+	return strings.Join(out, ";")
+}
+
+// atoiByte parses an SGR color component, clamped to 0-255.
+func atoiByte(s string) int {
+	n, _ := strconv.Atoi(s)
+	return min(max(n, 0), 255)
+}
+
+// cubeLevels are the channel values of the 6x6x6 cube in the 256-color palette.
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// rgbTo256 returns the nearest 256-color cube or grayscale index.
+func rgbTo256(rgb [3]int) int {
+	var idx [3]int
+	var cube [3]int
+	for c, v := range rgb {
+		best := 0
+		for i, level := range cubeLevels {
+			if abs(v-level) < abs(v-cubeLevels[best]) {
+				best = i
+			}
+		}
+		idx[c], cube[c] = best, cubeLevels[best]
+	}
+	cubeIndex := 16 + 36*idx[0] + 6*idx[1] + idx[2]
+
+	avg := (rgb[0] + rgb[1] + rgb[2]) / 3
+	grayStep := min(max((avg-8+5)/10, 0), 23)
+	gray := 8 + 10*grayStep
+	if colorDist(rgb, [3]int{gray, gray, gray}) < colorDist(rgb, cube) {
+		return 232 + grayStep
+	}
+	return cubeIndex
+}
+
+// ansi16Colors approximates the 16 basic colors (xterm defaults).
+var ansi16Colors = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// ansi16 returns the SGR code (30-37/90-97, or 40-47/100-107 for
+// backgrounds) of the basic color nearest 256-color index.
+func ansi16(index int, bg bool) int {
+	basic := index
+	if index >= 16 {
+		rgb := palette256(index)
+		basic = 0
+		for i, c := range ansi16Colors {
+			if colorDist(rgb, c) < colorDist(rgb, ansi16Colors[basic]) {
+				basic = i
+			}
+		}
+	}
+
+	code := 30 + basic
+	if basic >= 8 {
+		code = 90 + basic - 8
+	}
+	if bg {
+		code += 10
+	}
+	return code
+}
+
+// palette256 returns the RGB value of a 256-color index >= 16.
+func palette256(index int) [3]int {
+	if index >= 232 {
+		v := 8 + 10*(index-232)
+		return [3]int{v, v, v}
+	}
+	i := index - 16
+	return [3]int{cubeLevels[i/36], cubeLevels[(i/6)%6], cubeLevels[i%6]}
+}
+
+func colorDist(a, b [3]int) int {
+	dr, dg, db := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dr*dr + dg*dg + db*db
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
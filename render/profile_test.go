@@ -0,0 +1,69 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestColorProfileFor(t *testing.T) {
+	tests := []struct {
+		colorterm, term string
+		want            ColorProfile
+	}{
+		{"truecolor", "xterm", ProfileTrueColor},
+		{"24bit", "", ProfileTrueColor},
+		{"", "xterm-direct", ProfileTrueColor},
+		{"", "xterm-256color", Profile256},
+		{"", "screen-256color", Profile256},
+		{"", "xterm", ProfileANSI},
+		{"", "linux", ProfileANSI},
+		{"", "", ProfileANSI},
+		{"", "dumb", ProfileNone},
+	}
+
+	for _, tt := range tests {
+		if got := ColorProfileFor(tt.colorterm, tt.term); got != tt.want {
+			t.Errorf("ColorProfileFor(%q, %q) = %v, want %v", tt.colorterm, tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestProfileWriter(t *testing.T) {
+	tests := []struct {
+		profile ColorProfile
+		input   string
+		want    string
+	}{
+		// ColorFile: 256-color gray becomes bright black, not bold
+		{ProfileANSI, ColorFile + "a.go" + ColorReset, "\033[90ma.go\033[0m"},
+		{ProfileANSI, "\033[1;38;5;196mx", "\033[1;91mx"},
+		{ProfileANSI, "\033[48;5;21mx", "\033[44mx"},
+		{ProfileANSI, "\033[38;2;0;200;0mx", "\033[32mx"},
+		{Profile256, "\033[38;2;255;135;0mx", "\033[38;5;208mx"},
+		{Profile256, ColorFile + "a", ColorFile + "a"},
+		{ProfileANSI, ColorAdd + "+1\033[2K", ColorAdd + "+1\033[2K"}, // basic codes and non-SGR untouched
+		{ProfileNone, ColorAdd + "+1" + ColorReset, "+1"},
+		{ProfileTrueColor, "\033[38;2;1;2;3mx", "\033[38;2;1;2;3mx"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		NewProfileWriter(&buf, tt.profile).Write([]byte(tt.input))
+		if buf.String() != tt.want {
+			t.Errorf("%v: %q -> %q, want %q", tt.profile, tt.input, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestProfileWriter_SplitSequence(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewProfileWriter(&buf, ProfileANSI)
+	for _, chunk := range []string{"a\033[38;5", ";8mb\033", "[0mc"} {
+		if n, err := w.Write([]byte(chunk)); err != nil || n != len(chunk) {
+			t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+		}
+	}
+	if want := "a\033[90mb\033[0mc"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,36 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// WriteQuickfix writes diff stats in compiler/quickfix format ("path:1: +A -D"),
+// ordered by total changes descending so editors jump to hotspots first.
+// Vim: :cfile FILE, Emacs: M-x compile with "cat FILE".
+func WriteQuickfix(w io.Writer, stats *diff.DiffStats) error {
+	files := make([]diff.FileStat, len(stats.Files))
+	copy(files, stats.Files)
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Additions+files[i].Deletions > files[j].Additions+files[j].Deletions
+	})
+
+	for _, f := range files {
+		var msg string
+		switch {
+		case f.IsBinary:
+			msg = "binary"
+		case f.IsNew():
+			msg = fmt.Sprintf("+%d -%d (new)", f.Additions, f.Deletions)
+		default:
+			msg = fmt.Sprintf("+%d -%d", f.Additions, f.Deletions)
+		}
+		if _, err := fmt.Fprintf(w, "%s:1: %s\n", f.Path, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
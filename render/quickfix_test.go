@@ -0,0 +1,28 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestWriteQuickfix_OrdersByTotal(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteQuickfix(&buf, &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "small.go", Additions: 1},
+			{Path: "src/big.go", Additions: 40, Deletions: 2},
+			{Path: "logo.png", IsBinary: true},
+		},
+		TotalFiles: 3,
+	})
+	if err != nil {
+		t.Fatalf("WriteQuickfix() error = %v", err)
+	}
+
+	want := "src/big.go:1: +40 -2\nsmall.go:1: +1 -0\nlogo.png:1: binary\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteQuickfix() =\n%s\nwant:\n%s", got, want)
+	}
+}
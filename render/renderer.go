@@ -3,6 +3,13 @@ package render
 import "github.com/kylesnowschwartz/diff-viz/diff"
 
 // Renderer defines the interface for diff visualization renderers.
+// Renderers write only to the io.Writer given to their constructor, so output
+// can be redirected to files, buffers, or a StripANSIWriter without a terminal.
+//
+// Render does not modify the renderer or stats; per-call state stays local.
+// Once configured, one renderer may serve concurrent Render calls, provided its
+// writer is safe for concurrent use. For separate outputs, construct one
+// renderer per writer.
 type Renderer interface {
 	Render(stats *diff.DiffStats)
 }
@@ -0,0 +1,165 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+	"github.com/kylesnowschwartz/diff-viz/render/layout"
+)
+
+// Slack emoji for SlackBlocksRenderer bars.
+const (
+	slackAddEmoji   = ":large_green_square:"
+	slackDelEmoji   = ":large_red_square:"
+	slackEmptyEmoji = ":white_large_square:"
+)
+
+// SlackBlocksRenderer writes a diff digest as Slack Block Kit JSON
+// (https://api.slack.com/block-kit): a header with the totals, then the
+// largest top-level directories and files with emoji bars. Blocks carry
+// fixed block_ids so a bot refreshing its message can replace them in place.
+// Output is never colored.
+type SlackBlocksRenderer struct {
+	N     int // Directories and files listed in each section
+	Width int // Bar width in emoji
+	w     io.Writer
+}
+
+// NewSlackBlocksRenderer creates a Slack Block Kit renderer.
+func NewSlackBlocksRenderer(w io.Writer) *SlackBlocksRenderer {
+	return &SlackBlocksRenderer{N: 5, Width: 8, w: w}
+}
+
+// slackMessage is the payload chat.postMessage and chat.update accept.
+type slackMessage struct {
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type    string     `json:"type"`
+	BlockID string     `json:"block_id,omitempty"`
+	Text    *slackText `json:"text,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"` // "plain_text" or "mrkdwn"
+	Text string `json:"text"`
+}
+
+// slackRow is one directory or file line.
+type slackRow struct {
+	name     string
+	add, del int
+}
+
+// Render writes the message JSON.
+func (r *SlackBlocksRenderer) Render(stats *diff.DiffStats) {
+	noun := "files"
+	if stats.TotalFiles == 1 {
+		noun = "file"
+	}
+	msg := slackMessage{Blocks: []slackBlock{{
+		Type:    "header",
+		BlockID: "diff-header",
+		Text:    &slackText{Type: "plain_text", Text: fmt.Sprintf("%d %s changed, +%d -%d", stats.TotalFiles, noun, stats.TotalAdd, stats.TotalDel)},
+	}}}
+
+	dirs, files := slackRows(stats.Files)
+	if len(dirs) > 0 {
+		msg.Blocks = append(msg.Blocks, r.section("diff-dirs", "Top directories", dirs))
+	}
+	if len(files) > 0 {
+		msg.Blocks = append(msg.Blocks, r.section("diff-files", "Top files", files))
+	}
+
+	data, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		return // Plain strings and ints always marshal
+	}
+	fmt.Fprintln(r.w, string(data))
+}
+
+// slackRows totals files by top-level directory (root files under ".") and
+// lists the files, both largest first.
+func slackRows(files []diff.FileStat) (dirs, rows []slackRow) {
+	byDir := make(map[string]*slackRow)
+	for _, f := range files {
+		p := f.Path
+		if newPath, ok := diff.RenameTarget(p); ok {
+			p = newPath
+		}
+		dir := "."
+		if strings.Contains(p, "/") {
+			dir = GetTopDir(p) + "/"
+		}
+		if byDir[dir] == nil {
+			byDir[dir] = &slackRow{name: dir}
+		}
+		byDir[dir].add += f.Additions
+		byDir[dir].del += f.Deletions
+		rows = append(rows, slackRow{name: f.Path, add: f.Additions, del: f.Deletions})
+	}
+	for _, d := range byDir {
+		dirs = append(dirs, *d)
+	}
+	sortSlackRows(dirs)
+	sortSlackRows(rows)
+	return dirs, rows
+}
+
+// sortSlackRows orders rows by changed lines, then name.
+func sortSlackRows(rows []slackRow) {
+	sort.Slice(rows, func(i, j int) bool {
+		ti, tj := rows[i].add+rows[i].del, rows[j].add+rows[j].del
+		if ti != tj {
+			return ti > tj
+		}
+		return rows[i].name < rows[j].name
+	})
+}
+
+// section lists the first N rows under a bold title, one per line.
+func (r *SlackBlocksRenderer) section(id, title string, rows []slackRow) slackBlock {
+	maxTotal := 0
+	for _, row := range rows {
+		maxTotal = max(maxTotal, row.add+row.del)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("*" + title + "*")
+	for i, row := range rows {
+		if i == r.N {
+			fmt.Fprintf(&sb, "\n…and %d more", len(rows)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "\n%s `%s` +%d -%d", r.bar(row.add, row.del, maxTotal), slackEscape(row.name), row.add, row.del)
+	}
+	return slackBlock{Type: "section", BlockID: id, Text: &slackText{Type: "mrkdwn", Text: sb.String()}}
+}
+
+// bar draws add+del scaled to maxTotal as green and red squares, padded
+// with white ones to Width.
+func (r *SlackBlocksRenderer) bar(add, del, maxTotal int) string {
+	filled := layout.Scale(add+del, maxTotal, r.Width, 1)
+	addBlocks := 0
+	if filled > 0 {
+		addBlocks = add * filled / (add + del)
+		if add > 0 && addBlocks == 0 {
+			addBlocks = 1
+		} else if del > 0 && addBlocks == filled && filled > 1 {
+			addBlocks--
+		}
+	}
+	return strings.Repeat(slackAddEmoji, addBlocks) +
+		strings.Repeat(slackDelEmoji, filled-addBlocks) +
+		strings.Repeat(slackEmptyEmoji, r.Width-filled)
+}
+
+// slackEscape escapes the characters mrkdwn treats as control sequences.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
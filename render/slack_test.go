@@ -0,0 +1,53 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestSlackBlocksRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewSlackBlocksRenderer(&buf)
+	r.N = 2
+	r.Width = 4
+	r.Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/a.go", Additions: 6, Deletions: 2},
+			{Path: "src/<b>.go", Additions: 4},
+			{Path: "docs/x.md", Deletions: 1},
+			{Path: "README.md", Additions: 1},
+		},
+		TotalFiles: 4, TotalAdd: 11, TotalDel: 3,
+	})
+
+	var got slackMessage
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(got.Blocks) != 3 {
+		t.Fatalf("got %d blocks, want header, dirs, files:\n%s", len(got.Blocks), buf.String())
+	}
+
+	header := got.Blocks[0]
+	if header.Type != "header" || header.BlockID != "diff-header" || header.Text.Text != "4 files changed, +11 -3" {
+		t.Errorf("header = %+v", header)
+	}
+
+	dirs := got.Blocks[1].Text.Text
+	wantDirs := "*Top directories*\n" +
+		strings.Repeat(slackAddEmoji, 3) + slackDelEmoji + " `src/` +10 -2\n" +
+		slackAddEmoji + strings.Repeat(slackEmptyEmoji, 3) + " `.` +1 -0\n" +
+		"…and 1 more"
+	if got.Blocks[1].BlockID != "diff-dirs" || dirs != wantDirs {
+		t.Errorf("dirs block %q =\n%s\nwant\n%s", got.Blocks[1].BlockID, dirs, wantDirs)
+	}
+
+	files := got.Blocks[2].Text.Text
+	if got.Blocks[2].BlockID != "diff-files" || !strings.Contains(files, "`src/&lt;b&gt;.go` +4 -0") {
+		t.Errorf("files block %q does not list the escaped path:\n%s", got.Blocks[2].BlockID, files)
+	}
+}
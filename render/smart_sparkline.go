@@ -1,10 +1,12 @@
 package render
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"strings"
 
+	"github.com/kylesnowschwartz/diff-viz/config"
 	"github.com/kylesnowschwartz/diff-viz/diff"
 )
 
@@ -18,19 +20,55 @@ const smartBarWidth = 10 // Fixed width for sparkline bars
 //   - 1: aggregate at top-level only (replaces collapsed mode)
 //   - 2: group by depth-2 (default)
 //
+// RootGroup names a virtual group collecting root-level files (shown as
+// "./(5) ████" at depth 1); empty gives each root file its own group.
+//
 // Width controls line wrapping (0 = no wrapping, single line).
+// Multiline prints one line per top-level directory; segments that overflow
+// Width continue on indented lines.
+// Vertical prints one row per group instead, with aligned columns
+// (name, +adds, -dels, files, bar), for panes too narrow for one line.
 type SmartSparklineRenderer struct {
-	UseColor bool
-	MaxDepth int // 1=top-level only, 2=depth-2 grouping (default)
-	Width    int // Max line width before wrapping (0=no wrap)
-	w        io.Writer
+	UseColor  bool
+	MaxDepth  int      // 1=top-level only, 2=depth-2 grouping (default)
+	Width     int      // Max line width before wrapping (0=no wrap)
+	Multiline bool     // One line per top-level directory
+	Vertical  bool     // One aligned row per group
+	Bar       BarStyle // Zero-change and padding options
+	RootGroup string   // Group name for root-level files ("" = one group per file)
+	Separator string   // Between groups ("" = " │ ", or " | " without color)
+
+	// CleanupRatio marks groups with this share of deletions (see
+	// IsCleanup; 0 = off).
+	CleanupRatio float64
+
+	// DirDepths overrides MaxDepth per top-level directory (see
+	// analyze.AutoDepths); root-level files keep MaxDepth.
+	DirDepths diff.DirDepths
+
+	// ScaleLegend prints a line explaining block shades and bar lengths.
+	ScaleLegend bool
+
+	// AbbrevCounts shortens Vertical's counts of 1000 and more with
+	// AbbrevCount ("+12.4k").
+	AbbrevCounts bool
+
+	// SizeClass is appended to the last line (see diff.SizeClass).
+	SizeClass diff.SizeClass
+
+	// Composition splits each bar into new-file lines (yellow), additions to
+	// existing files (green), and deletions (red). New files are untracked or
+	// have Status "A" (see diff.AddChangeDetails).
+	Composition bool
+	w           io.Writer
 }
 
 // NewSmartSparklineRenderer creates a smart sparkline renderer.
 // Default MaxDepth is 2 for depth-2 aggregation.
 // Default Width is 0 (no wrapping - original single-line behavior).
+// Default RootGroup is config.DefaultRootGroup.
 func NewSmartSparklineRenderer(w io.Writer, useColor bool) *SmartSparklineRenderer {
-	return &SmartSparklineRenderer{UseColor: useColor, MaxDepth: 2, Width: 0, w: w}
+	return &SmartSparklineRenderer{UseColor: useColor, MaxDepth: 2, Width: 0, RootGroup: config.DefaultRootGroup, CleanupRatio: DefaultCleanupRatio, w: w}
 }
 
 // Render outputs diff stats with configurable depth aggregation.
@@ -47,7 +85,8 @@ func (r *SmartSparklineRenderer) Render(stats *diff.DiffStats) {
 	}
 
 	// Group by directory structure at configured depth
-	topDirs := GroupByDepth(stats.Files, depth)
+	topDirs := r.groupFiles(stats.Files, depth)
+	GroupRootFiles(topDirs, r.RootGroup, depth)
 
 	// Find max total for scaling
 	maxTotal := 0
@@ -62,15 +101,54 @@ func (r *SmartSparklineRenderer) Render(stats *diff.DiffStats) {
 	// Sort top-level dirs by total changes
 	sortedTops := SortTopDirs(topDirs)
 
-	// Render each top-level directory to strings
-	var groups []string
-	for _, topDir := range sortedTops {
-		segments := topDirs[topDir]
-		groups = append(groups, r.formatTopDir(topDir, segments, maxTotal))
+	// Buffered so the size label can follow the last line
+	out := r.w
+	var buf bytes.Buffer
+	r.w = &buf
+	if r.Vertical {
+		r.outputVertical(sortedTops, topDirs)
+	} else if r.Multiline {
+		for _, topDir := range sortedTops {
+			r.outputGroupLines(r.formatTopDirParts(topDir, topDirs[topDir], maxTotal))
+		}
+	} else {
+		// Render each top-level directory to strings
+		var groups []string
+		for _, topDir := range sortedTops {
+			segments := topDirs[topDir]
+			groups = append(groups, r.formatTopDir(topDir, segments, maxTotal))
+		}
+
+		// Output with smart line packing
+		r.outputWithPacking(groups)
 	}
+	r.w = out
+	writeSizeSuffixed(r.w, buf.Bytes(), r.SizeClass)
 
-	// Output with smart line packing
-	r.outputWithPacking(groups)
+	if r.ScaleLegend {
+		writeScaleLegend(r.w, r.Bar.Scale, r.color)
+	}
+}
+
+// groupFiles groups files with GroupByDepth, at each top-level directory's
+// DirDepths entry when set.
+func (r *SmartSparklineRenderer) groupFiles(files []diff.FileStat, depth int) map[string][]PathSegment {
+	if r.DirDepths == nil {
+		return GroupByDepth(files, depth)
+	}
+
+	byDepth := make(map[int][]diff.FileStat)
+	for _, f := range files {
+		d := max(r.DirDepths.For(f.Path, depth), 1)
+		byDepth[d] = append(byDepth[d], f)
+	}
+	groups := make(map[string][]PathSegment)
+	for d, subset := range byDepth {
+		for key, segs := range GroupByDepth(subset, d) {
+			groups[key] = append(groups[key], segs...)
+		}
+	}
+	return groups
 }
 
 // outputWithPacking renders groups with optional line wrapping.
@@ -81,7 +159,10 @@ func (r *SmartSparklineRenderer) outputWithPacking(groups []string) {
 		return
 	}
 
-	sep := Separator(r.UseColor)
+	sep := r.Separator
+	if sep == "" {
+		sep = Separator(r.UseColor)
+	}
 
 	// No width limit: single line output (original behavior)
 	if r.Width <= 0 {
@@ -121,8 +202,98 @@ func (r *SmartSparklineRenderer) outputWithPacking(groups []string) {
 	}
 }
 
+// multilineIndent prefixes continuation lines in Multiline mode.
+const multilineIndent = "  "
+
+// outputGroupLines prints one top-level group's segments on a line,
+// continuing on indented lines when Width would be exceeded.
+func (r *SmartSparklineRenderer) outputGroupLines(parts []string) {
+	var line strings.Builder
+	lineWidth := 0
+
+	for _, part := range parts {
+		partWidth := VisibleWidth(part)
+		switch {
+		case lineWidth == 0:
+			line.WriteString(part)
+			lineWidth = partWidth
+		case r.Width <= 0 || lineWidth+1+partWidth <= r.Width:
+			line.WriteString(" ")
+			line.WriteString(part)
+			lineWidth += 1 + partWidth
+		default:
+			fmt.Fprintln(r.w, line.String())
+			line.Reset()
+			line.WriteString(multilineIndent)
+			line.WriteString(part)
+			lineWidth = len(multilineIndent) + partWidth
+		}
+	}
+
+	if lineWidth > 0 {
+		fmt.Fprintln(r.w, line.String())
+	}
+}
+
+// outputVertical prints one row per segment: name, +adds, -dels, file
+// count, and bar, each column aligned across rows.
+func (r *SmartSparklineRenderer) outputVertical(sortedTops []string, topDirs map[string][]PathSegment) {
+	type row struct {
+		seg                     PathSegment
+		name, adds, dels, files string
+	}
+	var rows []row
+	var nameW, addW, delW, filesW int
+	for _, topDir := range sortedTops {
+		for _, seg := range topDirs[topDir] {
+			name := seg.SubPath
+			if topDir != seg.SubPath {
+				name = topDir + "/" + seg.SubPath
+			}
+			if r.isCleanup(seg) {
+				name = CleanupMarker + " " + name
+			}
+			noun := "files"
+			if seg.FileCount == 1 {
+				noun = "file"
+			}
+			rw := row{seg, name, "+" + formatCount(seg.Add, r.AbbrevCounts), "-" + formatCount(seg.Del, r.AbbrevCounts), fmt.Sprintf("%d %s", seg.FileCount, noun)}
+			nameW = max(nameW, VisibleWidth(rw.name))
+			addW = max(addW, len(rw.adds))
+			delW = max(delW, len(rw.dels))
+			filesW = max(filesW, len(rw.files))
+			rows = append(rows, rw)
+		}
+	}
+
+	for _, rw := range rows {
+		nameColor := ColorDir
+		if rw.seg.IsFile {
+			nameColor = ColorReset
+		}
+		if rw.seg.HasNew {
+			nameColor = ColorNew
+		}
+		if r.isCleanup(rw.seg) {
+			nameColor = ColorCleanup
+		}
+		fmt.Fprintf(r.w, "%s%s%s%s  %s%*s%s  %s%*s%s  %s%*s%s  %s\n",
+			r.color(nameColor), rw.name, r.color(ColorReset), strings.Repeat(" ", nameW-VisibleWidth(rw.name)),
+			r.color(ColorAdd), addW, rw.adds, r.color(ColorReset),
+			r.color(ColorDel), delW, rw.dels, r.color(ColorReset),
+			r.color(ColorFile), filesW, rw.files, r.color(ColorReset),
+			r.formatBar(rw.seg))
+	}
+}
+
 // formatTopDir formats all segments within a top-level directory.
 func (r *SmartSparklineRenderer) formatTopDir(topDir string, segments []PathSegment, maxTotal int) string {
+	return strings.Join(r.formatTopDirParts(topDir, segments, maxTotal), " ")
+}
+
+// formatTopDirParts formats each segment within a top-level directory.
+// The first segment carries the top-level directory prefix.
+func (r *SmartSparklineRenderer) formatTopDirParts(topDir string, segments []PathSegment, maxTotal int) []string {
 	var parts []string
 
 	for i, seg := range segments {
@@ -147,6 +318,12 @@ func (r *SmartSparklineRenderer) formatTopDir(topDir string, segments []PathSegm
 				nameColor = ColorNew
 			}
 		}
+		if r.isCleanup(seg) {
+			nameColor = ColorCleanup
+			sb.WriteString(r.color(nameColor))
+			sb.WriteString(CleanupMarker)
+			sb.WriteString(" ")
+		}
 
 		sb.WriteString(r.color(nameColor))
 		sb.WriteString(seg.SubPath)
@@ -159,23 +336,33 @@ func (r *SmartSparklineRenderer) formatTopDir(topDir string, segments []PathSegm
 			sb.WriteString(r.color(ColorReset))
 		}
 
-		sb.WriteString(" ")
-
 		// Sparkline bar
-		sb.WriteString(r.formatBar(seg.Add, seg.Del))
+		if bar := r.formatBar(seg); bar != "" {
+			sb.WriteString(" ")
+			sb.WriteString(bar)
+		}
 
 		parts = append(parts, sb.String())
 	}
 
-	return strings.Join(parts, " ")
+	return parts
 }
 
 // formatBar creates a sparkline bar with ratio-split coloring.
-func (r *SmartSparklineRenderer) formatBar(add, del int) string {
-	total := add + del
-	filled := min(filledFromTotal(total), smartBarWidth)
+func (r *SmartSparklineRenderer) formatBar(seg PathSegment) string {
+	total := seg.Total()
+	filled := r.Bar.Scale.Filled(total, smartBarWidth)
 	block := blockChar(total)
-	return RatioBar(add, del, filled, smartBarWidth, block, r.color)
+	if r.Composition {
+		return r.Bar.CompositionBar(seg.NewAdd, seg.Add, seg.Del, filled, smartBarWidth, block, r.color)
+	}
+	return r.Bar.Bar(seg.Add, seg.Del, filled, smartBarWidth, block, r.color)
+}
+
+// isCleanup reports whether seg is a group whose changes are mostly
+// deletions.
+func (r *SmartSparklineRenderer) isCleanup(seg PathSegment) bool {
+	return !seg.IsFile && IsCleanup(seg.Add, seg.Del, r.CleanupRatio)
 }
 
 // color returns the ANSI code if color is enabled.
@@ -55,6 +55,45 @@ func TestSmartSparkline_GroupsByTopDir(t *testing.T) {
 	}
 }
 
+func TestSmartSparkline_Separator(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewSmartSparklineRenderer(&buf, true)
+	r.Separator = " ;; "
+	r.Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/main.go", Additions: 10},
+			{Path: "tests/main_test.go", Additions: 20},
+		},
+		TotalFiles: 2,
+	})
+
+	got := buf.String()
+	if !strings.Contains(got, " ;; ") || strings.Contains(got, "│") {
+		t.Errorf("want groups joined by %q, got %q", " ;; ", got)
+	}
+}
+
+func TestSmartSparkline_Cleanup(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewSmartSparklineRenderer(&buf, false)
+	r.Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "legacy/api/old.go", Deletions: 80},
+			{Path: "legacy/api/util.go", Additions: 2, Deletions: 30},
+			{Path: "src/api/new.go", Additions: 30, Deletions: 5},
+		},
+		TotalFiles: 2,
+	})
+
+	got := buf.String()
+	if !strings.Contains(got, "legacy/"+CleanupMarker+" api") {
+		t.Errorf("deletion-only group not marked: %q", got)
+	}
+	if strings.Count(got, CleanupMarker) != 1 {
+		t.Errorf("want one marked group, got %q", got)
+	}
+}
+
 func TestSmartSparkline_WidthNoWrap(t *testing.T) {
 	var buf bytes.Buffer
 	r := NewSmartSparklineRenderer(&buf, false)
@@ -174,6 +213,48 @@ func TestSmartSparkline_SortsByTotal(t *testing.T) {
 	}
 }
 
+func TestSmartSparkline_ScaleLegend(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files:      []diff.FileStat{{Path: "src/main.go", Additions: 120}},
+		TotalFiles: 1,
+	}
+
+	var buf bytes.Buffer
+	r := NewSmartSparklineRenderer(&buf, false)
+	r.Bar = BarStyle{Scale: ScaleLinear, NoPadding: true}
+	r.Render(stats)
+	if strings.Contains(buf.String(), "bar scale:") {
+		t.Errorf("legend shown without ScaleLegend: %q", buf.String())
+	}
+	if out := buf.String(); !strings.Contains(out, strings.Repeat(BlockMedium, 4)) || strings.Contains(out, strings.Repeat(BlockMedium, 5)) {
+		t.Errorf("linear scale: want 4 blocks for 120 lines, got %q", buf.String())
+	}
+
+	buf.Reset()
+	r.ScaleLegend = true
+	r.Render(stats)
+	if !strings.Contains(buf.String(), "bar scale: "+ScaleLegend(ScaleLinear)) {
+		t.Errorf("missing linear scale legend in %q", buf.String())
+	}
+}
+
+func TestSmartSparkline_SizeClass(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files:      []diff.FileStat{{Path: "src/main.go", Additions: 120}},
+		TotalFiles: 1,
+	}
+
+	var buf bytes.Buffer
+	r := NewSmartSparklineRenderer(&buf, false)
+	r.SizeClass = diff.SizeL
+	r.ScaleLegend = true
+	r.Render(stats)
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasSuffix(lines[0], " [L]") || strings.Contains(lines[1], "[L]") {
+		t.Errorf("want size label on the last group line, before the legend: %q", buf.String())
+	}
+}
+
 func TestVisibleWidth(t *testing.T) {
 	tests := []struct {
 		input string
@@ -186,6 +267,8 @@ func TestVisibleWidth(t *testing.T) {
 		{"no colors", 9},                // Plain text
 		{"\033[38;5;8mdark\033[0m", 4},  // 256-color dark gray
 		{"\033[1m\033[32mbold green\033[0m\033[0m", 10}, // Multiple escapes
+		{"\u05e9\u05b8\u05dc\u05d5\u05b9\u05dd", 4},     // Hebrew with vowel points
+		{"\u200fsrc", 3}, // Bidi mark
 	}
 
 	for _, tt := range tests {
@@ -195,3 +278,73 @@ func TestVisibleWidth(t *testing.T) {
 		}
 	}
 }
+
+func TestSmartSparkline_Multiline(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewSmartSparklineRenderer(&buf, false)
+	r.Width = 200 // Wide enough that single-line mode would not wrap
+	r.Multiline = true
+	r.Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "a/file1.go", Additions: 10},
+			{Path: "b/file2.go", Additions: 20},
+			{Path: "c/file3.go", Additions: 30},
+		},
+		TotalFiles: 3,
+	})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected one line per top-level dir (3), got %d: %q", len(lines), lines)
+	}
+	if !strings.HasPrefix(lines[0], "c/") {
+		t.Errorf("expected largest dir first, got %q", lines[0])
+	}
+}
+
+func TestSmartSparkline_MultilineIndentsOverflow(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewSmartSparklineRenderer(&buf, false)
+	r.MaxDepth = 3
+	r.Width = 30
+	r.Multiline = true
+	r.Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/alpha.go", Additions: 30},
+			{Path: "src/beta.go", Additions: 20},
+			{Path: "src/gamma.go", Additions: 10},
+		},
+		TotalFiles: 3,
+	})
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected overflow onto continuation lines, got %q", lines)
+	}
+	for _, line := range lines[1:] {
+		if !strings.HasPrefix(line, "  ") {
+			t.Errorf("continuation line should be indented, got %q", line)
+		}
+	}
+}
+
+func TestSmartSparkline_Vertical(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewSmartSparklineRenderer(&buf, false)
+	r.MaxDepth = 1
+	r.Vertical = true
+	r.Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "render/a.go", Additions: 100, Deletions: 20},
+			{Path: "render/b.go", Additions: 10},
+			{Path: "main.go", Additions: 5, Deletions: 3},
+		},
+		TotalFiles: 3,
+	})
+
+	want := "render   +110  -20  2 files  ▓▓▓▓▓▓░░░░\n" +
+		"main.go    +5   -3   1 file  ▒▒░░░░░░░░\n"
+	if got := buf.String(); got != want {
+		t.Errorf("vertical output:\n%s\nwant:\n%s", got, want)
+	}
+}
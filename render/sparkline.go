@@ -0,0 +1,73 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// sparkLevels are the block heights used by Sparkline, lowest first.
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws one block per value, scaled so the largest value is a full
+// block. Zero values draw as the lowest block.
+func Sparkline(values []int) string {
+	maxVal := 0
+	for _, v := range values {
+		maxVal = max(maxVal, v)
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		level := 0
+		if maxVal > 0 {
+			level = (v * (len(sparkLevels) - 1)) / maxVal
+		}
+		sb.WriteRune(sparkLevels[level])
+	}
+	return sb.String()
+}
+
+// FileHistoryRenderer prints a one-line summary of a file's recent commits.
+type FileHistoryRenderer struct {
+	UseColor bool
+	w        io.Writer
+}
+
+// NewFileHistoryRenderer creates a file history renderer.
+func NewFileHistoryRenderer(w io.Writer, useColor bool) *FileHistoryRenderer {
+	return &FileHistoryRenderer{UseColor: useColor, w: w}
+}
+
+// RenderHistory outputs a sparkline of lines changed per commit (oldest
+// first) with the totals across those commits.
+func (r *FileHistoryRenderer) RenderHistory(path string, commits []diff.FileCommit) {
+	if len(commits) == 0 {
+		fmt.Fprintf(r.w, "%s: no commits\n", path)
+		return
+	}
+
+	values := make([]int, len(commits))
+	adds, dels := 0, 0
+	for i, c := range commits {
+		values[i] = c.Adds + c.Dels
+		adds += c.Adds
+		dels += c.Dels
+	}
+
+	fmt.Fprintf(r.w, "%s: last %d commits %s%s%s %s+%d%s %s-%d%s (since %s)\n",
+		path, len(commits),
+		r.color(ColorAdd), Sparkline(values), r.color(ColorReset),
+		r.color(ColorAdd), adds, r.color(ColorReset),
+		r.color(ColorDel), dels, r.color(ColorReset),
+		commits[0].Time.Local().Format("2006-01-02"))
+}
+
+func (r *FileHistoryRenderer) color(code string) string {
+	if r.UseColor {
+		return code
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// speedscopeSchema identifies the speedscope file format.
+const speedscopeSchema = "https://www.speedscope.app/file-format-schema.json"
+
+// SpeedscopeRenderer writes the diff as a speedscope profile
+// (https://www.speedscope.app) so flamegraph viewers can zoom and search it.
+// Each changed file is one sample whose stack is its directories followed by
+// the file, weighted by changed lines (additions + deletions). Files with no
+// line changes (binary files) are left out. Output is never colored.
+type SpeedscopeRenderer struct {
+	Name string // Profile name shown by the viewer (default "changed lines")
+	w    io.Writer
+}
+
+// NewSpeedscopeRenderer creates a speedscope profile renderer.
+func NewSpeedscopeRenderer(w io.Writer) *SpeedscopeRenderer {
+	return &SpeedscopeRenderer{Name: "changed lines", w: w}
+}
+
+// speedscopeFile is the top-level speedscope JSON document.
+type speedscopeFile struct {
+	Schema             string              `json:"$schema"`
+	Shared             speedscopeShared    `json:"shared"`
+	Profiles           []speedscopeProfile `json:"profiles"`
+	Name               string              `json:"name"`
+	ActiveProfileIndex int                 `json:"activeProfileIndex"`
+	Exporter           string              `json:"exporter"`
+}
+
+type speedscopeShared struct {
+	Frames []speedscopeFrame `json:"frames"`
+}
+
+// speedscopeFrame is a directory ("src/") or file ("main.go") in a stack.
+type speedscopeFrame struct {
+	Name string `json:"name"`
+	File string `json:"file,omitempty"` // Repo-relative path
+}
+
+// speedscopeProfile is a sampled profile: samples[i] is a stack of frame
+// indexes (outermost first) and weights[i] its changed lines.
+type speedscopeProfile struct {
+	Type       string  `json:"type"`
+	Name       string  `json:"name"`
+	Unit       string  `json:"unit"`
+	StartValue int     `json:"startValue"`
+	EndValue   int     `json:"endValue"`
+	Samples    [][]int `json:"samples"`
+	Weights    []int   `json:"weights"`
+}
+
+// Render writes the profile as one JSON document.
+func (r *SpeedscopeRenderer) Render(stats *diff.DiffStats) {
+	files := append([]diff.FileStat(nil), stats.Files...)
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	frames := []speedscopeFrame{}
+	index := make(map[string]int) // Frame path ("src/", "src/main.go") -> frame index
+	frameFor := func(name, path string) int {
+		if i, ok := index[path]; ok {
+			return i
+		}
+		index[path] = len(frames)
+		frames = append(frames, speedscopeFrame{Name: name, File: strings.TrimSuffix(path, "/")})
+		return index[path]
+	}
+
+	profile := speedscopeProfile{
+		Type:    "sampled",
+		Name:    r.Name,
+		Unit:    "none",
+		Samples: [][]int{},
+		Weights: []int{},
+	}
+	for _, f := range files {
+		weight := f.Additions + f.Deletions
+		if weight == 0 {
+			continue
+		}
+		parts := strings.Split(f.Path, "/")
+		stack := make([]int, len(parts))
+		for i, part := range parts {
+			if i < len(parts)-1 {
+				stack[i] = frameFor(part+"/", strings.Join(parts[:i+1], "/")+"/")
+			} else {
+				stack[i] = frameFor(part, f.Path)
+			}
+		}
+		profile.Samples = append(profile.Samples, stack)
+		profile.Weights = append(profile.Weights, weight)
+		profile.EndValue += weight
+	}
+
+	enc := json.NewEncoder(r.w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(speedscopeFile{
+		Schema:   speedscopeSchema,
+		Shared:   speedscopeShared{Frames: frames},
+		Profiles: []speedscopeProfile{profile},
+		Name:     r.Name,
+		Exporter: "git-diff-tree",
+	})
+}
@@ -0,0 +1,49 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestSpeedscopeRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	NewSpeedscopeRenderer(&buf).Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/lib/b.go", Additions: 5, Deletions: 1},
+			{Path: "src/a.go", Additions: 10},
+			{Path: "logo.png", IsBinary: true},
+			{Path: "README.md", Deletions: 2},
+		},
+		TotalFiles: 4, TotalAdd: 15, TotalDel: 3,
+	})
+
+	var got speedscopeFile
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if got.Schema != speedscopeSchema || len(got.Profiles) != 1 {
+		t.Fatalf("schema %q with %d profiles, want one speedscope profile", got.Schema, len(got.Profiles))
+	}
+
+	var names []string
+	for _, f := range got.Shared.Frames {
+		names = append(names, f.Name)
+	}
+	wantNames := []string{"README.md", "src/", "a.go", "lib/", "b.go"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("frames = %v, want %v", names, wantNames)
+	}
+
+	p := got.Profiles[0]
+	wantSamples := [][]int{{0}, {1, 2}, {1, 3, 4}}
+	if !reflect.DeepEqual(p.Samples, wantSamples) || !reflect.DeepEqual(p.Weights, []int{2, 10, 6}) {
+		t.Errorf("samples = %v weights = %v, want %v and [2 10 6]", p.Samples, p.Weights, wantSamples)
+	}
+	if p.Type != "sampled" || p.EndValue != 18 {
+		t.Errorf("type %q endValue %d, want sampled and 18", p.Type, p.EndValue)
+	}
+}
@@ -0,0 +1,143 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+const stashesMaxDirs = 3 // Top-level dirs named per stash before "+N"
+
+// StashesRenderer prints one summary line per stash entry so old stashes
+// can be told apart before popping. Like TrendRenderer, it consumes stash
+// entries rather than a single DiffStats.
+// Format: stash@{0}  2 days ago  +12  -3   src/ docs/  On main: wip
+type StashesRenderer struct {
+	UseColor bool
+	Now      time.Time // Reference time for ages (zero = time.Now)
+	w        io.Writer
+}
+
+// NewStashesRenderer creates a stash list renderer.
+func NewStashesRenderer(w io.Writer, useColor bool) *StashesRenderer {
+	return &StashesRenderer{UseColor: useColor, w: w}
+}
+
+// RenderStashes outputs one aligned line per stash entry.
+func (r *StashesRenderer) RenderStashes(entries []diff.StashEntry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(r.w, "No stashes")
+		return
+	}
+
+	now := r.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	refWidth, ageWidth, dirsWidth := 0, 0, 0
+	ages := make([]string, len(entries))
+	dirs := make([]string, len(entries))
+	for i, e := range entries {
+		ages[i] = RelativeAge(e.Time, now)
+		dirs[i] = stashDirs(e.Stats)
+		refWidth = max(refWidth, len(e.Ref))
+		ageWidth = max(ageWidth, len(ages[i]))
+		dirsWidth = max(dirsWidth, VisibleWidth(dirs[i]))
+	}
+
+	for i, e := range entries {
+		add, del := 0, 0
+		if e.Stats != nil {
+			add, del = e.Stats.TotalAdd, e.Stats.TotalDel
+		}
+
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("%-*s  ", refWidth, e.Ref))
+		sb.WriteString(r.color(ColorDim))
+		sb.WriteString(fmt.Sprintf("%-*s", ageWidth, ages[i]))
+		sb.WriteString(r.color(ColorReset))
+		sb.WriteString("  ")
+		sb.WriteString(fmt.Sprintf("%s+%-5d%s %s-%-5d%s ", r.color(ColorAdd), add, r.color(ColorReset), r.color(ColorDel), del, r.color(ColorReset)))
+		sb.WriteString(r.color(ColorDir))
+		sb.WriteString(dirs[i])
+		sb.WriteString(r.color(ColorReset))
+		sb.WriteString(strings.Repeat(" ", dirsWidth-VisibleWidth(dirs[i])+2))
+		sb.WriteString(e.Message)
+		fmt.Fprintln(r.w, sb.String())
+	}
+}
+
+// stashDirs lists the top-level directories a stash touches, largest first.
+func stashDirs(stats *diff.DiffStats) string {
+	if stats == nil || len(stats.Files) == 0 {
+		return "-"
+	}
+
+	totals := make(map[string]int)
+	for _, f := range stats.Files {
+		name := GetTopDir(f.Path)
+		if name != f.Path {
+			name += "/"
+		}
+		totals[name] += f.Additions + f.Deletions
+	}
+
+	names := make([]string, 0, len(totals))
+	for name := range totals {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if totals[names[i]] != totals[names[j]] {
+			return totals[names[i]] > totals[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	if len(names) > stashesMaxDirs {
+		more := len(names) - stashesMaxDirs
+		names = append(names[:stashesMaxDirs], fmt.Sprintf("+%d", more))
+	}
+	return strings.Join(names, " ")
+}
+
+// RelativeAge formats the time elapsed from t to now, e.g. "3 days ago".
+func RelativeAge(t, now time.Time) string {
+	d := now.Sub(t)
+	if d < time.Minute {
+		return "just now"
+	}
+
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"week", 7 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, u := range units {
+		if n := int(d / u.size); n >= 1 {
+			if n == 1 {
+				return fmt.Sprintf("1 %s ago", u.name)
+			}
+			return fmt.Sprintf("%d %ss ago", n, u.name)
+		}
+	}
+	return "just now"
+}
+
+// color returns the ANSI code if color is enabled.
+func (r *StashesRenderer) color(code string) string {
+	if r.UseColor {
+		return code
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestRelativeAge(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{5 * time.Hour, "5 hours ago"},
+		{50 * time.Hour, "2 days ago"},
+		{15 * 24 * time.Hour, "2 weeks ago"},
+		{400 * 24 * time.Hour, "1 year ago"},
+	}
+	for _, tt := range tests {
+		if got := RelativeAge(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("RelativeAge(-%v) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+}
+
+func TestStashesRenderer(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	r := NewStashesRenderer(&buf, false)
+	r.Now = now
+	r.RenderStashes([]diff.StashEntry{
+		{
+			Ref: "stash@{0}", Message: "On main: try lib", Time: now.Add(-3 * time.Hour),
+			Stats: &diff.DiffStats{
+				Files: []diff.FileStat{
+					{Path: "a/x.go", Additions: 1}, {Path: "b/x.go", Additions: 9},
+					{Path: "c/x.go", Additions: 5}, {Path: "d/x.go", Deletions: 2},
+					{Path: "README.md", Additions: 1},
+				},
+				TotalAdd: 16, TotalDel: 2,
+			},
+		},
+		{Ref: "stash@{1}", Message: "WIP on main", Time: now.Add(-72 * time.Hour), Stats: &diff.DiffStats{}},
+	})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	for _, want := range []string{"stash@{0}", "3 hours ago", "+16", "-2", "b/ c/ d/ +2", "On main: try lib"} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("line 0 missing %q: %q", want, lines[0])
+		}
+	}
+	if !strings.Contains(lines[1], "3 days ago") || !strings.Contains(lines[1], " - ") {
+		t.Errorf("line 1 = %q, want age and empty dirs marker", lines[1])
+	}
+}
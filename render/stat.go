@@ -0,0 +1,152 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+const (
+	statDefaultWidth = 80 // git's width when not writing to a terminal
+	statMinGraph     = 10 // Graph columns kept by shortening long names
+	statMinName      = 10 // Shortest a name gets before the graph gives way
+)
+
+// StatRenderer mimics git diff --stat: one " path | count +++--" line per
+// file, then a "N files changed" summary. Graphs scale like git's so the
+// largest change fits the line; long paths are cut from the left ("...").
+// Format:  src/api/handler.go | 12 ++++++++----
+type StatRenderer struct {
+	UseColor  bool
+	Width     int                 // Total line width (default 80)
+	SizeClass diff.SizeClass      // Optional size label appended to summary
+	Excluded  diff.ExcludedTotals // Excluded categories; the summary adds totals without them
+	w         io.Writer
+}
+
+// NewStatRenderer creates a git --stat style renderer.
+func NewStatRenderer(w io.Writer, useColor bool) *StatRenderer {
+	return &StatRenderer{UseColor: useColor, Width: statDefaultWidth, w: w}
+}
+
+// Render outputs one line per file and a summary line.
+func (r *StatRenderer) Render(stats *diff.DiffStats) {
+	if stats.TotalFiles == 0 {
+		fmt.Fprintln(r.w, "No changes")
+		return
+	}
+
+	nameWidth, countWidth, maxTotal := 0, 0, 0
+	for _, f := range stats.Files {
+		nameWidth = max(nameWidth, VisibleWidth(f.Path))
+		countWidth = max(countWidth, len(statCount(f)))
+		if !f.IsBinary {
+			maxTotal = max(maxTotal, f.Additions+f.Deletions)
+		}
+	}
+
+	width := r.Width
+	if width <= 0 {
+		width = statDefaultWidth
+	}
+	// " name | count graph"
+	graphWidth := width - nameWidth - countWidth - 5
+	if graphWidth < statMinGraph {
+		nameWidth = max(nameWidth-(statMinGraph-graphWidth), statMinName)
+		graphWidth = max(width-nameWidth-countWidth-5, 1)
+	}
+	graphWidth = min(graphWidth, maxTotal)
+
+	for _, f := range stats.Files {
+		name := statName(f.Path, nameWidth)
+		fmt.Fprintf(r.w, " %s%s | %*s", name, strings.Repeat(" ", nameWidth-VisibleWidth(name)), countWidth, statCount(f))
+		if !f.IsBinary && f.Additions+f.Deletions > 0 {
+			adds, dels := statScale(f.Additions, f.Deletions, graphWidth, maxTotal)
+			fmt.Fprint(r.w, " ", r.graph(adds, "+", ColorAdd), r.graph(dels, "-", ColorDel))
+		}
+		fmt.Fprintln(r.w)
+	}
+
+	fmt.Fprintf(r.w, " %s%s%s\n", statSummary(stats), excludedSuffix(stats, r.Excluded), sizeSuffix(r.SizeClass))
+}
+
+// statCount is the count column: changed lines, or "Bin" for binary files.
+func statCount(f diff.FileStat) string {
+	if f.IsBinary {
+		return "Bin"
+	}
+	return fmt.Sprint(f.Additions + f.Deletions)
+}
+
+// statName cuts p from the left to fit width, marking the cut with "...".
+func statName(p string, width int) string {
+	if VisibleWidth(p) <= width {
+		return p
+	}
+	runes := []rune(p)
+	return "..." + string(runes[len(runes)-(width-3):])
+}
+
+// statScale returns the +/- columns for a file, scaled like git's graph:
+// when the largest change exceeds width, every nonzero count keeps at least
+// one column, and the smaller side is scaled on its own so it stays visible.
+func statScale(adds, dels, width, maxTotal int) (int, int) {
+	if maxTotal <= width {
+		return adds, dels
+	}
+	scale := func(n int) int {
+		if n == 0 {
+			return 0
+		}
+		return 1 + n*(width-1)/maxTotal
+	}
+	total := scale(adds + dels)
+	if total < 2 && adds > 0 && dels > 0 {
+		total = 2
+	}
+	if adds < dels {
+		scaledAdds := scale(adds)
+		return scaledAdds, total - scaledAdds
+	}
+	scaledDels := scale(dels)
+	return total - scaledDels, scaledDels
+}
+
+// statSummary is git's "N files changed, X insertions(+), Y deletions(-)",
+// leaving out a zero count unless both are zero.
+func statSummary(stats *diff.DiffStats) string {
+	none := stats.TotalAdd == 0 && stats.TotalDel == 0
+	parts := []string{statPlural(stats.TotalFiles, "file changed", "files changed")}
+	if stats.TotalAdd > 0 || none {
+		parts = append(parts, statPlural(stats.TotalAdd, "insertion(+)", "insertions(+)"))
+	}
+	if stats.TotalDel > 0 || none {
+		parts = append(parts, statPlural(stats.TotalDel, "deletion(-)", "deletions(-)"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func statPlural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+// graph draws n copies of mark in color (nothing when n is 0).
+func (r *StatRenderer) graph(n int, mark, color string) string {
+	if n <= 0 {
+		return ""
+	}
+	return r.color(color) + strings.Repeat(mark, n) + r.color(ColorReset)
+}
+
+// color returns the ANSI code if color is enabled.
+func (r *StatRenderer) color(code string) string {
+	if r.UseColor {
+		return code
+	}
+	return ""
+}
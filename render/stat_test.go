@@ -0,0 +1,77 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestStatRenderer(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "README.md", Additions: 3, Deletions: 1},
+			{Path: "src/api/handler.go", Additions: 10},
+			{Path: "logo.png", IsBinary: true},
+		},
+		TotalAdd: 13, TotalDel: 1, TotalFiles: 3,
+	}
+	var buf bytes.Buffer
+	NewStatRenderer(&buf, false).Render(stats)
+
+	want := ` README.md          |   4 +++-
+ src/api/handler.go |  10 ++++++++++
+ logo.png           | Bin
+ 3 files changed, 13 insertions(+), 1 deletion(-)
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestStatRenderer_Narrow(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "internal/storage/postgres/migrations.go", Additions: 200, Deletions: 2},
+			{Path: "a.go", Additions: 1, Deletions: 1},
+		},
+		TotalAdd: 201, TotalDel: 3, TotalFiles: 2,
+	}
+	var buf bytes.Buffer
+	r := NewStatRenderer(&buf, false)
+	r.Width = 40
+	r.Render(stats)
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	for _, line := range lines[:2] {
+		if VisibleWidth(line) > 40 {
+			t.Errorf("line wider than 40 columns: %q", line)
+		}
+	}
+	if !strings.HasPrefix(lines[0], " ...") {
+		t.Errorf("long path not cut from the left: %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "| 2 +-") && !strings.HasSuffix(lines[1], "|   2 +-") {
+		t.Errorf("small change should keep one + and one -: %q", lines[1])
+	}
+}
+
+func TestStatScale(t *testing.T) {
+	tests := []struct {
+		adds, dels, width, maxTotal int
+		wantAdds, wantDels          int
+	}{
+		{3, 1, 40, 10, 3, 1},     // Fits: unscaled
+		{100, 0, 40, 100, 40, 0}, // Largest fills the width
+		{1, 1, 40, 1000, 1, 1},   // Both sides stay visible
+		{900, 10, 40, 910, 39, 1},
+	}
+	for _, tt := range tests {
+		adds, dels := statScale(tt.adds, tt.dels, tt.width, tt.maxTotal)
+		if adds != tt.wantAdds || dels != tt.wantDels {
+			t.Errorf("statScale(%d, %d, %d, %d) = %d, %d; want %d, %d",
+				tt.adds, tt.dels, tt.width, tt.maxTotal, adds, dels, tt.wantAdds, tt.wantDels)
+		}
+	}
+}
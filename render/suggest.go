@@ -0,0 +1,189 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// SuggestRenderer prints a one-line change summary to start a commit message
+// or PR title from, e.g. in a prepare-commit-msg hook.
+// Output is never colored. Nothing is printed when there are no changes.
+// Format:
+//
+//	refactor render: icicle layout (+410/-88, 8 files)
+//
+// The verb comes from the kind of change (see suggestVerb), the scope is the
+// top-level directory with the most changed lines (omitted for root-level
+// files), and the subject names the files or subdirectory that dominate it.
+type SuggestRenderer struct {
+	w io.Writer
+}
+
+// NewSuggestRenderer creates a change summary renderer.
+// useColor is accepted for signature parity and ignored.
+func NewSuggestRenderer(w io.Writer, useColor bool) *SuggestRenderer {
+	return &SuggestRenderer{w: w}
+}
+
+// Render outputs the suggested summary line.
+func (r *SuggestRenderer) Render(stats *diff.DiffStats) {
+	if stats.TotalFiles == 0 {
+		return
+	}
+
+	scope := stats.DirStats(1)[0].Path
+	var files []diff.FileStat
+	for _, f := range stats.Files {
+		if dirAtTop(f.Path) == scope {
+			files = append(files, f)
+		}
+	}
+
+	prefix := suggestVerb(stats.Files)
+	if scope != "." {
+		prefix += " " + scope
+	}
+	noun := "files"
+	if stats.TotalFiles == 1 {
+		noun = "file"
+	}
+	fmt.Fprintf(r.w, "%s: %s (+%d/-%d, %d %s)\n", prefix, suggestSubject(scope, sortedByTotal(files)),
+		stats.TotalAdd, stats.TotalDel, stats.TotalFiles, noun)
+}
+
+// suggestVerb classifies a change, first match wins: "remove" (only deleted
+// files), "docs" or "test" (only documentation or test files), "add" (most
+// added lines are in new files), "refactor" (deletions at least a fifth of
+// the additions: existing code was reworked), else "update".
+func suggestVerb(files []diff.FileStat) string {
+	allDeleted, allDocs, allTests := true, true, true
+	var add, newAdd, del int
+	for _, f := range files {
+		allDeleted = allDeleted && f.IsDeleted()
+		allDocs = allDocs && isDocPath(f.Path)
+		allTests = allTests && isTestPath(f.Path)
+		add += f.Additions
+		del += f.Deletions
+		if f.IsNew() {
+			newAdd += f.Additions
+		}
+	}
+
+	switch {
+	case allDeleted:
+		return "remove"
+	case allDocs:
+		return "docs"
+	case allTests:
+		return "test"
+	case add > 0 && newAdd*2 > add:
+		return "add"
+	case del > 0 && del*5 >= add:
+		return "refactor"
+	}
+	return "update"
+}
+
+// suggestSubject names what dominates files (sorted by total, all under
+// scope): a file or a subdirectory holding at least half the changed lines,
+// else the two largest files.
+func suggestSubject(scope string, files []diff.FileStat) string {
+	total := 0
+	bySubdir := make(map[string]int)
+	for _, f := range files {
+		total += f.Additions + f.Deletions
+		if rest, ok := strings.CutPrefix(f.Path, scope+"/"); ok && strings.Contains(rest, "/") {
+			bySubdir[strings.SplitN(rest, "/", 2)[0]] += f.Additions + f.Deletions
+		}
+	}
+
+	top := files[0]
+	if len(files) == 1 || (top.Additions+top.Deletions)*2 >= total {
+		return fileSubject(top.Path)
+	}
+	dirs := make([]string, 0, len(bySubdir))
+	for dir := range bySubdir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		if n := bySubdir[dir]; n*2 >= total && total > 0 {
+			return humanizeName(dir)
+		}
+	}
+	if len(files) == 2 {
+		return fileSubject(files[0].Path) + " and " + fileSubject(files[1].Path)
+	}
+	return fmt.Sprintf("%s, %s and %d more", fileSubject(files[0].Path), fileSubject(files[1].Path), len(files)-2)
+}
+
+// fileSubject turns a path into words: "render/icicle_layout.go" -> "icicle layout".
+// Test files keep a "tests" suffix so they read differently from the code.
+func fileSubject(p string) string {
+	name := path.Base(p)
+	if newPath, ok := diff.RenameTarget(p); ok {
+		name = path.Base(newPath)
+	}
+	stem := strings.TrimSuffix(name, path.Ext(name))
+	if base, ok := strings.CutSuffix(stem, "_test"); ok {
+		return humanizeName(base) + " tests"
+	}
+	return humanizeName(stem)
+}
+
+// humanizeName replaces _ and - separators with spaces.
+func humanizeName(s string) string {
+	return strings.NewReplacer("_", " ", "-", " ").Replace(s)
+}
+
+// dirAtTop returns the top-level directory of p, or "." for root-level files.
+func dirAtTop(p string) string {
+	if top, _, found := strings.Cut(p, "/"); found {
+		return top
+	}
+	return "."
+}
+
+// sortedByTotal returns a copy of files sorted by total changes (descending),
+// ties broken by path.
+func sortedByTotal(files []diff.FileStat) []diff.FileStat {
+	sorted := append([]diff.FileStat(nil), files...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ti := sorted[i].Additions + sorted[i].Deletions
+		tj := sorted[j].Additions + sorted[j].Deletions
+		if ti != tj {
+			return ti > tj
+		}
+		return sorted[i].Path < sorted[j].Path
+	})
+	return sorted
+}
+
+// isDocPath reports whether p looks like documentation.
+func isDocPath(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".md", ".markdown", ".rst", ".adoc", ".txt":
+		return true
+	}
+	return strings.HasPrefix(p, "docs/") || strings.HasPrefix(p, "doc/")
+}
+
+// isTestPath reports whether p looks like a test file or test fixture.
+func isTestPath(p string) bool {
+	name := path.Base(p)
+	if strings.Contains(name, "_test.") || strings.Contains(name, ".test.") || strings.Contains(name, ".spec.") {
+		return true
+	}
+	for _, part := range strings.Split(path.Dir(p), "/") {
+		switch part {
+		case "test", "tests", "testdata", "__tests__":
+			return true
+		}
+	}
+	return false
+}
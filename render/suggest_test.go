@@ -0,0 +1,91 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestSuggestRenderer(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []diff.FileStat
+		want  string
+	}{
+		{
+			name: "dominant file",
+			files: []diff.FileStat{
+				{Path: "render/icicle_layout.go", Additions: 300, Deletions: 80},
+				{Path: "render/icicle.go", Additions: 60, Deletions: 8},
+				{Path: "README.md", Additions: 50},
+			},
+			want: "refactor render: icicle layout (+410/-88, 3 files)\n",
+		},
+		{
+			name: "dominant subdirectory",
+			files: []diff.FileStat{
+				{Path: "render/bubbletea/model.go", Additions: 40},
+				{Path: "render/bubbletea/keys.go", Additions: 30},
+				{Path: "render/tree.go", Additions: 35},
+			},
+			want: "update render: bubbletea (+105/-0, 3 files)\n",
+		},
+		{
+			name: "new files",
+			files: []diff.FileStat{
+				{Path: "publish/slack.go", Additions: 120, IsUntracked: true},
+				{Path: "publish/slack_test.go", Additions: 100, IsUntracked: true},
+				{Path: "publish/github.go", Additions: 5, Deletions: 1},
+			},
+			want: "add publish: slack (+225/-1, 3 files)\n",
+		},
+		{
+			name: "no dominant file",
+			files: []diff.FileStat{
+				{Path: "cmd/serve.go", Additions: 40},
+				{Path: "cmd/main.go", Additions: 30},
+				{Path: "cmd/flags.go", Additions: 20},
+			},
+			want: "update cmd: serve, main and 1 more (+90/-0, 3 files)\n",
+		},
+		{
+			name:  "docs only",
+			files: []diff.FileStat{{Path: "docs/guide.md", Additions: 12, Deletions: 3}},
+			want:  "docs docs: guide (+12/-3, 1 file)\n",
+		},
+		{
+			name:  "deletions only",
+			files: []diff.FileStat{{Path: "old.go", Deletions: 40, Status: "D"}},
+			want:  "remove: old (+0/-40, 1 file)\n",
+		},
+		{
+			name: "tests only",
+			files: []diff.FileStat{
+				{Path: "diff/diff_test.go", Additions: 30},
+				{Path: "diff/testdata/a.txt", Additions: 25},
+			},
+			want: "test diff: diff tests (+55/-0, 2 files)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		stats := &diff.DiffStats{Files: tt.files, TotalFiles: len(tt.files)}
+		for _, f := range tt.files {
+			stats.TotalAdd += f.Additions
+			stats.TotalDel += f.Deletions
+		}
+
+		var buf bytes.Buffer
+		NewSuggestRenderer(&buf, true).Render(stats)
+		if buf.String() != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, buf.String(), tt.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	NewSuggestRenderer(&buf, false).Render(&diff.DiffStats{})
+	if buf.Len() != 0 {
+		t.Errorf("no changes should print nothing, got %q", buf.String())
+	}
+}
@@ -0,0 +1,228 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Terminal screenshot geometry, in SVG user units.
+const (
+	svgFontSize   = 14
+	svgCharWidth  = 8.4 // Advance of a 14px monospace glyph
+	svgLineHeight = 18
+	svgPadding    = 12
+	svgTabWidth   = 8
+)
+
+// Terminal screenshot colors: a dark theme matching the palette the
+// renderers assume.
+const (
+	svgBackground = "#1e1e1e"
+	svgForeground = "#d0d0d0"
+)
+
+// svgStyle is the SGR state in effect for a run of text.
+type svgStyle struct {
+	fg, bg string // "" means the default
+	bold   bool
+	dim    bool
+}
+
+// svgRun is styled text starting at a column.
+type svgRun struct {
+	col   int
+	text  string
+	style svgStyle
+}
+
+// WriteTerminalSVG renders ANSI text (as a renderer writes it) into an SVG
+// "terminal screenshot": monospace lines on a dark background with SGR
+// colors, bold, and dim applied. Other escape sequences are dropped and
+// tabs expand to 8 columns. Glyphs are assumed to be one column wide.
+func WriteTerminalSVG(w io.Writer, ansi string) error {
+	lines := strings.Split(strings.TrimSuffix(ansi, "\n"), "\n")
+	runs := make([][]svgRun, len(lines))
+	cols := 0
+	var style svgStyle
+	for i, line := range lines {
+		runs[i], style = parseSVGLine(line, style)
+		if n := len(runs[i]); n > 0 {
+			last := runs[i][n-1]
+			cols = max(cols, last.col+VisibleWidth(last.text))
+		}
+	}
+
+	width := float64(cols)*svgCharWidth + 2*svgPadding
+	height := len(lines)*svgLineHeight + 2*svgPadding
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%d" viewBox="0 0 %.0f %d">`+"\n", width, height, width, height)
+	fmt.Fprintf(&sb, `<rect width="100%%" height="100%%" rx="6" fill="%s"/>`+"\n", svgBackground)
+	fmt.Fprintf(&sb, `<g font-family="ui-monospace, Menlo, Consolas, monospace" font-size="%d" fill="%s" xml:space="preserve">`+"\n", svgFontSize, svgForeground)
+	for i, line := range runs {
+		y := svgPadding + i*svgLineHeight
+		for _, run := range line {
+			if run.style.bg != "" {
+				fmt.Fprintf(&sb, `<rect x="%s" y="%d" width="%s" height="%d" fill="%s"/>`+"\n",
+					svgX(run.col), y, svgNum(float64(VisibleWidth(run.text))*svgCharWidth), svgLineHeight, run.style.bg)
+			}
+		}
+		if len(line) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, `<text y="%d">`, y+svgFontSize)
+		for _, run := range line {
+			sb.WriteString(`<tspan x="` + svgX(run.col) + `"`)
+			if run.style.fg != "" {
+				sb.WriteString(` fill="` + run.style.fg + `"`)
+			}
+			if run.style.bold {
+				sb.WriteString(` font-weight="bold"`)
+			}
+			if run.style.dim {
+				sb.WriteString(` opacity="0.6"`)
+			}
+			sb.WriteString(">" + html.EscapeString(run.text) + "</tspan>")
+		}
+		sb.WriteString("</text>\n")
+	}
+	sb.WriteString("</g>\n</svg>\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// parseSVGLine splits one line into styled runs, starting from style and
+// returning the style in effect at the end of the line. Blank runs with no
+// background are dropped; their columns still advance.
+func parseSVGLine(line string, style svgStyle) ([]svgRun, svgStyle) {
+	var runs []svgRun
+	var text strings.Builder
+	col, start := 0, 0
+	flush := func() {
+		if text.Len() > 0 && (strings.TrimSpace(text.String()) != "" || style.bg != "") {
+			runs = append(runs, svgRun{col: start, text: text.String(), style: style})
+		}
+		text.Reset()
+		start = col
+	}
+
+	for i := 0; i < len(line); {
+		switch {
+		case line[i] == 0x1b && i+1 < len(line) && line[i+1] == '[':
+			end := i + 2
+			for end < len(line) && (line[end] < 0x40 || line[end] > 0x7e) {
+				end++
+			}
+			if end < len(line) && line[end] == 'm' {
+				flush()
+				style = applySGR(style, line[i+2:end])
+			}
+			i = end + 1
+		case line[i] == 0x1b && i+1 < len(line) && line[i+1] == ']':
+			// OSC (hyperlinks, titles): skip to BEL or ST
+			end := i + 2
+			for end < len(line) && line[end] != 0x07 && !(line[end] == 0x1b && end+1 < len(line) && line[end+1] == '\\') {
+				end++
+			}
+			if end < len(line) && line[end] == 0x1b {
+				end++
+			}
+			i = end + 1
+		case line[i] == 0x1b:
+			i += 2
+		case line[i] == '\t':
+			n := svgTabWidth - col%svgTabWidth
+			text.WriteString(strings.Repeat(" ", n))
+			col += n
+			i++
+		default:
+			r, size := utf8.DecodeRuneInString(line[i:])
+			text.WriteString(line[i : i+size])
+			col += VisibleWidth(string(r))
+			i += size
+		}
+	}
+	flush()
+	return runs, style
+}
+
+// applySGR updates style with an SGR parameter list ("1;38;5;208").
+func applySGR(style svgStyle, params string) svgStyle {
+	if params == "" {
+		return svgStyle{}
+	}
+	parts := strings.Split(params, ";")
+	for i := 0; i < len(parts); i++ {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			continue
+		}
+		switch {
+		case n == 0:
+			style = svgStyle{}
+		case n == 1:
+			style.bold = true
+		case n == 2:
+			style.dim = true
+		case n == 22:
+			style.bold, style.dim = false, false
+		case n >= 30 && n <= 37:
+			style.fg = svgHex(ansi16Colors[n-30])
+		case n >= 90 && n <= 97:
+			style.fg = svgHex(ansi16Colors[n-90+8])
+		case n == 39:
+			style.fg = ""
+		case n >= 40 && n <= 47:
+			style.bg = svgHex(ansi16Colors[n-40])
+		case n >= 100 && n <= 107:
+			style.bg = svgHex(ansi16Colors[n-100+8])
+		case n == 49:
+			style.bg = ""
+		case (n == 38 || n == 48) && i+1 < len(parts):
+			rgb, used, ok := extendedColor(parts[i+1:])
+			i += used
+			if !ok {
+				continue
+			}
+			if n == 38 {
+				style.fg = svgHex(rgb)
+			} else {
+				style.bg = svgHex(rgb)
+			}
+		}
+	}
+	return style
+}
+
+// extendedColor reads the arguments after a 38 or 48 parameter ("5;N" or
+// "2;R;G;B"), returning the color and how many parameters it used.
+func extendedColor(args []string) (rgb [3]int, used int, ok bool) {
+	switch {
+	case args[0] == "5" && len(args) >= 2:
+		index := atoiByte(args[1])
+		if index < 16 {
+			return ansi16Colors[index], 2, true
+		}
+		return palette256(index), 2, true
+	case args[0] == "2" && len(args) >= 4:
+		return [3]int{atoiByte(args[1]), atoiByte(args[2]), atoiByte(args[3])}, 4, true
+	}
+	return rgb, 1, false
+}
+
+func svgHex(rgb [3]int) string {
+	return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2])
+}
+
+func svgX(col int) string {
+	return svgNum(svgPadding + float64(col)*svgCharWidth)
+}
+
+// svgNum formats a coordinate without trailing zeros.
+func svgNum(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
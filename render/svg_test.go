@@ -0,0 +1,60 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriteTerminalSVG(t *testing.T) {
+	var buf bytes.Buffer
+	ansi := "src/ " + ColorAdd + "+12" + ColorReset + " <a&b>\n" +
+		"\t" + "\033[1;38;5;208m" + "hot" + ColorReset + "\n" +
+		"\033]8;;https://example.com\033\\link\033]8;;\033\\\n"
+	if err := WriteTerminalSVG(&buf, ansi); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+
+	// Well-formed XML
+	dec := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		if _, err := dec.Token(); err != nil {
+			if err != io.EOF {
+				t.Fatalf("invalid SVG: %v\n%s", err, svg)
+			}
+			break
+		}
+	}
+
+	for _, want := range []string{
+		`<tspan x="12">src/ </tspan>`,
+		`<tspan x="54" fill="#00cd00">+12</tspan>`,
+		`<tspan x="79.2"> &lt;a&amp;b&gt;</tspan>`,
+		`<tspan x="79.2" fill="#ff8700" font-weight="bold">hot</tspan>`,
+		`<tspan x="12">link</tspan>`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("missing %s in:\n%s", want, svg)
+		}
+	}
+	if strings.Contains(svg, "\033") || strings.Contains(svg, "example.com") {
+		t.Errorf("escape sequences leaked into:\n%s", svg)
+	}
+}
+
+func TestApplySGR(t *testing.T) {
+	style := applySGR(svgStyle{}, "1;2;41;38;2;1;2;3")
+	want := svgStyle{fg: "#010203", bg: "#cd0000", bold: true, dim: true}
+	if style != want {
+		t.Errorf("applySGR = %+v, want %+v", style, want)
+	}
+	if got := applySGR(style, "0"); got != (svgStyle{}) {
+		t.Errorf("reset = %+v, want zero style", got)
+	}
+	if got := applySGR(style, "22;39;49"); got != (svgStyle{}) {
+		t.Errorf("22;39;49 = %+v, want zero style", got)
+	}
+}
@@ -21,14 +21,37 @@ const (
 	SortByTotal SortBy = "total" // Sort by total changes (adds + dels)
 	SortByAdds  SortBy = "adds"  // Sort by additions only
 	SortByDels  SortBy = "dels"  // Sort by deletions only
+	SortByFuncs SortBy = "funcs" // Sort by functions changed (requires diff.CountFunctionsChanged)
 )
 
 // TopNRenderer shows the N files with the most changes.
 type TopNRenderer struct {
-	N        int
-	SortBy   SortBy // Sorting criteria (default: total)
-	UseColor bool
-	w        io.Writer
+	N           int
+	SortBy      SortBy // Sorting criteria (default: total)
+	UseColor    bool
+	SizeClass   diff.SizeClass      // Optional size label appended to summary
+	Excluded    diff.ExcludedTotals // Excluded categories; the summary adds totals without them
+	Bar         BarStyle            // Zero-change and padding options
+	Annotations []string            // Annotation keys to show after each file
+	AgeHeat     bool                // Color paths by FileStat.ReplacedAge
+	ScaleLegend bool                // Print a line explaining block shades and bar lengths
+
+	// Bytes means counts are bytes (see diff.DiffStats.InBytes): they are
+	// shown with units, and bars grow one block per doubling from 1KB
+	// (full at 512KB) whatever Bar.Scale is.
+	Bytes bool
+
+	// PerDir shows the top N files of each top-level directory under a
+	// directory header instead of the top N overall, so one busy directory
+	// does not crowd out the rest. Directories are ordered by their total.
+	PerDir bool
+
+	// Compare, when set, holds the stats of an earlier range. Each entry
+	// shows how far it moved in the ranking since then: "↑3", "↓1", "=",
+	// or "new" for files absent from Compare. With PerDir, directory
+	// headers show their movement among the directories too.
+	Compare *diff.DiffStats
+	w       io.Writer
 }
 
 // NewTopNRenderer creates a top-N summary renderer.
@@ -46,12 +69,33 @@ func (r *TopNRenderer) Render(stats *diff.DiffStats) {
 		return
 	}
 
-	// Sort files by configured criteria (descending)
-	files := make([]diff.FileStat, len(stats.Files))
-	copy(files, stats.Files)
-	sort.Slice(files, func(i, j int) bool {
-		return r.sortValue(files[i]) > r.sortValue(files[j])
-	})
+	var showCount int
+	if r.PerDir {
+		showCount = r.renderPerDir(stats.Files)
+	} else {
+		showCount = r.renderTop(stats.Files)
+	}
+
+	writeConflicts(r.w, stats, r.color)
+	writeBinarySummary(r.w, stats, r.color)
+	if r.AgeHeat {
+		writeAgeLegend(r.w, r.color)
+	}
+	if r.ScaleLegend {
+		writeScaleLegend(r.w, r.Bar.Scale, r.color)
+	}
+
+	// Summary line
+	r.renderSummary(stats, showCount)
+}
+
+// renderTop prints the top N files overall and returns how many it showed.
+func (r *TopNRenderer) renderTop(all []diff.FileStat) int {
+	files := r.rank(all)
+	var prior map[string]int
+	if r.Compare != nil {
+		prior = r.ranks(r.Compare.Files)
+	}
 
 	// Take top N
 	showCount := min(r.N, len(files))
@@ -61,31 +105,193 @@ func (r *TopNRenderer) Render(stats *diff.DiffStats) {
 	// Display paths as-is (no truncation) to maintain alignment of stats column.
 	maxPathLen := 0
 	for _, f := range topFiles {
-		maxPathLen = max(maxPathLen, len(f.Path))
+		maxPathLen = max(maxPathLen, VisibleWidth(topNPath(f)))
 	}
 
 	// Print each file
-	for _, f := range topFiles {
-		r.renderFile(f, maxPathLen)
+	for i, f := range topFiles {
+		movement := ""
+		if prior != nil {
+			movement = r.formatMovement(i+1, prior, f.Path)
+		}
+		r.renderFile(f, "", maxPathLen, movement)
+	}
+	return showCount
+}
+
+// topNGroup is one top-level directory in PerDir output.
+type topNGroup struct {
+	dir   string
+	files []diff.FileStat // Ranked
+	value int             // Sum of the files' sort values
+	add   int
+	del   int
+}
+
+// renderPerDir prints each top-level directory's top N files and returns
+// how many files it showed. With Compare, a directory's movement is the
+// change in its rank among directories, and a file's is the change in rank
+// within its directory.
+func (r *TopNRenderer) renderPerDir(all []diff.FileStat) int {
+	groups := r.groupByDir(all)
+	var prior, priorDirs map[string]int
+	if r.Compare != nil {
+		prior = make(map[string]int, len(r.Compare.Files))
+		priorDirs = make(map[string]int)
+		for i, g := range r.groupByDir(r.Compare.Files) {
+			priorDirs[g.dir] = i + 1
+			for path, rank := range r.ranks(g.files) {
+				prior[path] = rank
+			}
+		}
 	}
 
-	// Summary line
-	r.renderSummary(stats, showCount)
+	maxPathLen := 0
+	for _, g := range groups {
+		for _, f := range g.files[:min(r.N, len(g.files))] {
+			maxPathLen = max(maxPathLen, VisibleWidth(topNPath(f)))
+		}
+	}
+
+	shown := 0
+	for i, g := range groups {
+		top := g.files[:min(r.N, len(g.files))]
+		movement := ""
+		if priorDirs != nil {
+			movement = r.formatMovement(i+1, priorDirs, g.dir)
+		}
+		r.renderDirHeader(g, len(top), movement)
+		for j, f := range top {
+			movement := ""
+			if prior != nil {
+				movement = r.formatMovement(j+1, prior, f.Path)
+			}
+			r.renderFile(f, "  ", maxPathLen, movement)
+		}
+		shown += len(top)
+	}
+	return shown
+}
+
+// groupByDir splits files by top-level directory ("." for root files), each
+// group ranked, ordered by the groups' summed sort values (ties by name).
+func (r *TopNRenderer) groupByDir(files []diff.FileStat) []topNGroup {
+	index := make(map[string]int)
+	var groups []topNGroup
+	for _, f := range r.rank(files) {
+		dir := "."
+		if strings.Contains(f.Path, "/") {
+			dir = GetTopDir(f.Path) + "/"
+		}
+		i, ok := index[dir]
+		if !ok {
+			i = len(groups)
+			index[dir] = i
+			groups = append(groups, topNGroup{dir: dir})
+		}
+		g := &groups[i]
+		g.files = append(g.files, f)
+		g.value += r.sortValue(f)
+		g.add += f.Additions
+		g.del += f.Deletions
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].value != groups[j].value {
+			return groups[i].value > groups[j].value
+		}
+		return groups[i].dir < groups[j].dir
+	})
+	return groups
 }
 
-// renderFile outputs a single file line.
-func (r *TopNRenderer) renderFile(f diff.FileStat, maxPathLen int) {
+// renderDirHeader outputs a PerDir directory line with its totals, how many
+// files are shown when some are hidden, and its movement against Compare
+// ("" when not comparing).
+func (r *TopNRenderer) renderDirHeader(g topNGroup, shown int, movement string) {
 	var sb strings.Builder
+	sb.WriteString(r.color(ColorDir))
+	sb.WriteString(g.dir)
+	sb.WriteString(r.color(ColorReset))
+	sb.WriteString(" ")
+	sb.WriteString(r.color(ColorAdd))
+	sb.WriteString("+" + formatAmount(g.add, r.Bytes))
+	sb.WriteString(r.color(ColorReset))
+	sb.WriteString(" ")
+	sb.WriteString(r.color(ColorDel))
+	sb.WriteString("-" + formatAmount(g.del, r.Bytes))
+	sb.WriteString(r.color(ColorReset))
+	if shown < len(g.files) {
+		sb.WriteString(r.color(ColorDim))
+		sb.WriteString(fmt.Sprintf(" (%d of %d files)", shown, len(g.files)))
+		sb.WriteString(r.color(ColorReset))
+	}
+	if movement != "" {
+		sb.WriteString("  ")
+		sb.WriteString(movement)
+	}
+	fmt.Fprintln(r.w, sb.String())
+}
 
-	// Path (left-aligned with padding, no indent for compact status line display)
-	path := f.Path
+// ranks maps each path in files to its 1-based rank.
+func (r *TopNRenderer) ranks(files []diff.FileStat) map[string]int {
+	ranks := make(map[string]int, len(files))
+	for i, f := range r.rank(files) {
+		ranks[f.Path] = i + 1
+	}
+	return ranks
+}
+
+// rank returns a copy of files sorted by the configured criteria
+// (descending). Ties are broken by path so rankings of two ranges compare.
+func (r *TopNRenderer) rank(files []diff.FileStat) []diff.FileStat {
+	ranked := make([]diff.FileStat, len(files))
+	copy(ranked, files)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		vi, vj := r.sortValue(ranked[i]), r.sortValue(ranked[j])
+		if vi != vj {
+			return vi > vj
+		}
+		return ranked[i].Path < ranked[j].Path
+	})
+	return ranked
+}
+
+// formatMovement compares rank (1-based) with key's rank in prior.
+func (r *TopNRenderer) formatMovement(rank int, prior map[string]int, key string) string {
+	was, ok := prior[key]
+	switch {
+	case !ok:
+		return r.color(ColorNew) + "new" + r.color(ColorReset)
+	case was > rank:
+		return fmt.Sprintf("↑%d", was-rank)
+	case was < rank:
+		return fmt.Sprintf("↓%d", rank-was)
+	}
+	return r.color(ColorDim) + "=" + r.color(ColorReset)
+}
+
+// renderFile outputs a single file line after indent. movement is the
+// ranking change against Compare ("" when not comparing).
+func (r *TopNRenderer) renderFile(f diff.FileStat, indent string, maxPathLen int, movement string) {
+	var sb strings.Builder
+	sb.WriteString(indent)
+
+	// Path (left-aligned with padding)
+	path := topNPath(f)
 	pathColor := ColorReset
-	if f.IsUntracked {
+	if f.IsNew() {
 		pathColor = ColorNew
 	}
+	if heat := AgeHeatColor(f.ReplacedAge); r.AgeHeat && heat != "" {
+		pathColor = heat
+	}
+	if f.IsUnmerged {
+		pathColor = ColorConflict
+	}
 	sb.WriteString(r.color(pathColor))
-	sb.WriteString(fmt.Sprintf("%-*s", maxPathLen, path))
+	sb.WriteString(path)
 	sb.WriteString(r.color(ColorReset))
+	sb.WriteString(strings.Repeat(" ", max(maxPathLen-VisibleWidth(path), 0)))
 
 	// Stats: +X -Y (right-aligned in fixed width)
 	statsStr := r.formatStats(f.Additions, f.Deletions)
@@ -93,31 +299,59 @@ func (r *TopNRenderer) renderFile(f diff.FileStat, maxPathLen int) {
 	sb.WriteString(statsStr)
 
 	// Sparkline bar
-	sb.WriteString("  ")
-	sb.WriteString(r.formatBar(f.Additions, f.Deletions))
+	if bar := r.formatBar(f.Additions, f.Deletions); bar != "" {
+		sb.WriteString("  ")
+		sb.WriteString(bar)
+	}
+
+	if movement != "" {
+		sb.WriteString("  ")
+		sb.WriteString(movement)
+	}
+	if r.SortBy == SortByFuncs {
+		sb.WriteString(fmt.Sprintf("  %d funcs", f.FunctionsChanged))
+	}
+	sb.WriteString(formatAnnotations(f.Annotations, r.Annotations, r.color))
+	if r.AgeHeat {
+		sb.WriteString(formatAgeSuffix(f.ReplacedAge, r.color))
+	}
 
 	fmt.Fprintln(r.w, sb.String())
 }
 
+// topNPath returns f's path as listed, with ConflictMarker on unmerged
+// paths so they stand out without color too.
+func topNPath(f diff.FileStat) string {
+	if f.IsUnmerged {
+		return ConflictMarker + " " + f.Path
+	}
+	return f.Path
+}
+
 // formatStats returns colored +X -Y string.
 func (r *TopNRenderer) formatStats(add, del int) string {
 	var sb strings.Builder
 
-	// Fixed width: +XXX -XXX (14 chars total)
+	// Fixed width: +XXX -XXX, or +XXXXX -XXXXX for bytes ("+1.5MB")
+	width := 4
+	if r.Bytes {
+		width = 5
+	}
+	blank := strings.Repeat(" ", width+1)
 	if add > 0 {
 		sb.WriteString(r.color(ColorAdd))
-		sb.WriteString(fmt.Sprintf("+%-4d", add))
+		sb.WriteString(fmt.Sprintf("+%-*s", width, formatAmount(add, r.Bytes)))
 		sb.WriteString(r.color(ColorReset))
 	} else {
-		sb.WriteString("     ")
+		sb.WriteString(blank)
 	}
 
 	if del > 0 {
 		sb.WriteString(r.color(ColorDel))
-		sb.WriteString(fmt.Sprintf("-%-4d", del))
+		sb.WriteString(fmt.Sprintf("-%-*s", width, formatAmount(del, r.Bytes)))
 		sb.WriteString(r.color(ColorReset))
 	} else {
-		sb.WriteString("     ")
+		sb.WriteString(blank)
 	}
 
 	return sb.String()
@@ -126,9 +360,13 @@ func (r *TopNRenderer) formatStats(add, del int) string {
 // formatBar creates a sparkline bar with absolute scaling.
 func (r *TopNRenderer) formatBar(add, del int) string {
 	total := add + del
-	filled := filledFromTotal(total)
+	if r.Bytes {
+		kb := (total + 1023) / 1024
+		return r.Bar.Bar(add, del, ScaleLog.Filled(kb, barWidth), barWidth, blockChar(kb), r.color)
+	}
+	filled := r.Bar.Scale.Filled(total, barWidth)
 	block := blockChar(total)
-	return RatioBar(add, del, filled, barWidth, block, r.color)
+	return r.Bar.Bar(add, del, filled, barWidth, block, r.color)
 }
 
 // renderSummary outputs the totals line with hidden file context.
@@ -141,11 +379,11 @@ func (r *TopNRenderer) renderSummary(stats *diff.DiffStats, shown int) {
 
 	// Always show total stats first
 	sb.WriteString(r.color(ColorAdd))
-	sb.WriteString(fmt.Sprintf("+%d", stats.TotalAdd))
+	sb.WriteString("+" + formatAmount(stats.TotalAdd, r.Bytes))
 	sb.WriteString(r.color(ColorReset))
 	sb.WriteString(" ")
 	sb.WriteString(r.color(ColorDel))
-	sb.WriteString(fmt.Sprintf("-%d", stats.TotalDel))
+	sb.WriteString("-" + formatAmount(stats.TotalDel, r.Bytes))
 	sb.WriteString(r.color(ColorReset))
 
 	// File count with hidden context
@@ -154,6 +392,8 @@ func (r *TopNRenderer) renderSummary(stats *diff.DiffStats, shown int) {
 	} else {
 		sb.WriteString(fmt.Sprintf(" (%d files)", stats.TotalFiles))
 	}
+	sb.WriteString(excludedSuffix(stats, r.Excluded))
+	sb.WriteString(sizeSuffix(r.SizeClass))
 
 	fmt.Fprintln(r.w, sb.String())
 }
@@ -173,6 +413,8 @@ func (r *TopNRenderer) sortValue(f diff.FileStat) int {
 		return f.Additions
 	case SortByDels:
 		return f.Deletions
+	case SortByFuncs:
+		return f.FunctionsChanged
 	default:
 		return f.Additions + f.Deletions
 	}
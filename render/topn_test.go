@@ -0,0 +1,190 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestTopNRenderer_Compare(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "a.go", Additions: 100},
+			{Path: "b.go", Additions: 50},
+			{Path: "c.go", Additions: 20},
+			{Path: "d.go", Additions: 5},
+		},
+		TotalFiles: 4, TotalAdd: 175,
+	}
+	prior := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "c.go", Additions: 90},
+			{Path: "x.go", Additions: 80},
+			{Path: "b.go", Additions: 40},
+			{Path: "a.go", Additions: 10},
+		},
+		TotalFiles: 4, TotalAdd: 220,
+	}
+
+	var buf bytes.Buffer
+	r := NewTopNRenderer(&buf, false, 4)
+	r.Compare = prior
+	r.Render(stats)
+
+	want := map[string]string{"a.go": "↑3", "b.go": "↑1", "c.go": "↓2", "d.go": "new"}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if movement, ok := want[fields[0]]; ok {
+			if got := fields[len(fields)-1]; got != movement {
+				t.Errorf("%s: movement %q, want %q (line %q)", fields[0], got, movement, line)
+			}
+			delete(want, fields[0])
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("missing entries %v in:\n%s", want, buf.String())
+	}
+}
+
+func TestTopNRenderer_RankBreaksTiesByPath(t *testing.T) {
+	r := NewTopNRenderer(nil, false, 3)
+	ranked := r.rank([]diff.FileStat{
+		{Path: "z.go", Additions: 5},
+		{Path: "m.go", Additions: 9},
+		{Path: "a.go", Additions: 5},
+	})
+
+	var got []string
+	for _, f := range ranked {
+		got = append(got, f.Path)
+	}
+	if strings.Join(got, ",") != "m.go,a.go,z.go" {
+		t.Errorf("rank = %v, want [m.go a.go z.go]", got)
+	}
+}
+
+func TestTopNRenderer_ConflictMarker(t *testing.T) {
+	var buf bytes.Buffer
+	NewTopNRenderer(&buf, false, 5).Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/merge.go", Additions: 9, IsUnmerged: true},
+			{Path: "src/clean.go", Additions: 3},
+		},
+		TotalFiles: 2, TotalAdd: 12,
+	})
+
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasPrefix(lines[0], ConflictMarker+" src/merge.go  +9") {
+		t.Errorf("unmerged row lacks the marker without color: %q", lines[0])
+	}
+	// Other rows stay aligned with the marked one
+	if !strings.HasPrefix(lines[1], "src/clean.go    +3") {
+		t.Errorf("clean row misaligned: %q", lines[1])
+	}
+}
+
+func TestTopNRenderer_PerDir(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "hot/a.go", Additions: 100},
+			{Path: "hot/b.go", Additions: 90},
+			{Path: "hot/c.go", Additions: 80},
+			{Path: "cold/d.go", Additions: 3},
+			{Path: "main.go", Deletions: 10},
+		},
+		TotalFiles: 5, TotalAdd: 273, TotalDel: 10,
+	}
+
+	var buf bytes.Buffer
+	r := NewTopNRenderer(&buf, false, 2)
+	r.PerDir = true
+	r.Render(stats)
+
+	var got []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			got = append(got, fields[0])
+		}
+	}
+	want := []string{"hot/", "hot/a.go", "hot/b.go", ".", "main.go", "cold/", "cold/d.go", "+273"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("entries = %v, want %v\n%s", got, want, buf.String())
+	}
+	if !strings.Contains(buf.String(), "hot/ +270 -0 (2 of 3 files)") {
+		t.Errorf("missing hot/ header with hidden count:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "(4 of 5 files)") {
+		t.Errorf("summary should count files shown across directories:\n%s", buf.String())
+	}
+}
+
+func TestTopNRenderer_PerDirCompare(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "hot/a.go", Additions: 100},
+			{Path: "hot/b.go", Additions: 90},
+			{Path: "cold/d.go", Additions: 3},
+			{Path: "new/e.go", Additions: 1},
+		},
+		TotalFiles: 4, TotalAdd: 194,
+	}
+	prior := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "cold/d.go", Additions: 50},
+			{Path: "hot/b.go", Additions: 20},
+			{Path: "hot/a.go", Additions: 10},
+		},
+		TotalFiles: 3, TotalAdd: 80,
+	}
+
+	var buf bytes.Buffer
+	r := NewTopNRenderer(&buf, false, 2)
+	r.PerDir = true
+	r.Compare = prior
+	r.Render(stats)
+
+	want := map[string]string{"hot/": "↑1", "hot/a.go": "↑1", "hot/b.go": "↓1", "cold/": "↓1", "new/": "new"}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if movement, ok := want[fields[0]]; ok {
+			if got := fields[len(fields)-1]; got != movement {
+				t.Errorf("%s: movement %q, want %q (line %q)", fields[0], got, movement, line)
+			}
+			delete(want, fields[0])
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("missing entries %v in:\n%s", want, buf.String())
+	}
+}
+
+func TestTopNRenderer_Bytes(t *testing.T) {
+	stats, _ := (&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "hero.png", IsBinary: true, SizeDelta: 600 << 10, HasSizeDelta: true},
+			{Path: "icon.png", IsBinary: true, SizeDelta: -3 << 10, HasSizeDelta: true},
+		},
+		TotalFiles: 2,
+	}).InBytes()
+	var buf bytes.Buffer
+	r := NewTopNRenderer(&buf, false, 5)
+	r.Bytes = true
+	r.Render(stats)
+
+	want := "hero.png  +600KB        ██████████\n" +
+		"icon.png        -3KB    ▒▒░░░░░░░░\n"
+	if !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("got:\n%s\nwant prefix:\n%s", buf.String(), want)
+	}
+	if !strings.Contains(buf.String(), "+600KB -3KB (2 files)") {
+		t.Errorf("summary not in bytes:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,51 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// TrailersRenderer prints git commit-message trailers describing the diff,
+// for appending from a commit-msg hook (git interpret-trailers --trailer ...).
+// Output is never colored. Nothing is printed when there are no changes, so
+// hooks don't add empty trailers.
+// Format:
+//
+//	Diff-Files: 14
+//	Diff-Lines: +412/-88
+//	Diff-Dirs: src,render,docs
+//	Diff-Size: L
+type TrailersRenderer struct {
+	SizeClass diff.SizeClass // Optional Diff-Size trailer
+	w         io.Writer
+}
+
+// NewTrailersRenderer creates a commit trailer renderer.
+// useColor is accepted for signature parity and ignored.
+func NewTrailersRenderer(w io.Writer, useColor bool) *TrailersRenderer {
+	return &TrailersRenderer{w: w}
+}
+
+// Render outputs one trailer per line. Diff-Dirs lists top-level
+// directories by total changes ("." for root-level files).
+func (r *TrailersRenderer) Render(stats *diff.DiffStats) {
+	if stats.TotalFiles == 0 {
+		return
+	}
+
+	dirs := stats.DirStats(1)
+	names := make([]string, len(dirs))
+	for i, d := range dirs {
+		names[i] = d.Path
+	}
+
+	fmt.Fprintf(r.w, "Diff-Files: %d\n", stats.TotalFiles)
+	fmt.Fprintf(r.w, "Diff-Lines: +%d/-%d\n", stats.TotalAdd, stats.TotalDel)
+	fmt.Fprintf(r.w, "Diff-Dirs: %s\n", strings.Join(names, ","))
+	if r.SizeClass != "" {
+		fmt.Fprintf(r.w, "Diff-Size: %s\n", r.SizeClass)
+	}
+}
@@ -0,0 +1,33 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestTrailersRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewTrailersRenderer(&buf, true)
+	r.SizeClass = diff.SizeL
+	r.Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/a.go", Additions: 300, Deletions: 80},
+			{Path: "render/tree.go", Additions: 100},
+			{Path: "README.md", Additions: 12, Deletions: 8},
+		},
+		TotalFiles: 3, TotalAdd: 412, TotalDel: 88,
+	})
+
+	want := "Diff-Files: 3\nDiff-Lines: +412/-88\nDiff-Dirs: src,render,.\nDiff-Size: L\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	NewTrailersRenderer(&buf, false).Render(&diff.DiffStats{})
+	if buf.Len() != 0 {
+		t.Errorf("no changes should print nothing, got %q", buf.String())
+	}
+}
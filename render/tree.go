@@ -1,9 +1,11 @@
 package render
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/kylesnowschwartz/diff-viz/diff"
 )
@@ -15,24 +17,70 @@ type TreeNode struct {
 	IsDir       bool
 	Add         int
 	Del         int
+	NewAdd      int // Additions in new files (subset of Add)
 	IsBinary    bool
-	IsUntracked bool
+	IsNew       bool // Untracked or added (see diff.FileStat.IsNew)
+	IsUnmerged  bool
+	IsDeleted   bool
+	IsLarge     bool // Untracked file too large to count (see diff.FileStat.IsLarge)
+	Annotations map[string]string
+	ReplacedAge time.Duration
 	Children    []*TreeNode
+	Aliases     []string // Paths of directories CollapseSingleChildPaths merged into this node
+	Files       int      // Changed files in the subtree (1 for a file), set by CalcTotals
 }
 
 // TreeRenderer renders diff stats as a hierarchical tree.
+//
+// RTL (experimental) mirrors the layout for right-to-left terminals: lines
+// are right-aligned to Width, connectors point left, and stats come before
+// names.
 type TreeRenderer struct {
-	UseColor bool
-	w        io.Writer
+	UseColor     bool
+	SizeClass    diff.SizeClass      // Optional size label appended to summary
+	Excluded     diff.ExcludedTotals // Excluded categories; the summary adds totals without them
+	DirsOnly     bool                // Show directories with aggregate stats, no files
+	MaxDepth     int                 // Directory levels to show (0 = unlimited)
+	DirDepths    diff.DirDepths      // Per-top-level-directory MaxDepth overrides (see analyze.AutoDepths)
+	MaxPathDepth int                 // Fold directories nested deeper than this (see FoldDeepPaths; 0 = off)
+	Annotations  []string            // Annotation keys to show after file stats
+	AgeHeat      bool                // Color files by FileStat.ReplacedAge
+	Composition  bool                // Split directory additions into edits and new-file lines
+	RTL          bool                // Mirror the layout right-to-left
+	Width        int                 // Line width RTL output aligns to (default 80)
+	CleanupRatio float64             // Mark directories with this share of deletions (see IsCleanup; 0 = off)
+	Bytes        bool                // Counts are bytes (see diff.DiffStats.InBytes), shown with units
+	w            io.Writer
 }
 
 // NewTreeRenderer creates a tree renderer.
 func NewTreeRenderer(w io.Writer, useColor bool) *TreeRenderer {
-	return &TreeRenderer{UseColor: useColor, w: w}
+	return &TreeRenderer{UseColor: useColor, CleanupRatio: DefaultCleanupRatio, w: w}
 }
 
 // Render outputs the diff stats as a tree.
 func (r *TreeRenderer) Render(stats *diff.DiffStats) {
+	if !r.RTL {
+		r.render(stats)
+		return
+	}
+	// Render into a buffer through a copy so concurrent Renders stay safe,
+	// then push every line to the right edge
+	var buf bytes.Buffer
+	mirrored := *r
+	mirrored.w = &buf
+	mirrored.render(stats)
+	width := r.Width
+	if width <= 0 {
+		width = treeRTLDefaultWidth
+	}
+	writeRightAligned(r.w, buf.String(), width)
+}
+
+// treeRTLDefaultWidth is the RTL line width when Width is unset.
+const treeRTLDefaultWidth = 80
+
+func (r *TreeRenderer) render(stats *diff.DiffStats) {
 	if stats.TotalFiles == 0 {
 		fmt.Fprintln(r.w, "No changes")
 		return
@@ -40,6 +88,11 @@ func (r *TreeRenderer) Render(stats *diff.DiffStats) {
 
 	// Build tree from flat file list
 	root := r.buildTree(stats.Files)
+	CalcTotals(root)
+	TruncateDirDepths(root, r.DirDepths, r.MaxDepth)
+	if r.DirsOnly {
+		PruneFiles(root)
+	}
 
 	// Render each top-level node
 	for i, child := range root.Children {
@@ -47,53 +100,88 @@ func (r *TreeRenderer) Render(stats *diff.DiffStats) {
 		r.renderNode(child, isLast, nil)
 	}
 
+	writeConflicts(r.w, stats, r.color)
+	writeDeleted(r.w, stats, r.Bytes, r.color)
+	writeBinarySummary(r.w, stats, r.color)
+	if r.AgeHeat {
+		writeAgeLegend(r.w, r.color)
+	}
+
 	// Summary line
 	fmt.Fprintln(r.w)
-	fmt.Fprintf(r.w, "%s+%d%s %s-%d%s in %d files\n",
-		r.color(ColorAdd), stats.TotalAdd, r.color(ColorReset),
-		r.color(ColorDel), stats.TotalDel, r.color(ColorReset),
-		stats.TotalFiles)
+	fmt.Fprintf(r.w, "%s+%s%s %s-%s%s in %d files%s%s\n",
+		r.color(ColorAdd), formatAmount(stats.TotalAdd, r.Bytes), r.color(ColorReset),
+		r.color(ColorDel), formatAmount(stats.TotalDel, r.Bytes), r.color(ColorReset),
+		stats.TotalFiles, excludedSuffix(stats, r.Excluded), sizeSuffix(r.SizeClass))
 }
 
 // buildTree constructs a tree from flat file paths.
 func (r *TreeRenderer) buildTree(files []diff.FileStat) *TreeNode {
-	return BuildTreeFromFiles(files)
+	root := BuildTreeFromFiles(files)
+	FoldDeepPaths(root, r.MaxPathDepth)
+	return root
 }
 
 // renderNode outputs a single tree node with proper prefixes.
 // parentIsLast tracks whether ancestors were last children (for prefix rendering).
 func (r *TreeRenderer) renderNode(node *TreeNode, isLast bool, parentIsLast []bool) {
-	// Build prefix from parent state
-	var sb strings.Builder
-	for _, wasLast := range parentIsLast {
-		if wasLast {
-			sb.WriteString("    ")
-		} else {
-			sb.WriteString("│   ")
-		}
+	// Off-page lines of a paged render only need counting
+	if page, ok := r.w.(*PageWriter); ok && !page.Visible() {
+		io.WriteString(r.w, "\n")
+		r.renderChildren(node, isLast, parentIsLast)
+		return
 	}
 
-	// Add connector
-	if isLast {
-		sb.WriteString("└── ")
-	} else {
-		sb.WriteString("├── ")
-	}
+	prefix := treePrefix(parentIsLast, isLast, r.RTL)
 
 	// Render name with color
-	if node.IsDir {
-		fmt.Fprintf(r.w, "%s%s%s/%s\n", sb.String(), r.color(ColorDir), node.Name, r.color(ColorReset))
+	if node.IsDir && (r.DirsOnly || len(node.Children) == 0) {
+		// Directory with aggregated stats (dirs-only, or cut off by MaxDepth)
+		r.writeLine(prefix, r.dirName(node), r.formatStats(node))
+	} else if node.IsDir {
+		if r.RTL {
+			fmt.Fprintf(r.w, "%s%s\n", r.dirName(node), prefix)
+		} else {
+			fmt.Fprintf(r.w, "%s%s\n", prefix, r.dirName(node))
+		}
 	} else {
-		// File with stats - yellow for untracked, gray for tracked
+		// File with stats - yellow for new, gray for existing
 		fileColor := ColorFile
-		if node.IsUntracked {
+		name := node.Name
+		if node.IsNew {
 			fileColor = ColorNew
 		}
-		stats := r.formatStats(node)
-		fmt.Fprintf(r.w, "%s%s%s%s %s\n", sb.String(), r.color(fileColor), node.Name, r.color(ColorReset), stats)
+		if node.IsDeleted {
+			fileColor = ColorDel
+		}
+		if heat := AgeHeatColor(node.ReplacedAge); r.AgeHeat && heat != "" {
+			fileColor = heat
+		}
+		if node.IsUnmerged {
+			fileColor = ColorConflict
+			name = ConflictMarker + " " + name
+		}
+		stats := r.formatStats(node) + formatAnnotations(node.Annotations, r.Annotations, r.color)
+		if r.AgeHeat {
+			stats += formatAgeSuffix(node.ReplacedAge, r.color)
+		}
+		r.writeLine(prefix, r.color(fileColor)+name+r.color(ColorReset), stats)
+	}
+
+	r.renderChildren(node, isLast, parentIsLast)
+}
+
+// dirName colors a directory name, marking it when its changes are mostly
+// deletions. Directory totals must be set (see CalcTotals).
+func (r *TreeRenderer) dirName(node *TreeNode) string {
+	if IsCleanup(node.Add, node.Del, r.CleanupRatio) {
+		return r.color(ColorCleanup) + CleanupMarker + " " + node.Name + "/" + r.color(ColorReset)
 	}
+	return r.color(ColorDir) + node.Name + "/" + r.color(ColorReset)
+}
 
-	// Render children
+// renderChildren renders node's children one level deeper.
+func (r *TreeRenderer) renderChildren(node *TreeNode, isLast bool, parentIsLast []bool) {
 	newParentIsLast := append(parentIsLast, isLast)
 	for i, child := range node.Children {
 		childIsLast := i == len(node.Children)-1
@@ -101,18 +189,80 @@ func (r *TreeRenderer) renderNode(node *TreeNode, isLast bool, parentIsLast []bo
 	}
 }
 
+// writeLine writes a node with stats: prefix, name, stats left to right, or
+// stats, name, mirrored prefix in RTL.
+func (r *TreeRenderer) writeLine(prefix, name, stats string) {
+	if r.RTL {
+		fmt.Fprintf(r.w, "%s %s%s\n", stats, name, prefix)
+		return
+	}
+	fmt.Fprintf(r.w, "%s%s %s\n", prefix, name, stats)
+}
+
+// treePrefix builds the indentation and connector for a node. In RTL the
+// pieces are mirrored and ordered right to left, outermost ancestor last.
+func treePrefix(parentIsLast []bool, isLast, rtl bool) string {
+	pieces := make([]string, 0, len(parentIsLast)+1)
+	for _, wasLast := range parentIsLast {
+		if wasLast {
+			pieces = append(pieces, "    ")
+		} else {
+			pieces = append(pieces, "│   ")
+		}
+	}
+	if isLast {
+		pieces = append(pieces, "└── ")
+	} else {
+		pieces = append(pieces, "├── ")
+	}
+	if !rtl {
+		return strings.Join(pieces, "")
+	}
+
+	var sb strings.Builder
+	for i := len(pieces) - 1; i >= 0; i-- {
+		sb.WriteString(treeMirror[pieces[i]])
+	}
+	return sb.String()
+}
+
+// treeMirror maps each prefix piece to its right-to-left form.
+var treeMirror = map[string]string{
+	"    ": "    ",
+	"│   ": "   │",
+	"├── ": " ──┤",
+	"└── ": " ──┘",
+}
+
+// writeRightAligned writes text with each non-empty line padded on the left
+// to width columns. Lines already wider are written as they are.
+func writeRightAligned(w io.Writer, text string, width int) {
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		if pad := width - VisibleWidth(line); line != "" && pad > 0 {
+			line = strings.Repeat(" ", pad) + line
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
 // formatStats formats the +N -M stats for a file.
 func (r *TreeRenderer) formatStats(node *TreeNode) string {
-	if node.IsBinary {
+	// Sizes in bytes cover binary and large files too
+	if node.IsBinary && (!r.Bytes || node.Add+node.Del == 0) {
 		return "(binary)"
 	}
+	if node.IsLarge && !r.Bytes {
+		return "(large)"
+	}
 
 	var parts []string
-	if node.Add > 0 {
-		parts = append(parts, fmt.Sprintf("%s+%d%s", r.color(ColorAdd), node.Add, r.color(ColorReset)))
+	if node.IsDir && r.Composition && node.NewAdd > 0 && !r.Bytes {
+		parts = append(parts, NewCodeCounts(node.Add, node.NewAdd, r.color))
+	} else if node.Add > 0 {
+		parts = append(parts, fmt.Sprintf("%s+%s%s", r.color(ColorAdd), formatAmount(node.Add, r.Bytes), r.color(ColorReset)))
 	}
 	if node.Del > 0 {
-		parts = append(parts, fmt.Sprintf("%s-%d%s", r.color(ColorDel), node.Del, r.color(ColorReset)))
+		parts = append(parts, fmt.Sprintf("%s-%s%s", r.color(ColorDel), formatAmount(node.Del, r.Bytes), r.color(ColorReset)))
 	}
 	return strings.Join(parts, " ")
 }
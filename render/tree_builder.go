@@ -2,6 +2,7 @@ package render
 
 import (
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
@@ -56,8 +57,16 @@ func InsertPath(root *TreeNode, file diff.FileStat) {
 		if isFile {
 			child.Add = file.Additions
 			child.Del = file.Deletions
+			if file.IsNew() {
+				child.NewAdd = file.Additions
+			}
 			child.IsBinary = file.IsBinary
-			child.IsUntracked = file.IsUntracked
+			child.IsNew = file.IsNew()
+			child.IsUnmerged = file.IsUnmerged
+			child.IsDeleted = file.IsDeleted()
+			child.IsLarge = file.IsLarge
+			child.Annotations = file.Annotations
+			child.ReplacedAge = file.ReplacedAge
 		}
 
 		current = child
@@ -66,15 +75,20 @@ func InsertPath(root *TreeNode, file diff.FileStat) {
 
 // CalcTotals recursively calculates add/del totals for directories.
 // Returns the total additions and deletions for the subtree.
+// Directory NewAdd and Files totals are summed along the way.
 func CalcTotals(node *TreeNode) (add, del int) {
 	if !node.IsDir {
+		node.Files = 1
 		return node.Add, node.Del
 	}
 
+	node.NewAdd, node.Files = 0, 0
 	for _, child := range node.Children {
 		childAdd, childDel := CalcTotals(child)
 		add += childAdd
 		del += childDel
+		node.NewAdd += child.NewAdd
+		node.Files += child.Files
 	}
 
 	node.Add = add
@@ -82,8 +96,149 @@ func CalcTotals(node *TreeNode) (add, del int) {
 	return add, del
 }
 
+// PruneFiles removes file nodes recursively, leaving only directories.
+// Call after CalcTotals so directory totals still include their files.
+func PruneFiles(node *TreeNode) {
+	dirs := node.Children[:0]
+	for _, child := range node.Children {
+		if child.IsDir {
+			PruneFiles(child)
+			dirs = append(dirs, child)
+		}
+	}
+	node.Children = dirs
+}
+
+// TruncateDepth removes everything below depth directory levels (top-level
+// directories are level 1), leaving directories at that level as leaves that
+// keep their aggregate totals. Call CalcTotals first. depth <= 0 is a no-op.
+func TruncateDepth(node *TreeNode, depth int) {
+	if depth <= 0 {
+		return
+	}
+	for _, child := range node.Children {
+		if !child.IsDir {
+			continue
+		}
+		if depth == 1 {
+			child.Children = nil
+		} else {
+			TruncateDepth(child, depth-1)
+		}
+	}
+}
+
+// TruncateDirDepths is TruncateDepth with a depth per top-level directory;
+// directories without an entry (or a nil depths) use fallback.
+func TruncateDirDepths(root *TreeNode, depths diff.DirDepths, fallback int) {
+	if depths == nil {
+		TruncateDepth(root, fallback)
+		return
+	}
+	for _, child := range root.Children {
+		if !child.IsDir {
+			continue
+		}
+		switch depth := depths.For(child.Path, fallback); {
+		case depth == 1:
+			child.Children = nil
+		case depth > 1:
+			TruncateDepth(child, depth-1)
+		}
+	}
+}
+
+// FoldDeepPaths limits directory nesting below maxDepth levels (top-level
+// directories are level 1). Each directory at that level keeps its files and
+// gets every deeper directory that holds files as a direct child, named by
+// its path below the directory with skipped components shown as "…/": a
+// 14-level Java package becomes "…/billing/pdf" under src/main/java/com.
+// Names keep just enough trailing components to tell siblings apart.
+// Call before CalcTotals. maxDepth <= 0 is a no-op.
+func FoldDeepPaths(node *TreeNode, maxDepth int) {
+	if maxDepth <= 0 {
+		return
+	}
+	for _, child := range node.Children {
+		if !child.IsDir {
+			continue
+		}
+		if maxDepth == 1 {
+			foldSubdirs(child)
+		} else {
+			FoldDeepPaths(child, maxDepth-1)
+		}
+	}
+}
+
+// foldSubdirs flattens dir's subdirectories into one level (see FoldDeepPaths).
+func foldSubdirs(dir *TreeNode) {
+	var files, folded []*TreeNode
+	for _, child := range dir.Children {
+		if child.IsDir {
+			folded = collectFileDirs(child, folded)
+		} else {
+			files = append(files, child)
+		}
+	}
+
+	rels := make([][]string, len(folded))
+	for i, d := range folded {
+		rels[i] = strings.Split(strings.TrimPrefix(d.Path, dir.Path+"/"), "/")
+	}
+	for i, d := range folded {
+		parts := rels[i]
+		keep := 1
+		for keep < len(parts) && !uniqueSuffix(rels, i, keep) {
+			keep++
+		}
+		d.Name = strings.Join(parts[len(parts)-keep:], "/")
+		if keep < len(parts) {
+			d.Name = "…/" + d.Name
+		}
+	}
+
+	sort.SliceStable(folded, func(i, j int) bool { return folded[i].Path < folded[j].Path })
+	dir.Children = append(files, folded...)
+}
+
+// collectFileDirs appends each directory under (and including) d that holds
+// files, with only its files as children.
+func collectFileDirs(d *TreeNode, out []*TreeNode) []*TreeNode {
+	var files, subdirs []*TreeNode
+	for _, child := range d.Children {
+		if child.IsDir {
+			subdirs = append(subdirs, child)
+		} else {
+			files = append(files, child)
+		}
+	}
+	if len(files) > 0 {
+		d.Children = files
+		out = append(out, d)
+	}
+	for _, sub := range subdirs {
+		out = collectFileDirs(sub, out)
+	}
+	return out
+}
+
+// uniqueSuffix reports whether the last n components of rels[i] differ from
+// those of every other entry.
+func uniqueSuffix(rels [][]string, i, n int) bool {
+	suffix := strings.Join(rels[i][len(rels[i])-n:], "/")
+	for j, other := range rels {
+		if j != i && len(other) >= n && strings.Join(other[len(other)-n:], "/") == suffix {
+			return false
+		}
+	}
+	return true
+}
+
 // CollapseSingleChildPaths merges chains of single-child directories.
 // e.g., a/b/c/d where each has one child becomes "a/b/c/d" as one node.
+// The merged node takes the deepest directory's Path and keeps the paths of
+// the directories above it in Aliases, so FindNode still finds them.
 func CollapseSingleChildPaths(node *TreeNode) {
 	for i, child := range node.Children {
 		// First, recursively collapse children
@@ -94,6 +249,7 @@ func CollapseSingleChildPaths(node *TreeNode) {
 		for child.IsDir && len(child.Children) == 1 && child.Children[0].IsDir {
 			grandchild := child.Children[0]
 			child.Name = child.Name + "/" + grandchild.Name
+			child.Aliases = append(append(child.Aliases, child.Path), grandchild.Aliases...)
 			child.Path = grandchild.Path
 			child.Children = grandchild.Children
 			// Note: Add/Del already calculated correctly since they propagate up
@@ -103,10 +259,10 @@ func CollapseSingleChildPaths(node *TreeNode) {
 	}
 }
 
-// FindNode recursively finds a node by path in the tree.
-// Returns nil if not found.
+// FindNode recursively finds a node by path in the tree, matching a
+// collapsed node by any of its Aliases too. Returns nil if not found.
 func FindNode(node *TreeNode, path string) *TreeNode {
-	if node.Path == path {
+	if node.Path == path || slices.Contains(node.Aliases, path) {
 		return node
 	}
 	for _, child := range node.Children {
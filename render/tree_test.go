@@ -0,0 +1,330 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestTreeRenderer_DeletedSection(t *testing.T) {
+	var buf bytes.Buffer
+	NewTreeRenderer(&buf, false).Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/old.go", Deletions: 40, Status: "D"},
+			{Path: "src/main.go", Additions: 3, Status: "M"},
+		},
+		TotalFiles: 2, TotalAdd: 3, TotalDel: 40,
+	})
+	out := buf.String()
+
+	if !strings.Contains(out, DeletedMarker+" 1 deleted file (-40 lines):\n  src/old.go -40\n") {
+		t.Errorf("missing deleted section in:\n%s", out)
+	}
+
+	// No section without deletions
+	buf.Reset()
+	NewTreeRenderer(&buf, false).Render(&diff.DiffStats{
+		Files:      []diff.FileStat{{Path: "a.go", Additions: 1}},
+		TotalFiles: 1, TotalAdd: 1,
+	})
+	if strings.Contains(buf.String(), "deleted") {
+		t.Errorf("unexpected deleted section in:\n%s", buf.String())
+	}
+}
+
+func TestTreeRenderer_BinarySummary(t *testing.T) {
+	var buf bytes.Buffer
+	NewTreeRenderer(&buf, false).Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "img/a.png", IsBinary: true, SizeDelta: 200 << 10, HasSizeDelta: true},
+			{Path: "img/b.png", IsBinary: true, SizeDelta: 40 << 10, HasSizeDelta: true},
+			{Path: "dist/app.zip", IsBinary: true, SizeDelta: 2 << 20, HasSizeDelta: true},
+			{Path: "main.go", Additions: 1},
+		},
+		TotalFiles: 4, TotalAdd: 1,
+	})
+
+	if want := "\nbinary: images: 2 (+240KB), archives: 1 (+2MB)\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("missing %q in:\n%s", want, buf.String())
+	}
+}
+
+func TestFormatByteDelta(t *testing.T) {
+	tests := map[int64]string{
+		0:                "+0B",
+		512:              "+512B",
+		-1536:            "-1.5KB",
+		240 << 10:        "+240KB",
+		2 << 20:          "+2MB",
+		-(3<<30 + 1<<29): "-3.5GB",
+	}
+	for n, want := range tests {
+		if got := FormatByteDelta(n); got != want {
+			t.Errorf("FormatByteDelta(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestTreeRenderer_ExcludedSummary(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "api/service.pb.go", Additions: 210, Deletions: 40},
+			{Path: "main.go", Additions: 200, Deletions: 48},
+		},
+		TotalFiles: 2, TotalAdd: 410, TotalDel: 88,
+	}
+	var buf bytes.Buffer
+	r := NewTreeRenderer(&buf, false)
+	r.Excluded = stats.Excluded([]diff.Exclusion{{Name: "generated", Patterns: []string{"*.pb.go"}}})
+	r.Render(stats)
+
+	if want := "+410 -88 in 2 files (excl. generated: +200 -48)\n"; !strings.HasSuffix(buf.String(), want) {
+		t.Errorf("summary = %q, want suffix %q", buf.String(), want)
+	}
+}
+
+func TestTreeRenderer_Cleanup(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "old/a.go", Additions: 1, Deletions: 40},
+			{Path: "old/b.go", Deletions: 20},
+			{Path: "src/c.go", Additions: 5, Deletions: 10},
+		},
+		TotalFiles: 3, TotalAdd: 6, TotalDel: 70,
+	}
+
+	var buf bytes.Buffer
+	NewTreeRenderer(&buf, false).Render(stats)
+	if !strings.Contains(buf.String(), CleanupMarker+" old/") {
+		t.Errorf("deletion-only dir not marked:\n%s", buf.String())
+	}
+	if strings.Contains(buf.String(), CleanupMarker+" src/") {
+		t.Errorf("mixed dir marked:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	r := NewTreeRenderer(&buf, false)
+	r.CleanupRatio = 0
+	r.Render(stats)
+	if strings.Contains(buf.String(), CleanupMarker) {
+		t.Errorf("CleanupRatio 0 still marks dirs:\n%s", buf.String())
+	}
+}
+
+func TestIsCleanup(t *testing.T) {
+	tests := []struct {
+		add, del int
+		ratio    float64
+		want     bool
+	}{
+		{0, 10, 0.9, true},
+		{1, 9, 0.9, true},
+		{2, 9, 0.9, false},
+		{0, 0, 0.9, false},
+		{0, 10, 0, false},
+	}
+	for _, tt := range tests {
+		if got := IsCleanup(tt.add, tt.del, tt.ratio); got != tt.want {
+			t.Errorf("IsCleanup(%d, %d, %v) = %v, want %v", tt.add, tt.del, tt.ratio, got, tt.want)
+		}
+	}
+}
+
+func TestTreeRenderer_RTL(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewTreeRenderer(&buf, false)
+	r.RTL = true
+	r.Width = 30
+	r.Render(&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/a.go", Additions: 3},
+			{Path: "src/lib/b.go", Deletions: 12},
+		},
+		TotalFiles: 2, TotalAdd: 3, TotalDel: 12,
+	})
+
+	// Right-aligned to 30 columns; %30s pads by runes, one column each here
+	want := []string{
+		fmt.Sprintf("%30s", "src/ ──┘"),
+		fmt.Sprintf("%30s", "+3 a.go ──┤    "),
+		fmt.Sprintf("%30s", "✂ lib/ ──┘    "),
+		fmt.Sprintf("%30s", "-12 b.go ──┘        "),
+		"",
+		fmt.Sprintf("%30s", "+3 -12 in 2 files"),
+	}
+	if got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("RTL tree =\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCollapseSingleChildPaths_Aliases(t *testing.T) {
+	root := BuildTreeFromFiles([]diff.FileStat{
+		{Path: "a/b/c/x.go", Additions: 1},
+		{Path: "a/b/c/y.go", Additions: 2},
+		{Path: "z.go", Additions: 3},
+	})
+	CollapseSingleChildPaths(root)
+
+	collapsed := root.Children[0]
+	if collapsed.Name != "a/b/c" || collapsed.Path != "a/b/c" {
+		t.Fatalf("collapsed node = %q (%q), want a/b/c", collapsed.Name, collapsed.Path)
+	}
+	for _, p := range []string{"a", "a/b", "a/b/c"} {
+		if got := FindNode(root, p); got != collapsed {
+			t.Errorf("FindNode(%q) = %v, want the collapsed a/b/c node", p, got)
+		}
+	}
+	if got := FindNode(root, "a/b/c/x.go"); got == nil || got.Add != 1 {
+		t.Errorf("FindNode(a/b/c/x.go) = %v", got)
+	}
+	if got := FindNode(root, "a/c"); got != nil {
+		t.Errorf("FindNode(a/c) = %v, want nil", got)
+	}
+}
+
+func TestFoldDeepPaths(t *testing.T) {
+	root := BuildTreeFromFiles([]diff.FileStat{
+		{Path: "src/com/App.java", Additions: 1},
+		{Path: "src/com/acme/billing/invoice/pdf/R.java", Additions: 2},
+		{Path: "src/com/acme/billing/tax/T.java", Additions: 3},
+		{Path: "src/com/acme/util/pdf/U.java", Additions: 4},
+		{Path: "src/com/x/Y.java", Additions: 5},
+	})
+	FoldDeepPaths(root, 2)
+	CalcTotals(root)
+
+	com := FindNode(root, "src/com")
+	var names []string
+	for _, c := range com.Children {
+		names = append(names, c.Name)
+	}
+	want := []string{"App.java", "…/invoice/pdf", "…/tax", "…/util/pdf", "x"}
+	if strings.Join(names, " ") != strings.Join(want, " ") {
+		t.Errorf("src/com children = %q, want %q", names, want)
+	}
+	if pdf := FindNode(root, "src/com/acme/billing/invoice/pdf"); pdf == nil || len(pdf.Children) != 1 || pdf.Add != 2 {
+		t.Errorf("folded invoice/pdf node = %+v", pdf)
+	}
+	if com.Add != 15 {
+		t.Errorf("src/com total = %d, want 15", com.Add)
+	}
+
+	// 0 leaves the tree alone
+	root = BuildTreeFromFiles([]diff.FileStat{{Path: "a/b/c/d.go"}})
+	FoldDeepPaths(root, 0)
+	if FindNode(root, "a/b/c").Name != "c" {
+		t.Error("FoldDeepPaths(0) changed the tree")
+	}
+}
+
+func TestTreeRenderer_Bytes(t *testing.T) {
+	stats, _ := (&diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "img/a.png", IsBinary: true, SizeDelta: 200 << 10, HasSizeDelta: true},
+			{Path: "img/b.png", IsBinary: true, SizeDelta: -1536, HasSizeDelta: true},
+			{Path: "main.go", Additions: 1, SizeDelta: 12, HasSizeDelta: true},
+		},
+		TotalFiles: 3, TotalAdd: 1,
+	}).InBytes()
+	var buf bytes.Buffer
+	r := NewTreeRenderer(&buf, false)
+	r.Bytes = true
+	r.Render(stats)
+
+	for _, want := range []string{"a.png +200KB\n", "b.png -1.5KB\n", "main.go +12B\n", "+200KB -1.5KB in 3 files"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestPruneFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []diff.FileStat
+		want  string // Remaining nodes as "path +add -del", depth-first
+	}{
+		{
+			name:  "root files only",
+			files: []diff.FileStat{{Path: "a.go", Additions: 1}, {Path: "b.go", Deletions: 2}},
+			want:  "",
+		},
+		{
+			name: "files under directories",
+			files: []diff.FileStat{
+				{Path: "src/a.go", Additions: 3},
+				{Path: "src/lib/b.go", Additions: 2, Deletions: 1},
+				{Path: "README.md", Additions: 5},
+			},
+			want: "src +5 -1, src/lib +2 -1",
+		},
+		{
+			name: "directory holding only subdirectories",
+			files: []diff.FileStat{
+				{Path: "pkg/x/a.go", Additions: 1},
+				{Path: "pkg/y/b.go", Deletions: 4},
+			},
+			want: "pkg +1 -4, pkg/x +1 -0, pkg/y +0 -4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := BuildTreeFromFiles(tt.files)
+			CalcTotals(root)
+			PruneFiles(root)
+
+			var got []string
+			var walk func(*TreeNode)
+			walk = func(n *TreeNode) {
+				for _, c := range n.Children {
+					if !c.IsDir {
+						t.Errorf("file %s left after PruneFiles", c.Path)
+					}
+					got = append(got, fmt.Sprintf("%s +%d -%d", c.Path, c.Add, c.Del))
+					walk(c)
+				}
+			}
+			walk(root)
+			if s := strings.Join(got, ", "); s != tt.want {
+				t.Errorf("got %q, want %q", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestTreeRenderer_DirsOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxDepth int
+		want     string
+	}{
+		{
+			name: "all levels",
+			want: "├── docs/ +3\n└── src/ +16 -3\n    └── lib/ +15 -2\n        └── deep/ +10 -2\n",
+		},
+		{
+			name:     "with MaxDepth",
+			maxDepth: 1,
+			want:     "├── docs/ +3\n└── src/ +16 -3\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			r := NewTreeRenderer(&buf, false)
+			r.DirsOnly = true
+			r.MaxDepth = tt.maxDepth
+			r.Render(depthTestStats())
+
+			// Directory totals include their files; the summary still counts every file
+			if want := tt.want + "\n+19 -3 in 4 files\n"; buf.String() != want {
+				t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+			}
+		})
+	}
+}
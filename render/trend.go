@@ -0,0 +1,99 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+// trendTimeFormat is the timestamp layout for trend rows.
+const trendTimeFormat = "2006-01-02 15:04"
+
+// TrendRenderer charts how a tracked range's size changed across snapshots.
+// Unlike the mode renderers it consumes history entries, not DiffStats.
+type TrendRenderer struct {
+	UseColor bool
+	Width    int // Bar width in characters
+	w        io.Writer
+}
+
+// NewTrendRenderer creates a trend renderer.
+func NewTrendRenderer(w io.Writer, useColor bool) *TrendRenderer {
+	return &TrendRenderer{UseColor: useColor, Width: 20, w: w}
+}
+
+// RenderTrend outputs one row per snapshot with a bar scaled to the largest,
+// followed by the overall growth since the first snapshot.
+func (r *TrendRenderer) RenderTrend(label string, entries []diff.HistoryEntry) {
+	if len(entries) == 0 {
+		fmt.Fprintf(r.w, "No history for %q (record with: git-diff-tree track --label %s)\n", label, label)
+		return
+	}
+
+	maxTotal := 0
+	for _, e := range entries {
+		maxTotal = max(maxTotal, e.Adds+e.Dels)
+	}
+
+	for i, e := range entries {
+		total := e.Adds + e.Dels
+		// Any change gets at least one cell; an empty snapshot gets none
+		filled := 0
+		if total > 0 {
+			filled = max((total*r.Width)/maxTotal, 1)
+		}
+
+		var sb strings.Builder
+		sb.WriteString(e.Time.Local().Format(trendTimeFormat))
+		sb.WriteString("  ")
+		sb.WriteString(shortSHA(e.Head))
+		sb.WriteString("  ")
+		sb.WriteString(fmt.Sprintf("%s+%-5d%s %s-%-5d%s", r.color(ColorAdd), e.Adds, r.color(ColorReset), r.color(ColorDel), e.Dels, r.color(ColorReset)))
+		sb.WriteString(" ")
+		sb.WriteString(RatioBar(e.Adds, e.Dels, filled, r.Width, BlockMedium, r.color))
+		if i > 0 {
+			sb.WriteString(" ")
+			sb.WriteString(r.formatDelta(total - (entries[i-1].Adds + entries[i-1].Dels)))
+		}
+		fmt.Fprintln(r.w, sb.String())
+	}
+
+	first, last := entries[0], entries[len(entries)-1]
+	fmt.Fprintln(r.w)
+	fmt.Fprintf(r.w, "%s: %d snapshots, %d -> %d lines (%s), %s -> %s\n",
+		label, len(entries), first.Adds+first.Dels, last.Adds+last.Dels,
+		r.formatDelta((last.Adds+last.Dels)-(first.Adds+first.Dels)), first.Size, last.Size)
+}
+
+// formatDelta formats a signed change in total lines.
+func (r *TrendRenderer) formatDelta(delta int) string {
+	switch {
+	case delta > 0:
+		return fmt.Sprintf("%s▲%d%s", r.color(ColorDel), delta, r.color(ColorReset))
+	case delta < 0:
+		return fmt.Sprintf("%s▼%d%s", r.color(ColorAdd), -delta, r.color(ColorReset))
+	default:
+		return "="
+	}
+}
+
+// color returns the ANSI code if color is enabled.
+func (r *TrendRenderer) color(code string) string {
+	if r.UseColor {
+		return code
+	}
+	return ""
+}
+
+// shortSHA abbreviates a commit hash to 7 characters.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	if sha == "" {
+		return "-------"
+	}
+	return sha
+}
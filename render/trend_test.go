@@ -0,0 +1,40 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestTrendRenderer(t *testing.T) {
+	t0 := time.Date(2025, 6, 1, 9, 30, 0, 0, time.Local)
+	var buf bytes.Buffer
+	r := NewTrendRenderer(&buf, false)
+	r.Width = 10
+	r.RenderTrend("pr-1", []diff.HistoryEntry{
+		{Time: t0, Head: "aaaaaaaaaa", Adds: 20, Size: "S"},
+		{Time: t0.Add(time.Hour), Head: "bbbbbbbbbb", Adds: 30, Dels: 10, Size: "M"},
+		{Time: t0.Add(2 * time.Hour), Size: "XS"}, // Everything reverted
+	})
+
+	want := "" +
+		"2025-06-01 09:30  aaaaaaa  +20    -0     ▓▓▓▓▓░░░░░\n" +
+		"2025-06-01 10:30  bbbbbbb  +30    -10    ▓▓▓▓▓▓▓▓▓▓ ▲20\n" +
+		"2025-06-01 11:30  -------  +0     -0     ░░░░░░░░░░ ▼40\n" +
+		"\n" +
+		"pr-1: 3 snapshots, 20 -> 0 lines (▼20), S -> XS\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestTrendRenderer_NoHistory(t *testing.T) {
+	var buf bytes.Buffer
+	NewTrendRenderer(&buf, false).RenderTrend("pr-1", nil)
+	if !strings.Contains(buf.String(), "git-diff-tree track --label pr-1") {
+		t.Errorf("missing track hint in %q", buf.String())
+	}
+}
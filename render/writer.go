@@ -0,0 +1,226 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// StripANSIWriter wraps an io.Writer and removes ANSI escape sequences
+// (CSI like "\033[32m" and OSC like "\033]0;title\a") from everything written.
+// Escape sequences split across Write calls are handled correctly.
+//
+// Renderers only write through their injected io.Writer, so wrapping it
+// strips color from any renderer without changing its configuration:
+//
+//	r := NewTreeRenderer(NewStripANSIWriter(logFile), true)
+type StripANSIWriter struct {
+	w     io.Writer
+	state ansiState
+}
+
+// ansiState tracks progress through an escape sequence.
+type ansiState int
+
+const (
+	ansiText    ansiState = iota // Plain text
+	ansiEscape                   // Saw ESC
+	ansiCSI                      // Inside ESC [ ... final byte
+	ansiOSC                      // Inside ESC ] ... BEL or ST
+	ansiOSCEsc                   // Saw ESC inside OSC (possible ST terminator)
+	ansiCharset                  // ESC ( or ESC ) charset designation, one more byte
+)
+
+// NewStripANSIWriter returns a writer that strips ANSI escapes before writing to w.
+func NewStripANSIWriter(w io.Writer) *StripANSIWriter {
+	return &StripANSIWriter{w: w}
+}
+
+// Write strips escape sequences from p and writes the remaining text.
+// Returns len(p) on success so callers see a full write.
+func (s *StripANSIWriter) Write(p []byte) (int, error) {
+	out := make([]byte, 0, len(p))
+	for _, b := range p {
+		switch s.state {
+		case ansiText:
+			if b == 0x1b {
+				s.state = ansiEscape
+			} else {
+				out = append(out, b)
+			}
+		case ansiEscape:
+			switch b {
+			case '[':
+				s.state = ansiCSI
+			case ']':
+				s.state = ansiOSC
+			case '(', ')':
+				s.state = ansiCharset
+			default:
+				s.state = ansiText // Two-byte sequence (e.g., ESC c)
+			}
+		case ansiCSI:
+			if b >= 0x40 && b <= 0x7e {
+				s.state = ansiText
+			}
+		case ansiOSC:
+			if b == 0x07 {
+				s.state = ansiText
+			} else if b == 0x1b {
+				s.state = ansiOSCEsc
+			}
+		case ansiOSCEsc:
+			if b == '\\' {
+				s.state = ansiText
+			} else {
+				s.state = ansiOSC
+			}
+		case ansiCharset:
+			s.state = ansiText
+		}
+	}
+
+	if len(out) > 0 {
+		if _, err := s.w.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// StripANSI removes ANSI escape sequences from s.
+func StripANSI(s string) string {
+	var buf bytes.Buffer
+	NewStripANSIWriter(&buf).Write([]byte(s))
+	return buf.String()
+}
+
+// LineLimitWriter passes at most Max lines through to w. When more arrive,
+// the last allowed line is replaced by a summary of what was dropped, so
+// output including the summary never exceeds Max lines. Close writes the
+// held line or the summary and must be called once writing is done.
+type LineLimitWriter struct {
+	w       io.Writer
+	max     int
+	lines   int    // Complete lines passed through
+	held    []byte // Line max, kept until we know whether output overflows
+	dropped int    // Lines after held, counting a trailing partial line
+	partial bool   // The last dropped line has no newline yet
+}
+
+// NewLineLimitWriter returns a writer that keeps output to maxLines lines
+// (at least 1).
+func NewLineLimitWriter(w io.Writer, maxLines int) *LineLimitWriter {
+	return &LineLimitWriter{w: w, max: max(maxLines, 1)}
+}
+
+// Write passes lines through until the limit and counts the rest.
+// Returns len(p) on success so callers see a full write.
+func (l *LineLimitWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		line, rest, hasNewline := bytes.Cut(p, []byte("\n"))
+		if hasNewline {
+			line = p[:len(line)+1]
+		}
+		p = rest
+
+		switch {
+		case l.lines < l.max-1:
+			if _, err := l.w.Write(line); err != nil {
+				return 0, err
+			}
+			if hasNewline {
+				l.lines++
+			}
+		case l.dropped == 0 && !l.partial && !bytes.HasSuffix(l.held, []byte("\n")):
+			l.held = append(l.held, line...)
+		default:
+			if !l.partial {
+				l.dropped++
+			}
+			l.partial = !hasNewline
+		}
+	}
+	return n, nil
+}
+
+// Dropped returns how many lines were (or will be) left out.
+func (l *LineLimitWriter) Dropped() int {
+	if l.dropped == 0 {
+		return 0
+	}
+	return l.dropped + 1 // The held line gives way to the summary
+}
+
+// Close writes the held last line, or the summary when lines were dropped.
+func (l *LineLimitWriter) Close() error {
+	var err error
+	if dropped := l.Dropped(); dropped > 0 {
+		_, err = fmt.Fprintf(l.w, "… %d more lines not shown\n", dropped)
+	} else if len(l.held) > 0 {
+		_, err = l.w.Write(l.held)
+	}
+	l.held = nil
+	return err
+}
+
+// PageWriter passes through only lines offset to offset+limit-1 (counting
+// from 0) of what is written and counts every line, so a viewport can show
+// one page of an enormous rendering without holding the rest.
+//
+// Renderers that check Visible before formatting a line, as TreeRenderer
+// does, skip the work for lines off the page; see RenderPage.
+type PageWriter struct {
+	w       io.Writer
+	offset  int
+	limit   int  // <= 0 means no limit
+	line    int  // Index of the line being written
+	partial bool // The current line has text but no newline yet
+}
+
+// NewPageWriter returns a writer that keeps limit lines starting at line
+// offset. A limit <= 0 keeps every line from offset on.
+func NewPageWriter(w io.Writer, offset, limit int) *PageWriter {
+	return &PageWriter{w: w, offset: max(offset, 0), limit: limit}
+}
+
+// Visible reports whether the line being written is on the page.
+func (p *PageWriter) Visible() bool {
+	return p.line >= p.offset && (p.limit <= 0 || p.line < p.offset+p.limit)
+}
+
+// Write passes the page's lines through and counts the rest.
+// Returns len(p) on success so callers see a full write.
+func (p *PageWriter) Write(b []byte) (int, error) {
+	n := len(b)
+	for len(b) > 0 {
+		line, rest, hasNewline := bytes.Cut(b, []byte("\n"))
+		if hasNewline {
+			line = b[:len(line)+1]
+		}
+		b = rest
+
+		if p.Visible() {
+			if _, err := p.w.Write(line); err != nil {
+				return 0, err
+			}
+		}
+		if hasNewline {
+			p.line++
+			p.partial = false
+		} else {
+			p.partial = true
+		}
+	}
+	return n, nil
+}
+
+// Lines returns how many lines were written, on the page or not, counting a
+// trailing line without a newline.
+func (p *PageWriter) Lines() int {
+	if p.partial {
+		return p.line + 1
+	}
+	return p.line
+}
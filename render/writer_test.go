@@ -0,0 +1,160 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/diff-viz/diff"
+)
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"plain", "plain"},
+		{"\033[32m+10\033[0m", "+10"},
+		{"\033[38;5;8mfile.go\033[0m │ x", "file.go │ x"},
+		{"\033]0;title\007text", "text"},
+		{"\033]8;;http://x\033\\link", "link"},
+	}
+
+	for _, tt := range tests {
+		if got := StripANSI(tt.input); got != tt.want {
+			t.Errorf("StripANSI(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestStripANSIWriter_SplitSequence(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewStripANSIWriter(&buf)
+
+	// Escape sequence split across writes
+	for _, chunk := range []string{"a\033[3", "2mb\033", "[0mc"} {
+		n, err := w.Write([]byte(chunk))
+		if err != nil || n != len(chunk) {
+			t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+		}
+	}
+
+	if got := buf.String(); got != "abc" {
+		t.Errorf("got %q, want %q", got, "abc")
+	}
+}
+
+func TestStripANSIWriter_Renderer(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewTreeRenderer(NewStripANSIWriter(&buf), true)
+	r.Render(&diff.DiffStats{
+		Files:      []diff.FileStat{{Path: "src/main.go", Additions: 3}},
+		TotalAdd:   3,
+		TotalFiles: 1,
+	})
+
+	got := buf.String()
+	if strings.Contains(got, "\033") {
+		t.Errorf("expected no escape sequences, got %q", got)
+	}
+	if !strings.Contains(got, "main.go +3") {
+		t.Errorf("expected plain stats in output, got %q", got)
+	}
+}
+
+func TestLineLimitWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		max    int
+		writes []string
+		want   string
+	}{
+		{"under limit", 3, []string{"a\nb\n"}, "a\nb\n"},
+		{"exactly at limit", 3, []string{"a\nb\nc\n"}, "a\nb\nc\n"},
+		{"over limit", 3, []string{"a\nb\nc\nd\ne\n"}, "a\nb\n… 3 more lines not shown\n"},
+		{"split writes", 2, []string{"a", "\nb", "\nc"}, "a\n… 2 more lines not shown\n"},
+		{"trailing partial line", 2, []string{"a\nb"}, "a\nb"},
+		{"limit of one", 1, []string{"a\nb\n"}, "… 2 more lines not shown\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			lw := NewLineLimitWriter(&buf, tt.max)
+			for _, s := range tt.writes {
+				lw.Write([]byte(s))
+			}
+			lw.Close()
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineLimitWriter_Renderer(t *testing.T) {
+	stats := &diff.DiffStats{TotalFiles: 40, TotalAdd: 40}
+	for i := range 40 {
+		stats.Files = append(stats.Files, diff.FileStat{Path: fmt.Sprintf("dir%d/file.go", i), Additions: 1})
+	}
+
+	var buf bytes.Buffer
+	lw := NewLineLimitWriter(&buf, 10)
+	NewTreeRenderer(lw, false).Render(stats)
+	lw.Close()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 10 {
+		t.Fatalf("got %d lines, want 10:\n%s", len(lines), buf.String())
+	}
+	if want := "… 73 more lines not shown"; lines[9] != want {
+		t.Errorf("last line = %q, want %q", lines[9], want)
+	}
+}
+
+func TestPageWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewPageWriter(&buf, 1, 2)
+	for _, chunk := range []string{"a\nb", "\nc\n", "d\ne"} {
+		w.Write([]byte(chunk))
+	}
+
+	if got, want := buf.String(), "b\nc\n"; got != want {
+		t.Errorf("page = %q, want %q", got, want)
+	}
+	if got := w.Lines(); got != 5 {
+		t.Errorf("Lines() = %d, want 5", got)
+	}
+}
+
+func TestRenderPage_MatchesFullRender(t *testing.T) {
+	stats := &diff.DiffStats{
+		Files: []diff.FileStat{
+			{Path: "src/a.go", Additions: 50, Deletions: 3},
+			{Path: "src/lib/b.go", Additions: 40},
+			{Path: "src/lib/c.go", Additions: 4},
+			{Path: "docs/c.md", Deletions: 7},
+		},
+		TotalFiles: 4, TotalAdd: 94, TotalDel: 10,
+	}
+	tree := func(w io.Writer) Renderer { return NewTreeRenderer(w, true) }
+
+	var full bytes.Buffer
+	tree(&full).Render(stats)
+	lines := strings.SplitAfter(full.String(), "\n")
+	lines = lines[:len(lines)-1] // SplitAfter leaves "" after the final newline
+
+	for offset := 0; offset <= len(lines); offset++ {
+		var page bytes.Buffer
+		total := RenderPage(&page, stats, offset, 3, tree)
+		if total != len(lines) {
+			t.Fatalf("RenderPage(offset %d) total = %d, want %d", offset, total, len(lines))
+		}
+		want := strings.Join(lines[offset:min(offset+3, len(lines))], "")
+		if page.String() != want {
+			t.Errorf("RenderPage(offset %d) = %q, want %q", offset, page.String(), want)
+		}
+	}
+}